@@ -0,0 +1,118 @@
+// Command backfill-phone-numbers rewrites the phone numbers of existing users and clients to
+// E.164. Rows whose numbers collide after normalization, or that cannot be parsed, are left
+// untouched and reported so they can be merged or fixed by hand.
+//
+//	go run ./cmd/backfill-phone-numbers -dry-run
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"gorm.io/gorm"
+
+	"alfredo/ruu-properties/config"
+	"alfredo/ruu-properties/pkg/helpers"
+)
+
+type phoneRow struct {
+	UUID        string
+	PhoneNumber string
+}
+
+type backfillReport struct {
+	updated    int
+	unchanged  int
+	invalid    []phoneRow
+	collisions map[string][]phoneRow
+}
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report the changes without writing them")
+	flag.Parse()
+
+	db := config.InitDatabasePostgres()
+	region := helpers.PhoneDefaultRegion()
+
+	failed := false
+	for _, table := range []string{"users", "clients"} {
+		report, err := backfillTable(db, table, region, *dryRun)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", table, err)
+			failed = true
+			continue
+		}
+		printReport(table, report)
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// backfillTable normalizes every phone number of a table, soft-deleted rows included because
+// they still take part in the unique index.
+func backfillTable(db *gorm.DB, table string, region string, dryRun bool) (*backfillReport, error) {
+	var rows []phoneRow
+	if err := db.Table(table).Select("uuid", "phone_number").Order("created_at asc").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch rows: %w", err)
+	}
+
+	report := &backfillReport{collisions: make(map[string][]phoneRow)}
+	groups := make(map[string][]phoneRow)
+	for _, row := range rows {
+		normalized, err := helpers.NormalizePhoneNumber(row.PhoneNumber, region)
+		if err != nil {
+			report.invalid = append(report.invalid, row)
+			continue
+		}
+		groups[normalized] = append(groups[normalized], row)
+	}
+
+	for normalized, group := range groups {
+		if len(group) > 1 {
+			report.collisions[normalized] = group
+			continue
+		}
+
+		row := group[0]
+		if row.PhoneNumber == normalized {
+			report.unchanged++
+			continue
+		}
+
+		if !dryRun {
+			err := db.Table(table).Where("uuid = ?", row.UUID).Update("phone_number", normalized).Error
+			if err != nil {
+				return nil, fmt.Errorf("failed to update %s: %w", row.UUID, err)
+			}
+		}
+		report.updated++
+	}
+
+	return report, nil
+}
+
+func printReport(table string, report *backfillReport) {
+	fmt.Printf("%s: %d updated, %d already normalized, %d invalid, %d collisions\n",
+		table, report.updated, report.unchanged, len(report.invalid), len(report.collisions))
+
+	for _, row := range report.invalid {
+		fmt.Printf("  invalid   %s %q\n", row.UUID, row.PhoneNumber)
+	}
+
+	normalized := make([]string, 0, len(report.collisions))
+	for phone := range report.collisions {
+		normalized = append(normalized, phone)
+	}
+	sort.Strings(normalized)
+
+	for _, phone := range normalized {
+		fmt.Printf("  collision %s\n", phone)
+		for _, row := range report.collisions[phone] {
+			fmt.Printf("    %s %q\n", row.UUID, row.PhoneNumber)
+		}
+	}
+}
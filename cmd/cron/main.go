@@ -0,0 +1,52 @@
+// Command cron runs the scheduled maintenance jobs once and exits, so it can be driven by the
+// system crontab or a Kubernetes CronJob. The trash purge hard-deletes clients, properties and
+// features that have been soft-deleted for longer than trash.retention_days, and the KYC refresh
+// takes verified clients whose documents expired since the last run out of the verified status.
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"alfredo/ruu-properties/config"
+	"alfredo/ruu-properties/pkg/repositories"
+	"alfredo/ruu-properties/pkg/services"
+)
+
+const defaultTrashRetentionDays = 30
+
+func main() {
+	retentionDays := trashRetentionDays()
+	fmt.Printf("purging records deleted more than %d days ago\n", retentionDays)
+
+	db := config.InitDatabasePostgres()
+	trashService := services.NewTrashService(repositories.NewTrashRepository(db))
+
+	results, err := trashService.Purge(retentionDays)
+	for _, result := range results {
+		fmt.Printf("%s: %d purged, %d still referenced\n", result.Table, result.Purged, result.Skipped)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	documentService := services.NewClientDocumentService(repositories.NewClientDocumentRepository(db))
+	refreshed, err := documentService.RefreshExpired()
+	fmt.Printf("kyc: %d clients with expired documents\n", refreshed)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// trashRetentionDays reads trash.retention_days, falling back to the default when it is unset
+// or not a positive number
+func trashRetentionDays() int {
+	days, err := strconv.Atoi(config.TrashRetentionDays)
+	if err != nil || days < 1 {
+		return defaultTrashRetentionDays
+	}
+	return days
+}
@@ -30,4 +30,23 @@ var (
 	IsRunningCron         = GetValue("isRunningCron", "false")
 	WhatsAppUrl           = GetValue("whatsappUrl", "")
 	WhatsAppToken         = GetValue("whatsappToken", "")
+	PhoneDefaultRegion    = GetValue("phone.default_region", "")
+	TrashRetentionDays    = GetValue("trash.retention_days", "")
+	KycLeasePolicy        = GetValue("kyc.lease_policy", "")
+	RegistrationEnabled   = GetValue("registration.enabled", "")
+	RegistrationRole      = GetValue("registration.default_role", "")
+	MailTransport         = GetValue("mail.transport", "")
+	MailFrom              = GetValue("mail.from", "")
+	MailSmtpHost          = GetValue("mail.smtp.host", "")
+	MailSmtpPort          = GetValue("mail.smtp.port", "")
+	MailSmtpUser          = GetValue("mail.smtp.username", "")
+	MailSmtpPass          = GetValue("mail.smtp.password", "")
+	MailOutboxDir         = GetValue("mail.outbox_dir", "")
+	PasswordResetUrl      = GetValue("password_reset.url", "")
+	PasswordResetTtl      = GetValue("password_reset.ttl_minutes", "")
+	EmailVerifyRequired   = GetValue("email_verification.required", "")
+	EmailVerifyUrl        = GetValue("email_verification.url", "")
+	EmailVerifyTtl        = GetValue("email_verification.ttl_minutes", "")
+	TwoFactorIssuer       = GetValue("two_factor.issuer", "")
+	TwoFactorKey          = GetValue("two_factor.encryption_key", "")
 )
@@ -87,8 +87,9 @@ func (a *Application) CloseConnectionDatabase() {
 
 func (a *Application) CorsMiddleware() fiber.Handler {
 	return fiberCors.New(fiberCors.Config{
-		AllowOrigins: "*",
-		AllowMethods: "GET, POST, PUT, DELETE, OPTIONS",
-		AllowHeaders: "Origin, Content-Type, Accept, Authorization, Lang, lang, Accept-Encoding",
+		AllowOrigins:  "*",
+		AllowMethods:  "GET, POST, PUT, PATCH, DELETE, OPTIONS",
+		AllowHeaders:  "Origin, Content-Type, Accept, Authorization, Lang, lang, Accept-Encoding, If-Match",
+		ExposeHeaders: "ETag",
 	})
 }
@@ -43,7 +43,8 @@ func InitDatabasePostgres() *gorm.DB {
 
 		// Open the database connection
 		db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-			Logger: logger.Default.LogMode(logger.Silent),
+			Logger:  logger.Default.LogMode(logger.Silent),
+			NowFunc: NowMicro,
 		})
 		if err != nil {
 			panic(err)
@@ -70,3 +71,9 @@ func InitDatabasePostgres() *gorm.DB {
 
 	return databasePostgresInstance
 }
+
+// NowMicro is the clock used by GORM. Postgres keeps timestamps to the microsecond, so values
+// written by the app read back unchanged, which the ETags derived from updated_at rely on.
+func NowMicro() time.Time {
+	return time.Now().Truncate(time.Microsecond)
+}
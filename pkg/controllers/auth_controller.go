@@ -1,40 +1,84 @@
 package controllers
 
 import (
+	"errors"
+
 	"github.com/gofiber/fiber/v2"
 
 	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/helpers"
+	"alfredo/ruu-properties/pkg/middleware/jwt"
+	"alfredo/ruu-properties/pkg/middleware/permission"
+	"alfredo/ruu-properties/pkg/models"
 	"alfredo/ruu-properties/pkg/services"
 )
 
 type AuthController interface {
 	Login(c *fiber.Ctx) error
 	Logout(c *fiber.Ctx) error
+	LogoutAll(c *fiber.Ctx) error
 	RefreshToken(c *fiber.Ctx) error
+	ForgotPassword(c *fiber.Ctx) error
+	ResetPassword(c *fiber.Ctx) error
+	VerifyEmail(c *fiber.Ctx) error
+	ResendVerification(c *fiber.Ctx) error
+	GetSessions(c *fiber.Ctx) error
+	RevokeSession(c *fiber.Ctx) error
+	RevokeUserSessions(c *fiber.Ctx) error
+	VerifyTwoFactor(c *fiber.Ctx) error
+	GetTwoFactor(c *fiber.Ctx) error
+	EnrollTwoFactor(c *fiber.Ctx) error
+	ConfirmTwoFactor(c *fiber.Ctx) error
+	DisableTwoFactor(c *fiber.Ctx) error
+	RegenerateRecoveryCodes(c *fiber.Ctx) error
 	Router(router fiber.Router)
 }
 
 type authControllerImpl struct {
-	authService  services.AuthService
-	redisService services.RedisService
-	jwtService   services.JwtService
+	authService    services.AuthService
+	redisService   services.RedisService
+	jwtService     services.JwtService
+	userService    services.UserService
+	sessionService services.SessionService
+	verification   services.EmailVerificationService
+	twoFactor      services.TwoFactorService
 }
 
 func (a *authControllerImpl) Router(router fiber.Router) {
 	router.Post("/login", a.Login)
-	router.Post("/logout", a.Logout)
 	router.Post("/refresh-token", a.RefreshToken)
+	router.Post("/forgot-password", a.ForgotPassword)
+	router.Post("/reset-password", a.ResetPassword)
+	router.Post("/verify-email", a.VerifyEmail)
+	router.Post("/resend-verification", a.ResendVerification)
+	router.Post("/2fa/verify", a.VerifyTwoFactor)
+
+	withMiddleware := router.Use(jwt.JwtMiddleware(a.userService, a.redisService))
+	{
+		withMiddleware.Post("/logout", a.Logout)
+		withMiddleware.Post("/logout-all", a.LogoutAll)
+		withMiddleware.Get("/sessions", a.GetSessions)
+		withMiddleware.Delete("/sessions/:id", a.RevokeSession)
+		withMiddleware.Delete("/users/:id/sessions", permission.RequirePermission(models.PermissionUsersManage), a.RevokeUserSessions)
+		withMiddleware.Get("/2fa", a.GetTwoFactor)
+		withMiddleware.Post("/2fa/enroll", a.EnrollTwoFactor)
+		withMiddleware.Post("/2fa/confirm", a.ConfirmTwoFactor)
+		withMiddleware.Post("/2fa/disable", a.DisableTwoFactor)
+		withMiddleware.Post("/2fa/recovery-codes", a.RegenerateRecoveryCodes)
+	}
 }
 
 // Login godoc
 // @Summary User login
-// @Description Authenticate a user and return JWT tokens
+// @Description Authenticate a user and return JWT tokens. Deactivated users, and unverified users when verification is required, get a 403. Users with two-factor authentication get a challenge token instead, to complete at /auth/2fa/verify.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param request body dtos.LoginRequest true "Login credentials"
 // @Success 200 {object} dtos.SuccessResponse{data=dtos.LoginResponse}
 // @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 403 {object} dtos.ErrorResponseDTO
+// @Failure 429 {object} dtos.ErrorResponseDTO
 // @Failure 500 {object} dtos.ErrorResponseDTO
 // @Router /auth/login [post]
 func (a *authControllerImpl) Login(c *fiber.Ctx) error {
@@ -49,13 +93,19 @@ func (a *authControllerImpl) Login(c *fiber.Ctx) error {
 		})
 	}
 
-	response, err := a.authService.Login(request)
+	response, err := a.authService.Login(request, sessionClient(c))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, services.ErrAccountDeactivated) || errors.Is(err, services.ErrEmailNotVerified) {
+			status = fiber.StatusForbidden
+		} else if errors.Is(err, services.ErrTwoFactorLocked) {
+			status = fiber.StatusTooManyRequests
+		}
+		return c.Status(status).JSON(
 			dtos.ErrorResponseDTO{
 				Success: false,
 				Message: err.Error(),
-				Code:    fiber.StatusInternalServerError,
+				Code:    status,
 				Errors:  err.Error(),
 			},
 		)
@@ -68,42 +118,786 @@ func (a *authControllerImpl) Login(c *fiber.Ctx) error {
 	})
 }
 
-// / Logout godoc
+// Logout godoc
 // @Summary User logout
-// @Description Invalidate user's refresh token
+// @Description Revoke the access token of the request and, when given, the refresh token of the same login
 // @Tags auth
 // @Accept json
 // @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param request body dtos.LogoutRequest false "Refresh token to revoke"
 // @Success 200 {object} dtos.SuccessResponse
 // @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
 // @Failure 500 {object} dtos.ErrorResponseDTO
 // @Router /auth/logout [post]
 func (a *authControllerImpl) Logout(c *fiber.Ctx) error {
-	panic("unimplemented")
+	var request dtos.LogoutRequest
+
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+				Success: false,
+				Message: "Invalid request body",
+				Code:    fiber.StatusBadRequest,
+				Errors:  err.Error(),
+			})
+		}
+	}
+
+	request.AccessToken, _ = c.Locals("token").(string)
+	request.UserUuid, _ = c.Locals("user_uuid").(string)
+
+	if err := a.authService.Logout(request); err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidToken) {
+			status = fiber.StatusUnauthorized
+		}
+		return c.Status(status).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: err.Error(),
+			Code:    status,
+			Errors:  err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Logout successful",
+		Data:    nil,
+	})
+}
+
+// LogoutAll godoc
+// @Summary Logout everywhere
+// @Description Revoke every access and refresh token of the signed in user, on every device
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} dtos.SuccessResponse
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /auth/logout-all [post]
+func (a *authControllerImpl) LogoutAll(c *fiber.Ctx) error {
+	userUuid, _ := c.Locals("user_uuid").(string)
+
+	if err := a.authService.LogoutAll(userUuid); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: err.Error(),
+			Code:    fiber.StatusInternalServerError,
+			Errors:  err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Logged out on every device",
+		Data:    nil,
+	})
 }
 
 // RefreshToken godoc
 // @Summary Refresh access token
-// @Description Get a new access token using refresh token
+// @Description Exchange a refresh token for a new access and refresh token pair. Every refresh token works once; presenting a used one again signs out every device of that login.
 // @Tags auth
 // @Accept json
 // @Produce json
+// @Param request body dtos.RefreshTokenRequest true "Refresh token"
 // @Success 200 {object} dtos.SuccessResponse{data=dtos.GenerateTokenResponse}
 // @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
 // @Failure 500 {object} dtos.ErrorResponseDTO
 // @Router /auth/refresh-token [post]
 func (a *authControllerImpl) RefreshToken(c *fiber.Ctx) error {
-	panic("unimplemented")
+	var request dtos.RefreshTokenRequest
+
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  err.Error(),
+		})
+	}
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	response, err := a.authService.RefreshToken(request, sessionClient(c))
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidToken) || errors.Is(err, services.ErrRevokedToken) || errors.Is(err, services.ErrTokenReused) {
+			status = fiber.StatusUnauthorized
+		}
+		return c.Status(status).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: err.Error(),
+			Code:    status,
+			Errors:  err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Token refreshed successfully",
+		Data:    response,
+	})
+}
+
+// ForgotPassword godoc
+// @Summary Request a password reset
+// @Description Mail a single-use password reset link to the account of the email. The response is the same whether or not the email is registered.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dtos.ForgotPasswordRequest true "Email of the account"
+// @Success 200 {object} dtos.SuccessResponse
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /auth/forgot-password [post]
+func (a *authControllerImpl) ForgotPassword(c *fiber.Ctx) error {
+	var request dtos.ForgotPasswordRequest
+
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  err.Error(),
+		})
+	}
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	if err := a.authService.ForgotPassword(request); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: err.Error(),
+			Code:    fiber.StatusInternalServerError,
+			Errors:  err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "If the email is registered, a password reset link has been sent to it",
+	})
+}
+
+// ResetPassword godoc
+// @Summary Reset a password
+// @Description Choose a new password with the token of a reset link. The token works once, and every session of the account ends.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dtos.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} dtos.SuccessResponse
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /auth/reset-password [post]
+func (a *authControllerImpl) ResetPassword(c *fiber.Ctx) error {
+	var request dtos.ResetPasswordRequest
+
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  err.Error(),
+		})
+	}
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	if err := a.authService.ResetPassword(request); err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidResetToken) {
+			status = fiber.StatusBadRequest
+		}
+		return c.Status(status).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: err.Error(),
+			Code:    status,
+			Errors:  err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Password reset successfully",
+	})
+}
+
+// VerifyEmail godoc
+// @Summary Verify an email address
+// @Description Confirm the email address of an account with the token of a verification link. The token works once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dtos.VerifyEmailRequest true "Verification token"
+// @Success 200 {object} dtos.SuccessResponse
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /auth/verify-email [post]
+func (a *authControllerImpl) VerifyEmail(c *fiber.Ctx) error {
+	var request dtos.VerifyEmailRequest
+
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  err.Error(),
+		})
+	}
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	if err := a.verification.Verify(request); err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidVerificationToken) {
+			status = fiber.StatusBadRequest
+		}
+		return c.Status(status).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: err.Error(),
+			Code:    status,
+			Errors:  err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Email verified successfully",
+	})
+}
+
+// ResendVerification godoc
+// @Summary Resend the verification mail
+// @Description Mail a new verification link to the account of the email, the links sent before stop working. The response is the same whether or not the email is registered or already verified.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dtos.ResendVerificationRequest true "Email of the account"
+// @Success 200 {object} dtos.SuccessResponse
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /auth/resend-verification [post]
+func (a *authControllerImpl) ResendVerification(c *fiber.Ctx) error {
+	var request dtos.ResendVerificationRequest
+
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  err.Error(),
+		})
+	}
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	if err := a.verification.Resend(request); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: err.Error(),
+			Code:    fiber.StatusInternalServerError,
+			Errors:  err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "If the email is registered and not verified yet, a verification link has been sent to it",
+	})
+}
+
+// GetSessions godoc
+// @Summary List active sessions
+// @Description List the signed in user's logins that are neither logged out nor expired, most recently used first. The session of the request is flagged as current.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} dtos.SuccessResponse{data=[]dtos.SessionResponse}
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /auth/sessions [get]
+func (a *authControllerImpl) GetSessions(c *fiber.Ctx) error {
+	userUuid, _ := c.Locals("user_uuid").(string)
+	token, _ := c.Locals("token").(string)
+
+	sessions, err := a.sessionService.GetAll(userUuid, token)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: err.Error(),
+			Code:    fiber.StatusInternalServerError,
+			Errors:  err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Sessions retrieved successfully",
+		Data:    sessions,
+	})
+}
+
+// RevokeSession godoc
+// @Summary Revoke a session
+// @Description Sign one of the user's own sessions out remotely, e.g. on a shared tablet. Every token of the session stops working.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Session ID"
+// @Success 200 {object} dtos.SuccessResponse
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /auth/sessions/{id} [delete]
+func (a *authControllerImpl) RevokeSession(c *fiber.Ctx) error {
+	uuid := c.Params("id")
+	if !helpers.CheckLengthUUID(uuid) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid session ID",
+			Code:    fiber.StatusBadRequest,
+		})
+	}
+	userUuid, _ := c.Locals("user_uuid").(string)
+
+	if err := a.sessionService.Revoke(userUuid, uuid); err != nil {
+		status := fiber.StatusInternalServerError
+		if err.Error() == "session not found" {
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: err.Error(),
+			Code:    status,
+			Errors:  err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Session revoked successfully",
+		Data:    nil,
+	})
+}
+
+// RevokeUserSessions godoc
+// @Summary Revoke every session of a user
+// @Description Requires the users:manage permission. Signs the user out on every device, including logins from before sessions were recorded.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "User ID"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.SessionRevokeAllResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 403 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /auth/users/{id}/sessions [delete]
+func (a *authControllerImpl) RevokeUserSessions(c *fiber.Ctx) error {
+	userUuid := c.Params("id")
+	if !helpers.CheckLengthUUID(userUuid) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid user ID",
+			Code:    fiber.StatusBadRequest,
+		})
+	}
+
+	if _, err := a.userService.FindUserByUuid(userUuid); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "user not found",
+			Code:    fiber.StatusNotFound,
+			Errors:  "user not found",
+		})
+	}
+
+	response, err := a.sessionService.RevokeAll(userUuid)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: err.Error(),
+			Code:    fiber.StatusInternalServerError,
+			Errors:  err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Sessions revoked successfully",
+		Data:    response,
+	})
+}
+
+// VerifyTwoFactor godoc
+// @Summary Complete a two-factor login
+// @Description Exchange the challenge token of a login and a code of the authenticator app, or an unused recovery code, for JWT tokens. A challenge lasts five minutes and five wrong codes; ten wrong codes within fifteen minutes lock the account out of two-factor logins for a while.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dtos.TwoFactorVerifyRequest true "Challenge token and code"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.LoginResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 403 {object} dtos.ErrorResponseDTO
+// @Failure 429 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /auth/2fa/verify [post]
+func (a *authControllerImpl) VerifyTwoFactor(c *fiber.Ctx) error {
+	var request dtos.TwoFactorVerifyRequest
+
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  err.Error(),
+		})
+	}
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	response, err := a.authService.VerifyTwoFactor(request, sessionClient(c))
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidChallenge) || errors.Is(err, services.ErrInvalidTwoFactorCode) {
+			status = fiber.StatusUnauthorized
+		} else if errors.Is(err, services.ErrAccountDeactivated) {
+			status = fiber.StatusForbidden
+		} else if errors.Is(err, services.ErrTwoFactorLocked) {
+			status = fiber.StatusTooManyRequests
+		}
+		return c.Status(status).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: err.Error(),
+			Code:    status,
+			Errors:  err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Login successful",
+		Data:    response,
+	})
+}
+
+// GetTwoFactor godoc
+// @Summary Two-factor authentication status
+// @Description Whether the signed in user has two-factor authentication, whether their role requires it, and how many recovery codes are left
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.TwoFactorStatusResponse}
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /auth/2fa [get]
+func (a *authControllerImpl) GetTwoFactor(c *fiber.Ctx) error {
+	user, _ := c.Locals("user").(*models.User)
+
+	response, err := a.twoFactor.Status(user)
+	if err != nil {
+		return twoFactorErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Two-factor authentication status retrieved successfully",
+		Data:    response,
+	})
+}
+
+// EnrollTwoFactor godoc
+// @Summary Enroll two-factor authentication
+// @Description Create a TOTP secret for the signed in user. Add it to an authenticator app with the provisioning URI, then confirm it with a code. Enrolling again before confirming replaces the secret.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.TwoFactorEnrollResponse}
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 409 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /auth/2fa/enroll [post]
+func (a *authControllerImpl) EnrollTwoFactor(c *fiber.Ctx) error {
+	user, _ := c.Locals("user").(*models.User)
+
+	response, err := a.twoFactor.Enroll(user)
+	if err != nil {
+		return twoFactorErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Two-factor authentication enrolled, confirm it with a code",
+		Data:    response,
+	})
+}
+
+// ConfirmTwoFactor godoc
+// @Summary Confirm two-factor authentication
+// @Description Turn two-factor authentication on with a code of the enrolled secret. The response lists the recovery codes, they are shown this once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param request body dtos.TwoFactorCodeRequest true "Code of the authenticator app"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.TwoFactorRecoveryCodesResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 409 {object} dtos.ErrorResponseDTO
+// @Failure 429 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /auth/2fa/confirm [post]
+func (a *authControllerImpl) ConfirmTwoFactor(c *fiber.Ctx) error {
+	var request dtos.TwoFactorCodeRequest
+
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  err.Error(),
+		})
+	}
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	user, _ := c.Locals("user").(*models.User)
+
+	response, err := a.twoFactor.Confirm(user, request)
+	if err != nil {
+		return twoFactorErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Two-factor authentication enabled",
+		Data:    response,
+	})
+}
+
+// DisableTwoFactor godoc
+// @Summary Disable two-factor authentication
+// @Description Turn two-factor authentication off with a code of the authenticator app or a recovery code. Refused while the role of the user requires it.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param request body dtos.TwoFactorCodeRequest true "Code of the authenticator app or a recovery code"
+// @Success 200 {object} dtos.SuccessResponse
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 403 {object} dtos.ErrorResponseDTO
+// @Failure 409 {object} dtos.ErrorResponseDTO
+// @Failure 429 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /auth/2fa/disable [post]
+func (a *authControllerImpl) DisableTwoFactor(c *fiber.Ctx) error {
+	var request dtos.TwoFactorCodeRequest
+
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  err.Error(),
+		})
+	}
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	user, _ := c.Locals("user").(*models.User)
+
+	if err := a.twoFactor.Disable(user, request); err != nil {
+		return twoFactorErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Two-factor authentication disabled",
+		Data:    nil,
+	})
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate recovery codes
+// @Description Replace the recovery codes of the signed in user, the codes handed out before stop working. Takes a code of the authenticator app.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param request body dtos.TwoFactorCodeRequest true "Code of the authenticator app"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.TwoFactorRecoveryCodesResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 409 {object} dtos.ErrorResponseDTO
+// @Failure 429 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /auth/2fa/recovery-codes [post]
+func (a *authControllerImpl) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	var request dtos.TwoFactorCodeRequest
+
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  err.Error(),
+		})
+	}
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	user, _ := c.Locals("user").(*models.User)
+
+	response, err := a.twoFactor.RegenerateRecoveryCodes(user, request)
+	if err != nil {
+		return twoFactorErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Recovery codes regenerated",
+		Data:    response,
+	})
+}
+
+// twoFactorErrorResponse maps the errors of the two-factor service to their status
+func twoFactorErrorResponse(c *fiber.Ctx, err error) error {
+	status := fiber.StatusConflict
+	switch {
+	case errors.Is(err, services.ErrInvalidTwoFactorCode):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, services.ErrTwoFactorLocked):
+		status = fiber.StatusTooManyRequests
+	case err.Error() == "two-factor authentication is required for your role":
+		status = fiber.StatusForbidden
+	case err.Error() == "please try again later":
+		status = fiber.StatusInternalServerError
+	}
+	return c.Status(status).JSON(dtos.ErrorResponseDTO{
+		Success: false,
+		Message: err.Error(),
+		Code:    status,
+		Errors:  err.Error(),
+	})
+}
+
+// sessionClient describes the device of the request for its session
+func sessionClient(c *fiber.Ctx) dtos.SessionClient {
+	return dtos.SessionClient{
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		IPAddress: c.IP(),
+	}
 }
 
 func NewAuthController(
 	authService services.AuthService,
 	redisService services.RedisService,
 	jwtService services.JwtService,
+	userService services.UserService,
+	sessionService services.SessionService,
+	verification services.EmailVerificationService,
+	twoFactor services.TwoFactorService,
 ) AuthController {
 	return &authControllerImpl{
-		authService:  authService,
-		redisService: redisService,
-		jwtService:   jwtService,
+		authService:    authService,
+		redisService:   redisService,
+		jwtService:     jwtService,
+		userService:    userService,
+		sessionService: sessionService,
+		verification:   verification,
+		twoFactor:      twoFactor,
 	}
 }
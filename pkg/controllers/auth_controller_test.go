@@ -10,9 +10,11 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 
 	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/services"
 	"alfredo/ruu-properties/pkg/testutils"
 	"alfredo/ruu-properties/pkg/testutils/mocks"
 )
@@ -24,6 +26,10 @@ type AuthControllerTestSuite struct {
 	mockAuthService  *mocks.MockAuthService
 	mockRedisService *mocks.MockRedisService
 	mockJWTService   *mocks.MockJWTService
+	mockUserService  *mocks.MockUserService
+	mockSession      *mocks.MockSessionService
+	mockVerification *mocks.MockEmailVerificationService
+	mockTwoFactor    *mocks.MockTwoFactorService
 }
 
 func (suite *AuthControllerTestSuite) SetupTest() {
@@ -31,11 +37,19 @@ func (suite *AuthControllerTestSuite) SetupTest() {
 	suite.mockAuthService = new(mocks.MockAuthService)
 	suite.mockRedisService = new(mocks.MockRedisService)
 	suite.mockJWTService = new(mocks.MockJWTService)
+	suite.mockUserService = new(mocks.MockUserService)
+	suite.mockSession = new(mocks.MockSessionService)
+	suite.mockVerification = new(mocks.MockEmailVerificationService)
+	suite.mockTwoFactor = new(mocks.MockTwoFactorService)
 
 	suite.authController = NewAuthController(
 		suite.mockAuthService,
 		suite.mockRedisService,
 		suite.mockJWTService,
+		suite.mockUserService,
+		suite.mockSession,
+		suite.mockVerification,
+		suite.mockTwoFactor,
 	)
 
 	// Setup routes - this was missing!
@@ -57,7 +71,7 @@ func (suite *AuthControllerTestSuite) TestLogin_Success() {
 		RefreshToken: "refresh_token",
 	}
 
-	suite.mockAuthService.On("Login", loginRequest).Return(expectedResponse, nil)
+	suite.mockAuthService.On("Login", loginRequest, mock.AnythingOfType("dtos.SessionClient")).Return(expectedResponse, nil)
 
 	// Act
 	reqBody, _ := json.Marshal(loginRequest)
@@ -105,7 +119,7 @@ func (suite *AuthControllerTestSuite) TestLogin_ServiceError() {
 		Password: "wrongpassword",
 	}
 
-	suite.mockAuthService.On("Login", loginRequest).Return(dtos.LoginResponse{}, errors.New("invalid credentials"))
+	suite.mockAuthService.On("Login", loginRequest, mock.AnythingOfType("dtos.SessionClient")).Return(dtos.LoginResponse{}, errors.New("invalid credentials"))
 
 	// Act
 	reqBody, _ := json.Marshal(loginRequest)
@@ -127,6 +141,335 @@ func (suite *AuthControllerTestSuite) TestLogin_ServiceError() {
 	suite.mockAuthService.AssertExpectations(suite.T())
 }
 
+func (suite *AuthControllerTestSuite) TestRefreshToken_Success() {
+	// Arrange
+	request := dtos.RefreshTokenRequest{RefreshToken: "refresh_token"}
+	expectedResponse := dtos.GenerateTokenResponse{
+		TokenType:    "Bearer",
+		ExpiresIn:    900,
+		AccessToken:  "new_access_token",
+		RefreshToken: "new_refresh_token",
+	}
+
+	suite.mockAuthService.On("RefreshToken", request, mock.AnythingOfType("dtos.SessionClient")).Return(expectedResponse, nil)
+
+	// Act
+	reqBody, _ := json.Marshal(request)
+	req := httptest.NewRequest("POST", "/auth/refresh-token", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := suite.app.Test(req)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+
+	var response dtos.SuccessResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+
+	assert.True(suite.T(), response.Success)
+	assert.Equal(suite.T(), "new_refresh_token", response.Data.(map[string]interface{})["refresh_token"])
+
+	suite.mockAuthService.AssertExpectations(suite.T())
+}
+
+func (suite *AuthControllerTestSuite) TestRefreshToken_MissingToken() {
+	// Act
+	req := httptest.NewRequest("POST", "/auth/refresh-token", strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := suite.app.Test(req)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusBadRequest, resp.StatusCode)
+	suite.mockAuthService.AssertNotCalled(suite.T(), "RefreshToken", mock.Anything, mock.Anything)
+}
+
+func (suite *AuthControllerTestSuite) TestRefreshToken_Reused() {
+	// Arrange
+	request := dtos.RefreshTokenRequest{RefreshToken: "used_refresh_token"}
+	suite.mockAuthService.On("RefreshToken", request, mock.AnythingOfType("dtos.SessionClient")).Return(dtos.GenerateTokenResponse{}, services.ErrTokenReused)
+
+	// Act
+	reqBody, _ := json.Marshal(request)
+	req := httptest.NewRequest("POST", "/auth/refresh-token", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := suite.app.Test(req)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusUnauthorized, resp.StatusCode)
+
+	var response dtos.ErrorResponseDTO
+	json.NewDecoder(resp.Body).Decode(&response)
+
+	assert.False(suite.T(), response.Success)
+	assert.Equal(suite.T(), services.ErrTokenReused.Error(), response.Message)
+
+	suite.mockAuthService.AssertExpectations(suite.T())
+}
+
+func (suite *AuthControllerTestSuite) TestLogout_Unauthorized() {
+	for _, path := range []string{"/auth/logout", "/auth/logout-all"} {
+		// Act
+		req := httptest.NewRequest("POST", path, nil)
+		resp, err := suite.app.Test(req)
+
+		// Assert
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), fiber.StatusUnauthorized, resp.StatusCode)
+	}
+	suite.mockAuthService.AssertNotCalled(suite.T(), "Logout", mock.Anything)
+	suite.mockAuthService.AssertNotCalled(suite.T(), "LogoutAll", mock.Anything)
+}
+
+func (suite *AuthControllerTestSuite) TestForgotPassword_Success() {
+	// Arrange
+	request := dtos.ForgotPasswordRequest{Email: "unknown@example.com"}
+	suite.mockAuthService.On("ForgotPassword", request).Return(nil)
+
+	// Act
+	reqBody, _ := json.Marshal(request)
+	req := httptest.NewRequest("POST", "/auth/forgot-password", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := suite.app.Test(req)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+
+	suite.mockAuthService.AssertExpectations(suite.T())
+}
+
+func (suite *AuthControllerTestSuite) TestResetPassword_InvalidToken() {
+	// Arrange
+	request := dtos.ResetPasswordRequest{Token: "used_token", Password: "newpassword", ConfirmationPassword: "newpassword"}
+	suite.mockAuthService.On("ResetPassword", request).Return(services.ErrInvalidResetToken)
+
+	// Act
+	reqBody, _ := json.Marshal(request)
+	req := httptest.NewRequest("POST", "/auth/reset-password", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := suite.app.Test(req)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusBadRequest, resp.StatusCode)
+
+	var response dtos.ErrorResponseDTO
+	json.NewDecoder(resp.Body).Decode(&response)
+
+	assert.False(suite.T(), response.Success)
+	assert.Equal(suite.T(), services.ErrInvalidResetToken.Error(), response.Message)
+
+	suite.mockAuthService.AssertExpectations(suite.T())
+}
+
+func (suite *AuthControllerTestSuite) TestResetPassword_PasswordMismatch() {
+	// Act
+	reqBody, _ := json.Marshal(dtos.ResetPasswordRequest{Token: "token", Password: "newpassword", ConfirmationPassword: "otherpassword"})
+	req := httptest.NewRequest("POST", "/auth/reset-password", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := suite.app.Test(req)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusBadRequest, resp.StatusCode)
+	suite.mockAuthService.AssertNotCalled(suite.T(), "ResetPassword", mock.Anything)
+}
+
+func (suite *AuthControllerTestSuite) TestLogin_EmailNotVerified() {
+	// Arrange
+	request := dtos.LoginRequest{Email: "unverified@example.com", Password: "password123"}
+	suite.mockAuthService.On("Login", request, mock.AnythingOfType("dtos.SessionClient")).Return(dtos.LoginResponse{}, services.ErrEmailNotVerified)
+
+	// Act
+	reqBody, _ := json.Marshal(request)
+	req := httptest.NewRequest("POST", "/auth/login", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := suite.app.Test(req)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusForbidden, resp.StatusCode)
+
+	suite.mockAuthService.AssertExpectations(suite.T())
+}
+
+func (suite *AuthControllerTestSuite) TestVerifyEmail_InvalidToken() {
+	// Arrange
+	request := dtos.VerifyEmailRequest{Token: "expired_token"}
+	suite.mockVerification.On("Verify", request).Return(services.ErrInvalidVerificationToken)
+
+	// Act
+	reqBody, _ := json.Marshal(request)
+	req := httptest.NewRequest("POST", "/auth/verify-email", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := suite.app.Test(req)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusBadRequest, resp.StatusCode)
+
+	suite.mockVerification.AssertExpectations(suite.T())
+}
+
+func (suite *AuthControllerTestSuite) TestResendVerification_Success() {
+	// Arrange
+	request := dtos.ResendVerificationRequest{Email: "john@example.com"}
+	suite.mockVerification.On("Resend", request).Return(nil)
+
+	// Act
+	reqBody, _ := json.Marshal(request)
+	req := httptest.NewRequest("POST", "/auth/resend-verification", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := suite.app.Test(req)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+
+	suite.mockVerification.AssertExpectations(suite.T())
+}
+
+func (suite *AuthControllerTestSuite) TestLogin_TwoFactorChallenge() {
+	// Arrange
+	loginRequest := dtos.LoginRequest{
+		Email:    "test@example.com",
+		Password: "password123",
+	}
+
+	expectedResponse := dtos.LoginResponse{
+		TwoFactorRequired: true,
+		ChallengeToken:    "challenge_token",
+	}
+
+	suite.mockAuthService.On("Login", loginRequest, mock.AnythingOfType("dtos.SessionClient")).Return(expectedResponse, nil)
+
+	// Act
+	reqBody, _ := json.Marshal(loginRequest)
+	req := httptest.NewRequest("POST", "/auth/login", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := suite.app.Test(req)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+
+	var response struct {
+		Data dtos.LoginResponse `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&response)
+
+	assert.True(suite.T(), response.Data.TwoFactorRequired)
+	assert.Equal(suite.T(), "challenge_token", response.Data.ChallengeToken)
+	assert.Empty(suite.T(), response.Data.AccessToken)
+
+	suite.mockAuthService.AssertExpectations(suite.T())
+}
+
+func (suite *AuthControllerTestSuite) TestVerifyTwoFactor_Success() {
+	// Arrange
+	request := dtos.TwoFactorVerifyRequest{ChallengeToken: "challenge_token", Code: "123456"}
+	expectedResponse := dtos.LoginResponse{
+		TokenType:    "Bearer",
+		AccessToken:  "access_token",
+		RefreshToken: "refresh_token",
+	}
+	suite.mockAuthService.On("VerifyTwoFactor", request, mock.AnythingOfType("dtos.SessionClient")).Return(expectedResponse, nil)
+
+	// Act
+	reqBody, _ := json.Marshal(request)
+	req := httptest.NewRequest("POST", "/auth/2fa/verify", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := suite.app.Test(req)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+
+	suite.mockAuthService.AssertExpectations(suite.T())
+}
+
+func (suite *AuthControllerTestSuite) TestVerifyTwoFactor_InvalidChallenge() {
+	// Arrange
+	request := dtos.TwoFactorVerifyRequest{ChallengeToken: "expired_challenge", Code: "123456"}
+	suite.mockAuthService.On("VerifyTwoFactor", request, mock.AnythingOfType("dtos.SessionClient")).Return(dtos.LoginResponse{}, services.ErrInvalidChallenge)
+
+	// Act
+	reqBody, _ := json.Marshal(request)
+	req := httptest.NewRequest("POST", "/auth/2fa/verify", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := suite.app.Test(req)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusUnauthorized, resp.StatusCode)
+
+	suite.mockAuthService.AssertExpectations(suite.T())
+}
+
+func (suite *AuthControllerTestSuite) TestLogin_TwoFactorLocked() {
+	// Arrange
+	loginRequest := dtos.LoginRequest{
+		Email:    "test@example.com",
+		Password: "password123",
+	}
+	suite.mockAuthService.On("Login", loginRequest, mock.AnythingOfType("dtos.SessionClient")).Return(dtos.LoginResponse{}, services.ErrTwoFactorLocked)
+
+	// Act
+	reqBody, _ := json.Marshal(loginRequest)
+	req := httptest.NewRequest("POST", "/auth/login", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := suite.app.Test(req)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusTooManyRequests, resp.StatusCode)
+
+	suite.mockAuthService.AssertExpectations(suite.T())
+}
+
+func (suite *AuthControllerTestSuite) TestVerifyTwoFactor_MissingCode() {
+	// Act
+	reqBody, _ := json.Marshal(dtos.TwoFactorVerifyRequest{ChallengeToken: "challenge_token"})
+	req := httptest.NewRequest("POST", "/auth/2fa/verify", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := suite.app.Test(req)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusBadRequest, resp.StatusCode)
+
+	suite.mockAuthService.AssertNotCalled(suite.T(), "VerifyTwoFactor", mock.Anything, mock.Anything)
+}
+
+func (suite *AuthControllerTestSuite) TestEnrollTwoFactor_Unauthorized() {
+	// Act
+	req := httptest.NewRequest("POST", "/auth/2fa/enroll", nil)
+
+	resp, err := suite.app.Test(req)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusUnauthorized, resp.StatusCode)
+
+	suite.mockTwoFactor.AssertNotCalled(suite.T(), "Enroll", mock.Anything)
+}
+
 func TestAuthControllerTestSuite(t *testing.T) {
 	suite.Run(t, new(AuthControllerTestSuite))
 }
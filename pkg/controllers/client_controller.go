@@ -1,12 +1,26 @@
 package controllers
 
 import (
-	"github.com/go-playground/validator/v10"
+	"archive/zip"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 
 	"alfredo/ruu-properties/pkg/dtos"
 	"alfredo/ruu-properties/pkg/helpers"
+	"alfredo/ruu-properties/pkg/middleware"
 	"alfredo/ruu-properties/pkg/middleware/jwt"
+	"alfredo/ruu-properties/pkg/middleware/permission"
+	"alfredo/ruu-properties/pkg/models"
 	"alfredo/ruu-properties/pkg/services"
 )
 
@@ -15,14 +29,62 @@ type ClientController interface {
 	GetAll(c *fiber.Ctx) error
 	GetByID(c *fiber.Ctx) error
 	Update(c *fiber.Ctx) error
+	Patch(c *fiber.Ctx) error
 	Delete(c *fiber.Ctx) error
+	Export(c *fiber.Ctx) error
+	GetDuplicates(c *fiber.Ctx) error
+	Merge(c *fiber.Ctx) error
+	SetTags(c *fiber.Ctx) error
+	GetTags(c *fiber.Ctx) error
+	GetTimeline(c *fiber.Ctx) error
+	CreateNote(c *fiber.Ctx) error
+	CreateCall(c *fiber.Ctx) error
+	CreateEmail(c *fiber.Ctx) error
+	GetTrash(c *fiber.Ctx) error
+	Restore(c *fiber.Ctx) error
+	GetHistory(c *fiber.Ctx) error
+	UploadDocument(c *fiber.Ctx) error
+	GetDocuments(c *fiber.Ctx) error
+	DownloadDocument(c *fiber.Ctx) error
+	ReviewDocument(c *fiber.Ctx) error
+	GetContacts(c *fiber.Ctx) error
+	CreateContact(c *fiber.Ctx) error
+	UpdateContact(c *fiber.Ctx) error
+	DeleteContact(c *fiber.Ctx) error
+	ExportData(c *fiber.Ctx) error
+	Erase(c *fiber.Ctx) error
+	SetOwner(c *fiber.Ctx) error
+	Reassign(c *fiber.Ctx) error
 	Router(router fiber.Router)
 }
 
+// clientExportColumns maps every exportable column to the way its value is rendered
+var clientExportColumns = map[string]func(client *dtos.ClientResponse) string{
+	"uuid":           func(client *dtos.ClientResponse) string { return client.UUID },
+	"name":           func(client *dtos.ClientResponse) string { return client.Name },
+	"email":          func(client *dtos.ClientResponse) string { return client.Email },
+	"phone_number":   func(client *dtos.ClientResponse) string { return client.PhoneNumber },
+	"address":        func(client *dtos.ClientResponse) string { return client.Address },
+	"contact_person": func(client *dtos.ClientResponse) string { return client.ContactPerson },
+	"kyc_status":     func(client *dtos.ClientResponse) string { return client.KycStatus },
+	"created_at":     func(client *dtos.ClientResponse) string { return client.CreatedAt.Format(time.RFC3339) },
+	"updated_at":     func(client *dtos.ClientResponse) string { return client.UpdatedAt.Format(time.RFC3339) },
+}
+
+var defaultClientExportColumns = []string{
+	"uuid", "name", "email", "phone_number", "address", "contact_person", "created_at", "updated_at",
+}
+
 type clientControllerImpl struct {
-	redisService  services.RedisService
-	userService   services.UserService
-	clientService services.ClientService
+	redisService     services.RedisService
+	userService      services.UserService
+	clientService    services.ClientService
+	segmentService   services.SegmentService
+	activityService  services.ClientActivityService
+	changeLogService services.ChangeLogService
+	documentService  services.ClientDocumentService
+	contactService   services.ClientContactService
+	privacyService   services.ClientPrivacyService
 }
 
 // Update Client godoc
@@ -34,11 +96,14 @@ type clientControllerImpl struct {
 // @Security BearerAuth
 // @Param Authorization header string true "Bearer token"
 // @Param id path string true "Client ID"
+// @Param If-Match header string false "ETag of the version being changed"
 // @Param request body dtos.ClientRequest true "Client request"
 // @Success 200 {object} dtos.SuccessResponse{data=dtos.ClientResponse}
+// @Header 200 {string} ETag "Version of the updated client"
 // @Failure 400 {object} dtos.ErrorResponseDTO
 // @Failure 401 {object} dtos.ErrorResponseDTO
 // @Failure 404 {object} dtos.ErrorResponseDTO
+// @Failure 412 {object} dtos.ErrorResponseDTO
 // @Failure 500 {object} dtos.ErrorResponseDTO
 // @Router /clients/{id}/update [put]
 func (cs *clientControllerImpl) Update(c *fiber.Ctx) error {
@@ -65,6 +130,8 @@ func (cs *clientControllerImpl) Update(c *fiber.Ctx) error {
 		})
 	}
 	request.UUID = uuid
+	request.IfMatch = c.Get(fiber.HeaderIfMatch)
+	request.Actor = changeActor(c)
 
 	clientResponse, err := cs.clientService.Update(request)
 	if err != nil {
@@ -74,6 +141,12 @@ func (cs *clientControllerImpl) Update(c *fiber.Ctx) error {
 				Message: err.Error(),
 				Errors:  []string{err.Error()},
 			})
+		} else if err.Error() == "client has been modified" {
+			return c.Status(fiber.StatusPreconditionFailed).JSON(dtos.ErrorResponseDTO{
+				Success: false,
+				Message: err.Error(),
+				Errors:  []string{err.Error()},
+			})
 		} else {
 			return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
 				Success: false,
@@ -83,6 +156,7 @@ func (cs *clientControllerImpl) Update(c *fiber.Ctx) error {
 		}
 	}
 
+	c.Set(fiber.HeaderETag, clientResponse.ETag)
 	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
 		Success: true,
 		Message: "Client updated successfully",
@@ -90,6 +164,118 @@ func (cs *clientControllerImpl) Update(c *fiber.Ctx) error {
 	})
 }
 
+// Patch Client godoc
+// @Summary Patch a client
+// @Description Apply a JSON Merge Patch (application/merge-patch+json or application/json) or a JSON Patch (application/json-patch+json) to a client.
+// @Description The patched client is validated as a whole. Every client field is required, so nulling or removing one is rejected.
+// @Tags Client
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Client ID"
+// @Param If-Match header string false "ETag of the version being changed"
+// @Param request body object true "Merge patch document or array of JSON Patch operations"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.ClientResponse}
+// @Header 200 {string} ETag "Version of the patched client"
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Failure 412 {object} dtos.ErrorResponseDTO
+// @Failure 415 {object} dtos.ErrorResponseDTO
+// @Router /clients/{id} [patch]
+func (cs *clientControllerImpl) Patch(c *fiber.Ctx) error {
+	uuid := c.Params("id")
+	if !helpers.CheckLengthUUID(uuid) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid client ID",
+		})
+	}
+
+	format, err := helpers.PatchContentType(c.Get(fiber.HeaderContentType))
+	if err != nil {
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	current, err := cs.clientService.GetByID(uuid)
+	if err != nil {
+		if err.Error() == "client not found" {
+			return c.Status(fiber.StatusNotFound).JSON(dtos.ErrorResponseDTO{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	if ifMatch := c.Get(fiber.HeaderIfMatch); ifMatch != "" && !helpers.MatchesETag(ifMatch, current.ETag) {
+		return c.Status(fiber.StatusPreconditionFailed).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "client has been modified",
+		})
+	}
+
+	document := dtos.ClientPatchRequest{
+		Name:          current.Name,
+		Email:         current.Email,
+		PhoneNumber:   current.PhoneNumber,
+		Address:       current.Address,
+		ContactPerson: current.ContactPerson,
+	}
+	var request dtos.ClientPatchRequest
+	if err := helpers.ApplyPatch(format, document, c.Body(), &request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid patch",
+			Errors:  []string{err.Error()},
+		})
+	}
+	// The patch was applied to the version read above, the write fails if it changed since
+	request.UUID = uuid
+	request.IfMatch = current.ETag
+	request.Actor = changeActor(c)
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	client, err := cs.clientService.Patch(request)
+	if err != nil {
+		status := fiber.StatusBadRequest
+		switch err.Error() {
+		case "client not found":
+			status = fiber.StatusNotFound
+		case "client has been modified":
+			status = fiber.StatusPreconditionFailed
+		}
+		return c.Status(status).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: err.Error(),
+			Errors:  []string{err.Error()},
+		})
+	}
+
+	c.Set(fiber.HeaderETag, client.ETag)
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Client updated successfully",
+		Data:    client,
+	})
+}
+
 // Delete Client godoc
 // @Summary Delete a client
 // @Description Delete a client by ID
@@ -99,9 +285,11 @@ func (cs *clientControllerImpl) Update(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param Authorization header string true "Bearer token"
 // @Param id path string true "Client ID"
+// @Param If-Match header string false "ETag of the version being changed"
 // @Success 200 {object} dtos.SuccessResponse
 // @Failure 401 {object} dtos.ErrorResponseDTO
 // @Failure 404 {object} dtos.ErrorResponseDTO
+// @Failure 412 {object} dtos.ErrorResponseDTO
 // @Failure 500 {object} dtos.ErrorResponseDTO
 // @Router /clients/{id}/delete [delete]
 func (cs *clientControllerImpl) Delete(c *fiber.Ctx) error {
@@ -120,13 +308,19 @@ func (cs *clientControllerImpl) Delete(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := cs.clientService.Delete(uuid); err != nil {
+	if err := cs.clientService.Delete(uuid, c.Get(fiber.HeaderIfMatch), changeActor(c)); err != nil {
 		if err.Error() == "client not found" {
 			return c.Status(fiber.StatusNotFound).JSON(dtos.ErrorResponseDTO{
 				Success: false,
 				Message: err.Error(),
 				Errors:  []string{err.Error()},
 			})
+		} else if err.Error() == "client has been modified" {
+			return c.Status(fiber.StatusPreconditionFailed).JSON(dtos.ErrorResponseDTO{
+				Success: false,
+				Message: err.Error(),
+				Errors:  []string{err.Error()},
+			})
 		} else {
 			return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
 				Success: false,
@@ -144,7 +338,9 @@ func (cs *clientControllerImpl) Delete(c *fiber.Ctx) error {
 
 // GetAll Client godoc
 // @Summary Get all clients
-// @Description Get a list of all clients with pagination and search functionality
+// @Description Get a list of all clients with pagination and search functionality.
+// @Description Passing cursor, empty for the first page, switches to keyset pagination: meta then holds limit, next_cursor, has_more and, unless with_total=false, total.
+// @Description Filters combine as filter[field][operator]=value, e.g. filter[created_at][gte]=2025-01-01&filter[email][ilike]=company&filter[tags][in]=vip,corporate. Text fields (name, email, phone_number, address, contact_person, kyc_status) accept eq, ne, in, nin, ilike and null, time fields (created_at, updated_at) eq, gt, gte, lt, lte and null, and tags in and all. filter[field]=value is short for eq.
 // @Tags Client
 // @Accept json
 // @Produce json
@@ -156,6 +352,10 @@ func (cs *clientControllerImpl) Delete(c *fiber.Ctx) error {
 // @Param search_by query string false "Field to search by (name, email, phone_number, contact_person)" default(name)
 // @Param sort_by query string false "Field to sort by (name, email, created_at, updated_at)" default(created_at)
 // @Param sort_order query string false "Sort order (asc, desc)" default(desc)
+// @Param tags query string false "Comma separated tags the client must all have"
+// @Param segment query string false "Segment ID"
+// @Param cursor query string false "next_cursor of the previous page, empty for the first page"
+// @Param with_total query bool false "Count the matching clients in cursor mode" default(true)
 // @Success 200 {object} dtos.PaginatedSuccessResponse{data=[]dtos.ClientResponse,meta=dtos.PaginationMeta}
 // @Failure 400 {object} dtos.ErrorResponseDTO
 // @Failure 401 {object} dtos.ErrorResponseDTO
@@ -178,43 +378,31 @@ func (cs *clientControllerImpl) GetAll(c *fiber.Ctx) error {
 		request.Limit = 10
 	}
 
-	// Validasi tambahan untuk parameter search_by dan sort_by
-	if request.SearchBy != "" {
-		allowedSearchFields := map[string]bool{
-			"name":           true,
-			"email":          true,
-			"phone_number":   true,
-			"contact_person": true,
-		}
-		if !allowedSearchFields[request.SearchBy] {
-			return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
-				Success: false,
-				Message: "Invalid search_by parameter. Allowed values: name, email, phone_number, contact_person",
-			})
-		}
-	}
-
-	if request.SortBy != "" {
-		allowedSortFields := map[string]bool{
-			"name":       true,
-			"email":      true,
-			"created_at": true,
-			"updated_at": true,
-		}
-		if !allowedSortFields[request.SortBy] {
-			return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
-				Success: false,
-				Message: "Invalid sort_by parameter. Allowed values: name, email, created_at, updated_at",
-			})
-		}
+	if message := validateClientGetRequest(request); message != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: message,
+		})
 	}
 
-	if request.SortOrder != "" && request.SortOrder != "asc" && request.SortOrder != "desc" {
+	filters, err := helpers.ParseFilters(c.Queries(), dtos.ClientFilterFields)
+	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
 			Success: false,
-			Message: "Invalid sort_order parameter. Allowed values: asc, desc",
+			Message: "Invalid filter parameter",
+			Errors:  []string{err.Error()},
 		})
 	}
+	request.Filters = filters
+	request.Visibility = clientVisibility(c)
+
+	if err := cs.resolveSegment(&request); err != nil {
+		return segmentErrorResponse(c, err)
+	}
+
+	if c.Context().QueryArgs().Has("cursor") {
+		return cs.getAllByCursor(c, request)
+	}
 
 	clients, paginationMeta, err := cs.clientService.GetAll(request)
 	if err != nil {
@@ -233,6 +421,50 @@ func (cs *clientControllerImpl) GetAll(c *fiber.Ctx) error {
 	})
 }
 
+// getAllByCursor serves GET /clients in keyset mode. The cursor is checked against the requested
+// sort here, so a stale or tampered cursor is a bad request rather than a server error.
+func (cs *clientControllerImpl) getAllByCursor(c *fiber.Ctx, request dtos.ClientGetRequest) error {
+	if request.SortBy == "" {
+		request.SortBy = "created_at"
+	}
+	if request.SortOrder == "" {
+		request.SortOrder = "desc"
+	}
+
+	if request.Cursor != "" {
+		after, err := helpers.DecodeCursor(request.Cursor, request.SortBy, request.SortOrder)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+		request.After = after
+	}
+
+	clients, cursorMeta, err := cs.clientService.GetAllByCursor(request)
+	if err != nil {
+		if err.Error() == "invalid cursor" {
+			return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Failed to fetch clients",
+			Errors:  err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.CursorPaginatedSuccessResponse{
+		Success: true,
+		Message: "Successfully fetched clients",
+		Data:    clients,
+		Meta:    *cursorMeta,
+	})
+}
+
 // GetByID Client godoc
 // @Summary Get a client by ID
 // @Description Get detailed information of a specific client
@@ -243,6 +475,7 @@ func (cs *clientControllerImpl) GetAll(c *fiber.Ctx) error {
 // @Param Authorization header string true "Bearer token"
 // @Param id path string true "Client ID"
 // @Success 200 {object} dtos.SuccessResponse{data=dtos.ClientResponse}
+// @Header 200 {string} ETag "Version of the client, send it back as If-Match to update or delete"
 // @Failure 401 {object} dtos.ErrorResponseDTO
 // @Failure 404 {object} dtos.ErrorResponseDTO
 // @Failure 500 {object} dtos.ErrorResponseDTO
@@ -274,6 +507,7 @@ func (cs *clientControllerImpl) GetByID(c *fiber.Ctx) error {
 			})
 	}
 
+	c.Set(fiber.HeaderETag, client.ETag)
 	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
 		Success: true,
 		Message: "Successfully fetched client",
@@ -281,15 +515,1621 @@ func (cs *clientControllerImpl) GetByID(c *fiber.Ctx) error {
 	})
 }
 
+// Export Client godoc
+// @Summary Export clients
+// @Description Stream every client matching the search, filter and sort parameters as CSV or XLSX. Filters are the filter[field][operator]=value parameters of the client list.
+// @Tags Client
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param format query string false "Export format (csv, xlsx)" default(csv)
+// @Param columns query string false "Comma separated columns (uuid, name, email, phone_number, address, contact_person, created_at, updated_at)"
+// @Param search query string false "Search term"
+// @Param search_by query string false "Field to search by (name, email, phone_number, contact_person)"
+// @Param sort_by query string false "Field to sort by (name, email, created_at, updated_at)" default(created_at)
+// @Param sort_order query string false "Sort order (asc, desc)" default(desc)
+// @Param tags query string false "Comma separated tags the client must all have"
+// @Param segment query string false "Segment ID"
+// @Success 200 {file} file
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Router /clients/export [get]
+func (cs *clientControllerImpl) Export(c *fiber.Ctx) error {
+	var request dtos.ClientExportRequest
+	if err := c.QueryParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid query parameters",
+			Errors:  err.Error(),
+		})
+	}
+
+	if request.Format == "" {
+		request.Format = helpers.ExportFormatCSV
+	}
+	if request.Format != helpers.ExportFormatCSV && request.Format != helpers.ExportFormatXLSX {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid format parameter. Allowed values: csv, xlsx",
+		})
+	}
+
+	if message := validateClientGetRequest(request.ClientGetRequest); message != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: message,
+		})
+	}
+
+	filters, err := helpers.ParseFilters(c.Queries(), dtos.ClientFilterFields)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid filter parameter",
+			Errors:  []string{err.Error()},
+		})
+	}
+	request.Filters = filters
+	request.Visibility = clientVisibility(c)
+
+	if err := cs.resolveSegment(&request.ClientGetRequest); err != nil {
+		return segmentErrorResponse(c, err)
+	}
+
+	columns := defaultClientExportColumns
+	if request.Columns != "" {
+		columns = strings.Split(request.Columns, ",")
+		for i, column := range columns {
+			columns[i] = strings.TrimSpace(column)
+			if _, ok := clientExportColumns[columns[i]]; !ok {
+				return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+					Success: false,
+					Message: fmt.Sprintf("Invalid columns parameter. Allowed values: %s", strings.Join(defaultClientExportColumns, ", ")),
+				})
+			}
+		}
+	}
+
+	filename := fmt.Sprintf("clients-%s.%s", time.Now().Format("20060102150405"), request.Format)
+	c.Set(fiber.HeaderContentType, helpers.ExportContentType(request.Format))
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	// The body is written while the rows are read, after the handler has returned
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		var phoneColumns []int
+		for i, column := range columns {
+			if column == "phone_number" {
+				phoneColumns = append(phoneColumns, i)
+			}
+		}
+
+		writer, err := helpers.NewRowWriter(request.Format, w, phoneColumns...)
+		if err != nil {
+			log.Println("Error while exporting clients", "error", err)
+			return
+		}
+
+		if err := writer.WriteRow(columns); err != nil {
+			log.Println("Error while exporting clients", "error", err)
+			return
+		}
+
+		err = cs.clientService.Export(request.ClientGetRequest, func(client *dtos.ClientResponse) error {
+			values := make([]string, len(columns))
+			for i, column := range columns {
+				values[i] = clientExportColumns[column](client)
+			}
+			return writer.WriteRow(values)
+		})
+		if err != nil {
+			log.Println("Error while exporting clients", "error", err)
+		}
+
+		if err := writer.Close(); err != nil {
+			log.Println("Error while exporting clients", "error", err)
+		}
+		w.Flush()
+	})
+
+	return nil
+}
+
+// GetDuplicates Client godoc
+// @Summary Get duplicate client candidates
+// @Description Score pairs of clients by normalized phone number, email local-part and name similarity
+// @Tags Client
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param min_score query number false "Minimum score between 0 and 1" default(0.5)
+// @Param limit query int false "Maximum number of pairs" default(20)
+// @Success 200 {object} dtos.SuccessResponse{data=[]dtos.ClientDuplicateResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /clients/duplicates [get]
+func (cs *clientControllerImpl) GetDuplicates(c *fiber.Ctx) error {
+	request := dtos.ClientDuplicateRequest{MinScore: 0.5, Limit: 20}
+	if err := c.QueryParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid query parameters",
+			Errors:  err.Error(),
+		})
+	}
+
+	if request.MinScore < 0 || request.MinScore > 1 {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid min_score parameter. Allowed values: 0 to 1",
+		})
+	}
+	if request.Limit < 1 || request.Limit > 100 {
+		request.Limit = 20
+	}
+
+	request.Visibility = clientVisibility(c)
+	duplicates, err := cs.clientService.GetDuplicates(request)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Failed to fetch duplicate clients",
+			Errors:  err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Successfully fetched duplicate clients",
+		Data:    duplicates,
+	})
+}
+
+// Merge Client godoc
+// @Summary Merge a duplicate client
+// @Description Move every record of the duplicate client onto this client and soft-delete the duplicate
+// @Tags Client
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Surviving client ID"
+// @Param request body dtos.ClientMergeRequest true "Client merge request"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.ClientResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /clients/{id}/merge [post]
+func (cs *clientControllerImpl) Merge(c *fiber.Ctx) error {
+	var request dtos.ClientMergeRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Errors:  []string{err.Error()},
+		})
+	}
+
+	uuid := c.Params("id")
+	if !helpers.CheckLengthUUID(uuid) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid client ID",
+		})
+	}
+	request.SurvivorUUID = uuid
+	request.Actor = changeActor(c)
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	if request.SurvivorUUID == request.DuplicateUUID {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "A client cannot be merged into itself",
+		})
+	}
+
+	// The duplicate is deleted by the merge, so it has to be visible to the user as well
+	if err := cs.clientService.CheckVisible(request.DuplicateUUID, *clientVisibility(c)); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "duplicate client not found",
+			Errors:  []string{"duplicate client not found"},
+		})
+	}
+
+	client, err := cs.clientService.Merge(request)
+	if err != nil {
+		if err.Error() == "client not found" || err.Error() == "duplicate client not found" {
+			return c.Status(fiber.StatusNotFound).JSON(dtos.ErrorResponseDTO{
+				Success: false,
+				Message: err.Error(),
+				Errors:  []string{err.Error()},
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: err.Error(),
+			Errors:  []string{err.Error()},
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Clients merged successfully",
+		Data:    client,
+	})
+}
+
+// resolveSegment loads the filter of the requested segment. Segments are evaluated on every
+// read, so the list always reflects the current tags and data.
+func (cs *clientControllerImpl) resolveSegment(request *dtos.ClientGetRequest) error {
+	if request.Segment == "" {
+		return nil
+	}
+
+	segment, err := cs.segmentService.GetByID(request.Segment)
+	if err != nil {
+		return err
+	}
+	request.Filter = &segment.Filter
+
+	return nil
+}
+
+// SetTags Client godoc
+// @Summary Replace the tags of a client
+// @Description Tags are lower cased, so "VIP" and "vip" are the same tag. An empty list removes every tag.
+// @Tags Client
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Client ID"
+// @Param request body dtos.ClientTagRequest true "Client tag request"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.ClientResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /clients/{id}/tags [put]
+func (cs *clientControllerImpl) SetTags(c *fiber.Ctx) error {
+	var request dtos.ClientTagRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Errors:  []string{err.Error()},
+		})
+	}
+
+	uuid := c.Params("id")
+	if !helpers.CheckLengthUUID(uuid) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid client ID",
+		})
+	}
+	request.UUID = uuid
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	client, err := cs.clientService.SetTags(request)
+	if err != nil {
+		if err.Error() == "client not found" {
+			return c.Status(fiber.StatusNotFound).JSON(dtos.ErrorResponseDTO{
+				Success: false,
+				Message: err.Error(),
+				Errors:  []string{err.Error()},
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: err.Error(),
+			Errors:  []string{err.Error()},
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Client tags updated successfully",
+		Data:    client,
+	})
+}
+
+// GetTags Client godoc
+// @Summary Get client tags
+// @Description Get every tag in use with the number of clients carrying it, counting only the clients the user may see
+// @Tags Client
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} dtos.SuccessResponse{data=[]dtos.ClientTagCountResponse}
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /clients/tags [get]
+func (cs *clientControllerImpl) GetTags(c *fiber.Ctx) error {
+	tags, err := cs.clientService.GetTags(clientVisibility(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Failed to fetch client tags",
+			Errors:  err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Successfully fetched client tags",
+		Data:    tags,
+	})
+}
+
+// GetTimeline Client godoc
+// @Summary Get the activity timeline of a client
+// @Description Notes, calls, emails and deposit events of a client, newest first
+// @Tags Client
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Client ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of items per page" default(20)
+// @Param types query string false "Comma separated entry types (note, call, email, deposit_received, deposit_deduction, deposit_refunded)"
+// @Success 200 {object} dtos.PaginatedSuccessResponse{data=[]dtos.ClientTimelineItem,meta=dtos.PaginationMeta}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /clients/{id}/timeline [get]
+func (cs *clientControllerImpl) GetTimeline(c *fiber.Ctx) error {
+	var request dtos.ClientTimelineRequest
+	if err := c.QueryParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid query parameters",
+			Errors:  err.Error(),
+		})
+	}
+
+	uuid := c.Params("id")
+	if !helpers.CheckLengthUUID(uuid) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid client ID",
+		})
+	}
+	request.ClientUUID = uuid
+
+	if request.Page < 1 {
+		request.Page = 1
+	}
+	if request.Limit < 1 {
+		request.Limit = 20
+	}
+
+	if request.Types != "" {
+		allowedTypes := make(map[string]bool, len(dtos.ClientTimelineTypes))
+		for _, entryType := range dtos.ClientTimelineTypes {
+			allowedTypes[entryType] = true
+		}
+		for _, entryType := range strings.Split(request.Types, ",") {
+			if !allowedTypes[entryType] {
+				return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+					Success: false,
+					Message: fmt.Sprintf("Invalid types parameter. Allowed values: %s", strings.Join(dtos.ClientTimelineTypes, ", ")),
+				})
+			}
+		}
+	}
+
+	items, paginationMeta, err := cs.activityService.GetTimeline(request)
+	if err != nil {
+		return clientActivityErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.PaginatedSuccessResponse{
+		Success: true,
+		Message: "Successfully fetched client timeline",
+		Data:    items,
+		Meta:    *paginationMeta,
+	})
+}
+
+// CreateNote Client godoc
+// @Summary Add a note to a client
+// @Description Add a free-form note to the client timeline
+// @Tags Client
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Client ID"
+// @Param request body dtos.ClientNoteRequest true "Client note request"
+// @Success 201 {object} dtos.SuccessResponse{data=dtos.ClientActivityResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Router /clients/{id}/notes [post]
+func (cs *clientControllerImpl) CreateNote(c *fiber.Ctx) error {
+	var request dtos.ClientNoteRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Errors:  []string{err.Error()},
+		})
+	}
+
+	uuid := c.Params("id")
+	if !helpers.CheckLengthUUID(uuid) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid client ID",
+		})
+	}
+	request.ClientUUID = uuid
+	request.AuthorUUID, _ = c.Locals("user_uuid").(string)
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	note, err := cs.activityService.CreateNote(request)
+	if err != nil {
+		return clientActivityErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Note added successfully",
+		Data:    note,
+	})
+}
+
+// CreateCall Client godoc
+// @Summary Log a call with a client
+// @Description Log a call with its outcome and an optional follow-up date
+// @Tags Client
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Client ID"
+// @Param request body dtos.ClientCallRequest true "Client call request"
+// @Success 201 {object} dtos.SuccessResponse{data=dtos.ClientActivityResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Router /clients/{id}/calls [post]
+func (cs *clientControllerImpl) CreateCall(c *fiber.Ctx) error {
+	var request dtos.ClientCallRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Errors:  []string{err.Error()},
+		})
+	}
+
+	uuid := c.Params("id")
+	if !helpers.CheckLengthUUID(uuid) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid client ID",
+		})
+	}
+	request.ClientUUID = uuid
+	request.AuthorUUID, _ = c.Locals("user_uuid").(string)
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	call, err := cs.activityService.CreateCall(request)
+	if err != nil {
+		return clientActivityErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Call logged successfully",
+		Data:    call,
+	})
+}
+
+// CreateEmail Client godoc
+// @Summary Log an email with a client
+// @Description Log an email sent to or received from the client
+// @Tags Client
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Client ID"
+// @Param request body dtos.ClientEmailRequest true "Client email request"
+// @Success 201 {object} dtos.SuccessResponse{data=dtos.ClientActivityResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Router /clients/{id}/emails [post]
+func (cs *clientControllerImpl) CreateEmail(c *fiber.Ctx) error {
+	var request dtos.ClientEmailRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Errors:  []string{err.Error()},
+		})
+	}
+
+	uuid := c.Params("id")
+	if !helpers.CheckLengthUUID(uuid) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid client ID",
+		})
+	}
+	request.ClientUUID = uuid
+	request.AuthorUUID, _ = c.Locals("user_uuid").(string)
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	email, err := cs.activityService.CreateEmail(request)
+	if err != nil {
+		return clientActivityErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Email logged successfully",
+		Data:    email,
+	})
+}
+
+func clientActivityErrorResponse(c *fiber.Ctx, err error) error {
+	if err.Error() == "client not found" {
+		return c.Status(fiber.StatusNotFound).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: err.Error(),
+			Errors:  []string{err.Error()},
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(dtos.ErrorResponseDTO{
+		Success: false,
+		Message: err.Error(),
+		Errors:  []string{err.Error()},
+	})
+}
+
+// GetTrash Client godoc
+// @Summary Get deleted clients
+// @Description Get a paginated list of soft-deleted clients, most recently deleted first. Requires the clients:manage permission.
+// @Tags Client
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of items per page" default(10)
+// @Param search query string false "Search by name, email or phone number"
+// @Success 200 {object} dtos.PaginatedSuccessResponse{data=[]dtos.ClientResponse,meta=dtos.PaginationMeta}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 403 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /clients/trash [get]
+func (cs *clientControllerImpl) GetTrash(c *fiber.Ctx) error {
+	var request dtos.TrashGetRequest
+	if err := c.QueryParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid query parameters",
+			Errors:  err.Error(),
+		})
+	}
+
+	if request.Page < 1 {
+		request.Page = 1
+	}
+	if request.Limit < 1 {
+		request.Limit = 10
+	}
+
+	clients, paginationMeta, err := cs.clientService.GetTrash(request)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Failed to fetch deleted clients",
+			Errors:  err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.PaginatedSuccessResponse{
+		Success: true,
+		Message: "Successfully fetched deleted clients",
+		Data:    clients,
+		Meta:    *paginationMeta,
+	})
+}
+
+// Restore Client godoc
+// @Summary Restore a deleted client
+// @Description Bring a soft-deleted client back. Fails with 409 when a live client already uses its email or phone number. Requires the clients:manage permission.
+// @Tags Client
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Client ID"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.ClientResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 403 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Failure 409 {object} dtos.ErrorResponseDTO
+// @Router /clients/{id}/restore [post]
+func (cs *clientControllerImpl) Restore(c *fiber.Ctx) error {
+	uuid := c.Params("id")
+	if !helpers.CheckLengthUUID(uuid) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid client ID",
+		})
+	}
+
+	client, err := cs.clientService.Restore(uuid, changeActor(c))
+	if err != nil {
+		return restoreErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Client restored successfully",
+		Data:    client,
+	})
+}
+
+// restoreErrorResponse maps the errors shared by every restore endpoint. A record that is not in
+// the trash is reported as not found, and a live record holding the same unique value or an
+// erased client as a conflict.
+func restoreErrorResponse(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case strings.HasSuffix(err.Error(), "not found"):
+		status = fiber.StatusNotFound
+	case strings.HasSuffix(err.Error(), "already exists"), strings.HasSuffix(err.Error(), "has been erased"):
+		status = fiber.StatusConflict
+	}
+
+	return c.Status(status).JSON(dtos.ErrorResponseDTO{
+		Success: false,
+		Message: err.Error(),
+		Errors:  []string{err.Error()},
+	})
+}
+
+// GetHistory Client godoc
+// @Summary Get the change history of a client
+// @Description Every create, update, delete, restore and merge of a client with the changed fields, who made the change and in which request, newest first
+// @Tags Client
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Client ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of items per page" default(20)
+// @Param field query string false "Only changes to this field, e.g. phone_number"
+// @Success 200 {object} dtos.PaginatedSuccessResponse{data=[]dtos.ChangeLogResponse,meta=dtos.PaginationMeta}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /clients/{id}/history [get]
+func (cs *clientControllerImpl) GetHistory(c *fiber.Ctx) error {
+	var request dtos.ChangeLogGetRequest
+	if err := c.QueryParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid query parameters",
+			Errors:  err.Error(),
+		})
+	}
+
+	uuid := c.Params("id")
+	if !helpers.CheckLengthUUID(uuid) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid client ID",
+		})
+	}
+	request.EntityType = models.ChangeLogEntityClient
+	request.EntityUUID = uuid
+
+	if request.Page < 1 {
+		request.Page = 1
+	}
+	if request.Limit < 1 {
+		request.Limit = 20
+	}
+
+	entries, paginationMeta, err := cs.changeLogService.GetHistory(request)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if err.Error() == "client not found" {
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.PaginatedSuccessResponse{
+		Success: true,
+		Message: "Successfully fetched client history",
+		Data:    entries,
+		Meta:    *paginationMeta,
+	})
+}
+
+// UploadDocument Client godoc
+// @Summary Upload a KYC document of a client
+// @Description Upload a KTP, NPWP or passport scan (PDF, JPEG or PNG, up to 5MB). The document waits for a reviewer to verify it, and the client KYC status is derived again.
+// @Tags Client
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Client ID"
+// @Param type formData string true "Document type (ktp, npwp, passport)"
+// @Param number formData string true "Document number"
+// @Param expires_at formData string false "Expiry date (YYYY-MM-DD)"
+// @Param file formData file true "Document scan"
+// @Success 201 {object} dtos.SuccessResponse{data=dtos.ClientDocumentResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /clients/{id}/documents [post]
+func (cs *clientControllerImpl) UploadDocument(c *fiber.Ctx) error {
+	var request dtos.ClientDocumentRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Errors:  []string{err.Error()},
+		})
+	}
+
+	uuid := c.Params("id")
+	if !helpers.CheckLengthUUID(uuid) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid client ID",
+		})
+	}
+	request.ClientUUID = uuid
+	request.Actor = changeActor(c)
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid document",
+			Errors:  []string{"file is required"},
+		})
+	}
+	if err := helpers.ValidateDocumentFile(file); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid document",
+			Errors:  []string{err.Error()},
+		})
+	}
+
+	path, err := helpers.SaveUploadedFile(c, file)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Failed to save file",
+			Errors:  []string{err.Error()},
+		})
+	}
+	request.FilePath = path
+
+	document, err := cs.documentService.Upload(request)
+	if err != nil {
+		// Identity scans are not kept around for a document that was never recorded
+		os.Remove(path)
+		return clientDocumentErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Document uploaded successfully",
+		Data:    document,
+	})
+}
+
+// GetDocuments Client godoc
+// @Summary List the KYC documents of a client
+// @Description Every document handed in by a client, newest first. Only the newest document of each type counts towards the KYC status.
+// @Tags Client
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Client ID"
+// @Success 200 {object} dtos.SuccessResponse{data=[]dtos.ClientDocumentResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Router /clients/{id}/documents [get]
+func (cs *clientControllerImpl) GetDocuments(c *fiber.Ctx) error {
+	uuid := c.Params("id")
+	if !helpers.CheckLengthUUID(uuid) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid client ID",
+		})
+	}
+
+	documents, err := cs.documentService.GetByClient(uuid)
+	if err != nil {
+		return clientDocumentErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Successfully fetched client documents",
+		Data:    documents,
+	})
+}
+
+// DownloadDocument Client godoc
+// @Summary Download the scan of a KYC document
+// @Tags Client
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Client ID"
+// @Param documentId path string true "Document ID"
+// @Success 200 {file} file
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Router /clients/{id}/documents/{documentId}/file [get]
+func (cs *clientControllerImpl) DownloadDocument(c *fiber.Ctx) error {
+	uuid := c.Params("id")
+	documentUUID := c.Params("documentId")
+	if !helpers.CheckLengthUUID(uuid) || !helpers.CheckLengthUUID(documentUUID) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid client or document ID",
+		})
+	}
+
+	document, err := cs.documentService.GetByID(uuid, documentUUID)
+	if err != nil {
+		return clientDocumentErrorResponse(c, err)
+	}
+
+	filename := fmt.Sprintf("%s-%s%s", document.Type, document.UUID, filepath.Ext(document.FilePath))
+	return c.Download(document.FilePath, filename)
+}
+
+// ReviewDocument Client godoc
+// @Summary Verify or reject a KYC document
+// @Description Requires the documents:review permission. A pending document is verified, or rejected with a reason, and the client KYC status is derived again.
+// @Tags Client
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Client ID"
+// @Param documentId path string true "Document ID"
+// @Param request body dtos.ClientDocumentReviewRequest true "Review decision"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.ClientDocumentResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 403 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Failure 409 {object} dtos.ErrorResponseDTO
+// @Router /clients/{id}/documents/{documentId}/review [post]
+func (cs *clientControllerImpl) ReviewDocument(c *fiber.Ctx) error {
+	var request dtos.ClientDocumentReviewRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Errors:  []string{err.Error()},
+		})
+	}
+
+	request.ClientUUID = c.Params("id")
+	request.DocumentUUID = c.Params("documentId")
+	if !helpers.CheckLengthUUID(request.ClientUUID) || !helpers.CheckLengthUUID(request.DocumentUUID) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid client or document ID",
+		})
+	}
+	request.Actor = changeActor(c)
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	document, err := cs.documentService.Review(request)
+	if err != nil {
+		return clientDocumentErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: fmt.Sprintf("Document %s successfully", document.Status),
+		Data:    document,
+	})
+}
+
+// clientDocumentErrorResponse maps the errors of the KYC document endpoints. A document that
+// was reviewed already is a conflict, anything else the caller can fix is a bad request.
+func clientDocumentErrorResponse(c *fiber.Ctx, err error) error {
+	status := fiber.StatusBadRequest
+	switch {
+	case strings.HasSuffix(err.Error(), "not found"):
+		status = fiber.StatusNotFound
+	case strings.HasPrefix(err.Error(), "document has already been"):
+		status = fiber.StatusConflict
+	case err.Error() == "please try again later":
+		status = fiber.StatusInternalServerError
+	}
+
+	return c.Status(status).JSON(dtos.ErrorResponseDTO{
+		Success: false,
+		Message: err.Error(),
+		Errors:  []string{err.Error()},
+	})
+}
+
+// GetContacts Client godoc
+// @Summary List the contacts of a client
+// @Description The primary contact comes first, the others follow by name
+// @Tags Client
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Client ID"
+// @Success 200 {object} dtos.SuccessResponse{data=[]dtos.ClientContactResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Router /clients/{id}/contacts [get]
+func (cs *clientControllerImpl) GetContacts(c *fiber.Ctx) error {
+	uuid := c.Params("id")
+	if !helpers.CheckLengthUUID(uuid) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid client ID",
+		})
+	}
+
+	contacts, err := cs.contactService.GetByClient(uuid)
+	if err != nil {
+		return clientContactErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Successfully fetched client contacts",
+		Data:    contacts,
+	})
+}
+
+// CreateContact Client godoc
+// @Summary Add a contact to a client
+// @Description The first contact of a client becomes its primary contact. A new primary contact takes over from the current one and becomes the contact_person of the client.
+// @Tags Client
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Client ID"
+// @Param request body dtos.ClientContactRequest true "Contact"
+// @Success 201 {object} dtos.SuccessResponse{data=dtos.ClientContactResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Router /clients/{id}/contacts [post]
+func (cs *clientControllerImpl) CreateContact(c *fiber.Ctx) error {
+	var request dtos.ClientContactRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Errors:  []string{err.Error()},
+		})
+	}
+
+	request.ClientUUID = c.Params("id")
+	if !helpers.CheckLengthUUID(request.ClientUUID) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid client ID",
+		})
+	}
+	request.Actor = changeActor(c)
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	contact, err := cs.contactService.Create(request)
+	if err != nil {
+		return clientContactErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Contact created successfully",
+		Data:    contact,
+	})
+}
+
+// UpdateContact Client godoc
+// @Summary Update a contact of a client
+// @Description Every field of the contact is replaced. The primary contact stays primary until another contact is made primary.
+// @Tags Client
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Client ID"
+// @Param contactId path string true "Contact ID"
+// @Param request body dtos.ClientContactRequest true "Contact"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.ClientContactResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Router /clients/{id}/contacts/{contactId} [put]
+func (cs *clientControllerImpl) UpdateContact(c *fiber.Ctx) error {
+	var request dtos.ClientContactRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Errors:  []string{err.Error()},
+		})
+	}
+
+	request.ClientUUID = c.Params("id")
+	request.UUID = c.Params("contactId")
+	if !helpers.CheckLengthUUID(request.ClientUUID) || !helpers.CheckLengthUUID(request.UUID) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid client or contact ID",
+		})
+	}
+	request.Actor = changeActor(c)
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	contact, err := cs.contactService.Update(request)
+	if err != nil {
+		return clientContactErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Contact updated successfully",
+		Data:    contact,
+	})
+}
+
+// DeleteContact Client godoc
+// @Summary Delete a contact of a client
+// @Description The primary contact cannot be deleted, make another contact primary first
+// @Tags Client
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Client ID"
+// @Param contactId path string true "Contact ID"
+// @Success 200 {object} dtos.SuccessResponse
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Router /clients/{id}/contacts/{contactId} [delete]
+func (cs *clientControllerImpl) DeleteContact(c *fiber.Ctx) error {
+	uuid := c.Params("id")
+	contactUUID := c.Params("contactId")
+	if !helpers.CheckLengthUUID(uuid) || !helpers.CheckLengthUUID(contactUUID) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid client or contact ID",
+		})
+	}
+
+	if err := cs.contactService.Delete(uuid, contactUUID); err != nil {
+		return clientContactErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Contact deleted successfully",
+	})
+}
+
+// clientContactErrorResponse maps the errors of the contact endpoints
+func clientContactErrorResponse(c *fiber.Ctx, err error) error {
+	status := fiber.StatusBadRequest
+	switch {
+	case strings.HasSuffix(err.Error(), "not found"):
+		status = fiber.StatusNotFound
+	case err.Error() == "please try again later":
+		status = fiber.StatusInternalServerError
+	}
+
+	return c.Status(status).JSON(dtos.ErrorResponseDTO{
+		Success: false,
+		Message: err.Error(),
+		Errors:  []string{err.Error()},
+	})
+}
+
+// ExportData Client godoc
+// @Summary Export everything held about a client
+// @Description Requires the clients:privacy permission. Answers a data access request under the PDP Law with the profile, contacts, KYC documents, timeline, change history and deposits of a client, deleted records included. The zip format adds the document scans next to client.json.
+// @Tags Client
+// @Produce json
+// @Produce application/zip
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Client ID"
+// @Param format query string false "Export format (json, zip)" default(json)
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.ClientDataExport}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 403 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Router /clients/{id}/data-export [get]
+func (cs *clientControllerImpl) ExportData(c *fiber.Ctx) error {
+	var request dtos.ClientDataExportRequest
+	if err := c.QueryParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid query parameters",
+			Errors:  err.Error(),
+		})
+	}
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid format parameter. Allowed values: json, zip",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	uuid := c.Params("id")
+	if !helpers.CheckLengthUUID(uuid) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid client ID",
+		})
+	}
+
+	export, err := cs.privacyService.Export(uuid)
+	if err != nil {
+		return clientPrivacyErrorResponse(c, err)
+	}
+
+	if request.Format != "zip" {
+		return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+			Success: true,
+			Message: "Successfully exported client data",
+			Data:    export,
+		})
+	}
+
+	filename := fmt.Sprintf("client-%s-%s.zip", uuid, export.ExportedAt.Format("20060102150405"))
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := writeClientDataArchive(w, export); err != nil {
+			log.Println("Error while exporting client data", "error", err)
+		}
+		w.Flush()
+	})
+
+	return nil
+}
+
+// writeClientDataArchive writes the export as client.json with every document scan under
+// documents/. A scan missing on disk is left out of the archive.
+func writeClientDataArchive(w io.Writer, export *dtos.ClientDataExport) error {
+	archive := zip.NewWriter(w)
+
+	bundle, err := archive.Create("client.json")
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(bundle)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(export); err != nil {
+		return err
+	}
+
+	for _, document := range export.Documents {
+		file, err := os.Open(document.FilePath)
+		if err != nil {
+			log.Println("Error while exporting client data", "file", document.FilePath, "error", err)
+			continue
+		}
+
+		name := fmt.Sprintf("documents/%s-%s%s", document.Type, document.UUID, filepath.Ext(document.FilePath))
+		entry, err := archive.Create(name)
+		if err == nil {
+			_, err = io.Copy(entry, file)
+		}
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// Erase Client godoc
+// @Summary Erase the personal data of a client
+// @Description Requires the clients:privacy permission. Answers an erasure request under the PDP Law. The client and every client merged into it are anonymized and moved to the trash, their contacts, tags, KYC documents and timeline are removed and personal values are blanked in their history. Deposits are kept as financial records without their notes and deduction photos. This cannot be undone.
+// @Tags Client
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Client ID"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.ClientErasureResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 403 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Failure 409 {object} dtos.ErrorResponseDTO
+// @Router /clients/{id}/erase [post]
+func (cs *clientControllerImpl) Erase(c *fiber.Ctx) error {
+	uuid := c.Params("id")
+	if !helpers.CheckLengthUUID(uuid) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid client ID",
+		})
+	}
+
+	erasure, err := cs.privacyService.Erase(uuid, changeActor(c))
+	if err != nil {
+		return clientPrivacyErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Client erased successfully",
+		Data:    erasure,
+	})
+}
+
+// clientPrivacyErrorResponse maps the errors of the data export and erasure endpoints
+func clientPrivacyErrorResponse(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case strings.HasSuffix(err.Error(), "not found"):
+		status = fiber.StatusNotFound
+	case strings.HasSuffix(err.Error(), "already been erased"):
+		status = fiber.StatusConflict
+	}
+
+	return c.Status(status).JSON(dtos.ErrorResponseDTO{
+		Success: false,
+		Message: err.Error(),
+		Errors:  []string{err.Error()},
+	})
+}
+
+// SetOwner Client godoc
+// @Summary Hand a client over to another agent
+// @Description Requires the clients:manage permission. The new owner and the members of their team see the client from now on.
+// @Tags Client
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Client ID"
+// @Param request body dtos.ClientOwnerRequest true "New owner"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.ClientResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 403 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Router /clients/{id}/owner [put]
+func (cs *clientControllerImpl) SetOwner(c *fiber.Ctx) error {
+	var request dtos.ClientOwnerRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Errors:  []string{err.Error()},
+		})
+	}
+
+	request.UUID = c.Params("id")
+	if !helpers.CheckLengthUUID(request.UUID) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid client ID",
+		})
+	}
+	request.Actor = changeActor(c)
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	client, err := cs.clientService.SetOwner(request)
+	if err != nil {
+		return clientOwnerErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Client reassigned successfully",
+		Data:    client,
+	})
+}
+
+// Reassign Client godoc
+// @Summary Move every client of an agent to another agent
+// @Description Requires the clients:manage permission, e.g. when an agent leaves. Deleted clients move along. An empty from_owner_uuid assigns the clients that have no owner yet, which only users with the clients:read-all permission see.
+// @Tags Client
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param request body dtos.ClientReassignRequest true "Agents to move the clients between"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.ClientReassignResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 403 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Router /clients/reassign [post]
+func (cs *clientControllerImpl) Reassign(c *fiber.Ctx) error {
+	var request dtos.ClientReassignRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Errors:  []string{err.Error()},
+		})
+	}
+	request.Actor = changeActor(c)
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	response, err := cs.clientService.Reassign(request)
+	if err != nil {
+		return clientOwnerErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: fmt.Sprintf("%d clients reassigned successfully", response.Reassigned),
+		Data:    response,
+	})
+}
+
+// clientOwnerErrorResponse maps the errors of the reassignment endpoints
+func clientOwnerErrorResponse(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	if strings.HasSuffix(err.Error(), "not found") {
+		status = fiber.StatusNotFound
+	}
+
+	return c.Status(status).JSON(dtos.ErrorResponseDTO{
+		Success: false,
+		Message: err.Error(),
+		Errors:  []string{err.Error()},
+	})
+}
+
+// visibleClient answers not found for a client outside the visibility of the signed in user, so
+// the clients of other agents cannot be probed. The handler reports a malformed ID itself.
+func (cs *clientControllerImpl) visibleClient(c *fiber.Ctx) error {
+	uuid := c.Params("id")
+	if !helpers.CheckLengthUUID(uuid) {
+		return c.Next()
+	}
+
+	if err := cs.clientService.CheckVisible(uuid, *clientVisibility(c)); err != nil {
+		status := fiber.StatusNotFound
+		if err.Error() == "please try again later" {
+			status = fiber.StatusInternalServerError
+		}
+		return c.Status(status).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: err.Error(),
+			Errors:  []string{err.Error()},
+		})
+	}
+	return c.Next()
+}
+
+// clientVisibility is the set of clients the signed in user works with
+func clientVisibility(c *fiber.Ctx) *dtos.ClientVisibility {
+	visibility := &dtos.ClientVisibility{}
+	if user, ok := c.Locals("user").(*models.User); ok {
+		visibility.UserUUID = user.UUID
+		visibility.Team = user.Team
+		visibility.All = permission.Has(c, models.PermissionClientsReadAll)
+	}
+	return visibility
+}
+
+// changeActor is the signed in user and the request ID a change is recorded under
+func changeActor(c *fiber.Ctx) dtos.ChangeActor {
+	var actor dtos.ChangeActor
+	actor.UserUUID, _ = c.Locals("user_uuid").(string)
+	actor.RequestID, _ = c.Locals(middleware.RequestIDKey).(string)
+	return actor
+}
+
+// validateClientGetRequest checks the search and sort parameters shared by the list and export
+// endpoints and returns the error message for the first invalid one.
+func validateClientGetRequest(request dtos.ClientGetRequest) string {
+	// Validasi tambahan untuk parameter search_by dan sort_by
+	if request.SearchBy != "" && !slices.Contains(dtos.ClientSearchFields, request.SearchBy) {
+		return fmt.Sprintf("Invalid search_by parameter. Allowed values: %s", strings.Join(dtos.ClientSearchFields, ", "))
+	}
+
+	if request.SortBy != "" && !slices.Contains(dtos.ClientSortFields, request.SortBy) {
+		return fmt.Sprintf("Invalid sort_by parameter. Allowed values: %s", strings.Join(dtos.ClientSortFields, ", "))
+	}
+
+	if request.SortOrder != "" && request.SortOrder != "asc" && request.SortOrder != "desc" {
+		return "Invalid sort_order parameter. Allowed values: asc, desc"
+	}
+
+	if request.Segment != "" && !helpers.CheckLengthUUID(request.Segment) {
+		return "Invalid segment parameter"
+	}
+
+	return ""
+}
+
 // Router implements ClientController.
 func (c *clientControllerImpl) Router(router fiber.Router) {
 	withMiddleware := router.Use(jwt.JwtMiddleware(c.userService, c.redisService))
 	{
-		withMiddleware.Get("/", c.GetAll)
-		withMiddleware.Get("/:id", c.GetByID)
-		withMiddleware.Post("/", c.Create)
-		withMiddleware.Put("/:id/update", c.Update)
-		withMiddleware.Delete("/:id/delete", c.Delete)
+		withMiddleware.Get("/", permission.RequirePermission(models.PermissionClientsRead), c.GetAll)
+		withMiddleware.Get("/export", permission.RequirePermission(models.PermissionClientsRead), c.Export)
+		withMiddleware.Get("/duplicates", permission.RequirePermission(models.PermissionClientsRead), c.GetDuplicates)
+		withMiddleware.Get("/tags", permission.RequirePermission(models.PermissionClientsRead), c.GetTags)
+		withMiddleware.Get("/trash", permission.RequirePermission(models.PermissionClientsManage), c.GetTrash)
+		withMiddleware.Post("/reassign", permission.RequirePermission(models.PermissionClientsManage), c.Reassign)
+		withMiddleware.Get("/:id", permission.RequirePermission(models.PermissionClientsRead), c.visibleClient, c.GetByID)
+		withMiddleware.Post("/", permission.RequirePermission(models.PermissionClientsWrite), c.Create)
+		withMiddleware.Post("/:id/merge", permission.RequirePermission(models.PermissionClientsDelete), c.visibleClient, c.Merge)
+		withMiddleware.Post("/:id/restore", permission.RequirePermission(models.PermissionClientsManage), c.Restore)
+		withMiddleware.Get("/:id/data-export", permission.RequirePermission(models.PermissionClientsPrivacy), c.ExportData)
+		withMiddleware.Post("/:id/erase", permission.RequirePermission(models.PermissionClientsPrivacy), c.Erase)
+		withMiddleware.Put("/:id/owner", permission.RequirePermission(models.PermissionClientsManage), c.SetOwner)
+		withMiddleware.Put("/:id/tags", permission.RequirePermission(models.PermissionClientsWrite), c.visibleClient, c.SetTags)
+		withMiddleware.Get("/:id/timeline", permission.RequirePermission(models.PermissionClientsRead), c.visibleClient, c.GetTimeline)
+		withMiddleware.Get("/:id/history", permission.RequirePermission(models.PermissionClientsRead), c.visibleClient, c.GetHistory)
+		withMiddleware.Get("/:id/documents", permission.RequirePermission(models.PermissionClientsRead), c.visibleClient, c.GetDocuments)
+		withMiddleware.Post("/:id/documents", permission.RequirePermission(models.PermissionClientsWrite), c.visibleClient, c.UploadDocument)
+		withMiddleware.Get("/:id/documents/:documentId/file", permission.RequirePermission(models.PermissionClientsRead), c.visibleClient, c.DownloadDocument)
+		withMiddleware.Post("/:id/documents/:documentId/review", permission.RequirePermission(models.PermissionDocumentsReview), c.ReviewDocument)
+		withMiddleware.Get("/:id/contacts", permission.RequirePermission(models.PermissionClientsRead), c.visibleClient, c.GetContacts)
+		withMiddleware.Post("/:id/contacts", permission.RequirePermission(models.PermissionClientsWrite), c.visibleClient, c.CreateContact)
+		withMiddleware.Put("/:id/contacts/:contactId", permission.RequirePermission(models.PermissionClientsWrite), c.visibleClient, c.UpdateContact)
+		withMiddleware.Delete("/:id/contacts/:contactId", permission.RequirePermission(models.PermissionClientsWrite), c.visibleClient, c.DeleteContact)
+		withMiddleware.Post("/:id/notes", permission.RequirePermission(models.PermissionClientsWrite), c.visibleClient, c.CreateNote)
+		withMiddleware.Post("/:id/calls", permission.RequirePermission(models.PermissionClientsWrite), c.visibleClient, c.CreateCall)
+		withMiddleware.Post("/:id/emails", permission.RequirePermission(models.PermissionClientsWrite), c.visibleClient, c.CreateEmail)
+		withMiddleware.Put("/:id/update", permission.RequirePermission(models.PermissionClientsWrite), c.visibleClient, c.Update)
+		withMiddleware.Patch("/:id", permission.RequirePermission(models.PermissionClientsWrite), c.visibleClient, c.Patch)
+		withMiddleware.Delete("/:id/delete", permission.RequirePermission(models.PermissionClientsDelete), c.visibleClient, c.Delete)
 	}
 }
 
@@ -316,7 +2156,7 @@ func (cs *clientControllerImpl) Create(c *fiber.Ctx) error {
 	}
 
 	// Validate the request first
-	validate := validator.New()
+	validate := helpers.NewValidator()
 	if err := validate.Struct(&request); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
 			Message: "Invalid request body",
@@ -325,6 +2165,7 @@ func (cs *clientControllerImpl) Create(c *fiber.Ctx) error {
 		})
 	}
 
+	request.Actor = changeActor(c)
 	response, err := cs.clientService.Create(request)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(dtos.ErrorResponseDTO{
@@ -344,10 +2185,22 @@ func NewClientController(
 	redisService services.RedisService,
 	userService services.UserService,
 	clientService services.ClientService,
+	segmentService services.SegmentService,
+	activityService services.ClientActivityService,
+	changeLogService services.ChangeLogService,
+	documentService services.ClientDocumentService,
+	contactService services.ClientContactService,
+	privacyService services.ClientPrivacyService,
 ) ClientController {
 	return &clientControllerImpl{
-		redisService:  redisService,
-		userService:   userService,
-		clientService: clientService,
+		redisService:     redisService,
+		userService:      userService,
+		clientService:    clientService,
+		segmentService:   segmentService,
+		activityService:  activityService,
+		changeLogService: changeLogService,
+		documentService:  documentService,
+		contactService:   contactService,
+		privacyService:   privacyService,
 	}
 }
@@ -0,0 +1,379 @@
+package controllers
+
+import (
+
+	"github.com/gofiber/fiber/v2"
+
+	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/helpers"
+	"alfredo/ruu-properties/pkg/middleware/jwt"
+	"alfredo/ruu-properties/pkg/middleware/permission"
+	"alfredo/ruu-properties/pkg/models"
+	"alfredo/ruu-properties/pkg/services"
+)
+
+type DepositController interface {
+	Create(c *fiber.Ctx) error
+	GetAll(c *fiber.Ctx) error
+	GetByID(c *fiber.Ctx) error
+	AddDeduction(c *fiber.Ctx) error
+	Refund(c *fiber.Ctx) error
+	GetSettlement(c *fiber.Ctx) error
+	Router(router fiber.Router)
+}
+
+type depositControllerImpl struct {
+	redisService   services.RedisService
+	userService    services.UserService
+	depositService services.DepositService
+}
+
+// Router implements DepositController.
+func (d *depositControllerImpl) Router(router fiber.Router) {
+	withMiddleware := router.Use(jwt.JwtMiddleware(d.userService, d.redisService))
+	{
+		withMiddleware.Get("/", permission.RequirePermission(models.PermissionDepositsRead), d.GetAll)
+		withMiddleware.Get("/:id", permission.RequirePermission(models.PermissionDepositsRead), d.GetByID)
+		withMiddleware.Get("/:id/settlement", permission.RequirePermission(models.PermissionDepositsRead), d.GetSettlement)
+		withMiddleware.Post("/", permission.RequirePermission(models.PermissionDepositsWrite), d.Create)
+		withMiddleware.Post("/:id/deductions", permission.RequirePermission(models.PermissionDepositsWrite), d.AddDeduction)
+		withMiddleware.Post("/:id/refund", permission.RequirePermission(models.PermissionDepositsRefund), d.Refund)
+	}
+}
+
+// Create Deposit godoc
+// @Summary Record a received security deposit
+// @Description Record the security deposit received from a client for a property
+// @Tags Deposit
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param request body dtos.DepositRequest true "Deposit request"
+// @Success 201 {object} dtos.SuccessResponse{data=dtos.DepositResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Router /deposits [post]
+func (d *depositControllerImpl) Create(c *fiber.Ctx) error {
+	var request dtos.DepositRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  []string{err.Error()},
+		})
+	}
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	deposit, err := d.depositService.Create(request)
+	if err != nil {
+		return depositErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Deposit recorded successfully",
+		Data:    deposit,
+	})
+}
+
+// GetAll Deposit godoc
+// @Summary Get all deposits
+// @Description Get a paginated list of deposits, optionally filtered by client, property or status
+// @Tags Deposit
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of items per page" default(10)
+// @Param client_uuid query string false "Client UUID"
+// @Param property_uuid query string false "Property UUID"
+// @Param status query string false "Deposit status (held, refunded)"
+// @Success 200 {object} dtos.PaginatedSuccessResponse{data=[]dtos.DepositResponse,meta=dtos.PaginationMeta}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /deposits [get]
+func (d *depositControllerImpl) GetAll(c *fiber.Ctx) error {
+	var request dtos.DepositGetRequest
+	if err := c.QueryParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid query parameters",
+			Errors:  err.Error(),
+		})
+	}
+
+	if request.Page < 1 {
+		request.Page = 1
+	}
+	if request.Limit < 1 {
+		request.Limit = 10
+	}
+
+	deposits, paginationMeta, err := d.depositService.GetAll(request)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Failed to fetch deposits",
+			Errors:  err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.PaginatedSuccessResponse{
+		Success: true,
+		Message: "Successfully fetched deposits",
+		Data:    deposits,
+		Meta:    *paginationMeta,
+	})
+}
+
+// GetByID Deposit godoc
+// @Summary Get a deposit by ID
+// @Description Get a deposit together with its deductions
+// @Tags Deposit
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Deposit ID"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.DepositResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Router /deposits/{id} [get]
+func (d *depositControllerImpl) GetByID(c *fiber.Ctx) error {
+	uuid := c.Params("id")
+	if !helpers.CheckLengthUUID(uuid) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid deposit ID",
+		})
+	}
+
+	deposit, err := d.depositService.GetByID(uuid)
+	if err != nil {
+		return depositErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Successfully fetched deposit",
+		Data:    deposit,
+	})
+}
+
+// AddDeduction Deposit godoc
+// @Summary Record a move-out deduction
+// @Description Record an itemised deduction (damage, unpaid utilities, ...) with evidence photos
+// @Tags Deposit
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Deposit ID"
+// @Param category formData string true "Deduction category (damage, unpaid_utilities, cleaning, other)"
+// @Param description formData string true "Deduction description"
+// @Param amount formData number true "Deducted amount"
+// @Param photos formData file false "Evidence photos"
+// @Success 201 {object} dtos.SuccessResponse{data=dtos.DepositResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /deposits/{id}/deductions [post]
+func (d *depositControllerImpl) AddDeduction(c *fiber.Ctx) error {
+	uuid := c.Params("id")
+	if !helpers.CheckLengthUUID(uuid) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid deposit ID",
+		})
+	}
+
+	var request dtos.DepositDeductionRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  []string{err.Error()},
+		})
+	}
+	request.DepositUUID = uuid
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	// Handle evidence photo uploads, stored with the extension of their actual content
+	if form, err := c.MultipartForm(); err == nil {
+		extensions := make([]string, len(form.File["photos"]))
+		for i, file := range form.File["photos"] {
+			ext, err := helpers.ValidatePhotoFile(file)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+					Message: "Evidence photos must be images",
+					Code:    fiber.StatusBadRequest,
+					Errors:  []string{err.Error()},
+				})
+			}
+			extensions[i] = ext
+		}
+
+		for i, file := range form.File["photos"] {
+			path, err := helpers.SaveUploadedFileAs(c, file, extensions[i])
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(dtos.ErrorResponseDTO{
+					Message: "Failed to save file",
+					Code:    fiber.StatusInternalServerError,
+					Errors:  err.Error(),
+				})
+			}
+			request.Photos = append(request.Photos, path)
+		}
+	}
+
+	deposit, err := d.depositService.AddDeduction(request)
+	if err != nil {
+		return depositErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Deduction recorded successfully",
+		Data:    deposit,
+	})
+}
+
+// Refund Deposit godoc
+// @Summary Record the deposit refund
+// @Description Record the refund payment of the refundable amount and close the deposit
+// @Tags Deposit
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Deposit ID"
+// @Param request body dtos.DepositRefundRequest true "Refund request"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.DepositResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Router /deposits/{id}/refund [post]
+func (d *depositControllerImpl) Refund(c *fiber.Ctx) error {
+	uuid := c.Params("id")
+	if !helpers.CheckLengthUUID(uuid) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid deposit ID",
+		})
+	}
+
+	var request dtos.DepositRefundRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  []string{err.Error()},
+		})
+	}
+	request.DepositUUID = uuid
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	deposit, err := d.depositService.Refund(request)
+	if err != nil {
+		return depositErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Deposit refunded successfully",
+		Data:    deposit,
+	})
+}
+
+// GetSettlement Deposit godoc
+// @Summary Get the deposit settlement statement
+// @Description Get the move-out settlement statement for the client
+// @Tags Deposit
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Deposit ID"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.DepositSettlementResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Router /deposits/{id}/settlement [get]
+func (d *depositControllerImpl) GetSettlement(c *fiber.Ctx) error {
+	uuid := c.Params("id")
+	if !helpers.CheckLengthUUID(uuid) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid deposit ID",
+		})
+	}
+
+	settlement, err := d.depositService.GetSettlement(uuid)
+	if err != nil {
+		return depositErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Successfully fetched settlement statement",
+		Data:    settlement,
+	})
+}
+
+func depositErrorResponse(c *fiber.Ctx, err error) error {
+	if err.Error() == "deposit not found" || err.Error() == "client not found" {
+		return c.Status(fiber.StatusNotFound).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: err.Error(),
+			Errors:  []string{err.Error()},
+		})
+	}
+
+	return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+		Success: false,
+		Message: err.Error(),
+		Errors:  []string{err.Error()},
+	})
+}
+
+func NewDepositController(
+	redisService services.RedisService,
+	userService services.UserService,
+	depositService services.DepositService,
+) DepositController {
+	return &depositControllerImpl{
+		redisService:   redisService,
+		userService:    userService,
+		depositService: depositService,
+	}
+}
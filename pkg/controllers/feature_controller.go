@@ -1,17 +1,32 @@
 package controllers
 
 import (
-	"github.com/go-playground/validator/v10"
+	"fmt"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/etag"
 
 	"alfredo/ruu-properties/pkg/dtos"
 	"alfredo/ruu-properties/pkg/helpers"
 	"alfredo/ruu-properties/pkg/middleware/jwt"
+	"alfredo/ruu-properties/pkg/middleware/permission"
+	"alfredo/ruu-properties/pkg/models"
 	"alfredo/ruu-properties/pkg/services"
 )
 
+// publicFeatureCacheControl lets browsers and CDNs cache the public amenity list for five minutes
+const publicFeatureCacheControl = "public, max-age=300"
+
 type FeatureController interface {
 	Create(c *fiber.Ctx) error
+	GetAll(c *fiber.Ctx) error
+	GetByID(c *fiber.Ctx) error
+	GetPublic(c *fiber.Ctx) error
+	Update(c *fiber.Ctx) error
+	Patch(c *fiber.Ctx) error
+	Delete(c *fiber.Ctx) error
+	GetTrash(c *fiber.Ctx) error
+	Restore(c *fiber.Ctx) error
 	Router(router fiber.Router)
 }
 
@@ -23,13 +38,39 @@ type featureControllerImpl struct {
 
 // Router implements FeatureController.
 func (f *featureControllerImpl) Router(router fiber.Router) {
+	// Public read-only endpoint, registered before the JWT middleware
+	router.Get("/public", etag.New(), f.GetPublic)
+
 	withMiddleware := router.Use(jwt.JwtMiddleware(f.userService, f.redisService))
 	{
-		withMiddleware.Post("/", f.Create)
+		withMiddleware.Get("/", permission.RequirePermission(models.PermissionFeaturesRead), f.GetAll)
+		withMiddleware.Get("/trash", permission.RequirePermission(models.PermissionFeaturesManage), f.GetTrash)
+		withMiddleware.Get("/:id", permission.RequirePermission(models.PermissionFeaturesRead), f.GetByID)
+		withMiddleware.Post("/", permission.RequirePermission(models.PermissionFeaturesWrite), f.Create)
+		withMiddleware.Post("/:id/restore", permission.RequirePermission(models.PermissionFeaturesManage), f.Restore)
+		withMiddleware.Put("/:id/update", permission.RequirePermission(models.PermissionFeaturesWrite), f.Update)
+		withMiddleware.Patch("/:id", permission.RequirePermission(models.PermissionFeaturesWrite), f.Patch)
+		withMiddleware.Delete("/:id/delete", permission.RequirePermission(models.PermissionFeaturesDelete), f.Delete)
 	}
 }
 
-// Create implements FeatureController.
+// Create Feature godoc
+// @Summary Create a new feature
+// @Description Create a new amenity with an optional PNG/SVG icon
+// @Tags Feature
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param name formData string true "Feature name"
+// @Param description formData string false "Feature description"
+// @Param is_public formData boolean false "Show the feature on the public site"
+// @Param icon formData file false "Feature icon (PNG or SVG)"
+// @Success 201 {object} dtos.SuccessResponse{data=dtos.FeatureResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /features [post]
 func (f *featureControllerImpl) Create(c *fiber.Ctx) error {
 	var request dtos.FeatureRequest
 	if err := c.BodyParser(&request); err != nil {
@@ -40,7 +81,7 @@ func (f *featureControllerImpl) Create(c *fiber.Ctx) error {
 		})
 	}
 
-	validate := validator.New()
+	validate := helpers.NewValidator()
 	if err := validate.Struct(&request); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
 			Message: "Invalid request body",
@@ -49,6 +90,29 @@ func (f *featureControllerImpl) Create(c *fiber.Ctx) error {
 		})
 	}
 
+	// Handle icon upload
+	file, err := c.FormFile("icon")
+	if err == nil && file != nil {
+		if err := helpers.ValidateIconFile(file); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+				Message: "Invalid icon",
+				Code:    fiber.StatusBadRequest,
+				Errors:  []string{err.Error()},
+			})
+		}
+
+		path, err := helpers.SaveIconFile(c, file)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(dtos.ErrorResponseDTO{
+				Message: "Failed to save file",
+				Code:    fiber.StatusInternalServerError,
+				Errors:  err.Error(),
+			})
+		}
+
+		request.Icon = path
+	}
+
 	feature, err := f.featureService.Create(request)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(dtos.ErrorResponseDTO{
@@ -65,6 +129,460 @@ func (f *featureControllerImpl) Create(c *fiber.Ctx) error {
 	})
 }
 
+// GetAll Feature godoc
+// @Summary Get all features
+// @Description Get a paginated list of features with search functionality
+// @Tags Feature
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of items per page" default(10)
+// @Param search query string false "Search term"
+// @Param sort_by query string false "Field to sort by (name, created_at, updated_at)" default(created_at)
+// @Param sort_order query string false "Sort order (asc, desc)" default(desc)
+// @Success 200 {object} dtos.PaginatedSuccessResponse{data=[]dtos.FeatureResponse,meta=dtos.PaginationMeta}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /features [get]
+func (f *featureControllerImpl) GetAll(c *fiber.Ctx) error {
+	var request dtos.FeatureGetRequest
+	if err := c.QueryParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid query parameters",
+			Errors:  err.Error(),
+		})
+	}
+
+	if request.Page < 1 {
+		request.Page = 1
+	}
+	if request.Limit < 1 {
+		request.Limit = 10
+	}
+
+	if request.SortBy != "" {
+		allowedSortFields := map[string]bool{
+			"name":       true,
+			"created_at": true,
+			"updated_at": true,
+		}
+		if !allowedSortFields[request.SortBy] {
+			return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+				Success: false,
+				Message: "Invalid sort_by parameter. Allowed values: name, created_at, updated_at",
+			})
+		}
+	}
+
+	if request.SortOrder != "" && request.SortOrder != "asc" && request.SortOrder != "desc" {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid sort_order parameter. Allowed values: asc, desc",
+		})
+	}
+
+	features, paginationMeta, err := f.featureService.GetAll(request)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Failed to fetch features",
+			Errors:  err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.PaginatedSuccessResponse{
+		Success: true,
+		Message: "Successfully fetched features",
+		Data:    features,
+		Meta:    *paginationMeta,
+	})
+}
+
+// GetByID Feature godoc
+// @Summary Get a feature by ID
+// @Description Get detailed information of a specific feature
+// @Tags Feature
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Feature ID"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.FeatureResponse}
+// @Header 200 {string} ETag "Version of the feature, send it back as If-Match to update or delete"
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Router /features/{id} [get]
+func (f *featureControllerImpl) GetByID(c *fiber.Ctx) error {
+	uuid := c.Params("id")
+	if !helpers.CheckLengthUUID(uuid) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid feature ID",
+		})
+	}
+
+	feature, err := f.featureService.GetByID(uuid)
+	if err != nil {
+		return featureErrorResponse(c, err)
+	}
+
+	c.Set(fiber.HeaderETag, feature.ETag)
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Successfully fetched feature",
+		Data:    feature,
+	})
+}
+
+// GetPublic Feature godoc
+// @Summary Get public features
+// @Description Get the amenities visible on the public site. The response is cacheable.
+// @Tags Feature
+// @Accept json
+// @Produce json
+// @Success 200 {object} dtos.SuccessResponse{data=[]dtos.PublicFeatureResponse}
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /features/public [get]
+func (f *featureControllerImpl) GetPublic(c *fiber.Ctx) error {
+	features, err := f.featureService.GetPublic()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Failed to fetch features",
+			Errors:  err.Error(),
+		})
+	}
+
+	c.Set(fiber.HeaderCacheControl, publicFeatureCacheControl)
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Successfully fetched features",
+		Data:    features,
+	})
+}
+
+// Update Feature godoc
+// @Summary Update an existing feature
+// @Description Update feature information and icon by ID
+// @Tags Feature
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Feature ID"
+// @Param If-Match header string false "ETag of the version being changed"
+// @Param name formData string false "Feature name"
+// @Param description formData string false "Feature description"
+// @Param is_public formData boolean false "Show the feature on the public site"
+// @Param icon formData file false "Feature icon (PNG or SVG)"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.FeatureResponse}
+// @Header 200 {string} ETag "Version of the updated feature"
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Failure 412 {object} dtos.ErrorResponseDTO
+// @Router /features/{id}/update [put]
+func (f *featureControllerImpl) Update(c *fiber.Ctx) error {
+	uuid := c.Params("id")
+	if !helpers.CheckLengthUUID(uuid) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid feature ID",
+		})
+	}
+
+	var request dtos.FeatureUpdateRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Errors:  []string{err.Error()},
+		})
+	}
+	request.UUID = uuid
+	request.IfMatch = c.Get(fiber.HeaderIfMatch)
+
+	// Handle icon upload
+	file, err := c.FormFile("icon")
+	if err == nil && file != nil {
+		if err := helpers.ValidateIconFile(file); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+				Message: "Invalid icon",
+				Code:    fiber.StatusBadRequest,
+				Errors:  []string{err.Error()},
+			})
+		}
+
+		path, err := helpers.SaveIconFile(c, file)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(dtos.ErrorResponseDTO{
+				Message: "Failed to save file",
+				Code:    fiber.StatusInternalServerError,
+				Errors:  err.Error(),
+			})
+		}
+
+		request.Icon = path
+	}
+
+	feature, err := f.featureService.Update(request)
+	if err != nil {
+		return featureErrorResponse(c, err)
+	}
+
+	c.Set(fiber.HeaderETag, feature.ETag)
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Feature updated successfully",
+		Data:    feature,
+	})
+}
+
+// Patch Feature godoc
+// @Summary Patch a feature
+// @Description Apply a JSON Merge Patch (application/merge-patch+json or application/json) or a JSON Patch (application/json-patch+json) to a feature.
+// @Description Description and icon can be cleared with null, name and is_public cannot. A new icon is uploaded through the update endpoint.
+// @Tags Feature
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Feature ID"
+// @Param If-Match header string false "ETag of the version being changed"
+// @Param request body object true "Merge patch document or array of JSON Patch operations"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.FeatureResponse}
+// @Header 200 {string} ETag "Version of the patched feature"
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Failure 412 {object} dtos.ErrorResponseDTO
+// @Failure 415 {object} dtos.ErrorResponseDTO
+// @Router /features/{id} [patch]
+func (f *featureControllerImpl) Patch(c *fiber.Ctx) error {
+	uuid := c.Params("id")
+	if !helpers.CheckLengthUUID(uuid) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid feature ID",
+		})
+	}
+
+	format, err := helpers.PatchContentType(c.Get(fiber.HeaderContentType))
+	if err != nil {
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+
+	current, err := f.featureService.GetByID(uuid)
+	if err != nil {
+		return featureErrorResponse(c, err)
+	}
+
+	if ifMatch := c.Get(fiber.HeaderIfMatch); ifMatch != "" && !helpers.MatchesETag(ifMatch, current.ETag) {
+		return featureErrorResponse(c, fmt.Errorf("%s", "feature has been modified"))
+	}
+
+	document := dtos.FeaturePatchRequest{
+		Name:        current.Name,
+		Description: &current.Description,
+		IsPublic:    current.IsPublic,
+		Icon:        &current.Icon,
+	}
+	var request dtos.FeaturePatchRequest
+	if err := helpers.ApplyPatch(format, document, c.Body(), &request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid patch",
+			Errors:  []string{err.Error()},
+		})
+	}
+	if request.Icon != nil && *request.Icon != current.Icon {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid patch",
+			Errors:  []string{"icon can only be cleared, upload a new icon through the update endpoint"},
+		})
+	}
+	// The patch was applied to the version read above, the write fails if it changed since
+	request.UUID = uuid
+	request.IfMatch = current.ETag
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	feature, err := f.featureService.Patch(request)
+	if err != nil {
+		return featureErrorResponse(c, err)
+	}
+
+	c.Set(fiber.HeaderETag, feature.ETag)
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Feature updated successfully",
+		Data:    feature,
+	})
+}
+
+// Delete Feature godoc
+// @Summary Delete a feature
+// @Description Delete a feature by ID
+// @Tags Feature
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Feature ID"
+// @Param If-Match header string false "ETag of the version being changed"
+// @Success 200 {object} dtos.SuccessResponse
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Failure 412 {object} dtos.ErrorResponseDTO
+// @Router /features/{id}/delete [delete]
+func (f *featureControllerImpl) Delete(c *fiber.Ctx) error {
+	uuid := c.Params("id")
+	if !helpers.CheckLengthUUID(uuid) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid feature ID",
+		})
+	}
+
+	if err := f.featureService.Delete(uuid, c.Get(fiber.HeaderIfMatch)); err != nil {
+		return featureErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Feature deleted successfully",
+	})
+}
+
+// GetTrash Feature godoc
+// @Summary Get deleted features
+// @Description Get a paginated list of soft-deleted amenities, most recently deleted first. Requires the features:manage permission.
+// @Tags Feature
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of items per page" default(10)
+// @Param search query string false "Search term"
+// @Success 200 {object} dtos.PaginatedSuccessResponse{data=[]dtos.FeatureResponse,meta=dtos.PaginationMeta}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 403 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /features/trash [get]
+func (f *featureControllerImpl) GetTrash(c *fiber.Ctx) error {
+	var request dtos.TrashGetRequest
+	if err := c.QueryParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid query parameters",
+			Errors:  err.Error(),
+		})
+	}
+
+	if request.Page < 1 {
+		request.Page = 1
+	}
+	if request.Limit < 1 {
+		request.Limit = 10
+	}
+
+	features, paginationMeta, err := f.featureService.GetTrash(request)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Failed to fetch deleted features",
+			Errors:  err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.PaginatedSuccessResponse{
+		Success: true,
+		Message: "Successfully fetched deleted features",
+		Data:    features,
+		Meta:    *paginationMeta,
+	})
+}
+
+// Restore Feature godoc
+// @Summary Restore a deleted feature
+// @Description Bring a soft-deleted amenity back. Fails with 409 when a live feature already uses its name. Requires the features:manage permission.
+// @Tags Feature
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Feature ID"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.FeatureResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 403 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Failure 409 {object} dtos.ErrorResponseDTO
+// @Router /features/{id}/restore [post]
+func (f *featureControllerImpl) Restore(c *fiber.Ctx) error {
+	uuid := c.Params("id")
+	if !helpers.CheckLengthUUID(uuid) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid feature ID",
+		})
+	}
+
+	feature, err := f.featureService.Restore(uuid)
+	if err != nil {
+		return restoreErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Feature restored successfully",
+		Data:    feature,
+	})
+}
+
+func featureErrorResponse(c *fiber.Ctx, err error) error {
+	if err.Error() == "feature not found" {
+		return c.Status(fiber.StatusNotFound).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: err.Error(),
+			Errors:  []string{err.Error()},
+		})
+	}
+	if err.Error() == "feature has been modified" {
+		return c.Status(fiber.StatusPreconditionFailed).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: err.Error(),
+			Errors:  []string{err.Error()},
+		})
+	}
+
+	return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+		Success: false,
+		Message: err.Error(),
+		Errors:  []string{err.Error()},
+	})
+}
+
 func NewFeatureController(featureService services.FeatureService, userService services.UserService, redisService services.RedisService) FeatureController {
 	return &featureControllerImpl{featureService: featureService, userService: userService, redisService: redisService}
 }
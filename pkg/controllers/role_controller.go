@@ -0,0 +1,272 @@
+package controllers
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/helpers"
+	"alfredo/ruu-properties/pkg/middleware/jwt"
+	"alfredo/ruu-properties/pkg/middleware/permission"
+	"alfredo/ruu-properties/pkg/models"
+	"alfredo/ruu-properties/pkg/services"
+)
+
+type RoleController interface {
+	GetAll(c *fiber.Ctx) error
+	GetPermissions(c *fiber.Ctx) error
+	Create(c *fiber.Ctx) error
+	Update(c *fiber.Ctx) error
+	Delete(c *fiber.Ctx) error
+	SetTwoFactor(c *fiber.Ctx) error
+	Router(router fiber.Router)
+}
+
+type roleControllerImpl struct {
+	roleService  services.RoleService
+	userService  services.UserService
+	redisService services.RedisService
+}
+
+// Router implements RoleController.
+func (r *roleControllerImpl) Router(router fiber.Router) {
+	withMiddleware := router.Use(jwt.JwtMiddleware(r.userService, r.redisService), permission.RequirePermission(models.PermissionRolesManage))
+	{
+		withMiddleware.Get("/", r.GetAll)
+		withMiddleware.Get("/permissions", r.GetPermissions)
+		withMiddleware.Post("/", r.Create)
+		withMiddleware.Put("/:name", r.Update)
+		withMiddleware.Delete("/:name", r.Delete)
+		withMiddleware.Put("/:name/two-factor", r.SetTwoFactor)
+	}
+}
+
+// GetAll Role godoc
+// @Summary Get all roles
+// @Description List every role with its permissions. Requires the roles:manage permission.
+// @Tags Role
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} dtos.SuccessResponse{data=[]dtos.RoleResponse}
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 403 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /roles [get]
+func (r *roleControllerImpl) GetAll(c *fiber.Ctx) error {
+	roles, err := r.roleService.GetAll()
+	if err != nil {
+		return roleErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Successfully fetched roles",
+		Data:    roles,
+	})
+}
+
+// GetPermissions Role godoc
+// @Summary Get the permission catalogue
+// @Description List every permission a role can grant. Requires the roles:manage permission.
+// @Tags Role
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} dtos.SuccessResponse{data=[]models.Permission}
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 403 {object} dtos.ErrorResponseDTO
+// @Router /roles/permissions [get]
+func (r *roleControllerImpl) GetPermissions(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Successfully fetched permissions",
+		Data:    r.roleService.GetPermissions(),
+	})
+}
+
+// Create Role godoc
+// @Summary Create a role
+// @Description Create a role from permissions of the catalogue. Requires the roles:manage permission.
+// @Tags Role
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param request body dtos.RoleRequest true "Role request"
+// @Success 201 {object} dtos.SuccessResponse{data=dtos.RoleResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 403 {object} dtos.ErrorResponseDTO
+// @Failure 409 {object} dtos.ErrorResponseDTO
+// @Router /roles [post]
+func (r *roleControllerImpl) Create(c *fiber.Ctx) error {
+	var request dtos.RoleRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Errors:  []string{err.Error()},
+		})
+	}
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	role, err := r.roleService.Create(request)
+	if err != nil {
+		return roleErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Role created successfully",
+		Data:    role,
+	})
+}
+
+// Update Role godoc
+// @Summary Update a role
+// @Description Replace the description and permissions of a role. Users holding the role get the new permissions on their next request. The admin role always holds every permission and cannot be changed. Requires the roles:manage permission.
+// @Tags Role
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param name path string true "Role name"
+// @Param request body dtos.RoleRequest true "Role request, the name is taken from the path"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.RoleResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 403 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Router /roles/{name} [put]
+func (r *roleControllerImpl) Update(c *fiber.Ctx) error {
+	var request dtos.RoleRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Errors:  []string{err.Error()},
+		})
+	}
+	request.Name = c.Params("name")
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	role, err := r.roleService.Update(request)
+	if err != nil {
+		return roleErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Role updated successfully",
+		Data:    role,
+	})
+}
+
+// Delete Role godoc
+// @Summary Delete a role
+// @Description Delete a role no user holds anymore. Requires the roles:manage permission.
+// @Tags Role
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param name path string true "Role name"
+// @Success 200 {object} dtos.SuccessResponse
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 403 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Failure 409 {object} dtos.ErrorResponseDTO
+// @Router /roles/{name} [delete]
+func (r *roleControllerImpl) Delete(c *fiber.Ctx) error {
+	if err := r.roleService.Delete(c.Params("name")); err != nil {
+		return roleErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Role deleted successfully",
+		Data:    nil,
+	})
+}
+
+// SetTwoFactor Role godoc
+// @Summary Require two-factor authentication for a role
+// @Description Turn the two-factor requirement of a role on or off, the admin role included. Holders without an authenticator can only enroll one from their next request on, and cannot disable theirs while it is required. Requires the roles:manage permission.
+// @Tags Role
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param name path string true "Role name"
+// @Param request body dtos.RoleTwoFactorRequest true "Whether the role requires two-factor authentication"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.RoleResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 403 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Router /roles/{name}/two-factor [put]
+func (r *roleControllerImpl) SetTwoFactor(c *fiber.Ctx) error {
+	var request dtos.RoleTwoFactorRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Errors:  []string{err.Error()},
+		})
+	}
+	request.Name = c.Params("name")
+
+	role, err := r.roleService.SetTwoFactor(request)
+	if err != nil {
+		return roleErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Role updated successfully",
+		Data:    role,
+	})
+}
+
+// roleErrorResponse maps the errors of the role endpoints
+func roleErrorResponse(c *fiber.Ctx, err error) error {
+	status := fiber.StatusBadRequest
+	switch {
+	case strings.HasSuffix(err.Error(), "not found"):
+		status = fiber.StatusNotFound
+	case err.Error() == "role already exists" || err.Error() == "role is still assigned to users":
+		status = fiber.StatusConflict
+	case err.Error() == "please try again later":
+		status = fiber.StatusInternalServerError
+	}
+
+	return c.Status(status).JSON(dtos.ErrorResponseDTO{
+		Success: false,
+		Message: err.Error(),
+		Errors:  []string{err.Error()},
+	})
+}
+
+func NewRoleController(roleService services.RoleService, userService services.UserService, redisService services.RedisService) RoleController {
+	return &roleControllerImpl{roleService: roleService, userService: userService, redisService: redisService}
+}
@@ -0,0 +1,278 @@
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/helpers"
+	"alfredo/ruu-properties/pkg/middleware/jwt"
+	"alfredo/ruu-properties/pkg/middleware/permission"
+	"alfredo/ruu-properties/pkg/models"
+	"alfredo/ruu-properties/pkg/services"
+)
+
+type SegmentController interface {
+	Create(c *fiber.Ctx) error
+	GetAll(c *fiber.Ctx) error
+	GetByID(c *fiber.Ctx) error
+	Update(c *fiber.Ctx) error
+	Delete(c *fiber.Ctx) error
+	Router(router fiber.Router)
+}
+
+type segmentControllerImpl struct {
+	segmentService services.SegmentService
+	userService    services.UserService
+	redisService   services.RedisService
+}
+
+// Router implements SegmentController.
+func (s *segmentControllerImpl) Router(router fiber.Router) {
+	withMiddleware := router.Use(jwt.JwtMiddleware(s.userService, s.redisService))
+	{
+		withMiddleware.Get("/", permission.RequirePermission(models.PermissionSegmentsRead), s.GetAll)
+		withMiddleware.Get("/:id", permission.RequirePermission(models.PermissionSegmentsRead), s.GetByID)
+		withMiddleware.Post("/", permission.RequirePermission(models.PermissionSegmentsWrite), s.Create)
+		withMiddleware.Put("/:id/update", permission.RequirePermission(models.PermissionSegmentsWrite), s.Update)
+		withMiddleware.Delete("/:id/delete", permission.RequirePermission(models.PermissionSegmentsWrite), s.Delete)
+	}
+}
+
+// Create Segment godoc
+// @Summary Create a new segment
+// @Description Save a client filter. Segments are evaluated on read through GET /clients?segment={id}.
+// @Tags Segment
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param request body dtos.SegmentRequest true "Segment request"
+// @Success 201 {object} dtos.SuccessResponse{data=dtos.SegmentResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Router /segments [post]
+func (s *segmentControllerImpl) Create(c *fiber.Ctx) error {
+	var request dtos.SegmentRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Errors:  []string{err.Error()},
+		})
+	}
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	segment, err := s.segmentService.Create(request)
+	if err != nil {
+		return segmentErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Segment created successfully",
+		Data:    segment,
+	})
+}
+
+// GetAll Segment godoc
+// @Summary Get all segments
+// @Description Get a paginated list of saved segments
+// @Tags Segment
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of items per page" default(10)
+// @Param search query string false "Search term"
+// @Success 200 {object} dtos.PaginatedSuccessResponse{data=[]dtos.SegmentResponse,meta=dtos.PaginationMeta}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /segments [get]
+func (s *segmentControllerImpl) GetAll(c *fiber.Ctx) error {
+	var request dtos.SegmentGetRequest
+	if err := c.QueryParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid query parameters",
+			Errors:  err.Error(),
+		})
+	}
+
+	if request.Page < 1 {
+		request.Page = 1
+	}
+	if request.Limit < 1 {
+		request.Limit = 10
+	}
+
+	segments, paginationMeta, err := s.segmentService.GetAll(request)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Failed to fetch segments",
+			Errors:  err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.PaginatedSuccessResponse{
+		Success: true,
+		Message: "Successfully fetched segments",
+		Data:    segments,
+		Meta:    *paginationMeta,
+	})
+}
+
+// GetByID Segment godoc
+// @Summary Get a segment by ID
+// @Description Get a saved segment and its filter
+// @Tags Segment
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Segment ID"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.SegmentResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Router /segments/{id} [get]
+func (s *segmentControllerImpl) GetByID(c *fiber.Ctx) error {
+	uuid := c.Params("id")
+	if !helpers.CheckLengthUUID(uuid) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid segment ID",
+		})
+	}
+
+	segment, err := s.segmentService.GetByID(uuid)
+	if err != nil {
+		return segmentErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Successfully fetched segment",
+		Data:    segment,
+	})
+}
+
+// Update Segment godoc
+// @Summary Update an existing segment
+// @Description Update the name, description or filter of a segment
+// @Tags Segment
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Segment ID"
+// @Param request body dtos.SegmentUpdateRequest true "Segment update request"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.SegmentResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Router /segments/{id}/update [put]
+func (s *segmentControllerImpl) Update(c *fiber.Ctx) error {
+	uuid := c.Params("id")
+	if !helpers.CheckLengthUUID(uuid) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid segment ID",
+		})
+	}
+
+	var request dtos.SegmentUpdateRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Errors:  []string{err.Error()},
+		})
+	}
+	request.UUID = uuid
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	segment, err := s.segmentService.Update(request)
+	if err != nil {
+		return segmentErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Segment updated successfully",
+		Data:    segment,
+	})
+}
+
+// Delete Segment godoc
+// @Summary Delete a segment
+// @Description Delete a segment by ID. Tagged clients are not affected.
+// @Tags Segment
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Segment ID"
+// @Success 200 {object} dtos.SuccessResponse
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Router /segments/{id}/delete [delete]
+func (s *segmentControllerImpl) Delete(c *fiber.Ctx) error {
+	uuid := c.Params("id")
+	if !helpers.CheckLengthUUID(uuid) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid segment ID",
+		})
+	}
+
+	if err := s.segmentService.Delete(uuid); err != nil {
+		return segmentErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Segment deleted successfully",
+	})
+}
+
+func segmentErrorResponse(c *fiber.Ctx, err error) error {
+	if err.Error() == "segment not found" {
+		return c.Status(fiber.StatusNotFound).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: err.Error(),
+			Errors:  []string{err.Error()},
+		})
+	}
+
+	return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+		Success: false,
+		Message: err.Error(),
+		Errors:  []string{err.Error()},
+	})
+}
+
+func NewSegmentController(segmentService services.SegmentService, userService services.UserService, redisService services.RedisService) SegmentController {
+	return &segmentControllerImpl{segmentService: segmentService, userService: userService, redisService: redisService}
+}
@@ -1,21 +1,29 @@
 package controllers
 
 import (
-	"fmt"
-	"os"
-	"path/filepath"
-	"time"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/google/uuid"
 
 	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/helpers"
+	"alfredo/ruu-properties/pkg/middleware/jwt"
+	"alfredo/ruu-properties/pkg/middleware/permission"
+	"alfredo/ruu-properties/pkg/models"
 	"alfredo/ruu-properties/pkg/services"
 )
 
 type UserController interface {
 	Router(router fiber.Router)
 	Register(c *fiber.Ctx) error
+	SetTeam(c *fiber.Ctx) error
+	SetRole(c *fiber.Ctx) error
+	GetAll(c *fiber.Ctx) error
+	Create(c *fiber.Ctx) error
+	Deactivate(c *fiber.Ctx) error
+	Reactivate(c *fiber.Ctx) error
+	ResetPassword(c *fiber.Ctx) error
+	Delete(c *fiber.Ctx) error
 }
 
 type userControllerImpl struct {
@@ -25,7 +33,7 @@ type userControllerImpl struct {
 
 // Register godoc
 // @Summary Register a new user
-// @Description Create a new user account
+// @Description Create a new user account with the default role. Returns 403 when public registration is disabled.
 // @Tags user
 // @Accept multipart/form-data
 // @Produce json
@@ -34,10 +42,10 @@ type userControllerImpl struct {
 // @Param password formData string true "Password (min 6 characters)"
 // @Param confirmation_password formData string true "Confirm password"
 // @Param phone_number formData string true "User's phone number"
-// @Param role formData string true "User role"
 // @Param image formData file false "User profile image"
 // @Success 200 {object} dtos.SuccessResponse{data=dtos.UserRegisterRequest}
 // @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 403 {object} dtos.ErrorResponseDTO
 // @Failure 500 {object} dtos.ErrorResponseDTO
 // @Router /user/register [post]
 func (u *userControllerImpl) Register(c *fiber.Ctx) error {
@@ -56,54 +64,519 @@ func (u *userControllerImpl) Register(c *fiber.Ctx) error {
 	// Handle file upload
 	file, err := c.FormFile("image")
 	if err == nil && file != nil {
-		// Create uploads directory if it doesn't exist
-		if err := os.MkdirAll("./uploads", 0755); err != nil {
+		path, err := helpers.SaveUploadedFile(c, file)
+		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(dtos.ErrorResponseDTO{
 				Success: false,
-				Message: "Failed to create upload directory",
+				Message: "Failed to save file",
 				Code:    fiber.StatusInternalServerError,
 				Errors:  err.Error(),
 			})
 		}
 
-		// Generate unique filename
-		ext := filepath.Ext(file.Filename)
-		filename := fmt.Sprintf("%d_%s%s", time.Now().Unix(), uuid.New().String(), ext)
-		filepath := fmt.Sprintf("./uploads/%s", filename)
+		// Set the image path in the request
+		request.Image = path
+	}
 
-		// Save the file
-		if err := c.SaveFile(file, filepath); err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(dtos.ErrorResponseDTO{
+	if err := u.userService.Register(request); err != nil {
+		status := fiber.StatusInternalServerError
+		if err.Error() == "registration is disabled" {
+			status = fiber.StatusForbidden
+		}
+		return c.Status(status).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: err.Error(),
+			Code:    status,
+			Errors:  err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "User registered successfully",
+		Data:    nil,
+	})
+}
+
+// SetTeam godoc
+// @Summary Assign a user to a team
+// @Description Requires the users:manage permission. Agents of the same team see and edit each other's clients. An empty team removes the user from their team.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "User ID"
+// @Param request body dtos.UserTeamRequest true "Team name"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.UserTeamResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 403 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /user/{id}/team [put]
+func (u *userControllerImpl) SetTeam(c *fiber.Ctx) error {
+	var request dtos.UserTeamRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  err.Error(),
+		})
+	}
+
+	request.UUID = c.Params("id")
+	if !helpers.CheckLengthUUID(request.UUID) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid user ID",
+			Code:    fiber.StatusBadRequest,
+		})
+	}
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	user, err := u.userService.SetTeam(request)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if err.Error() == "user not found" {
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: err.Error(),
+			Code:    status,
+			Errors:  err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Team updated successfully",
+		Data:    user,
+	})
+}
+
+// SetRole godoc
+// @Summary Assign a role to a user
+// @Description Requires the users:manage permission. The user gets the permissions of the role on their next request. The last admin cannot lose the admin role.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "User ID"
+// @Param request body dtos.UserRoleRequest true "Role name"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.UserRoleResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 403 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Failure 409 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /user/{id}/role [put]
+func (u *userControllerImpl) SetRole(c *fiber.Ctx) error {
+	var request dtos.UserRoleRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  err.Error(),
+		})
+	}
+
+	request.UUID = c.Params("id")
+	if !helpers.CheckLengthUUID(request.UUID) {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid user ID",
+			Code:    fiber.StatusBadRequest,
+		})
+	}
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	response, err := u.userService.SetRole(request)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		switch err.Error() {
+		case "user not found", "role not found":
+			status = fiber.StatusNotFound
+		case "the last admin cannot lose the admin role":
+			status = fiber.StatusConflict
+		}
+		return c.Status(status).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: err.Error(),
+			Code:    status,
+			Errors:  err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Role updated successfully",
+		Data:    response,
+	})
+}
+
+// GetAll godoc
+// @Summary List users
+// @Description Requires the users:manage permission. Search matches name, email and phone number.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of items per page" default(10)
+// @Param search query string false "Search term"
+// @Param role query string false "Only users with this role"
+// @Param status query string false "active or deactivated"
+// @Param sort_by query string false "Field to sort by (name, email, role, created_at, updated_at)" default(created_at)
+// @Param sort_order query string false "Sort order (asc, desc)" default(desc)
+// @Success 200 {object} dtos.PaginatedSuccessResponse{data=[]dtos.UserResponse,meta=dtos.PaginationMeta}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 403 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /user [get]
+func (u *userControllerImpl) GetAll(c *fiber.Ctx) error {
+	var request dtos.UserGetRequest
+	if err := c.QueryParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid query parameters",
+			Code:    fiber.StatusBadRequest,
+			Errors:  err.Error(),
+		})
+	}
+
+	if request.Page < 1 {
+		request.Page = 1
+	}
+	if request.Limit < 1 {
+		request.Limit = 10
+	}
+
+	if request.SortBy != "" {
+		allowedSortFields := map[string]bool{
+			"name":       true,
+			"email":      true,
+			"role":       true,
+			"created_at": true,
+			"updated_at": true,
+		}
+		if !allowedSortFields[request.SortBy] {
+			return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
 				Success: false,
-				Message: "Failed to save file",
-				Code:    fiber.StatusInternalServerError,
-				Errors:  err.Error(),
+				Message: "Invalid sort_by parameter. Allowed values: name, email, role, created_at, updated_at",
+				Code:    fiber.StatusBadRequest,
 			})
 		}
+	}
 
-		// Set the image path in the request
-		request.Image = filepath
+	if request.SortOrder != "" && request.SortOrder != "asc" && request.SortOrder != "desc" {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid sort_order parameter. Allowed values: asc, desc",
+			Code:    fiber.StatusBadRequest,
+		})
 	}
 
-	if err := u.userService.Register(request); err != nil {
+	if request.Status != "" && request.Status != dtos.UserStatusActive && request.Status != dtos.UserStatusDeactivated {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid status parameter. Allowed values: active, deactivated",
+			Code:    fiber.StatusBadRequest,
+		})
+	}
+
+	users, paginationMeta, err := u.userService.GetAll(request)
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(dtos.ErrorResponseDTO{
 			Success: false,
-			Message: err.Error(),
+			Message: "Failed to fetch users",
 			Code:    fiber.StatusInternalServerError,
 			Errors:  err.Error(),
 		})
 	}
 
+	return c.Status(fiber.StatusOK).JSON(dtos.PaginatedSuccessResponse{
+		Success: true,
+		Message: "Successfully fetched users",
+		Data:    users,
+		Meta:    *paginationMeta,
+	})
+}
+
+// Create godoc
+// @Summary Create a user
+// @Description Requires the users:manage permission. Unlike the public registration, the role is chosen by the caller.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param request body dtos.UserCreateRequest true "User"
+// @Success 201 {object} dtos.SuccessResponse{data=dtos.UserResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 403 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Failure 409 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /user [post]
+func (u *userControllerImpl) Create(c *fiber.Ctx) error {
+	var request dtos.UserCreateRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  err.Error(),
+		})
+	}
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	user, err := u.userService.Create(request)
+	if err != nil {
+		return userErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "User created successfully",
+		Data:    user,
+	})
+}
+
+// Deactivate godoc
+// @Summary Deactivate a user
+// @Description Requires the users:manage permission. The user is signed out everywhere and cannot sign in until reactivated. Admins cannot deactivate themselves or the last admin.
+// @Tags user
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "User ID"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.UserResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 403 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Failure 409 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /user/{id}/deactivate [post]
+func (u *userControllerImpl) Deactivate(c *fiber.Ctx) error {
+	uuid := c.Params("id")
+	if !helpers.CheckLengthUUID(uuid) {
+		return invalidUserIDResponse(c)
+	}
+
+	actorUuid, _ := c.Locals("user_uuid").(string)
+	user, err := u.userService.Deactivate(actorUuid, uuid)
+	if err != nil {
+		return userErrorResponse(c, err)
+	}
+
 	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
 		Success: true,
-		Message: "User registered successfully",
-		Data:    nil,
+		Message: "User deactivated successfully",
+		Data:    user,
+	})
+}
+
+// Reactivate godoc
+// @Summary Reactivate a user
+// @Description Requires the users:manage permission. The user can sign in again.
+// @Tags user
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "User ID"
+// @Success 200 {object} dtos.SuccessResponse{data=dtos.UserResponse}
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 403 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /user/{id}/reactivate [post]
+func (u *userControllerImpl) Reactivate(c *fiber.Ctx) error {
+	uuid := c.Params("id")
+	if !helpers.CheckLengthUUID(uuid) {
+		return invalidUserIDResponse(c)
+	}
+
+	user, err := u.userService.Reactivate(uuid)
+	if err != nil {
+		return userErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "User reactivated successfully",
+		Data:    user,
+	})
+}
+
+// ResetPassword godoc
+// @Summary Reset the password of a user
+// @Description Requires the users:manage permission. Every session of the user ends.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "User ID"
+// @Param request body dtos.UserPasswordResetRequest true "New password"
+// @Success 200 {object} dtos.SuccessResponse
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 403 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /user/{id}/reset-password [post]
+func (u *userControllerImpl) ResetPassword(c *fiber.Ctx) error {
+	var request dtos.UserPasswordResetRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  err.Error(),
+		})
+	}
+
+	request.UUID = c.Params("id")
+	if !helpers.CheckLengthUUID(request.UUID) {
+		return invalidUserIDResponse(c)
+	}
+
+	validate := helpers.NewValidator()
+	if err := validate.Struct(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+			Success: false,
+			Message: "Invalid request body",
+			Code:    fiber.StatusBadRequest,
+			Errors:  helpers.FormatValidationError(err),
+		})
+	}
+
+	if err := u.userService.ResetPassword(request); err != nil {
+		return userErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Password reset successfully",
+	})
+}
+
+// Delete godoc
+// @Summary Delete a user
+// @Description Requires the users:manage permission. The user is soft-deleted and signed out everywhere. Admins cannot delete themselves or the last admin.
+// @Tags user
+// @Produce json
+// @Security BearerAuth
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "User ID"
+// @Success 200 {object} dtos.SuccessResponse
+// @Failure 400 {object} dtos.ErrorResponseDTO
+// @Failure 401 {object} dtos.ErrorResponseDTO
+// @Failure 403 {object} dtos.ErrorResponseDTO
+// @Failure 404 {object} dtos.ErrorResponseDTO
+// @Failure 409 {object} dtos.ErrorResponseDTO
+// @Failure 500 {object} dtos.ErrorResponseDTO
+// @Router /user/{id} [delete]
+func (u *userControllerImpl) Delete(c *fiber.Ctx) error {
+	uuid := c.Params("id")
+	if !helpers.CheckLengthUUID(uuid) {
+		return invalidUserIDResponse(c)
+	}
+
+	actorUuid, _ := c.Locals("user_uuid").(string)
+	if err := u.userService.Delete(actorUuid, uuid); err != nil {
+		return userErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "User deleted successfully",
+	})
+}
+
+func invalidUserIDResponse(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponseDTO{
+		Success: false,
+		Message: "Invalid user ID",
+		Code:    fiber.StatusBadRequest,
+	})
+}
+
+// userErrorResponse maps the errors of the user management endpoints to a status
+func userErrorResponse(c *fiber.Ctx, err error) error {
+	status := fiber.StatusBadRequest
+	switch {
+	case strings.HasSuffix(err.Error(), "not found"):
+		status = fiber.StatusNotFound
+	case strings.HasSuffix(err.Error(), "already exists on database"),
+		strings.HasPrefix(err.Error(), "the last admin"),
+		strings.HasPrefix(err.Error(), "you cannot"):
+		status = fiber.StatusConflict
+	case err.Error() == "please try again later":
+		status = fiber.StatusInternalServerError
+	}
+
+	return c.Status(status).JSON(dtos.ErrorResponseDTO{
+		Success: false,
+		Message: err.Error(),
+		Code:    status,
+		Errors:  err.Error(),
 	})
 }
 
 // Router implements UserController.
 func (u *userControllerImpl) Router(router fiber.Router) {
 	router.Post("/register", u.Register)
+
+	withMiddleware := router.Use(jwt.JwtMiddleware(u.userService, u.redisService))
+	{
+		withMiddleware.Put("/:id/team", permission.RequirePermission(models.PermissionUsersManage), u.SetTeam)
+		withMiddleware.Put("/:id/role", permission.RequirePermission(models.PermissionUsersManage), u.SetRole)
+		withMiddleware.Get("/", permission.RequirePermission(models.PermissionUsersManage), u.GetAll)
+		withMiddleware.Post("/", permission.RequirePermission(models.PermissionUsersManage), u.Create)
+		withMiddleware.Post("/:id/deactivate", permission.RequirePermission(models.PermissionUsersManage), u.Deactivate)
+		withMiddleware.Post("/:id/reactivate", permission.RequirePermission(models.PermissionUsersManage), u.Reactivate)
+		withMiddleware.Post("/:id/reset-password", permission.RequirePermission(models.PermissionUsersManage), u.ResetPassword)
+		withMiddleware.Delete("/:id", permission.RequirePermission(models.PermissionUsersManage), u.Delete)
+	}
 }
 
 func NewUserController(
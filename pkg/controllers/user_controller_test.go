@@ -123,6 +123,69 @@ func (suite *UserControllerTestSuite) TestRegister_ServiceError() {
 	suite.mockUserService.AssertExpectations(suite.T())
 }
 
+func (suite *UserControllerTestSuite) TestRegister_IgnoresRole() {
+	// Arrange
+	suite.mockUserService.On("Register", mock.MatchedBy(func(request dtos.UserRegisterRequest) bool {
+		return request.Role == ""
+	})).Return(nil)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	fields := map[string]string{
+		"name":                  "John Doe",
+		"email":                 "john@example.com",
+		"password":              "password123",
+		"confirmation_password": "password123",
+		"phone_number":          "+1234567890",
+		"role":                  "admin",
+	}
+	for key, value := range fields {
+		writer.WriteField(key, value)
+	}
+	writer.Close()
+
+	// Act
+	req := httptest.NewRequest("POST", "/user/register", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	resp, err := suite.app.Test(req)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+	suite.mockUserService.AssertExpectations(suite.T())
+}
+
+func (suite *UserControllerTestSuite) TestRegister_Disabled() {
+	// Arrange
+	suite.mockUserService.On("Register", mock.AnythingOfType("dtos.UserRegisterRequest")).Return(errors.New("registration is disabled"))
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	writer.WriteField("email", "john@example.com")
+	writer.Close()
+
+	// Act
+	req := httptest.NewRequest("POST", "/user/register", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	resp, err := suite.app.Test(req)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusForbidden, resp.StatusCode)
+	suite.mockUserService.AssertExpectations(suite.T())
+}
+
+func (suite *UserControllerTestSuite) TestGetAll_Unauthorized() {
+	// Act
+	req := httptest.NewRequest("GET", "/user", nil)
+	resp, err := suite.app.Test(req)
+
+	// Assert
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusUnauthorized, resp.StatusCode)
+	suite.mockUserService.AssertNotCalled(suite.T(), "GetAll", mock.Anything)
+}
+
 func TestUserControllerTestSuite(t *testing.T) {
 	suite.Run(t, new(UserControllerTestSuite))
 }
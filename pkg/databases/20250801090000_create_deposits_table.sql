@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE deposits (
+    uuid UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_uuid UUID NOT NULL REFERENCES clients(uuid),
+    property_uuid UUID NOT NULL REFERENCES properties(uuid),
+    amount DECIMAL(14, 2) NOT NULL,
+    payment_method VARCHAR(50),
+    received_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'held' CHECK (status IN ('held', 'refunded')),
+    refund_amount DECIMAL(14, 2) NOT NULL DEFAULT 0,
+    refund_method VARCHAR(50),
+    refund_reference VARCHAR(100),
+    refunded_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE DEFAULT NULL
+);
+
+CREATE INDEX idx_deposits_client_uuid ON deposits(client_uuid);
+CREATE INDEX idx_deposits_property_uuid ON deposits(property_uuid);
+CREATE INDEX idx_deposits_status ON deposits(status);
+CREATE INDEX idx_deposits_deleted_at ON deposits(deleted_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS deposits;
+DROP INDEX IF EXISTS idx_deposits_client_uuid;
+DROP INDEX IF EXISTS idx_deposits_property_uuid;
+DROP INDEX IF EXISTS idx_deposits_status;
+DROP INDEX IF EXISTS idx_deposits_deleted_at;
+-- +goose StatementEnd
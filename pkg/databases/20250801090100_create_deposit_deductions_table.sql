@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE deposit_deductions (
+    uuid UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    deposit_uuid UUID NOT NULL REFERENCES deposits(uuid),
+    category VARCHAR(30) NOT NULL CHECK (category IN ('damage', 'unpaid_utilities', 'cleaning', 'other')),
+    description TEXT NOT NULL,
+    amount DECIMAL(14, 2) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE DEFAULT NULL
+);
+
+CREATE TABLE deposit_deduction_photos (
+    uuid UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    deduction_uuid UUID NOT NULL REFERENCES deposit_deductions(uuid),
+    photo_url TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE DEFAULT NULL
+);
+
+CREATE INDEX idx_deposit_deductions_deposit_uuid ON deposit_deductions(deposit_uuid);
+CREATE INDEX idx_deposit_deduction_photos_deduction_uuid ON deposit_deduction_photos(deduction_uuid);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS deposit_deduction_photos;
+DROP TABLE IF EXISTS deposit_deductions;
+DROP INDEX IF EXISTS idx_deposit_deductions_deposit_uuid;
+DROP INDEX IF EXISTS idx_deposit_deduction_photos_deduction_uuid;
+-- +goose StatementEnd
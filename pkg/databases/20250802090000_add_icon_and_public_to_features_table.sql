@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE features ADD COLUMN icon VARCHAR(255) DEFAULT NULL;
+ALTER TABLE features ADD COLUMN is_public BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_features_is_public ON features(is_public);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_features_is_public;
+ALTER TABLE features DROP COLUMN IF EXISTS is_public;
+ALTER TABLE features DROP COLUMN IF EXISTS icon;
+-- +goose StatementEnd
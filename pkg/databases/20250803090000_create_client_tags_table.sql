@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE client_tags (
+    uuid UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_uuid UUID NOT NULL REFERENCES clients(uuid),
+    tag VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_client_tags_client_tag ON client_tags(client_uuid, tag);
+CREATE INDEX idx_client_tags_tag ON client_tags(tag);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_client_tags_tag;
+DROP INDEX IF EXISTS idx_client_tags_client_tag;
+DROP TABLE IF EXISTS client_tags;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE client_activities (
+    uuid UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_uuid UUID NOT NULL REFERENCES clients(uuid),
+    author_uuid UUID REFERENCES users(uuid),
+    type VARCHAR(20) NOT NULL CHECK (type IN ('note', 'call', 'email')),
+    subject VARCHAR(255),
+    body TEXT,
+    outcome VARCHAR(30),
+    follow_up_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE DEFAULT NULL
+);
+
+CREATE INDEX idx_client_activities_client_uuid_occurred_at ON client_activities(client_uuid, occurred_at);
+CREATE INDEX idx_client_activities_follow_up_at ON client_activities(follow_up_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_client_activities_follow_up_at;
+DROP INDEX IF EXISTS idx_client_activities_client_uuid_occurred_at;
+DROP TABLE IF EXISTS client_activities;
+-- +goose StatementEnd
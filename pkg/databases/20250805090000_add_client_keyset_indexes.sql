@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Keyset pagination orders by the sort column with the uuid as a tiebreaker
+CREATE INDEX idx_clients_created_at_uuid ON clients(created_at, uuid) WHERE deleted_at IS NULL;
+CREATE INDEX idx_clients_updated_at_uuid ON clients(updated_at, uuid) WHERE deleted_at IS NULL;
+CREATE INDEX idx_clients_name_uuid ON clients(name, uuid) WHERE deleted_at IS NULL;
+CREATE INDEX idx_clients_email_uuid ON clients(email, uuid) WHERE deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_clients_email_uuid;
+DROP INDEX IF EXISTS idx_clients_name_uuid;
+DROP INDEX IF EXISTS idx_clients_updated_at_uuid;
+DROP INDEX IF EXISTS idx_clients_created_at_uuid;
+-- +goose StatementEnd
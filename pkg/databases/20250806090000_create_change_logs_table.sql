@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE change_logs (
+    uuid UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    entity_type VARCHAR(30) NOT NULL,
+    entity_uuid UUID NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('create', 'update', 'delete', 'restore', 'merge')),
+    actor_uuid UUID,
+    request_id VARCHAR(100),
+    changes JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_change_logs_entity ON change_logs(entity_type, entity_uuid, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_change_logs_entity;
+DROP TABLE IF EXISTS change_logs;
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE client_documents (
+    uuid UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_uuid UUID NOT NULL REFERENCES clients(uuid),
+    type VARCHAR(20) NOT NULL CHECK (type IN ('ktp', 'npwp', 'passport')),
+    number VARCHAR(50) NOT NULL,
+    expires_at DATE DEFAULT NULL,
+    file_path VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'verified', 'rejected')),
+    rejection_reason TEXT,
+    uploaded_by UUID REFERENCES users(uuid),
+    reviewed_by UUID REFERENCES users(uuid),
+    reviewed_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE DEFAULT NULL
+);
+
+CREATE INDEX idx_client_documents_client_uuid ON client_documents(client_uuid);
+CREATE INDEX idx_client_documents_expires_at ON client_documents(expires_at) WHERE status = 'verified';
+
+ALTER TABLE clients ADD COLUMN kyc_status VARCHAR(20) NOT NULL DEFAULT 'none';
+CREATE INDEX idx_clients_kyc_status ON clients(kyc_status);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_clients_kyc_status;
+ALTER TABLE clients DROP COLUMN IF EXISTS kyc_status;
+DROP INDEX IF EXISTS idx_client_documents_expires_at;
+DROP INDEX IF EXISTS idx_client_documents_client_uuid;
+DROP TABLE IF EXISTS client_documents;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE client_contacts (
+    uuid UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_uuid UUID NOT NULL REFERENCES clients(uuid),
+    name VARCHAR(255) NOT NULL,
+    role VARCHAR(30) NOT NULL DEFAULT 'general' CHECK (role IN ('general', 'finance', 'legal', 'facility', 'management')),
+    email VARCHAR(255),
+    phone_number VARCHAR(30),
+    preferred_channel VARCHAR(20) CHECK (preferred_channel IN ('', 'email', 'phone', 'whatsapp')),
+    is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE DEFAULT NULL
+);
+
+CREATE INDEX idx_client_contacts_client_uuid ON client_contacts(client_uuid);
+CREATE UNIQUE INDEX idx_client_contacts_primary ON client_contacts(client_uuid) WHERE is_primary AND deleted_at IS NULL;
+
+-- The free-text contact person of every existing client becomes its primary contact
+INSERT INTO client_contacts (client_uuid, name, is_primary, created_at, updated_at)
+SELECT uuid, contact_person, TRUE, created_at, updated_at
+FROM clients
+WHERE contact_person <> '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_client_contacts_primary;
+DROP INDEX IF EXISTS idx_client_contacts_client_uuid;
+DROP TABLE IF EXISTS client_contacts;
+-- +goose StatementEnd
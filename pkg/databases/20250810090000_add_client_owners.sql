@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN team VARCHAR(100) NOT NULL DEFAULT '';
+CREATE INDEX idx_users_team ON users(team);
+
+-- Existing clients have no owner, only admins see them until they are reassigned
+ALTER TABLE clients ADD COLUMN owner_uuid UUID DEFAULT NULL REFERENCES users(uuid);
+CREATE INDEX idx_clients_owner_uuid ON clients(owner_uuid);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_clients_owner_uuid;
+ALTER TABLE clients DROP COLUMN IF EXISTS owner_uuid;
+DROP INDEX IF EXISTS idx_users_team;
+ALTER TABLE users DROP COLUMN IF EXISTS team;
+-- +goose StatementEnd
@@ -0,0 +1,112 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE permissions (
+    name VARCHAR(100) PRIMARY KEY,
+    description VARCHAR(255)
+);
+
+CREATE TABLE roles (
+    name VARCHAR(50) PRIMARY KEY,
+    description VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE role_permissions (
+    role_name VARCHAR(50) NOT NULL REFERENCES roles(name) ON UPDATE CASCADE ON DELETE CASCADE,
+    permission_name VARCHAR(100) NOT NULL REFERENCES permissions(name) ON UPDATE CASCADE ON DELETE CASCADE,
+    PRIMARY KEY (role_name, permission_name)
+);
+
+INSERT INTO permissions (name, description) VALUES
+    ('clients:read', 'View clients visible to the user, with their timeline, history, documents and contacts'),
+    ('clients:write', 'Create and update clients, their contacts, tags, documents and activities'),
+    ('clients:delete', 'Delete clients and merge duplicates into another client'),
+    ('clients:read-all', 'See the clients of every agent, not only those of the user and their team'),
+    ('clients:manage', 'Manage the client trash and hand clients over to other agents'),
+    ('clients:privacy', 'Export and erase the personal data of clients'),
+    ('documents:review', 'Verify or reject KYC documents'),
+    ('deposits:read', 'View deposits and their settlements'),
+    ('deposits:write', 'Record deposits and deductions'),
+    ('deposits:refund', 'Refund deposits'),
+    ('features:read', 'View property features'),
+    ('features:write', 'Create and update property features'),
+    ('features:delete', 'Delete property features'),
+    ('features:manage', 'Manage the property feature trash'),
+    ('segments:read', 'View client segments'),
+    ('segments:write', 'Create, update and delete client segments'),
+    ('users:manage', 'Assign users to roles and teams and revoke their sessions'),
+    ('roles:manage', 'Create roles and change their permissions');
+
+-- The admin role needs no rows, it holds every permission
+INSERT INTO roles (name, description) VALUES
+    ('admin', 'Full access, including permissions added later'),
+    ('manager', 'Runs a team of agents and manages every client'),
+    ('agent', 'Works with their own and their team''s clients'),
+    ('finance', 'Handles deposits and refunds'),
+    ('viewer', 'Read-only access'),
+    ('user', 'Default role of registered users, same as agent');
+
+INSERT INTO role_permissions (role_name, permission_name) VALUES
+    ('manager', 'clients:read'),
+    ('manager', 'clients:write'),
+    ('manager', 'clients:delete'),
+    ('manager', 'clients:read-all'),
+    ('manager', 'clients:manage'),
+    ('manager', 'documents:review'),
+    ('manager', 'deposits:read'),
+    ('manager', 'deposits:write'),
+    ('manager', 'deposits:refund'),
+    ('manager', 'features:read'),
+    ('manager', 'features:write'),
+    ('manager', 'features:delete'),
+    ('manager', 'segments:read'),
+    ('manager', 'segments:write'),
+    ('agent', 'clients:read'),
+    ('agent', 'clients:write'),
+    ('agent', 'clients:delete'),
+    ('agent', 'deposits:read'),
+    ('agent', 'deposits:write'),
+    ('agent', 'features:read'),
+    ('agent', 'features:write'),
+    ('agent', 'features:delete'),
+    ('agent', 'segments:read'),
+    ('agent', 'segments:write'),
+    ('finance', 'clients:read'),
+    ('finance', 'clients:read-all'),
+    ('finance', 'deposits:read'),
+    ('finance', 'deposits:write'),
+    ('finance', 'deposits:refund'),
+    ('finance', 'features:read'),
+    ('finance', 'segments:read'),
+    ('viewer', 'clients:read'),
+    ('viewer', 'deposits:read'),
+    ('viewer', 'features:read'),
+    ('viewer', 'segments:read'),
+    ('user', 'clients:read'),
+    ('user', 'clients:write'),
+    ('user', 'clients:delete'),
+    ('user', 'deposits:read'),
+    ('user', 'deposits:write'),
+    ('user', 'features:read'),
+    ('user', 'features:write'),
+    ('user', 'features:delete'),
+    ('user', 'segments:read'),
+    ('user', 'segments:write');
+
+-- Roles typed into the old free-text column keep working, without permissions until granted
+INSERT INTO roles (name, description)
+SELECT DISTINCT role, 'Imported from the former role column'
+FROM users
+WHERE role NOT IN (SELECT name FROM roles);
+
+ALTER TABLE users ADD CONSTRAINT fk_users_role FOREIGN KEY (role) REFERENCES roles(name) ON UPDATE CASCADE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP CONSTRAINT IF EXISTS fk_users_role;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS roles;
+DROP TABLE IF EXISTS permissions;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- E.164 numbers have up to 15 digits, stored with their leading +
+ALTER TABLE users ALTER COLUMN phone_number TYPE VARCHAR(16);
+ALTER TABLE clients ALTER COLUMN phone_number TYPE VARCHAR(16);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE clients ALTER COLUMN phone_number TYPE VARCHAR(15);
+ALTER TABLE users ALTER COLUMN phone_number TYPE VARCHAR(15);
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Anyone can sign up and get the user role, so it only reads like viewer. Write access comes
+-- from a role an admin assigns.
+DELETE FROM role_permissions
+WHERE role_name = 'user'
+  AND permission_name NOT IN ('clients:read', 'deposits:read', 'features:read', 'segments:read');
+
+UPDATE roles SET description = 'Default role of registered users, read-only' WHERE name = 'user';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE roles SET description = 'Default role of registered users, same as agent' WHERE name = 'user';
+
+INSERT INTO role_permissions (role_name, permission_name) VALUES
+    ('user', 'clients:write'),
+    ('user', 'clients:delete'),
+    ('user', 'deposits:write'),
+    ('user', 'features:write'),
+    ('user', 'features:delete'),
+    ('user', 'segments:write')
+ON CONFLICT DO NOTHING;
+-- +goose StatementEnd
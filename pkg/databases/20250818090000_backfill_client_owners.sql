@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Clients from before owners existed go to the user who created them, when the change log
+-- records one who still has an account. Any left without an owner stay visible to admins
+-- only and have to be reassigned by an admin.
+UPDATE clients SET owner_uuid = creators.actor_uuid
+FROM (
+    SELECT DISTINCT ON (change_logs.entity_uuid) change_logs.entity_uuid, change_logs.actor_uuid
+    FROM change_logs
+    JOIN users ON users.uuid = change_logs.actor_uuid AND users.deleted_at IS NULL
+    WHERE change_logs.entity_type = 'client' AND change_logs.action = 'create'
+    ORDER BY change_logs.entity_uuid, change_logs.created_at ASC
+) AS creators
+WHERE clients.uuid = creators.entity_uuid AND clients.owner_uuid IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Owners given by the backfill cannot be told apart from ones assigned later, they are kept
+SELECT 1;
+-- +goose StatementEnd
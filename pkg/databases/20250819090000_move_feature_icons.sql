@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Icons are served from /uploads/icons. The files of icons uploaded before have to be moved
+-- from uploads/ into uploads/icons/ when this migration is deployed.
+UPDATE features SET icon = '/uploads/icons/' || substring(icon FROM length('./uploads/') + 1)
+WHERE icon LIKE './uploads/%';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE features SET icon = './uploads/' || substring(icon FROM length('/uploads/icons/') + 1)
+WHERE icon LIKE '/uploads/icons/%';
+-- +goose StatementEnd
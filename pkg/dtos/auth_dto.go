@@ -3,6 +3,8 @@ package dtos
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=6,max=100"`
+	// Device names the device of the session, e.g. "Front desk tablet"
+	Device string `json:"device" validate:"omitempty,max=100"`
 }
 
 type LoginResponse struct {
@@ -13,4 +15,36 @@ type LoginResponse struct {
 	Email        string `json:"email"`
 	UserUuid     string `json:"user_uuid"`
 	Name         string `json:"name"`
+	// TwoFactorRequired is set instead of the tokens for users with two-factor authentication,
+	// the login completes with the challenge token at /auth/2fa/verify
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type LogoutRequest struct {
+	AccessToken  string `json:"-"`
+	UserUuid     string `json:"-"`
+	RefreshToken string `json:"refresh_token" validate:"omitempty"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token                string `json:"token" validate:"required"`
+	Password             string `json:"password" validate:"required,min=6,max=100"`
+	ConfirmationPassword string `json:"confirmation_password" validate:"required,eqfield=Password"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
 }
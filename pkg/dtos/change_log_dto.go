@@ -0,0 +1,36 @@
+package dtos
+
+import "time"
+
+// ChangeActor identifies who made a change and the request it was made in
+type ChangeActor struct {
+	UserUUID  string
+	RequestID string
+}
+
+// FieldChange is the value of a field before and after a change. From is null for a created
+// record and To is null for a deleted one.
+type FieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+type ChangeLogGetRequest struct {
+	EntityType string `json:"-" query:"-"`
+	EntityUUID string `json:"-" query:"-"`
+	Page       int    `json:"page" query:"page" default:"1"`
+	Limit      int    `json:"limit" query:"limit" default:"20"`
+	// Field only returns changes that touched this field, e.g. phone_number
+	Field string `json:"field" query:"field"`
+}
+
+type ChangeLogResponse struct {
+	UUID       string                 `json:"uuid"`
+	EntityType string                 `json:"entity_type"`
+	EntityUUID string                 `json:"entity_uuid"`
+	Action     string                 `json:"action"`
+	ActorUUID  *string                `json:"actor_uuid"`
+	RequestID  string                 `json:"request_id"`
+	Changes    map[string]FieldChange `json:"changes"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
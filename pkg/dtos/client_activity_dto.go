@@ -0,0 +1,71 @@
+package dtos
+
+import "time"
+
+type ClientNoteRequest struct {
+	ClientUUID string
+	AuthorUUID string
+	Body       string `json:"body" validate:"required"`
+}
+
+type ClientCallRequest struct {
+	ClientUUID string
+	AuthorUUID string
+	Summary    string     `json:"summary" validate:"required"`
+	Outcome    string     `json:"outcome" validate:"required,oneof=answered no_answer voicemail busy wrong_number"`
+	FollowUpAt *time.Time `json:"follow_up_at"`
+	// OccurredAt defaults to now when the call is logged right away
+	OccurredAt *time.Time `json:"occurred_at"`
+}
+
+type ClientEmailRequest struct {
+	ClientUUID string
+	AuthorUUID string
+	Subject    string     `json:"subject" validate:"required,max=255"`
+	Body       string     `json:"body"`
+	OccurredAt *time.Time `json:"occurred_at"`
+}
+
+type ClientActivityResponse struct {
+	UUID       string     `json:"uuid"`
+	ClientUUID string     `json:"client_uuid"`
+	AuthorUUID *string    `json:"author_uuid"`
+	Type       string     `json:"type"`
+	Subject    string     `json:"subject,omitempty"`
+	Body       string     `json:"body"`
+	Outcome    string     `json:"outcome,omitempty"`
+	FollowUpAt *time.Time `json:"follow_up_at,omitempty"`
+	OccurredAt time.Time  `json:"occurred_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// ClientTimelineTypes lists every entry type the timeline can contain
+var ClientTimelineTypes = []string{
+	"note",
+	"call",
+	"email",
+	"deposit_received",
+	"deposit_deduction",
+	"deposit_refunded",
+}
+
+type ClientTimelineRequest struct {
+	ClientUUID string
+	Page       int    `json:"page" query:"page" default:"1"`
+	Limit      int    `json:"limit" query:"limit" default:"20"`
+	Types      string `json:"types" query:"types"`
+}
+
+// ClientTimelineItem is one entry of the client activity feed. Logged activities and system
+// events such as deposits share this shape.
+type ClientTimelineItem struct {
+	Type          string     `json:"type"`
+	ReferenceUUID string     `json:"reference_uuid"`
+	OccurredAt    time.Time  `json:"occurred_at"`
+	Summary       string     `json:"summary"`
+	Body          string     `json:"body,omitempty"`
+	Outcome       string     `json:"outcome,omitempty"`
+	FollowUpAt    *time.Time `json:"follow_up_at,omitempty"`
+	Amount        *float64   `json:"amount,omitempty"`
+	AuthorUUID    *string    `json:"author_uuid,omitempty"`
+}
@@ -0,0 +1,32 @@
+package dtos
+
+import "time"
+
+// ClientContactRequest creates a contact, or replaces every field of one on update. A contact
+// marked primary takes over from the current primary contact.
+type ClientContactRequest struct {
+	ClientUUID       string `json:"-"`
+	UUID             string `json:"-"`
+	Name             string `json:"name" validate:"required,max=255"`
+	Role             string `json:"role" validate:"required,oneof=general finance legal facility management"`
+	Email            string `json:"email" validate:"omitempty,email"`
+	PhoneNumber      string `json:"phone_number" validate:"omitempty,phone"`
+	PreferredChannel string `json:"preferred_channel" validate:"omitempty,oneof=email phone whatsapp"`
+	IsPrimary        bool   `json:"is_primary"`
+
+	// Actor is the signed in user making the change, recorded in the client history
+	Actor ChangeActor `json:"-"`
+}
+
+type ClientContactResponse struct {
+	UUID             string    `json:"uuid"`
+	ClientUUID       string    `json:"client_uuid"`
+	Name             string    `json:"name"`
+	Role             string    `json:"role"`
+	Email            string    `json:"email"`
+	PhoneNumber      string    `json:"phone_number"`
+	PreferredChannel string    `json:"preferred_channel"`
+	IsPrimary        bool      `json:"is_primary"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
@@ -0,0 +1,44 @@
+package dtos
+
+import "time"
+
+// ClientDocumentRequest is a KYC document upload. The file itself is read from the multipart
+// form and stored before the request reaches the service.
+type ClientDocumentRequest struct {
+	ClientUUID string `form:"-" json:"-"`
+	Type       string `form:"type" json:"type" validate:"required,oneof=ktp npwp passport"`
+	Number     string `form:"number" json:"number" validate:"required,max=50"`
+	FilePath   string `form:"-" json:"-"`
+
+	// ExpiresAt is the expiry date as YYYY-MM-DD, a KTP issued since 2011 does not expire
+	ExpiresAt string `form:"expires_at" json:"expires_at" validate:"omitempty,datetime=2006-01-02"`
+
+	// Actor is the signed in user handing in the document
+	Actor ChangeActor `form:"-" json:"-"`
+}
+
+// ClientDocumentReviewRequest is an admin's decision on a pending document
+type ClientDocumentReviewRequest struct {
+	ClientUUID   string      `json:"-"`
+	DocumentUUID string      `json:"-"`
+	Status       string      `json:"status" validate:"required,oneof=verified rejected"`
+	Reason       string      `json:"reason" validate:"required_if=Status rejected,max=500"`
+	Actor        ChangeActor `json:"-"`
+}
+
+type ClientDocumentResponse struct {
+	UUID            string     `json:"uuid"`
+	ClientUUID      string     `json:"client_uuid"`
+	Type            string     `json:"type"`
+	Number          string     `json:"number"`
+	ExpiresAt       *time.Time `json:"expires_at"`
+	Status          string     `json:"status"`
+	RejectionReason string     `json:"rejection_reason,omitempty"`
+	UploadedBy      *string    `json:"uploaded_by"`
+	ReviewedBy      *string    `json:"reviewed_by"`
+	ReviewedAt      *time.Time `json:"reviewed_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+
+	// FilePath is where the scan is stored, it is downloaded through its own endpoint
+	FilePath string `json:"-"`
+}
@@ -5,20 +5,56 @@ import "time"
 type ClientRequest struct {
 	Name          string `json:"name" validate:"required"`
 	Email         string `json:"email" validate:"required,email"`
-	PhoneNumber   string `json:"phone_number" validate:"required"`
+	PhoneNumber   string `json:"phone_number" validate:"required,phone"`
 	Address       string `json:"address" validate:"required"`
 	ContactPerson string `json:"contact_person" validate:"required"`
+
+	// Actor is the signed in user making the change, recorded in the client history
+	Actor ChangeActor `json:"-"`
 }
 
 type ClientResponse struct {
-	UUID          string    `json:"uuid"`
-	Name          string    `json:"name"`
-	Email         string    `json:"email"`
-	PhoneNumber   string    `json:"phone_number"`
-	Address       string    `json:"address"`
-	ContactPerson string    `json:"contact_person"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	UUID          string     `json:"uuid"`
+	Name          string     `json:"name"`
+	Email         string     `json:"email"`
+	PhoneNumber   string     `json:"phone_number"`
+	Address       string     `json:"address"`
+	ContactPerson string     `json:"contact_person"`
+	Tags          []string   `json:"tags"`
+	KycStatus     string     `json:"kyc_status"`
+	OwnerUUID     *string    `json:"owner_uuid"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	DeletedAt     *time.Time `json:"deleted_at,omitempty"`
+	ErasedAt      *time.Time `json:"erased_at,omitempty"`
+
+	// Contacts is only filled when a single client is read, ContactPerson is the name of the
+	// primary one
+	Contacts []ClientContactResponse `json:"contacts,omitempty"`
+
+	// ETag identifies this version of the client, it is sent as a header and not in the body
+	ETag string `json:"-"`
+}
+
+// ClientSearchFields are the fields search_by accepts, search without search_by also matches
+// the address
+var ClientSearchFields = []string{"name", "email", "phone_number", "contact_person"}
+
+// ClientSortFields are the fields sort_by accepts
+var ClientSortFields = []string{"name", "email", "created_at", "updated_at"}
+
+// ClientFilterFields are the fields the client list and export can be filtered on with
+// filter[field][operator]=value
+var ClientFilterFields = map[string]FilterField{
+	"name":           {Column: "clients.name", Type: FilterTypeText},
+	"email":          {Column: "clients.email", Type: FilterTypeText},
+	"phone_number":   {Column: "clients.phone_number", Type: FilterTypeText},
+	"address":        {Column: "clients.address", Type: FilterTypeText},
+	"contact_person": {Column: "clients.contact_person", Type: FilterTypeText},
+	"kyc_status":     {Column: "clients.kyc_status", Type: FilterTypeText},
+	"created_at":     {Column: "clients.created_at", Type: FilterTypeTime},
+	"updated_at":     {Column: "clients.updated_at", Type: FilterTypeTime},
+	"tags":           {Type: FilterTypeTags},
 }
 
 type ClientGetRequest struct {
@@ -28,13 +64,86 @@ type ClientGetRequest struct {
 	SearchBy  string `json:"search_by" query:"search_by"`
 	SortBy    string `json:"sort_by" query:"sort_by" default:"created_at"`
 	SortOrder string `json:"sort_order" query:"sort_order" default:"desc"`
+	Tags      string `json:"tags" query:"tags"`
+	Segment   string `json:"segment" query:"segment"`
+	Cursor    string `json:"cursor" query:"cursor"`
+	WithTotal *bool  `json:"with_total" query:"with_total"`
+
+	// Filter is the resolved filter of Segment, it is never read from the query string
+	Filter *SegmentFilter `json:"-" query:"-"`
+	// After is the decoded Cursor, it is never read from the query string
+	After *Cursor `json:"-" query:"-"`
+	// Filters are the parsed filter[field][operator] parameters
+	Filters []FilterCondition `json:"-" query:"-"`
+	// Visibility limits the result to the clients of the signed in user, nil lists every client
+	Visibility *ClientVisibility `json:"-" query:"-"`
+}
+
+type ClientExportRequest struct {
+	ClientGetRequest
+	Format  string `json:"format" query:"format" default:"csv"`
+	Columns string `json:"columns" query:"columns"`
 }
 
 type ClientUpdateRequest struct {
 	UUID          string
 	Name          string `json:"name" validate:"omitempty"`
 	Email         string `json:"email" validate:"omitempty,email"`
-	PhoneNumber   string `json:"phone_number" validate:"omitempty"`
+	PhoneNumber   string `json:"phone_number" validate:"omitempty,phone"`
 	Address       string `json:"address" validate:"omitempty"`
 	ContactPerson string `json:"contact_person" validate:"omitempty"`
+
+	// IfMatch is the If-Match header, the update only applies to the version it names
+	IfMatch string      `json:"-"`
+	Actor   ChangeActor `json:"-"`
+}
+
+// ClientPatchRequest is a client after a PATCH document was applied to it. Every field is
+// written, and IfMatch names the version the patch was applied to.
+type ClientPatchRequest struct {
+	UUID          string `json:"-"`
+	Name          string `json:"name" validate:"required"`
+	Email         string `json:"email" validate:"required,email"`
+	PhoneNumber   string `json:"phone_number" validate:"required,phone"`
+	Address       string `json:"address" validate:"required"`
+	ContactPerson string `json:"contact_person" validate:"required"`
+	IfMatch       string `json:"-"`
+
+	// Actor is the signed in user making the change, recorded in the client history
+	Actor ChangeActor `json:"-"`
+}
+
+type ClientDuplicateRequest struct {
+	MinScore float64 `json:"min_score" query:"min_score" default:"0.5"`
+	Limit    int     `json:"limit" query:"limit" default:"20"`
+
+	// Visibility limits the candidates to the clients of the signed in user
+	Visibility *ClientVisibility `json:"-" query:"-"`
+}
+
+type ClientDuplicateResponse struct {
+	Score   float64           `json:"score"`
+	Reasons []string          `json:"reasons"`
+	Clients [2]ClientResponse `json:"clients"`
+}
+
+type ClientMergeRequest struct {
+	SurvivorUUID  string
+	DuplicateUUID string `json:"duplicate_uuid" validate:"required,uuid"`
+
+	// Actor is the signed in user making the change, recorded in the client history
+	Actor ChangeActor `json:"-"`
+}
+
+type ClientTagRequest struct {
+	UUID string
+	Tags []string `json:"tags" validate:"dive,required,max=50"`
+
+	// Actor is the signed in user making the change, recorded in the client history
+	Actor ChangeActor `json:"-"`
+}
+
+type ClientTagCountResponse struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
 }
@@ -0,0 +1,33 @@
+package dtos
+
+// ClientVisibility is the set of clients a signed in user may see and change. Users with the
+// clients:read-all permission see every client, other users the clients they own and the
+// clients owned by members of their team.
+type ClientVisibility struct {
+	UserUUID string
+	Team     string
+	All      bool
+}
+
+// ClientOwnerRequest hands a client over to another agent
+type ClientOwnerRequest struct {
+	UUID      string `json:"-"`
+	OwnerUUID string `json:"owner_uuid" validate:"required,uuid"`
+
+	// Actor is the signed in user making the change, recorded in the client history
+	Actor ChangeActor `json:"-"`
+}
+
+// ClientReassignRequest moves every client of one agent to another, e.g. when an agent leaves.
+// An empty from_owner_uuid moves the clients that have no owner yet.
+type ClientReassignRequest struct {
+	FromOwnerUUID string `json:"from_owner_uuid" validate:"omitempty,uuid"`
+	ToOwnerUUID   string `json:"to_owner_uuid" validate:"required,uuid,nefield=FromOwnerUUID"`
+
+	// Actor is the signed in user making the change, recorded in the client history
+	Actor ChangeActor `json:"-"`
+}
+
+type ClientReassignResponse struct {
+	Reassigned int `json:"reassigned"`
+}
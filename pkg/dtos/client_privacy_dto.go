@@ -0,0 +1,28 @@
+package dtos
+
+import "time"
+
+type ClientDataExportRequest struct {
+	// Format is json for the bundle alone, zip adds the scans of the KYC documents
+	Format string `json:"format" query:"format" validate:"omitempty,oneof=json zip"`
+}
+
+// ClientDataExport is everything held about a client, as handed over on a data access request
+// under the PDP Law. Deleted records are included.
+type ClientDataExport struct {
+	ExportedAt time.Time                 `json:"exported_at"`
+	Client     ClientResponse            `json:"client"`
+	Documents  []*ClientDocumentResponse `json:"documents"`
+	Timeline   []*ClientActivityResponse `json:"timeline"`
+	History    []*ChangeLogResponse      `json:"history"`
+	Deposits   []*DepositResponse        `json:"deposits"`
+}
+
+type ClientErasureResponse struct {
+	UUID     string    `json:"uuid"`
+	ErasedAt time.Time `json:"erased_at"`
+	// MergedClients are the clients merged into this one earlier, erased along with it
+	MergedClients int64 `json:"merged_clients"`
+	// KeptDeposits are the financial records left in place, pointing at the anonymized client
+	KeptDeposits int64 `json:"kept_deposits"`
+}
@@ -0,0 +1,83 @@
+package dtos
+
+import "time"
+
+type DepositRequest struct {
+	ClientUUID    string     `json:"client_uuid" validate:"required,uuid"`
+	PropertyUUID  string     `json:"property_uuid" validate:"required,uuid"`
+	Amount        float64    `json:"amount" validate:"required,gt=0"`
+	PaymentMethod string     `json:"payment_method" validate:"omitempty,max=50"`
+	ReceivedAt    *time.Time `json:"received_at"`
+	Notes         string     `json:"notes"`
+}
+
+type DepositGetRequest struct {
+	Page         int    `json:"page" query:"page" default:"1"`
+	Limit        int    `json:"limit" query:"limit" default:"10"`
+	ClientUUID   string `json:"client_uuid" query:"client_uuid"`
+	PropertyUUID string `json:"property_uuid" query:"property_uuid"`
+	Status       string `json:"status" query:"status"`
+}
+
+type DepositDeductionRequest struct {
+	DepositUUID string
+	Category    string   `form:"category" json:"category" validate:"required,oneof=damage unpaid_utilities cleaning other"`
+	Description string   `form:"description" json:"description" validate:"required"`
+	Amount      float64  `form:"amount" json:"amount" validate:"required,gt=0"`
+	Photos      []string `form:"-" json:"-"`
+}
+
+type DepositRefundRequest struct {
+	DepositUUID     string
+	RefundMethod    string     `json:"refund_method" validate:"omitempty,max=50"`
+	RefundReference string     `json:"refund_reference" validate:"omitempty,max=100"`
+	RefundedAt      *time.Time `json:"refunded_at"`
+}
+
+type DepositDeductionResponse struct {
+	UUID        string    `json:"uuid"`
+	Category    string    `json:"category"`
+	Description string    `json:"description"`
+	Amount      float64   `json:"amount"`
+	Photos      []string  `json:"photos"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type DepositResponse struct {
+	UUID             string                      `json:"uuid"`
+	ClientUUID       string                      `json:"client_uuid"`
+	PropertyUUID     string                      `json:"property_uuid"`
+	Amount           float64                     `json:"amount"`
+	PaymentMethod    string                      `json:"payment_method"`
+	ReceivedAt       time.Time                   `json:"received_at"`
+	Status           string                      `json:"status"`
+	TotalDeductions  float64                     `json:"total_deductions"`
+	RefundableAmount float64                     `json:"refundable_amount"`
+	RefundAmount     float64                     `json:"refund_amount"`
+	RefundMethod     string                      `json:"refund_method"`
+	RefundReference  string                      `json:"refund_reference"`
+	RefundedAt       *time.Time                  `json:"refunded_at"`
+	Notes            string                      `json:"notes"`
+	Deductions       []*DepositDeductionResponse `json:"deductions"`
+	CreatedAt        time.Time                   `json:"created_at"`
+	UpdatedAt        time.Time                   `json:"updated_at"`
+}
+
+// DepositSettlementResponse is the move-out statement handed over to the client
+type DepositSettlementResponse struct {
+	DepositUUID        string                      `json:"deposit_uuid"`
+	Client             ClientResponse              `json:"client"`
+	PropertyUUID       string                      `json:"property_uuid"`
+	Status             string                      `json:"status"`
+	DepositAmount      float64                     `json:"deposit_amount"`
+	ReceivedAt         time.Time                   `json:"received_at"`
+	Deductions         []*DepositDeductionResponse `json:"deductions"`
+	TotalDeductions    float64                     `json:"total_deductions"`
+	RefundableAmount   float64                     `json:"refundable_amount"`
+	AmountOwedByClient float64                     `json:"amount_owed_by_client"`
+	RefundAmount       float64                     `json:"refund_amount"`
+	RefundMethod       string                      `json:"refund_method"`
+	RefundReference    string                      `json:"refund_reference"`
+	RefundedAt         *time.Time                  `json:"refunded_at"`
+	GeneratedAt        time.Time                   `json:"generated_at"`
+}
@@ -1,14 +1,60 @@
 package dtos
 
 type FeatureRequest struct {
-	Name        string `json:"name" validate:"required"`
-	Description string `json:"description"`
+	Name        string `form:"name" json:"name" validate:"required"`
+	Description string `form:"description" json:"description"`
+	IsPublic    bool   `form:"is_public" json:"is_public"`
+	Icon        string `form:"-" json:"-"`
+}
+
+type FeatureUpdateRequest struct {
+	UUID        string
+	Name        string `form:"name" json:"name" validate:"omitempty"`
+	Description string `form:"description" json:"description" validate:"omitempty"`
+	IsPublic    *bool  `form:"is_public" json:"is_public"`
+	Icon        string `form:"-" json:"-"`
+
+	// IfMatch is the If-Match header, the update only applies to the version it names
+	IfMatch string `form:"-" json:"-"`
+}
+
+// FeaturePatchRequest is a feature after a PATCH document was applied to it. The pointer fields
+// may be nulled to clear them, and IfMatch names the version the patch was applied to.
+type FeaturePatchRequest struct {
+	UUID        string  `json:"-"`
+	Name        string  `json:"name" validate:"required"`
+	Description *string `json:"description"`
+	IsPublic    bool    `json:"is_public"`
+	Icon        *string `json:"icon"`
+	IfMatch     string  `json:"-"`
+}
+
+type FeatureGetRequest struct {
+	Page      int    `json:"page" query:"page" default:"1"`
+	Limit     int    `json:"limit" query:"limit" default:"10"`
+	Search    string `json:"search" query:"search"`
+	SortBy    string `json:"sort_by" query:"sort_by" default:"created_at"`
+	SortOrder string `json:"sort_order" query:"sort_order" default:"desc"`
 }
 
 type FeatureResponse struct {
 	UUID        string `json:"uuid"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	Icon        string `json:"icon"`
+	IsPublic    bool   `json:"is_public"`
 	CreatedAt   string `json:"created_at"`
 	UpdatedAt   string `json:"updated_at"`
+	DeletedAt   string `json:"deleted_at,omitempty"`
+
+	// ETag identifies this version of the feature, it is sent as a header and not in the body
+	ETag string `json:"-"`
+}
+
+// PublicFeatureResponse is the amenity shape rendered on the public site
+type PublicFeatureResponse struct {
+	UUID        string `json:"uuid"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Icon        string `json:"icon"` // URL path of the icon, empty when there is none
 }
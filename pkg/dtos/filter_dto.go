@@ -0,0 +1,35 @@
+package dtos
+
+// Filter value types. The type of a field decides which operators it accepts and how its
+// values are parsed.
+const (
+	FilterTypeText = "text"
+	FilterTypeTime = "time"
+	// FilterTypeTags is a set of tags held in a separate table, the repository of the list
+	// supplies the query for it
+	FilterTypeTags = "tags"
+)
+
+// FilterOperators lists the operators every filter type accepts
+var FilterOperators = map[string][]string{
+	FilterTypeText: {"eq", "ne", "in", "nin", "ilike", "null"},
+	FilterTypeTime: {"eq", "gt", "gte", "lt", "lte", "null"},
+	FilterTypeTags: {"in", "all"},
+}
+
+// FilterField is a field a list endpoint can be filtered on. Column is the qualified column
+// the condition is applied to, it is never taken from the query string.
+type FilterField struct {
+	Column string
+	Type   string
+}
+
+// FilterCondition is one validated filter[field][operator]=value parameter. Values holds a
+// single value, or every comma separated value for in, nin and all.
+type FilterCondition struct {
+	Field    string
+	Column   string
+	Type     string
+	Operator string
+	Values   []interface{}
+}
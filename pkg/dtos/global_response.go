@@ -28,3 +28,29 @@ type PaginatedSuccessResponse struct {
 	Data    interface{}    `json:"data"`
 	Meta    PaginationMeta `json:"meta"`
 }
+
+// CursorMeta is the metadata of a keyset paginated list. NextCursor is empty on the last page
+// and Total is only filled when the request asked for it.
+type CursorMeta struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor"`
+	HasMore    bool   `json:"has_more"`
+	Total      *int   `json:"total,omitempty"`
+}
+
+// CursorPaginatedSuccessResponse is returned by list endpoints called with a cursor
+type CursorPaginatedSuccessResponse struct {
+	Success bool        `json:"success"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data"`
+	Meta    CursorMeta  `json:"meta"`
+}
+
+// Cursor points just past the last row of a keyset page. It carries the sort it was issued for,
+// so a cursor cannot be replayed against a different ordering.
+type Cursor struct {
+	SortBy    string `json:"s"`
+	SortOrder string `json:"o"`
+	Value     string `json:"v"`
+	UUID      string `json:"id"`
+}
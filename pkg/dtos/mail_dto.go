@@ -0,0 +1,8 @@
+package dtos
+
+// MailMessage is a plain text mail to a single recipient
+type MailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
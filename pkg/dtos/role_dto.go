@@ -0,0 +1,28 @@
+package dtos
+
+import "time"
+
+type RoleRequest struct {
+	Name        string   `json:"name" validate:"required,max=50"`
+	Description string   `json:"description" validate:"max=255"`
+	Permissions []string `json:"permissions" validate:"dive,required"`
+}
+
+type RoleResponse struct {
+	Name             string    `json:"name"`
+	Description      string    `json:"description"`
+	Permissions      []string  `json:"permissions"`
+	RequireTwoFactor bool      `json:"require_two_factor"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+type UserRoleRequest struct {
+	UUID string `json:"-"`
+	Role string `json:"role" validate:"required,max=50"`
+}
+
+type UserRoleResponse struct {
+	UUID string `json:"uuid"`
+	Role string `json:"role"`
+}
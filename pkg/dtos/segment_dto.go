@@ -0,0 +1,43 @@
+package dtos
+
+import "time"
+
+// SegmentFilter is the filter expression of a saved segment. Every condition that is set has
+// to match.
+type SegmentFilter struct {
+	// Tags the client must all have
+	Tags []string `json:"tags,omitempty" validate:"omitempty,dive,required,max=50"`
+	// AnyTags the client must have at least one of
+	AnyTags        []string   `json:"any_tags,omitempty" validate:"omitempty,dive,required,max=50"`
+	CreatedFrom    *time.Time `json:"created_from,omitempty"`
+	CreatedTo      *time.Time `json:"created_to,omitempty"`
+	HasHeldDeposit *bool      `json:"has_held_deposit,omitempty"`
+}
+
+type SegmentRequest struct {
+	Name        string        `json:"name" validate:"required,max=100"`
+	Description string        `json:"description"`
+	Filter      SegmentFilter `json:"filter"`
+}
+
+type SegmentUpdateRequest struct {
+	UUID        string
+	Name        string         `json:"name" validate:"omitempty,max=100"`
+	Description string         `json:"description" validate:"omitempty"`
+	Filter      *SegmentFilter `json:"filter" validate:"omitempty"`
+}
+
+type SegmentGetRequest struct {
+	Page   int    `json:"page" query:"page" default:"1"`
+	Limit  int    `json:"limit" query:"limit" default:"10"`
+	Search string `json:"search" query:"search"`
+}
+
+type SegmentResponse struct {
+	UUID        string        `json:"uuid"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Filter      SegmentFilter `json:"filter"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
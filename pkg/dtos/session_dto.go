@@ -0,0 +1,24 @@
+package dtos
+
+import "time"
+
+// SessionClient describes where a login or refresh came from
+type SessionClient struct {
+	UserAgent string
+	IPAddress string
+}
+
+type SessionResponse struct {
+	UUID       string    `json:"uuid"`
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+type SessionRevokeAllResponse struct {
+	Revoked int `json:"revoked"`
+}
@@ -0,0 +1,16 @@
+package dtos
+
+// TrashGetRequest lists soft-deleted records, most recently deleted first
+type TrashGetRequest struct {
+	Page   int    `json:"page" query:"page" default:"1"`
+	Limit  int    `json:"limit" query:"limit" default:"10"`
+	Search string `json:"search" query:"search"`
+}
+
+// TrashPurgeResult reports what the purge job did to one table. Skipped rows are past the
+// retention period but still referenced by live records, so they stay in the trash.
+type TrashPurgeResult struct {
+	Table   string `json:"table"`
+	Purged  int64  `json:"purged"`
+	Skipped int64  `json:"skipped"`
+}
@@ -0,0 +1,44 @@
+package dtos
+
+import "time"
+
+type TwoFactorStatusResponse struct {
+	Enabled           bool       `json:"enabled"`
+	EnabledAt         *time.Time `json:"enabled_at"`
+	Required          bool       `json:"required"`
+	RecoveryCodesLeft int64      `json:"recovery_codes_left"`
+}
+
+type TwoFactorEnrollResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// TwoFactorCodeRequest carries a code of the authenticator app. Where noted a recovery code is
+// accepted as well.
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required,max=32"`
+}
+
+// TwoFactorRecoveryCodesResponse lists recovery codes in plain text. They are shown this once.
+type TwoFactorRecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TwoFactorVerifyRequest completes a login that answered with a challenge. The code is a TOTP
+// code or an unused recovery code.
+type TwoFactorVerifyRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required,max=32"`
+}
+
+// TwoFactorChallenge is a password login waiting for its second factor
+type TwoFactorChallenge struct {
+	UserUuid string `json:"user_uuid"`
+	Device   string `json:"device"`
+}
+
+type RoleTwoFactorRequest struct {
+	Name     string `json:"-"`
+	Required bool   `json:"required"`
+}
@@ -1,11 +1,72 @@
 package dtos
 
+import "time"
+
+// UserRegisterRequest is the public sign up. The role is not taken from the request, every
+// self-registered user gets the configured default role.
 type UserRegisterRequest struct {
 	Name                 string `form:"name" json:"name" validate:"required"`
 	Email                string `form:"email" json:"email" validate:"required,email"`
 	Password             string `form:"password" json:"password" validate:"required,min=6"`
 	ConfirmationPassword string `form:"confirmation_password" json:"confirmation_password" validate:"required,eqfield=Password"`
-	PhoneNumber          string `form:"phone_number" json:"phone_number" validate:"required"`
-	Role                 string `form:"role" json:"role" validate:"required"`
+	PhoneNumber          string `form:"phone_number" json:"phone_number" validate:"required,phone"`
+	Role                 string `form:"-" json:"-"`
 	Image                string `form:"photo_url" json:"photo_url" validate:"omitempty"`
 }
+
+type UserTeamRequest struct {
+	UUID string `json:"-"`
+	Team string `json:"team" validate:"max=100"`
+}
+
+type UserTeamResponse struct {
+	UUID string `json:"uuid"`
+	Team string `json:"team"`
+}
+
+// User statuses the user list filters on
+const (
+	UserStatusActive      = "active"
+	UserStatusDeactivated = "deactivated"
+)
+
+type UserGetRequest struct {
+	Page      int    `json:"page" query:"page" default:"1"`
+	Limit     int    `json:"limit" query:"limit" default:"10"`
+	Search    string `json:"search" query:"search"`
+	Role      string `json:"role" query:"role"`
+	Status    string `json:"status" query:"status"`
+	SortBy    string `json:"sort_by" query:"sort_by" default:"created_at"`
+	SortOrder string `json:"sort_order" query:"sort_order" default:"desc"`
+}
+
+// UserCreateRequest is a user created by an admin, who picks the role
+type UserCreateRequest struct {
+	Name        string `json:"name" validate:"required"`
+	Email       string `json:"email" validate:"required,email"`
+	Password    string `json:"password" validate:"required,min=6"`
+	PhoneNumber string `json:"phone_number" validate:"required,phone"`
+	Role        string `json:"role" validate:"required,max=50"`
+	Team        string `json:"team" validate:"max=100"`
+}
+
+type UserPasswordResetRequest struct {
+	UUID                 string `json:"-"`
+	Password             string `json:"password" validate:"required,min=6"`
+	ConfirmationPassword string `json:"confirmation_password" validate:"required,eqfield=Password"`
+}
+
+type UserResponse struct {
+	UUID            string     `json:"uuid"`
+	Name            string     `json:"name"`
+	Email           string     `json:"email"`
+	PhoneNumber     string     `json:"phone_number"`
+	Image           string     `json:"image"`
+	Role            string     `json:"role"`
+	Team            string     `json:"team"`
+	Active          bool       `json:"active"`
+	DeactivatedAt   *time.Time `json:"deactivated_at"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
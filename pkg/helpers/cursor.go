@@ -0,0 +1,41 @@
+package helpers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"alfredo/ruu-properties/pkg/dtos"
+)
+
+// EncodeCursor turns a cursor into the opaque next_cursor string handed to clients
+func EncodeCursor(cursor dtos.Cursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor reads a cursor produced by EncodeCursor. It fails when the cursor was issued for
+// another sort, as its position means nothing in a different ordering, and when its uuid has been
+// tampered with. The value is checked against the sort column by the repository.
+func DecodeCursor(value string, sortBy string, sortOrder string) (*dtos.Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s", "invalid cursor")
+	}
+
+	var cursor dtos.Cursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return nil, fmt.Errorf("%s", "invalid cursor")
+	}
+	// uuid.Parse also takes forms Postgres does not, cursors only ever carry the canonical one
+	if id, err := uuid.Parse(cursor.UUID); err != nil || id.String() != cursor.UUID {
+		return nil, fmt.Errorf("%s", "invalid cursor")
+	}
+	if cursor.SortBy != sortBy || cursor.SortOrder != sortOrder {
+		return nil, fmt.Errorf("%s", "cursor does not match the requested sort")
+	}
+
+	return &cursor, nil
+}
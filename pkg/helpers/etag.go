@@ -0,0 +1,26 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ETag derives the entity tag of a record from its updated_at. Postgres keeps microseconds, so
+// the tag is taken at that precision to match the value read back after a write.
+func ETag(updatedAt time.Time) string {
+	return fmt.Sprintf(`"%x"`, updatedAt.UnixMicro())
+}
+
+// MatchesETag reports whether an If-Match header accepts etag. The header may be "*" or a comma
+// separated list of tags. If-Match uses the strong comparison (RFC 9110 §13.1.1), so a weak tag
+// never matches.
+func MatchesETag(ifMatch string, etag string) bool {
+	for _, tag := range strings.Split(ifMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
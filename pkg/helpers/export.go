@@ -0,0 +1,180 @@
+package helpers
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatXLSX = "xlsx"
+)
+
+// RowWriter writes tabular export data row by row so large result sets never have to be
+// held in memory.
+type RowWriter interface {
+	WriteRow(values []string) error
+	Close() error
+}
+
+// NewRowWriter returns a RowWriter for the given export format. Values of the phone columns,
+// given by index, keep their leading + when they are E.164 numbers.
+func NewRowWriter(format string, w io.Writer, phoneColumns ...int) (RowWriter, error) {
+	switch format {
+	case ExportFormatCSV:
+		phones := make(map[int]bool, len(phoneColumns))
+		for _, column := range phoneColumns {
+			phones[column] = true
+		}
+		return &csvRowWriter{writer: csv.NewWriter(w), phoneColumns: phones}, nil
+	case ExportFormatXLSX:
+		return newXlsxRowWriter(w)
+	default:
+		return nil, fmt.Errorf("unsupported export format %s", format)
+	}
+}
+
+// ExportContentType returns the MIME type of an export format.
+func ExportContentType(format string) string {
+	if format == ExportFormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+type csvRowWriter struct {
+	writer       *csv.Writer
+	phoneColumns map[int]bool
+}
+
+func (c *csvRowWriter) WriteRow(values []string) error {
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		if c.phoneColumns[i] && e164Pattern.MatchString(value) {
+			escaped[i] = value
+			continue
+		}
+		escaped[i] = escapeSpreadsheetFormula(value)
+	}
+	return c.writer.Write(escaped)
+}
+
+func (c *csvRowWriter) Close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+// e164Pattern matches a whole phone number as NormalizePhoneNumber stores it
+var e164Pattern = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+
+// escapeSpreadsheetFormula prevents a cell from being evaluated as a formula when the CSV is
+// opened in a spreadsheet. Numbers are escaped as well, -1+cmd|... is a formula too.
+func escapeSpreadsheetFormula(value string) string {
+	if value == "" {
+		return value
+	}
+
+	switch value[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + value
+	}
+	return value
+}
+
+// xlsxRowWriter streams a single-sheet workbook. Cells are written as inline strings, so no
+// shared string table has to be built up front.
+type xlsxRowWriter struct {
+	archive *zip.Writer
+	sheet   io.Writer
+}
+
+var xlsxStaticParts = []struct {
+	name    string
+	content string
+}{
+	{
+		name: "[Content_Types].xml",
+		content: `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+			`</Types>`,
+	},
+	{
+		name: "_rels/.rels",
+		content: `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`,
+	},
+	{
+		name: "xl/workbook.xml",
+		content: `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets>` +
+			`</workbook>`,
+	},
+	{
+		name: "xl/_rels/workbook.xml.rels",
+		content: `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`</Relationships>`,
+	},
+}
+
+func newXlsxRowWriter(w io.Writer) (*xlsxRowWriter, error) {
+	archive := zip.NewWriter(w)
+	for _, part := range xlsxStaticParts {
+		entry, err := archive.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(entry, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	// The worksheet is the last entry, so it can stay open while rows are appended
+	sheet, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.WriteString(sheet, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &xlsxRowWriter{archive: archive, sheet: sheet}, nil
+}
+
+func (x *xlsxRowWriter) WriteRow(values []string) error {
+	var row strings.Builder
+	row.WriteString("<row>")
+	for _, value := range values {
+		row.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+		if err := xml.EscapeText(&row, []byte(value)); err != nil {
+			return err
+		}
+		row.WriteString("</t></is></c>")
+	}
+	row.WriteString("</row>")
+
+	_, err := io.WriteString(x.sheet, row.String())
+	return err
+}
+
+func (x *xlsxRowWriter) Close() error {
+	if _, err := io.WriteString(x.sheet, "</sheetData></worksheet>"); err != nil {
+		return err
+	}
+	return x.archive.Close()
+}
@@ -0,0 +1,118 @@
+package helpers
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"alfredo/ruu-properties/pkg/dtos"
+)
+
+// filterParameter matches filter[field][operator] and the filter[field] shorthand for eq
+var filterParameter = regexp.MustCompile(`^filter\[([a-z_]+)\](?:\[([a-z]+)\])?$`)
+
+// ParseFilters reads every filter[field][operator]=value parameter of query and checks it
+// against the fields a list endpoint allows. Fields and operators outside the whitelist and
+// values that do not parse are rejected, so the conditions can be applied as they are.
+func ParseFilters(query map[string]string, fields map[string]dtos.FilterField) ([]dtos.FilterCondition, error) {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		if strings.HasPrefix(key, "filter[") {
+			keys = append(keys, key)
+		}
+	}
+	// Conditions are applied in a stable order, which keeps the generated SQL the same
+	sort.Strings(keys)
+
+	conditions := make([]dtos.FilterCondition, 0, len(keys))
+	for _, key := range keys {
+		match := filterParameter.FindStringSubmatch(key)
+		if match == nil {
+			return nil, fmt.Errorf("invalid filter parameter %s", key)
+		}
+
+		name, operator := match[1], match[2]
+		field, ok := fields[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown filter field %s", name)
+		}
+		if operator == "" {
+			operator = "eq"
+		}
+		if !slices.Contains(dtos.FilterOperators[field.Type], operator) {
+			return nil, fmt.Errorf("filter[%s] allows the operators %s", name, strings.Join(dtos.FilterOperators[field.Type], ", "))
+		}
+
+		values, err := parseFilterValues(field.Type, operator, query[key])
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for filter[%s][%s]: %w", name, operator, err)
+		}
+
+		conditions = append(conditions, dtos.FilterCondition{
+			Field:    name,
+			Column:   field.Column,
+			Type:     field.Type,
+			Operator: operator,
+			Values:   values,
+		})
+	}
+
+	return conditions, nil
+}
+
+func parseFilterValues(fieldType string, operator string, value string) ([]interface{}, error) {
+	if operator == "null" {
+		switch value {
+		case "true":
+			return []interface{}{true}, nil
+		case "false":
+			return []interface{}{false}, nil
+		}
+		return nil, fmt.Errorf("%s", "expected true or false")
+	}
+
+	raw := []string{value}
+	if operator == "in" || operator == "nin" || operator == "all" {
+		raw = strings.Split(value, ",")
+	}
+	if fieldType == dtos.FilterTypeTags {
+		raw = NormalizeTags(raw)
+	}
+
+	values := make([]interface{}, 0, len(raw))
+	for _, item := range raw {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			return nil, fmt.Errorf("%s", "empty value")
+		}
+
+		if fieldType == dtos.FilterTypeTime {
+			at, err := parseFilterTime(item)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, at)
+			continue
+		}
+		values = append(values, item)
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("%s", "empty value")
+	}
+	return values, nil
+}
+
+// parseFilterTime accepts an RFC 3339 timestamp or a date, read as midnight UTC
+func parseFilterTime(value string) (time.Time, error) {
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		return at, nil
+	}
+	at, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s", "expected a date or an RFC 3339 timestamp")
+	}
+	return at, nil
+}
@@ -0,0 +1,361 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MergePatchContentType selects JSON Merge Patch (RFC 7396), plain application/json is read the same way
+	MergePatchContentType = "application/merge-patch+json"
+	// JSONPatchContentType selects JSON Patch (RFC 6902)
+	JSONPatchContentType = "application/json-patch+json"
+)
+
+type jsonPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// PatchContentType returns the patch format of a Content-Type header
+func PatchContentType(header string) (string, error) {
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return "", fmt.Errorf("%s", "unsupported patch content type")
+	}
+
+	switch mediaType {
+	case MergePatchContentType, "application/json":
+		return MergePatchContentType, nil
+	case JSONPatchContentType:
+		return JSONPatchContentType, nil
+	}
+	return "", fmt.Errorf("%s", "unsupported patch content type")
+}
+
+// ApplyPatch applies a patch in the given format to document and decodes the result into
+// target, a pointer to a struct with the same json fields as document. A field may only be
+// nulled or removed when its target field is a pointer, unknown fields are rejected.
+func ApplyPatch(format string, document interface{}, patch []byte, target interface{}) error {
+	raw, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+	var original map[string]interface{}
+	if err := json.Unmarshal(raw, &original); err != nil {
+		return err
+	}
+
+	var patched interface{}
+	switch format {
+	case MergePatchContentType:
+		var mergePatch interface{}
+		if err := json.Unmarshal(patch, &mergePatch); err != nil {
+			return fmt.Errorf("invalid merge patch: %w", err)
+		}
+		patched = applyMergePatch(copyJSON(original), mergePatch)
+	case JSONPatchContentType:
+		var operations []jsonPatchOperation
+		if err := json.Unmarshal(patch, &operations); err != nil {
+			return fmt.Errorf("invalid json patch: %w", err)
+		}
+		if patched, err = applyJSONPatch(copyJSON(original), operations); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%s", "unsupported patch content type")
+	}
+
+	result, ok := patched.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s", "the patched document must be an object")
+	}
+	if err := checkPatchedFields(original, result, target); err != nil {
+		return err
+	}
+
+	out, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(out))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(target); err != nil {
+		return fmt.Errorf("invalid patched document: %w", err)
+	}
+	return nil
+}
+
+// checkPatchedFields rejects unknown fields and nulls on fields that cannot be cleared
+func checkPatchedFields(original map[string]interface{}, patched map[string]interface{}, target interface{}) error {
+	nullable := make(map[string]bool)
+	targetType := reflect.TypeOf(target).Elem()
+	for i := 0; i < targetType.NumField(); i++ {
+		field := targetType.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		nullable[name] = field.Type.Kind() == reflect.Ptr
+	}
+
+	for name, value := range patched {
+		canBeNull, known := nullable[name]
+		if !known {
+			return fmt.Errorf("unknown field %s", name)
+		}
+		if value == nil && !canBeNull {
+			return fmt.Errorf("%s cannot be null", name)
+		}
+	}
+	for name := range original {
+		if _, ok := patched[name]; !ok && !nullable[name] {
+			return fmt.Errorf("%s cannot be null", name)
+		}
+	}
+	return nil
+}
+
+func applyMergePatch(target interface{}, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = make(map[string]interface{})
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+			continue
+		}
+		targetObject[name] = applyMergePatch(targetObject[name], value)
+	}
+	return targetObject
+}
+
+func applyJSONPatch(document interface{}, operations []jsonPatchOperation) (interface{}, error) {
+	for i, operation := range operations {
+		path, err := parseJSONPointer(operation.Path)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+
+		switch operation.Op {
+		case "add", "replace", "test":
+			if operation.Value == nil {
+				return nil, fmt.Errorf("operation %d: %s needs a value", i, operation.Op)
+			}
+			var value interface{}
+			if err := json.Unmarshal(operation.Value, &value); err != nil {
+				return nil, fmt.Errorf("operation %d: invalid value: %w", i, err)
+			}
+
+			switch operation.Op {
+			case "add":
+				document, err = addJSONValue(document, path, value)
+			case "replace":
+				document, err = replaceJSONValue(document, path, value)
+			case "test":
+				var current interface{}
+				if current, err = getJSONValue(document, path); err == nil && !reflect.DeepEqual(current, value) {
+					err = fmt.Errorf("test failed at %s", operation.Path)
+				}
+			}
+		case "remove":
+			document, _, err = removeJSONValue(document, path)
+		case "move", "copy":
+			from, fromErr := parseJSONPointer(operation.From)
+			if fromErr != nil {
+				return nil, fmt.Errorf("operation %d: %w", i, fromErr)
+			}
+
+			var value interface{}
+			if operation.Op == "move" {
+				document, value, err = removeJSONValue(document, from)
+			} else if value, err = getJSONValue(document, from); err == nil {
+				value = copyJSON(value)
+			}
+			if err == nil {
+				document, err = addJSONValue(document, path, value)
+			}
+		default:
+			return nil, fmt.Errorf("operation %d: unknown op %q", i, operation.Op)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
+	return document, nil
+}
+
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid path %q", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func getJSONValue(node interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch container := node.(type) {
+		case map[string]interface{}:
+			value, ok := container[token]
+			if !ok {
+				return nil, fmt.Errorf("path %s does not exist", token)
+			}
+			node = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(container)-1)
+			if err != nil {
+				return nil, err
+			}
+			node = container[index]
+		default:
+			return nil, fmt.Errorf("path %s does not exist", token)
+		}
+	}
+	return node, nil
+}
+
+// updateJSONValue rebuilds the containers along path, calling fn on the parent of its last token
+func updateJSONValue(node interface{}, path []string, fn func(container interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return fn(node, path[0])
+	}
+
+	child, err := getJSONValue(node, path[:1])
+	if err != nil {
+		return nil, err
+	}
+	updated, err := updateJSONValue(child, path[1:], fn)
+	if err != nil {
+		return nil, err
+	}
+
+	switch container := node.(type) {
+	case map[string]interface{}:
+		container[path[0]] = updated
+	case []interface{}:
+		index, _ := arrayIndex(path[0], len(container)-1)
+		container[index] = updated
+	}
+	return node, nil
+}
+
+func addJSONValue(document interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return updateJSONValue(document, path, func(node interface{}, token string) (interface{}, error) {
+		switch container := node.(type) {
+		case map[string]interface{}:
+			container[token] = value
+			return container, nil
+		case []interface{}:
+			if token == "-" {
+				return append(container, value), nil
+			}
+			index, err := arrayIndex(token, len(container))
+			if err != nil {
+				return nil, err
+			}
+			container = append(container, nil)
+			copy(container[index+1:], container[index:])
+			container[index] = value
+			return container, nil
+		}
+		return nil, fmt.Errorf("cannot add to %s", token)
+	})
+}
+
+func replaceJSONValue(document interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	if _, err := getJSONValue(document, path); err != nil {
+		return nil, err
+	}
+
+	return updateJSONValue(document, path, func(node interface{}, token string) (interface{}, error) {
+		switch container := node.(type) {
+		case map[string]interface{}:
+			container[token] = value
+			return container, nil
+		case []interface{}:
+			index, _ := arrayIndex(token, len(container)-1)
+			container[index] = value
+			return container, nil
+		}
+		return nil, fmt.Errorf("cannot replace %s", token)
+	})
+}
+
+func removeJSONValue(document interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("%s", "cannot remove the whole document")
+	}
+	removed, err := getJSONValue(document, path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	document, err = updateJSONValue(document, path, func(node interface{}, token string) (interface{}, error) {
+		switch container := node.(type) {
+		case map[string]interface{}:
+			delete(container, token)
+			return container, nil
+		case []interface{}:
+			index, _ := arrayIndex(token, len(container)-1)
+			return append(container[:index], container[index+1:]...), nil
+		}
+		return nil, fmt.Errorf("cannot remove %s", token)
+	})
+	return document, removed, err
+}
+
+// arrayIndex parses an array index token that may not exceed max
+func arrayIndex(token string, max int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index > max || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %s", token)
+	}
+	return index, nil
+}
+
+// copyJSON deep copies a decoded JSON value so patches never alias the document they start from
+func copyJSON(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(typed))
+		for name, member := range typed {
+			copied[name] = copyJSON(member)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(typed))
+		for i, member := range typed {
+			copied[i] = copyJSON(member)
+		}
+		return copied
+	}
+	return value
+}
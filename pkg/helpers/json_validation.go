@@ -11,6 +11,7 @@ var customMessages = map[string]string{
 	"gte":      "harus lebih besar atau sama dengan %s",
 	"lte":      "harus lebih kecil atau sama dengan %s",
 	"integer":  "harus berupa bilangan bulat",
+	"phone":    "harus berupa nomor telepon yang valid",
 }
 
 func FormatValidationError(err error) map[string]string {
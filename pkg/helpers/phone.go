@@ -0,0 +1,96 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	"alfredo/ruu-properties/config"
+)
+
+const defaultPhoneRegion = "ID"
+
+// phoneRegions maps a region to its country calling code and national trunk prefix
+var phoneRegions = map[string]struct {
+	callingCode string
+	trunkPrefix string
+}{
+	"ID": {callingCode: "62", trunkPrefix: "0"},
+	"MY": {callingCode: "60", trunkPrefix: "0"},
+	"SG": {callingCode: "65", trunkPrefix: ""},
+	"AU": {callingCode: "61", trunkPrefix: "0"},
+	"US": {callingCode: "1", trunkPrefix: "1"},
+}
+
+// PhoneDefaultRegion returns the configured region for numbers written without a country code.
+func PhoneDefaultRegion() string {
+	region := strings.ToUpper(config.PhoneDefaultRegion)
+	if _, ok := phoneRegions[region]; !ok {
+		return defaultPhoneRegion
+	}
+	return region
+}
+
+// NormalizePhoneNumber converts a phone number to E.164. Numbers without a country code are
+// read as national numbers of region, so 0812-3456-7890, 62812 3456 7890 and +6281234567890
+// all become +6281234567890.
+func NormalizePhoneNumber(phone string, region string) (string, error) {
+	phone = strings.TrimSpace(phone)
+
+	var digits strings.Builder
+	international := false
+	for i, r := range phone {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && i == 0:
+			international = true
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+			// Formatting characters are dropped
+		default:
+			return "", fmt.Errorf("invalid phone number %s", phone)
+		}
+	}
+
+	number := digits.String()
+	if !international && strings.HasPrefix(number, "00") {
+		// 00 is the international call prefix
+		international = true
+		number = number[2:]
+	}
+
+	if !international {
+		settings, ok := phoneRegions[region]
+		if !ok {
+			return "", fmt.Errorf("unsupported phone region %s", region)
+		}
+
+		switch {
+		case settings.trunkPrefix != "" && strings.HasPrefix(number, settings.trunkPrefix):
+			number = settings.callingCode + strings.TrimPrefix(number, settings.trunkPrefix)
+		case !strings.HasPrefix(number, settings.callingCode):
+			number = settings.callingCode + number
+		}
+	}
+
+	// E.164 allows at most 15 digits and country codes never start with 0
+	if len(number) < 8 || len(number) > 15 || number[0] == '0' {
+		return "", fmt.Errorf("invalid phone number %s", phone)
+	}
+
+	return "+" + number, nil
+}
+
+// ValidatePhoneNumber is the validator for the phone tag.
+func ValidatePhoneNumber(fl validator.FieldLevel) bool {
+	_, err := NormalizePhoneNumber(fl.Field().String(), PhoneDefaultRegion())
+	return err == nil
+}
+
+// NewValidator returns a validator with the custom tags of this project registered.
+func NewValidator() *validator.Validate {
+	validate := validator.New()
+	_ = validate.RegisterValidation("phone", ValidatePhoneNumber)
+	return validate
+}
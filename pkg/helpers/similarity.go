@@ -0,0 +1,91 @@
+package helpers
+
+import (
+	"strings"
+	"unicode"
+)
+
+// EmailLocalPart returns the part before the @ without dots and +tags, lower cased.
+func EmailLocalPart(email string) string {
+	local := strings.ToLower(strings.TrimSpace(email))
+	if at := strings.LastIndex(local, "@"); at >= 0 {
+		local = local[:at]
+	}
+	if plus := strings.Index(local, "+"); plus >= 0 {
+		local = local[:plus]
+	}
+	return strings.ReplaceAll(local, ".", "")
+}
+
+// NameTokens splits a name into lower case words without punctuation.
+func NameTokens(name string) []string {
+	return strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// NameSimilarity scores two names between 0 and 1. Every word of the shorter name is matched
+// against its best counterpart, where an initial ("S.") matches a full word ("Santoso").
+func NameSimilarity(a, b string) float64 {
+	tokensA, tokensB := NameTokens(a), NameTokens(b)
+	if len(tokensA) == 0 || len(tokensB) == 0 {
+		return 0
+	}
+	if len(tokensA) > len(tokensB) {
+		tokensA, tokensB = tokensB, tokensA
+	}
+
+	var total float64
+	for _, tokenA := range tokensA {
+		var best float64
+		for _, tokenB := range tokensB {
+			if score := tokenSimilarity(tokenA, tokenB); score > best {
+				best = score
+			}
+		}
+		total += best
+	}
+
+	// Penalise names that have extra words left unmatched
+	coverage := float64(len(tokensA)) / float64(len(tokensB))
+	return total / float64(len(tokensA)) * (0.75 + 0.25*coverage)
+}
+
+func tokenSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+
+	ra, rb := []rune(a), []rune(b)
+	if (len(ra) == 1 || len(rb) == 1) && ra[0] == rb[0] {
+		return 0.8
+	}
+
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
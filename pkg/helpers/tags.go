@@ -0,0 +1,19 @@
+package helpers
+
+import "strings"
+
+// NormalizeTags lower cases tags, turns inner spaces into dashes and drops empty and repeated
+// tags, so "VIP", " vip " and "Vip" are the same tag.
+func NormalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.Join(strings.Fields(strings.ToLower(tag)), "-")
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
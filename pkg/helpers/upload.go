@@ -0,0 +1,293 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const (
+	UploadDirectory = "./uploads"
+
+	// IconDirectory holds the feature icons, the only uploads served publicly, under IconURLPath
+	IconDirectory = "./uploads/icons"
+	IconURLPath   = "/uploads/icons"
+
+	maxIconSize     = 1 << 20
+	maxDocumentSize = 5 << 20
+)
+
+var (
+	pngSignature  = []byte("\x89PNG\r\n\x1a\n")
+	jpegSignature = []byte("\xff\xd8\xff")
+	pdfSignature  = []byte("%PDF-")
+)
+
+// photoExtensions maps the image types accepted as photos to the extension they are stored with
+var photoExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// SaveUploadedFile stores a multipart file inside the uploads directory with a unique name
+// and returns the path that should be persisted on the model.
+func SaveUploadedFile(c *fiber.Ctx, file *multipart.FileHeader) (string, error) {
+	return SaveUploadedFileAs(c, file, filepath.Ext(file.Filename))
+}
+
+// SaveUploadedFileAs stores a multipart file like SaveUploadedFile, with the given extension
+// instead of the one of the uploaded name
+func SaveUploadedFileAs(c *fiber.Ctx, file *multipart.FileHeader, ext string) (string, error) {
+	filename, err := saveFile(c, file, UploadDirectory, ext)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/%s", UploadDirectory, filename), nil
+}
+
+// SaveIconFile stores an icon checked by ValidateIconFile inside the icon directory and returns
+// the URL it is served under.
+func SaveIconFile(c *fiber.Ctx, file *multipart.FileHeader) (string, error) {
+	filename, err := saveFile(c, file, IconDirectory, strings.ToLower(filepath.Ext(file.Filename)))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/%s", IconURLPath, filename), nil
+}
+
+// saveFile stores a multipart file inside directory under a unique name with the given extension
+// and returns that name
+func saveFile(c *fiber.Ctx, file *multipart.FileHeader, directory string, ext string) (string, error) {
+	// Create the directory if it doesn't exist
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return "", fmt.Errorf("failed to create upload directory: %w", err)
+	}
+
+	// Generate unique filename
+	filename := fmt.Sprintf("%d_%s%s", time.Now().Unix(), uuid.New().String(), ext)
+
+	if err := c.SaveFile(file, fmt.Sprintf("%s/%s", directory, filename)); err != nil {
+		return "", fmt.Errorf("failed to save file: %w", err)
+	}
+
+	return filename, nil
+}
+
+// ValidateIconFile makes sure an uploaded icon is a PNG or a script-free SVG.
+func ValidateIconFile(file *multipart.FileHeader) error {
+	if file.Size > maxIconSize {
+		return fmt.Errorf("icon must not be larger than 1MB")
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("failed to read icon: %w", err)
+	}
+	defer src.Close()
+
+	content, err := io.ReadAll(io.LimitReader(src, maxIconSize))
+	if err != nil {
+		return fmt.Errorf("failed to read icon: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(file.Filename)) {
+	case ".png":
+		if !bytes.HasPrefix(content, pngSignature) {
+			return fmt.Errorf("icon is not a valid PNG image")
+		}
+	case ".svg":
+		// SVG is served as-is on the public site, so only plain drawing markup is accepted
+		if err := validateSvg(content); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("icon must be a PNG or SVG file")
+	}
+
+	return nil
+}
+
+// ValidateDocumentFile makes sure an uploaded KYC document is a PDF, JPEG or PNG scan.
+func ValidateDocumentFile(file *multipart.FileHeader) error {
+	if file.Size > maxDocumentSize {
+		return fmt.Errorf("document must not be larger than 5MB")
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("failed to read document: %w", err)
+	}
+	defer src.Close()
+
+	header := make([]byte, len(pngSignature))
+	n, err := io.ReadFull(src, header)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return fmt.Errorf("failed to read document: %w", err)
+	}
+	header = header[:n]
+
+	var signature []byte
+	switch strings.ToLower(filepath.Ext(file.Filename)) {
+	case ".pdf":
+		signature = pdfSignature
+	case ".jpg", ".jpeg":
+		signature = jpegSignature
+	case ".png":
+		signature = pngSignature
+	default:
+		return fmt.Errorf("document must be a PDF, JPEG or PNG file")
+	}
+	if !bytes.HasPrefix(header, signature) {
+		return fmt.Errorf("document content does not match its %s extension", filepath.Ext(file.Filename))
+	}
+
+	return nil
+}
+
+// ValidatePhotoFile makes sure an uploaded photo is a JPEG, PNG or WebP image, judged by its
+// content rather than the type or name the client sent. It returns the extension to store the
+// photo with.
+func ValidatePhotoFile(file *multipart.FileHeader) (string, error) {
+	src, err := file.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to read photo: %w", err)
+	}
+	defer src.Close()
+
+	// DetectContentType looks at no more than the first 512 bytes
+	header := make([]byte, 512)
+	n, err := io.ReadFull(src, header)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return "", fmt.Errorf("failed to read photo: %w", err)
+	}
+
+	ext, ok := photoExtensions[http.DetectContentType(header[:n])]
+	if !ok {
+		return "", fmt.Errorf("photo must be a JPEG, PNG or WebP image")
+	}
+	return ext, nil
+}
+
+// svgElements are the SVG elements an icon may use. Anything able to run script, embed other
+// documents or load external resources is left out.
+var svgElements = map[string]bool{
+	"svg": true, "g": true, "defs": true, "symbol": true, "use": true, "title": true, "desc": true,
+	"path": true, "rect": true, "circle": true, "ellipse": true, "line": true, "polyline": true, "polygon": true,
+	"text": true, "tspan": true, "lineargradient": true, "radialgradient": true, "stop": true,
+	"clippath": true, "mask": true,
+}
+
+// svgAttributes are the attributes an icon may use, presentation only
+var svgAttributes = map[string]bool{
+	"id": true, "class": true, "version": true, "viewbox": true, "preserveaspectratio": true,
+	"width": true, "height": true, "x": true, "y": true, "x1": true, "y1": true, "x2": true, "y2": true,
+	"cx": true, "cy": true, "r": true, "rx": true, "ry": true, "dx": true, "dy": true,
+	"d": true, "points": true, "transform": true, "href": true, "offset": true,
+	"fill": true, "fill-opacity": true, "fill-rule": true, "opacity": true,
+	"stroke": true, "stroke-width": true, "stroke-linecap": true, "stroke-linejoin": true,
+	"stroke-miterlimit": true, "stroke-dasharray": true, "stroke-dashoffset": true, "stroke-opacity": true,
+	"stop-color": true, "stop-opacity": true, "gradientunits": true, "gradienttransform": true,
+	"spreadmethod": true, "fx": true, "fy": true, "clip-path": true, "clip-rule": true, "clippathunits": true,
+	"mask": true, "maskunits": true, "maskcontentunits": true, "visibility": true, "display": true,
+	"font-family": true, "font-size": true, "font-weight": true, "font-style": true,
+	"text-anchor": true, "dominant-baseline": true, "vector-effect": true,
+}
+
+const (
+	svgNamespace   = "http://www.w3.org/2000/svg"
+	xlinkNamespace = "http://www.w3.org/1999/xlink"
+)
+
+// validateSvg parses an SVG document and refuses every element and attribute outside the allow
+// lists. Links may only point at fragments of the document itself.
+func validateSvg(content []byte) error {
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	decoder.Strict = true
+
+	root := true
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("icon is not a valid SVG image")
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(token.Name.Local)
+			if root && name != "svg" {
+				return fmt.Errorf("icon is not a valid SVG image")
+			}
+			root = false
+			if (token.Name.Space != "" && token.Name.Space != svgNamespace) || !svgElements[name] {
+				return fmt.Errorf("icon SVG must not contain <%s> elements", token.Name.Local)
+			}
+			for _, attr := range token.Attr {
+				if err := validateSvgAttribute(attr); err != nil {
+					return err
+				}
+			}
+		case xml.Directive:
+			// DOCTYPEs can declare entities, nothing an icon needs
+			return fmt.Errorf("icon SVG must not contain a DOCTYPE")
+		case xml.ProcInst:
+			if token.Target != "xml" {
+				return fmt.Errorf("icon SVG must not contain processing instructions")
+			}
+		}
+	}
+
+	if root {
+		return fmt.Errorf("icon is not a valid SVG image")
+	}
+	return nil
+}
+
+func validateSvgAttribute(attr xml.Attr) error {
+	name := strings.ToLower(attr.Name.Local)
+	switch attr.Name.Space {
+	case "xmlns":
+		return nil
+	case "":
+		if name == "xmlns" {
+			return nil
+		}
+	case xlinkNamespace:
+		if name != "href" {
+			return fmt.Errorf("icon SVG must not contain the %s attribute", attr.Name.Local)
+		}
+	default:
+		return fmt.Errorf("icon SVG must not contain the %s attribute", attr.Name.Local)
+	}
+
+	if !svgAttributes[name] {
+		return fmt.Errorf("icon SVG must not contain the %s attribute", attr.Name.Local)
+	}
+
+	// The decoder has resolved character references, so encoded schemes show up here as well
+	value := strings.TrimSpace(attr.Value)
+	if name == "href" && !strings.HasPrefix(value, "#") {
+		return fmt.Errorf("icon SVG links must point inside the icon")
+	}
+	if lowered := strings.ToLower(value); strings.Contains(lowered, "url(") && !localUrlReference.MatchString(lowered) {
+		return fmt.Errorf("icon SVG links must point inside the icon")
+	}
+	return nil
+}
+
+// localUrlReference matches paint and clip values referring to an element of the same icon
+var localUrlReference = regexp.MustCompile(`^url\(\s*['"]?#[^'"()]*['"]?\s*\)(\s+[a-z#0-9]+)?$`)
@@ -32,6 +32,12 @@ var authSet = wire.NewSet(
 	initDBPostgresSet,
 	services.NewUserService,
 	repositories.NewUserRepository,
+	repositories.NewRoleRepository,
+	jwtSet,
+	services.NewSessionService,
+	repositories.NewSessionRepository,
+	services.NewEmailVerificationService,
+	services.NewMailer,
 	validator.NewValidator,
 )
 
@@ -43,9 +49,10 @@ func InitializeApplication() *config.Application {
 func InitializeAuthController() controllers.AuthController {
 	wire.Build(
 		authSet,
-		jwtSet,
 		controllers.NewAuthController,
 		services.NewAuthService,
+		services.NewTwoFactorService,
+		repositories.NewTwoFactorRepository,
 	)
 
 	return nil
@@ -66,6 +73,18 @@ func InitializeClientController() controllers.ClientController {
 		controllers.NewClientController,
 		services.NewClientService,
 		repositories.NewClientRepository,
+		services.NewSegmentService,
+		repositories.NewSegmentRepository,
+		services.NewClientActivityService,
+		repositories.NewClientActivityRepository,
+		services.NewChangeLogService,
+		repositories.NewChangeLogRepository,
+		services.NewClientDocumentService,
+		repositories.NewClientDocumentRepository,
+		services.NewClientContactService,
+		repositories.NewClientContactRepository,
+		services.NewClientPrivacyService,
+		repositories.NewClientPrivacyRepository,
 	)
 
 	return nil
@@ -81,3 +100,36 @@ func InitializeFeatureController() controllers.FeatureController {
 
 	return nil
 }
+
+func InitializeDepositController() controllers.DepositController {
+	wire.Build(
+		authSet,
+		controllers.NewDepositController,
+		services.NewDepositService,
+		repositories.NewDepositRepository,
+		repositories.NewClientRepository,
+	)
+
+	return nil
+}
+
+func InitializeSegmentController() controllers.SegmentController {
+	wire.Build(
+		authSet,
+		controllers.NewSegmentController,
+		services.NewSegmentService,
+		repositories.NewSegmentRepository,
+	)
+
+	return nil
+}
+
+func InitializeRoleController() controllers.RoleController {
+	wire.Build(
+		authSet,
+		controllers.NewRoleController,
+		services.NewRoleService,
+	)
+
+	return nil
+}
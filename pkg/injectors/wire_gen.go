@@ -30,9 +30,16 @@ func InitializeAuthController() controllers.AuthController {
 	jwtService := services.NewJwtService(redisService)
 	db := config.InitDatabasePostgres()
 	userRepository := repositories.NewUserRepository(db)
-	userService := services.NewUserService(userRepository)
-	authService := services.NewAuthService(jwtService, userService, userRepository, redisService)
-	authController := controllers.NewAuthController(authService, redisService, jwtService)
+	roleRepository := repositories.NewRoleRepository(db)
+	sessionRepository := repositories.NewSessionRepository(db)
+	sessionService := services.NewSessionService(sessionRepository, jwtService)
+	mailer := services.NewMailer()
+	emailVerificationService := services.NewEmailVerificationService(userRepository, redisService, mailer)
+	userService := services.NewUserService(userRepository, roleRepository, redisService, sessionService, emailVerificationService)
+	twoFactorRepository := repositories.NewTwoFactorRepository(db)
+	twoFactorService := services.NewTwoFactorService(twoFactorRepository, userService, redisService)
+	authService := services.NewAuthService(jwtService, userService, userRepository, redisService, sessionService, mailer, twoFactorService)
+	authController := controllers.NewAuthController(authService, redisService, jwtService, userService, sessionService, emailVerificationService, twoFactorService)
 	return authController
 }
 
@@ -42,7 +49,13 @@ func InitializeUserController() controllers.UserController {
 	redisService := services.NewRedisService(redisRepository)
 	db := config.InitDatabasePostgres()
 	userRepository := repositories.NewUserRepository(db)
-	userService := services.NewUserService(userRepository)
+	roleRepository := repositories.NewRoleRepository(db)
+	sessionRepository := repositories.NewSessionRepository(db)
+	jwtService := services.NewJwtService(redisService)
+	sessionService := services.NewSessionService(sessionRepository, jwtService)
+	mailer := services.NewMailer()
+	emailVerificationService := services.NewEmailVerificationService(userRepository, redisService, mailer)
+	userService := services.NewUserService(userRepository, roleRepository, redisService, sessionService, emailVerificationService)
 	userController := controllers.NewUserController(redisService, userService)
 	return userController
 }
@@ -53,10 +66,28 @@ func InitializeClientController() controllers.ClientController {
 	redisService := services.NewRedisService(redisRepository)
 	db := config.InitDatabasePostgres()
 	userRepository := repositories.NewUserRepository(db)
-	userService := services.NewUserService(userRepository)
+	roleRepository := repositories.NewRoleRepository(db)
+	sessionRepository := repositories.NewSessionRepository(db)
+	jwtService := services.NewJwtService(redisService)
+	sessionService := services.NewSessionService(sessionRepository, jwtService)
+	mailer := services.NewMailer()
+	emailVerificationService := services.NewEmailVerificationService(userRepository, redisService, mailer)
+	userService := services.NewUserService(userRepository, roleRepository, redisService, sessionService, emailVerificationService)
 	clientRepository := repositories.NewClientRepository(db)
 	clientService := services.NewClientService(clientRepository)
-	clientController := controllers.NewClientController(redisService, userService, clientService)
+	segmentRepository := repositories.NewSegmentRepository(db)
+	segmentService := services.NewSegmentService(segmentRepository)
+	clientActivityRepository := repositories.NewClientActivityRepository(db)
+	clientActivityService := services.NewClientActivityService(clientActivityRepository)
+	changeLogRepository := repositories.NewChangeLogRepository(db)
+	changeLogService := services.NewChangeLogService(changeLogRepository)
+	clientDocumentRepository := repositories.NewClientDocumentRepository(db)
+	clientDocumentService := services.NewClientDocumentService(clientDocumentRepository)
+	clientContactRepository := repositories.NewClientContactRepository(db)
+	clientContactService := services.NewClientContactService(clientContactRepository)
+	clientPrivacyRepository := repositories.NewClientPrivacyRepository(db)
+	clientPrivacyService := services.NewClientPrivacyService(clientPrivacyRepository)
+	clientController := controllers.NewClientController(redisService, userService, clientService, segmentService, clientActivityService, changeLogService, clientDocumentService, clientContactService, clientPrivacyService)
 	return clientController
 }
 
@@ -65,14 +96,77 @@ func InitializeFeatureController() controllers.FeatureController {
 	featureRepository := repositories.NewFeatureRepository(db)
 	featureService := services.NewFeatureService(featureRepository)
 	userRepository := repositories.NewUserRepository(db)
-	userService := services.NewUserService(userRepository)
+	roleRepository := repositories.NewRoleRepository(db)
 	client := config.InitRedis()
 	redisRepository := repositories.NewRedisRepository(client)
 	redisService := services.NewRedisService(redisRepository)
+	sessionRepository := repositories.NewSessionRepository(db)
+	jwtService := services.NewJwtService(redisService)
+	sessionService := services.NewSessionService(sessionRepository, jwtService)
+	mailer := services.NewMailer()
+	emailVerificationService := services.NewEmailVerificationService(userRepository, redisService, mailer)
+	userService := services.NewUserService(userRepository, roleRepository, redisService, sessionService, emailVerificationService)
 	featureController := controllers.NewFeatureController(featureService, userService, redisService)
 	return featureController
 }
 
+func InitializeDepositController() controllers.DepositController {
+	client := config.InitRedis()
+	redisRepository := repositories.NewRedisRepository(client)
+	redisService := services.NewRedisService(redisRepository)
+	db := config.InitDatabasePostgres()
+	userRepository := repositories.NewUserRepository(db)
+	roleRepository := repositories.NewRoleRepository(db)
+	sessionRepository := repositories.NewSessionRepository(db)
+	jwtService := services.NewJwtService(redisService)
+	sessionService := services.NewSessionService(sessionRepository, jwtService)
+	mailer := services.NewMailer()
+	emailVerificationService := services.NewEmailVerificationService(userRepository, redisService, mailer)
+	userService := services.NewUserService(userRepository, roleRepository, redisService, sessionService, emailVerificationService)
+	depositRepository := repositories.NewDepositRepository(db)
+	clientRepository := repositories.NewClientRepository(db)
+	depositService := services.NewDepositService(depositRepository, clientRepository)
+	depositController := controllers.NewDepositController(redisService, userService, depositService)
+	return depositController
+}
+
+func InitializeSegmentController() controllers.SegmentController {
+	db := config.InitDatabasePostgres()
+	segmentRepository := repositories.NewSegmentRepository(db)
+	segmentService := services.NewSegmentService(segmentRepository)
+	userRepository := repositories.NewUserRepository(db)
+	roleRepository := repositories.NewRoleRepository(db)
+	client := config.InitRedis()
+	redisRepository := repositories.NewRedisRepository(client)
+	redisService := services.NewRedisService(redisRepository)
+	sessionRepository := repositories.NewSessionRepository(db)
+	jwtService := services.NewJwtService(redisService)
+	sessionService := services.NewSessionService(sessionRepository, jwtService)
+	mailer := services.NewMailer()
+	emailVerificationService := services.NewEmailVerificationService(userRepository, redisService, mailer)
+	userService := services.NewUserService(userRepository, roleRepository, redisService, sessionService, emailVerificationService)
+	segmentController := controllers.NewSegmentController(segmentService, userService, redisService)
+	return segmentController
+}
+
+func InitializeRoleController() controllers.RoleController {
+	db := config.InitDatabasePostgres()
+	roleRepository := repositories.NewRoleRepository(db)
+	client := config.InitRedis()
+	redisRepository := repositories.NewRedisRepository(client)
+	redisService := services.NewRedisService(redisRepository)
+	roleService := services.NewRoleService(roleRepository, redisService)
+	userRepository := repositories.NewUserRepository(db)
+	sessionRepository := repositories.NewSessionRepository(db)
+	jwtService := services.NewJwtService(redisService)
+	sessionService := services.NewSessionService(sessionRepository, jwtService)
+	mailer := services.NewMailer()
+	emailVerificationService := services.NewEmailVerificationService(userRepository, redisService, mailer)
+	userService := services.NewUserService(userRepository, roleRepository, redisService, sessionService, emailVerificationService)
+	roleController := controllers.NewRoleController(roleService, userService, redisService)
+	return roleController
+}
+
 // injector.go:
 
 var initDBPostgresSet = wire.NewSet(config.InitDatabasePostgres)
@@ -83,5 +177,5 @@ var jwtSet = wire.NewSet(services.NewJwtService)
 
 var authSet = wire.NewSet(
 	redisSet,
-	initDBPostgresSet, services.NewUserService, repositories.NewUserRepository, validator.NewValidator,
+	initDBPostgresSet, services.NewUserService, repositories.NewUserRepository, repositories.NewRoleRepository, jwtSet, services.NewSessionService, repositories.NewSessionRepository, services.NewEmailVerificationService, services.NewMailer, validator.NewValidator,
 )
@@ -2,6 +2,7 @@ package jwt
 
 import (
 	"log"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
@@ -71,6 +72,15 @@ func JwtMiddleware(userService services.UserService, redisService services.Redis
 			})
 		}
 
+		// A reused refresh token revokes its family, including access tokens already handed out
+		if family, _ := claim["tokens"].(string); family != "" && jwtService.IsFamilyRevoked(family) {
+			log.Println("Error while validating token", "error", "Token Family Revoked")
+			return c.Status(fiber.StatusUnauthorized).JSON(dtos.ErrorResponseDTO{
+				Message: "Token Not Valid",
+				Code:    fiber.StatusUnauthorized,
+			})
+		}
+
 		return handleToken(&handle{
 			ctx:         c,
 			claim:       claim,
@@ -108,11 +118,61 @@ func handleToken(data *handle) error {
 		})
 	}
 
+	if userData.DeactivatedAt != nil {
+		return data.ctx.Status(fiber.StatusUnauthorized).JSON(dtos.ErrorResponseDTO{
+			Message: "Account Deactivated",
+			Code:    fiber.StatusUnauthorized,
+		})
+	}
+
+	// Users whose role requires two-factor authentication can only enroll until they did
+	required, err := data.userService.TwoFactorRequired(userData)
+	if err != nil {
+		return data.ctx.Status(fiber.StatusInternalServerError).JSON(dtos.ErrorResponseDTO{
+			Message: "Internal Server Error",
+			Code:    fiber.StatusInternalServerError,
+		})
+	}
+	if required && !allowedWithoutTwoFactor(data.ctx.Path()) {
+		return data.ctx.Status(fiber.StatusForbidden).JSON(dtos.ErrorResponseDTO{
+			Message: "Two-factor authentication required",
+			Code:    fiber.StatusForbidden,
+		})
+	}
+
+	permissions, err := data.userService.GetPermissions(userData)
+	if err != nil {
+		return data.ctx.Status(fiber.StatusInternalServerError).JSON(dtos.ErrorResponseDTO{
+			Message: "Internal Server Error",
+			Code:    fiber.StatusInternalServerError,
+		})
+	}
+
 	data.ctx.Locals("user", userData)
 	data.ctx.Locals("email", userData.Email)
 	data.ctx.Locals("user_uuid", userData.UUID)
 	data.ctx.Locals("token", data.jwtToken)
 	data.ctx.Locals("role", userData.Role)
+	data.ctx.Locals("permissions", permissions)
 
 	return data.ctx.Next()
 }
+
+// twoFactorEnrollmentPaths are the routes left to a user who still has to enroll two-factor authentication
+var twoFactorEnrollmentPaths = []string{
+	"/auth/2fa",
+	"/auth/2fa/enroll",
+	"/auth/2fa/confirm",
+	"/auth/logout",
+	"/auth/logout-all",
+}
+
+func allowedWithoutTwoFactor(path string) bool {
+	path = strings.TrimSuffix(path, "/")
+	for _, allowed := range twoFactorEnrollmentPaths {
+		if strings.HasSuffix(path, allowed) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package permission
+
+import (
+	"log"
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+
+	"alfredo/ruu-properties/pkg/dtos"
+)
+
+// RequirePermission lets the request through only when the signed in user's role grants the
+// permission. It runs after JwtMiddleware, which resolves the permissions of the user.
+func RequirePermission(permission string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, ok := c.Locals("permissions").([]string); !ok {
+			log.Println("Error: Permissions not found in context")
+			return c.Status(fiber.StatusUnauthorized).JSON(dtos.ErrorResponseDTO{
+				Message: "Unauthorized",
+				Code:    fiber.StatusUnauthorized,
+			})
+		}
+
+		if !Has(c, permission) {
+			log.Printf("Access denied for user %v without permission %s", c.Locals("email"), permission)
+			return c.Status(fiber.StatusForbidden).JSON(dtos.ErrorResponseDTO{
+				Message: "Access denied. Permission " + permission + " required",
+				Code:    fiber.StatusForbidden,
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// Has reports whether the signed in user's role grants the permission
+func Has(c *fiber.Ctx, permission string) bool {
+	permissions, _ := c.Locals("permissions").([]string)
+	return slices.Contains(permissions, permission)
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+const (
+	ChangeLogEntityClient = "client"
+
+	ChangeLogActionCreate  = "create"
+	ChangeLogActionUpdate  = "update"
+	ChangeLogActionDelete  = "delete"
+	ChangeLogActionRestore = "restore"
+	ChangeLogActionMerge   = "merge"
+	ChangeLogActionErase   = "erase"
+)
+
+// ChangeLog is one entry of the change history of a record. Changes holds a JSON object
+// keyed by field name with the value before and after the change. Entries are never edited,
+// except that erasing a client blanks the personal values in its history.
+type ChangeLog struct {
+	UUID       string    `json:"uuid" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	EntityType string    `json:"entity_type" gorm:"column:entity_type;type:varchar(30);not null;index:idx_change_logs_entity,priority:1"`
+	EntityUUID string    `json:"entity_uuid" gorm:"type:uuid;column:entity_uuid;not null;index:idx_change_logs_entity,priority:2"`
+	Action     string    `json:"action" gorm:"column:action;type:varchar(20);not null"`
+	ActorUUID  *string   `json:"actor_uuid" gorm:"type:uuid;column:actor_uuid"`
+	RequestID  string    `json:"request_id" gorm:"column:request_id;type:varchar(100)"`
+	Changes    string    `json:"changes" gorm:"column:changes;type:jsonb;not null"`
+	CreatedAt  time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime;index:idx_change_logs_entity,priority:3"`
+}
+
+func (c *ChangeLog) TableName() string {
+	return "change_logs"
+}
@@ -7,15 +7,20 @@ import (
 )
 
 type Client struct {
-	UUID          string         `json:"uuid" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
-	Name          string         `json:"name" gorm:"column:name;not null"`
-	Email         string         `json:"email" gorm:"column:email;not null;uniqueIndex"`
-	PhoneNumber   string         `json:"phone_number" gorm:"column:phone_number;not null;uniqueIndex"`
-	Address       string         `json:"address" gorm:"column:address;not null"`
-	ContactPerson string         `json:"contact_person" gorm:"column:contact_person;not null"`
-	CreatedAt     time.Time      `json:"created_at" gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt     time.Time      `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
-	DeletedAt     gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;index"`
+	UUID          string          `json:"uuid" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Name          string          `json:"name" gorm:"column:name;not null"`
+	Email         string          `json:"email" gorm:"column:email;not null;uniqueIndex"`
+	PhoneNumber   string          `json:"phone_number" gorm:"column:phone_number;type:varchar(16);not null;uniqueIndex"`
+	Address       string          `json:"address" gorm:"column:address;not null"`
+	ContactPerson string          `json:"contact_person" gorm:"column:contact_person;not null"`
+	KycStatus     string          `json:"kyc_status" gorm:"column:kyc_status;type:varchar(20);not null;default:'none';index"`
+	OwnerUUID     *string         `json:"owner_uuid" gorm:"type:uuid;column:owner_uuid;index"`
+	CreatedAt     time.Time       `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt     time.Time       `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+	DeletedAt     gorm.DeletedAt  `json:"deleted_at" gorm:"column:deleted_at;index"`
+	ErasedAt      *time.Time      `json:"erased_at" gorm:"column:erased_at"`
+	Tags          []ClientTag     `json:"tags" gorm:"foreignKey:ClientUUID;references:UUID"`
+	Contacts      []ClientContact `json:"contacts" gorm:"foreignKey:ClientUUID;references:UUID"`
 }
 
 func (c *Client) TableName() string {
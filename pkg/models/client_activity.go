@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ClientActivityTypeNote  = "note"
+	ClientActivityTypeCall  = "call"
+	ClientActivityTypeEmail = "email"
+)
+
+// ClientActivity is a note, call or email logged by an agent against a client
+type ClientActivity struct {
+	UUID       string         `json:"uuid" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	ClientUUID string         `json:"client_uuid" gorm:"type:uuid;column:client_uuid;not null;index"`
+	AuthorUUID *string        `json:"author_uuid" gorm:"type:uuid;column:author_uuid"`
+	Type       string         `json:"type" gorm:"column:type;type:varchar(20);not null;index"`
+	Subject    string         `json:"subject" gorm:"column:subject;type:varchar(255)"`
+	Body       string         `json:"body" gorm:"column:body;type:text"`
+	Outcome    string         `json:"outcome" gorm:"column:outcome;type:varchar(30)"`
+	FollowUpAt *time.Time     `json:"follow_up_at" gorm:"column:follow_up_at;index"`
+	OccurredAt time.Time      `json:"occurred_at" gorm:"column:occurred_at;not null"`
+	CreatedAt  time.Time      `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt  time.Time      `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+	DeletedAt  gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;index"`
+}
+
+func (c *ClientActivity) TableName() string {
+	return "client_activities"
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ClientContactChannelEmail    = "email"
+	ClientContactChannelPhone    = "phone"
+	ClientContactChannelWhatsApp = "whatsapp"
+)
+
+// ClientContact is a person to reach at a client, e.g. their finance or facility contact.
+// Exactly one contact of a client is primary, and its name is kept in Client.ContactPerson.
+type ClientContact struct {
+	UUID             string         `json:"uuid" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	ClientUUID       string         `json:"client_uuid" gorm:"type:uuid;column:client_uuid;not null;index;uniqueIndex:idx_client_contacts_primary,where:is_primary AND deleted_at IS NULL"`
+	Name             string         `json:"name" gorm:"column:name;type:varchar(255);not null"`
+	Role             string         `json:"role" gorm:"column:role;type:varchar(30);not null;default:'general'"`
+	Email            string         `json:"email" gorm:"column:email;type:varchar(255)"`
+	PhoneNumber      string         `json:"phone_number" gorm:"column:phone_number;type:varchar(30)"`
+	PreferredChannel string         `json:"preferred_channel" gorm:"column:preferred_channel;type:varchar(20)"`
+	IsPrimary        bool           `json:"is_primary" gorm:"column:is_primary;not null;default:false"`
+	CreatedAt        time.Time      `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt        time.Time      `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+	DeletedAt        gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;index"`
+}
+
+func (c *ClientContact) TableName() string {
+	return "client_contacts"
+}
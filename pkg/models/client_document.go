@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ClientDocumentTypeKTP      = "ktp"
+	ClientDocumentTypeNPWP     = "npwp"
+	ClientDocumentTypePassport = "passport"
+
+	ClientDocumentStatusPending  = "pending"
+	ClientDocumentStatusVerified = "verified"
+	ClientDocumentStatusRejected = "rejected"
+)
+
+// KYC statuses of a client, derived from its documents
+const (
+	KycStatusNone       = "none"
+	KycStatusIncomplete = "incomplete"
+	KycStatusPending    = "pending"
+	KycStatusRejected   = "rejected"
+	KycStatusExpired    = "expired"
+	KycStatusVerified   = "verified"
+)
+
+// ClientDocument is an identity or tax document a client handed in for KYC. A newer upload of
+// the same type supersedes the older one, which is kept for the record.
+type ClientDocument struct {
+	UUID            string         `json:"uuid" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	ClientUUID      string         `json:"client_uuid" gorm:"type:uuid;column:client_uuid;not null;index"`
+	Type            string         `json:"type" gorm:"column:type;type:varchar(20);not null"`
+	Number          string         `json:"number" gorm:"column:number;type:varchar(50);not null"`
+	ExpiresAt       *time.Time     `json:"expires_at" gorm:"column:expires_at;type:date"`
+	FilePath        string         `json:"file_path" gorm:"column:file_path;not null"`
+	Status          string         `json:"status" gorm:"column:status;type:varchar(20);not null;default:'pending'"`
+	RejectionReason string         `json:"rejection_reason" gorm:"column:rejection_reason;type:text"`
+	UploadedBy      *string        `json:"uploaded_by" gorm:"type:uuid;column:uploaded_by"`
+	ReviewedBy      *string        `json:"reviewed_by" gorm:"type:uuid;column:reviewed_by"`
+	ReviewedAt      *time.Time     `json:"reviewed_at" gorm:"column:reviewed_at"`
+	CreatedAt       time.Time      `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt       time.Time      `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+	DeletedAt       gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;index"`
+}
+
+func (d *ClientDocument) TableName() string {
+	return "client_documents"
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+type ClientTag struct {
+	UUID       string    `json:"uuid" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	ClientUUID string    `json:"client_uuid" gorm:"type:uuid;not null;uniqueIndex:idx_client_tags_client_tag"`
+	Tag        string    `json:"tag" gorm:"type:varchar(50);not null;uniqueIndex:idx_client_tags_client_tag;index"`
+	CreatedAt  time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (c *ClientTag) TableName() string {
+	return "client_tags"
+}
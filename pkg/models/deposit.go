@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	DepositStatusHeld     = "held"
+	DepositStatusRefunded = "refunded"
+)
+
+type Deposit struct {
+	UUID            string             `json:"uuid" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	ClientUUID      string             `json:"client_uuid" gorm:"type:uuid;column:client_uuid;not null;index"`
+	PropertyUUID    string             `json:"property_uuid" gorm:"type:uuid;column:property_uuid;not null;index"`
+	Amount          float64            `json:"amount" gorm:"column:amount;type:decimal(14,2);not null"`
+	PaymentMethod   string             `json:"payment_method" gorm:"column:payment_method;type:varchar(50)"`
+	ReceivedAt      time.Time          `json:"received_at" gorm:"column:received_at;not null"`
+	Status          string             `json:"status" gorm:"column:status;type:varchar(20);not null;default:'held';index"`
+	RefundAmount    float64            `json:"refund_amount" gorm:"column:refund_amount;type:decimal(14,2);not null;default:0"`
+	RefundMethod    string             `json:"refund_method" gorm:"column:refund_method;type:varchar(50)"`
+	RefundReference string             `json:"refund_reference" gorm:"column:refund_reference;type:varchar(100)"`
+	RefundedAt      *time.Time         `json:"refunded_at" gorm:"column:refunded_at"`
+	Notes           string             `json:"notes" gorm:"column:notes"`
+	Deductions      []DepositDeduction `json:"deductions" gorm:"foreignKey:DepositUUID;references:UUID"`
+	CreatedAt       time.Time          `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt       time.Time          `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+	DeletedAt       gorm.DeletedAt     `json:"deleted_at" gorm:"column:deleted_at;index"`
+}
+
+func (d *Deposit) TableName() string {
+	return "deposits"
+}
+
+type DepositDeduction struct {
+	UUID        string                  `json:"uuid" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	DepositUUID string                  `json:"deposit_uuid" gorm:"type:uuid;column:deposit_uuid;not null;index"`
+	Category    string                  `json:"category" gorm:"column:category;type:varchar(30);not null"`
+	Description string                  `json:"description" gorm:"column:description;not null"`
+	Amount      float64                 `json:"amount" gorm:"column:amount;type:decimal(14,2);not null"`
+	Photos      []DepositDeductionPhoto `json:"photos" gorm:"foreignKey:DeductionUUID;references:UUID"`
+	CreatedAt   time.Time               `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time               `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+	DeletedAt   gorm.DeletedAt          `json:"deleted_at" gorm:"column:deleted_at;index"`
+}
+
+func (d *DepositDeduction) TableName() string {
+	return "deposit_deductions"
+}
+
+type DepositDeductionPhoto struct {
+	UUID          string         `json:"uuid" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	DeductionUUID string         `json:"deduction_uuid" gorm:"type:uuid;column:deduction_uuid;not null;index"`
+	PhotoURL      string         `json:"photo_url" gorm:"column:photo_url;not null"`
+	CreatedAt     time.Time      `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt     time.Time      `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+	DeletedAt     gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;index"`
+}
+
+func (d *DepositDeductionPhoto) TableName() string {
+	return "deposit_deduction_photos"
+}
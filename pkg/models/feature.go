@@ -11,6 +11,8 @@ type Feature struct {
 	UUID        string         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"uuid"`
 	Name        string         `gorm:"not null" json:"name"`
 	Description string         `json:"description"`
+	Icon        string         `gorm:"type:varchar(255)" json:"icon"`
+	IsPublic    bool           `gorm:"not null;default:false;index" json:"is_public"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"deleted_at"`
@@ -0,0 +1,102 @@
+package models
+
+import "time"
+
+// RoleAdmin holds every permission, including ones added after the role was created
+const RoleAdmin = "admin"
+
+// The permission catalogue. Routes require these through permission.RequirePermission.
+const (
+	PermissionClientsRead     = "clients:read"
+	PermissionClientsWrite    = "clients:write"
+	PermissionClientsDelete   = "clients:delete"
+	PermissionClientsReadAll  = "clients:read-all"
+	PermissionClientsManage   = "clients:manage"
+	PermissionClientsPrivacy  = "clients:privacy"
+	PermissionDocumentsReview = "documents:review"
+	PermissionDepositsRead    = "deposits:read"
+	PermissionDepositsWrite   = "deposits:write"
+	PermissionDepositsRefund  = "deposits:refund"
+	PermissionFeaturesRead    = "features:read"
+	PermissionFeaturesWrite   = "features:write"
+	PermissionFeaturesDelete  = "features:delete"
+	PermissionFeaturesManage  = "features:manage"
+	PermissionSegmentsRead    = "segments:read"
+	PermissionSegmentsWrite   = "segments:write"
+	PermissionUsersManage     = "users:manage"
+	PermissionRolesManage     = "roles:manage"
+)
+
+// Permissions describes every permission of the catalogue
+var Permissions = []Permission{
+	{Name: PermissionClientsRead, Description: "View clients visible to the user, with their timeline, history, documents and contacts"},
+	{Name: PermissionClientsWrite, Description: "Create and update clients, their contacts, tags, documents and activities"},
+	{Name: PermissionClientsDelete, Description: "Delete clients and merge duplicates into another client"},
+	{Name: PermissionClientsReadAll, Description: "See the clients of every agent, not only those of the user and their team"},
+	{Name: PermissionClientsManage, Description: "Manage the client trash and hand clients over to other agents"},
+	{Name: PermissionClientsPrivacy, Description: "Export and erase the personal data of clients"},
+	{Name: PermissionDocumentsReview, Description: "Verify or reject KYC documents"},
+	{Name: PermissionDepositsRead, Description: "View deposits and their settlements"},
+	{Name: PermissionDepositsWrite, Description: "Record deposits and deductions"},
+	{Name: PermissionDepositsRefund, Description: "Refund deposits"},
+	{Name: PermissionFeaturesRead, Description: "View property features"},
+	{Name: PermissionFeaturesWrite, Description: "Create and update property features"},
+	{Name: PermissionFeaturesDelete, Description: "Delete property features"},
+	{Name: PermissionFeaturesManage, Description: "Manage the property feature trash"},
+	{Name: PermissionSegmentsRead, Description: "View client segments"},
+	{Name: PermissionSegmentsWrite, Description: "Create, update and delete client segments"},
+	{Name: PermissionUsersManage, Description: "Assign users to roles and teams and revoke their sessions"},
+	{Name: PermissionRolesManage, Description: "Create roles and change their permissions"},
+}
+
+// DefaultRoles are the permissions of the built-in roles, as seeded by the migrations. The
+// user role is what self-registered users get, so it equals viewer and writing takes a role
+// assigned by an admin.
+var DefaultRoles = map[string][]string{
+	"manager": {
+		PermissionClientsRead, PermissionClientsWrite, PermissionClientsDelete, PermissionClientsReadAll,
+		PermissionClientsManage, PermissionDocumentsReview, PermissionDepositsRead, PermissionDepositsWrite,
+		PermissionDepositsRefund, PermissionFeaturesRead, PermissionFeaturesWrite, PermissionFeaturesDelete,
+		PermissionSegmentsRead, PermissionSegmentsWrite,
+	},
+	"agent": {
+		PermissionClientsRead, PermissionClientsWrite, PermissionClientsDelete, PermissionDepositsRead,
+		PermissionDepositsWrite, PermissionFeaturesRead, PermissionFeaturesWrite, PermissionFeaturesDelete,
+		PermissionSegmentsRead, PermissionSegmentsWrite,
+	},
+	"finance": {
+		PermissionClientsRead, PermissionClientsReadAll, PermissionDepositsRead, PermissionDepositsWrite,
+		PermissionDepositsRefund, PermissionFeaturesRead, PermissionSegmentsRead,
+	},
+	"viewer": {
+		PermissionClientsRead, PermissionDepositsRead, PermissionFeaturesRead, PermissionSegmentsRead,
+	},
+	"user": {
+		PermissionClientsRead, PermissionDepositsRead, PermissionFeaturesRead, PermissionSegmentsRead,
+	},
+}
+
+// Role is a named set of permissions. Every user has exactly one role, stored by name in
+// User.Role.
+type Role struct {
+	Name             string       `json:"name" gorm:"column:name;type:varchar(50);primaryKey"`
+	Description      string       `json:"description" gorm:"column:description;type:varchar(255)"`
+	Permissions      []Permission `json:"permissions" gorm:"many2many:role_permissions;joinForeignKey:role_name;joinReferences:permission_name"`
+	RequireTwoFactor bool         `json:"require_two_factor" gorm:"column:require_two_factor;not null;default:false"`
+	CreatedAt        time.Time    `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt        time.Time    `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (r *Role) TableName() string {
+	return "roles"
+}
+
+// Permission allows an action, named as "<resource>:<action>"
+type Permission struct {
+	Name        string `json:"name" gorm:"column:name;type:varchar(100);primaryKey"`
+	Description string `json:"description" gorm:"column:description;type:varchar(255)"`
+}
+
+func (p *Permission) TableName() string {
+	return "permissions"
+}
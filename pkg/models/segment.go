@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Segment is a saved client filter. The filter is stored as JSON and evaluated every time the
+// segment is read, so its members follow the data.
+type Segment struct {
+	UUID        string         `json:"uuid" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Name        string         `json:"name" gorm:"type:varchar(100);not null;uniqueIndex"`
+	Description string         `json:"description" gorm:"type:text"`
+	Filter      string         `json:"filter" gorm:"type:text;not null"`
+	CreatedAt   time.Time      `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time      `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;index"`
+}
+
+func (s *Segment) TableName() string {
+	return "segments"
+}
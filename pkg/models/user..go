@@ -7,16 +7,21 @@ import (
 )
 
 type User struct {
-	UUID        string         `json:"uuid" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
-	Email       string         `json:"email" gorm:"type:varchar(255);uniqueIndex"`
-	Password    string         `json:"password" gorm:"type:varchar(255)"`
-	Name        string         `json:"name" gorm:"type:varchar(255)"`
-	PhoneNumber string         `json:"phone_number" gorm:"type:varchar(255)"`
-	Image       string         `json:"image" gorm:"type:varchar(255)"`
-	Role        string         `json:"role" gorm:"type:varchar(50);default:'user'"`
-	CreatedAt   time.Time      `json:"created_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
-	UpdatedAt   time.Time      `json:"updated_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
-	DeletedAt   gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+	UUID               string         `json:"uuid" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Email              string         `json:"email" gorm:"type:varchar(255);uniqueIndex"`
+	Password           string         `json:"password" gorm:"type:varchar(255)"`
+	Name               string         `json:"name" gorm:"type:varchar(255)"`
+	PhoneNumber        string         `json:"phone_number" gorm:"type:varchar(16)"`
+	Image              string         `json:"image" gorm:"type:varchar(255)"`
+	Role               string         `json:"role" gorm:"type:varchar(50);default:'user'"`
+	Team               string         `json:"team" gorm:"type:varchar(100);not null;default:'';index"`
+	DeactivatedAt      *time.Time     `json:"deactivated_at"`
+	EmailVerifiedAt    *time.Time     `json:"email_verified_at"`
+	TwoFactorSecret    string         `json:"-" gorm:"type:text;not null;default:''"`
+	TwoFactorEnabledAt *time.Time     `json:"two_factor_enabled_at"`
+	CreatedAt          time.Time      `json:"created_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt          time.Time      `json:"updated_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
+	DeletedAt          gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 }
 
 func (u *User) TableName() string {
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// UserRecoveryCode signs a user in once in place of a TOTP code, for when the authenticator
+// is lost. Only the hash of the code is stored.
+type UserRecoveryCode struct {
+	UUID      string     `json:"uuid" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserUUID  string     `json:"user_uuid" gorm:"type:uuid;column:user_uuid;not null;index"`
+	CodeHash  string     `json:"-" gorm:"column:code_hash;type:varchar(64);not null"`
+	UsedAt    *time.Time `json:"used_at" gorm:"column:used_at"`
+	CreatedAt time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+func (c *UserRecoveryCode) TableName() string {
+	return "user_recovery_codes"
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// UserSession is one login of a user on a device. It follows the refresh token family of the
+// login, so revoking the session revokes every token the login was issued.
+type UserSession struct {
+	UUID       string     `json:"uuid" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserUUID   string     `json:"user_uuid" gorm:"type:uuid;column:user_uuid;not null;index"`
+	Family     string     `json:"-" gorm:"column:family;type:varchar(64);not null;uniqueIndex"`
+	Device     string     `json:"device" gorm:"column:device;type:varchar(100)"`
+	UserAgent  string     `json:"user_agent" gorm:"column:user_agent;type:varchar(255)"`
+	IPAddress  string     `json:"ip_address" gorm:"column:ip_address;type:varchar(45)"`
+	LastSeenAt time.Time  `json:"last_seen_at" gorm:"column:last_seen_at;not null"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"column:expires_at;not null"`
+	RevokedAt  *time.Time `json:"revoked_at" gorm:"column:revoked_at"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt  time.Time  `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (s *UserSession) TableName() string {
+	return "user_sessions"
+}
@@ -0,0 +1,136 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+
+	"gorm.io/gorm"
+
+	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/models"
+)
+
+type ChangeLogRepository interface {
+	GetHistory(request dtos.ChangeLogGetRequest) ([]*dtos.ChangeLogResponse, *dtos.PaginationMeta, error)
+}
+
+// changeLogEntities maps every entity type with a change history to its table. The history
+// outlives soft deletes, so the lookup includes deleted rows.
+var changeLogEntities = map[string]string{
+	models.ChangeLogEntityClient: "clients",
+}
+
+type changeLogRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// GetHistory implements ChangeLogRepository.
+func (r *changeLogRepositoryImpl) GetHistory(request dtos.ChangeLogGetRequest) ([]*dtos.ChangeLogResponse, *dtos.PaginationMeta, error) {
+	var exists int64
+	if err := r.db.Table(changeLogEntities[request.EntityType]).Where("uuid = ?", request.EntityUUID).Count(&exists).Error; err != nil {
+		return nil, nil, fmt.Errorf("%s", "please try again later")
+	}
+	if exists == 0 {
+		return nil, nil, fmt.Errorf("%s not found", request.EntityType)
+	}
+
+	query := r.db.Model(&models.ChangeLog{}).Where("entity_type = ? AND entity_uuid = ?", request.EntityType, request.EntityUUID)
+	if request.Field != "" {
+		query = query.Where("changes -> ?::text IS NOT NULL", request.Field)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to count history: %w", err)
+	}
+
+	var entries []models.ChangeLog
+	offset := (request.Page - 1) * request.Limit
+	err := query.Order("created_at desc, uuid desc").Offset(offset).Limit(request.Limit).Find(&entries).Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch history: %w", err)
+	}
+
+	responses := make([]*dtos.ChangeLogResponse, len(entries))
+	for i, entry := range entries {
+		if responses[i], err = toChangeLogResponse(entry); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	paginationMeta := &dtos.PaginationMeta{
+		Page:       request.Page,
+		Limit:      request.Limit,
+		Total:      int(total),
+		TotalPages: int(math.Ceil(float64(total) / float64(request.Limit))),
+	}
+
+	return responses, paginationMeta, nil
+}
+
+func toChangeLogResponse(entry models.ChangeLog) (*dtos.ChangeLogResponse, error) {
+	changes := map[string]dtos.FieldChange{}
+	if err := json.Unmarshal([]byte(entry.Changes), &changes); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	return &dtos.ChangeLogResponse{
+		UUID:       entry.UUID,
+		EntityType: entry.EntityType,
+		EntityUUID: entry.EntityUUID,
+		Action:     entry.Action,
+		ActorUUID:  entry.ActorUUID,
+		RequestID:  entry.RequestID,
+		Changes:    changes,
+		CreatedAt:  entry.CreatedAt,
+	}, nil
+}
+
+// diffFields returns the fields whose value differs between before and after. A nil map
+// stands for a record that does not exist on that side, so every field of the other is
+// reported.
+func diffFields(before map[string]interface{}, after map[string]interface{}) map[string]dtos.FieldChange {
+	changes := map[string]dtos.FieldChange{}
+	for name, from := range before {
+		if to, ok := after[name]; !ok || !reflect.DeepEqual(from, to) {
+			changes[name] = dtos.FieldChange{From: from, To: after[name]}
+		}
+	}
+	for name, to := range after {
+		if _, ok := before[name]; !ok {
+			changes[name] = dtos.FieldChange{To: to}
+		}
+	}
+	return changes
+}
+
+// recordChange writes a history entry within tx, so the entry and the change commit together.
+// Nothing is written when no field changed.
+func recordChange(tx *gorm.DB, entityType string, entityUUID string, action string, actor dtos.ChangeActor, changes map[string]dtos.FieldChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	raw, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	entry := models.ChangeLog{
+		EntityType: entityType,
+		EntityUUID: entityUUID,
+		Action:     action,
+		ActorUUID:  authorUUID(actor.UserUUID),
+		RequestID:  actor.RequestID,
+		Changes:    string(raw),
+	}
+	if err := tx.Create(&entry).Error; err != nil {
+		return fmt.Errorf("failed to record change: %w", err)
+	}
+	return nil
+}
+
+func NewChangeLogRepository(db *gorm.DB) ChangeLogRepository {
+	return &changeLogRepositoryImpl{db: db}
+}
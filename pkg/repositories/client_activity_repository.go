@@ -0,0 +1,183 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/models"
+)
+
+type ClientActivityRepository interface {
+	CreateNote(request dtos.ClientNoteRequest) (*dtos.ClientActivityResponse, error)
+	CreateCall(request dtos.ClientCallRequest) (*dtos.ClientActivityResponse, error)
+	CreateEmail(request dtos.ClientEmailRequest) (*dtos.ClientActivityResponse, error)
+	GetTimeline(request dtos.ClientTimelineRequest) ([]*dtos.ClientTimelineItem, *dtos.PaginationMeta, error)
+}
+
+// clientTimelineSources are unioned into the client timeline. Every source selects the columns
+// of dtos.ClientTimelineItem in the same order and filters on the @client argument, so new
+// client-related records only have to add a source here.
+var clientTimelineSources = []string{
+	`SELECT type, uuid::text AS reference_uuid, occurred_at,
+		CASE WHEN type = 'email' THEN subject ELSE body END AS summary,
+		CASE WHEN type = 'email' THEN body ELSE '' END AS body,
+		outcome, follow_up_at, NULL::numeric AS amount, author_uuid::text AS author_uuid
+	FROM client_activities WHERE client_uuid = @client AND deleted_at IS NULL`,
+	`SELECT 'deposit_received', uuid::text, received_at, 'Deposit received', notes,
+		NULL, NULL, amount, NULL
+	FROM deposits WHERE client_uuid = @client AND deleted_at IS NULL`,
+	`SELECT 'deposit_deduction', deposit_deductions.uuid::text, deposit_deductions.created_at,
+		deposit_deductions.description, deposit_deductions.category,
+		NULL, NULL, deposit_deductions.amount, NULL
+	FROM deposit_deductions JOIN deposits ON deposits.uuid = deposit_deductions.deposit_uuid
+	WHERE deposits.client_uuid = @client AND deposits.deleted_at IS NULL AND deposit_deductions.deleted_at IS NULL`,
+	`SELECT 'deposit_refunded', uuid::text, refunded_at, 'Deposit refunded', refund_reference,
+		NULL, NULL, refund_amount, NULL
+	FROM deposits WHERE client_uuid = @client AND refunded_at IS NOT NULL AND deleted_at IS NULL`,
+}
+
+type clientActivityRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// CreateNote implements ClientActivityRepository.
+func (r *clientActivityRepositoryImpl) CreateNote(request dtos.ClientNoteRequest) (*dtos.ClientActivityResponse, error) {
+	return r.create(models.ClientActivity{
+		ClientUUID: request.ClientUUID,
+		AuthorUUID: authorUUID(request.AuthorUUID),
+		Type:       models.ClientActivityTypeNote,
+		Body:       request.Body,
+		OccurredAt: time.Now(),
+	})
+}
+
+// CreateCall implements ClientActivityRepository.
+func (r *clientActivityRepositoryImpl) CreateCall(request dtos.ClientCallRequest) (*dtos.ClientActivityResponse, error) {
+	occurredAt := time.Now()
+	if request.OccurredAt != nil {
+		occurredAt = *request.OccurredAt
+	}
+
+	return r.create(models.ClientActivity{
+		ClientUUID: request.ClientUUID,
+		AuthorUUID: authorUUID(request.AuthorUUID),
+		Type:       models.ClientActivityTypeCall,
+		Body:       request.Summary,
+		Outcome:    request.Outcome,
+		FollowUpAt: request.FollowUpAt,
+		OccurredAt: occurredAt,
+	})
+}
+
+// CreateEmail implements ClientActivityRepository.
+func (r *clientActivityRepositoryImpl) CreateEmail(request dtos.ClientEmailRequest) (*dtos.ClientActivityResponse, error) {
+	occurredAt := time.Now()
+	if request.OccurredAt != nil {
+		occurredAt = *request.OccurredAt
+	}
+
+	return r.create(models.ClientActivity{
+		ClientUUID: request.ClientUUID,
+		AuthorUUID: authorUUID(request.AuthorUUID),
+		Type:       models.ClientActivityTypeEmail,
+		Subject:    request.Subject,
+		Body:       request.Body,
+		OccurredAt: occurredAt,
+	})
+}
+
+func (r *clientActivityRepositoryImpl) create(activity models.ClientActivity) (*dtos.ClientActivityResponse, error) {
+	if err := r.findClient(activity.ClientUUID); err != nil {
+		return nil, err
+	}
+
+	if err := r.db.Create(&activity).Error; err != nil {
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+
+	return toClientActivityResponse(activity), nil
+}
+
+func toClientActivityResponse(activity models.ClientActivity) *dtos.ClientActivityResponse {
+	return &dtos.ClientActivityResponse{
+		UUID:       activity.UUID,
+		ClientUUID: activity.ClientUUID,
+		AuthorUUID: activity.AuthorUUID,
+		Type:       activity.Type,
+		Subject:    activity.Subject,
+		Body:       activity.Body,
+		Outcome:    activity.Outcome,
+		FollowUpAt: activity.FollowUpAt,
+		OccurredAt: activity.OccurredAt,
+		CreatedAt:  activity.CreatedAt,
+	}
+}
+
+// GetTimeline implements ClientActivityRepository.
+func (r *clientActivityRepositoryImpl) GetTimeline(request dtos.ClientTimelineRequest) ([]*dtos.ClientTimelineItem, *dtos.PaginationMeta, error) {
+	if err := r.findClient(request.ClientUUID); err != nil {
+		return nil, nil, err
+	}
+
+	timeline := fmt.Sprintf("(%s) AS timeline", strings.Join(clientTimelineSources, " UNION ALL "))
+	args := map[string]interface{}{"client": request.ClientUUID}
+
+	where := ""
+	if request.Types != "" {
+		where = " WHERE type IN @types"
+		args["types"] = strings.Split(request.Types, ",")
+	}
+
+	var total int64
+	if err := r.db.Raw("SELECT COUNT(*) FROM "+timeline+where, args).Scan(&total).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to count timeline: %w", err)
+	}
+
+	args["limit"] = request.Limit
+	args["offset"] = (request.Page - 1) * request.Limit
+
+	items := []*dtos.ClientTimelineItem{}
+	query := "SELECT * FROM " + timeline + where +
+		" ORDER BY occurred_at DESC, reference_uuid DESC LIMIT @limit OFFSET @offset"
+	if err := r.db.Raw(query, args).Scan(&items).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch timeline: %w", err)
+	}
+
+	paginationMeta := &dtos.PaginationMeta{
+		Page:       request.Page,
+		Limit:      request.Limit,
+		Total:      int(total),
+		TotalPages: int(math.Ceil(float64(total) / float64(request.Limit))),
+	}
+
+	return items, paginationMeta, nil
+}
+
+func (r *clientActivityRepositoryImpl) findClient(uuid string) error {
+	var client models.Client
+	if err := r.db.Select("uuid").Where("uuid = ?", uuid).First(&client).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%s", "client not found")
+		}
+		return fmt.Errorf("%s", "please try again later")
+	}
+	return nil
+}
+
+// authorUUID keeps activities logged without a signed in user authorless
+func authorUUID(uuid string) *string {
+	if uuid == "" {
+		return nil
+	}
+	return &uuid
+}
+
+func NewClientActivityRepository(db *gorm.DB) ClientActivityRepository {
+	return &clientActivityRepositoryImpl{db: db}
+}
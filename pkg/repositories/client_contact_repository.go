@@ -0,0 +1,232 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/models"
+)
+
+type ClientContactRepository interface {
+	GetByClient(clientUUID string) ([]*dtos.ClientContactResponse, error)
+	Create(request dtos.ClientContactRequest) (*dtos.ClientContactResponse, error)
+	Update(request dtos.ClientContactRequest) (*dtos.ClientContactResponse, error)
+	Delete(clientUUID string, uuid string) error
+}
+
+type clientContactRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// GetByClient implements ClientContactRepository.
+func (r *clientContactRepositoryImpl) GetByClient(clientUUID string) ([]*dtos.ClientContactResponse, error) {
+	if err := findContactClient(r.db, clientUUID); err != nil {
+		return nil, err
+	}
+
+	var contacts []models.ClientContact
+	if err := r.db.Where("client_uuid = ?", clientUUID).Scopes(orderClientContacts).Find(&contacts).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch contacts: %w", err)
+	}
+
+	responses := make([]*dtos.ClientContactResponse, len(contacts))
+	for i, contact := range contacts {
+		responses[i] = toClientContactResponse(contact)
+	}
+	return responses, nil
+}
+
+// Create implements ClientContactRepository.
+// The first contact of a client always becomes its primary contact.
+func (r *clientContactRepositoryImpl) Create(request dtos.ClientContactRequest) (*dtos.ClientContactResponse, error) {
+	contact := models.ClientContact{ClientUUID: request.ClientUUID}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := findContactClient(tx, request.ClientUUID); err != nil {
+			return err
+		}
+
+		var primaries int64
+		if err := tx.Model(&models.ClientContact{}).Where("client_uuid = ? AND is_primary", request.ClientUUID).Count(&primaries).Error; err != nil {
+			return fmt.Errorf("%s", "please try again later")
+		}
+		if request.IsPrimary || primaries == 0 {
+			if err := clearPrimaryContact(tx, request.ClientUUID); err != nil {
+				return err
+			}
+			request.IsPrimary = true
+		}
+
+		applyClientContactRequest(&contact, request)
+		if err := tx.Create(&contact).Error; err != nil {
+			return fmt.Errorf("%s", "please try again later")
+		}
+		return syncContactPerson(tx, request.ClientUUID, request.Actor)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toClientContactResponse(contact), nil
+}
+
+// Update implements ClientContactRepository.
+func (r *clientContactRepositoryImpl) Update(request dtos.ClientContactRequest) (*dtos.ClientContactResponse, error) {
+	var contact models.ClientContact
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("uuid = ? AND client_uuid = ?", request.UUID, request.ClientUUID).First(&contact).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%s", "contact not found")
+			}
+			return fmt.Errorf("%s", "please try again later")
+		}
+
+		if contact.IsPrimary && !request.IsPrimary {
+			return fmt.Errorf("%s", "a client needs a primary contact, make another contact primary instead")
+		}
+		if request.IsPrimary && !contact.IsPrimary {
+			if err := clearPrimaryContact(tx, request.ClientUUID); err != nil {
+				return err
+			}
+		}
+
+		applyClientContactRequest(&contact, request)
+		result := tx.Model(&contact).
+			Select("name", "role", "email", "phone_number", "preferred_channel", "is_primary").
+			Updates(&contact)
+		if result.Error != nil {
+			return fmt.Errorf("%s", "please try again later")
+		}
+		return syncContactPerson(tx, request.ClientUUID, request.Actor)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toClientContactResponse(contact), nil
+}
+
+// Delete implements ClientContactRepository.
+func (r *clientContactRepositoryImpl) Delete(clientUUID string, uuid string) error {
+	var contact models.ClientContact
+	if err := r.db.Where("uuid = ? AND client_uuid = ?", uuid, clientUUID).First(&contact).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%s", "contact not found")
+		}
+		return fmt.Errorf("%s", "please try again later")
+	}
+	if contact.IsPrimary {
+		return fmt.Errorf("%s", "the primary contact cannot be deleted, make another contact primary first")
+	}
+
+	if err := r.db.Delete(&contact).Error; err != nil {
+		return fmt.Errorf("%s", "please try again later")
+	}
+	return nil
+}
+
+// findContactClient checks the client a contact belongs to exists
+func findContactClient(tx *gorm.DB, clientUUID string) error {
+	var client models.Client
+	if err := tx.Select("uuid").Where("uuid = ?", clientUUID).First(&client).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%s", "client not found")
+		}
+		return fmt.Errorf("%s", "please try again later")
+	}
+	return nil
+}
+
+func clearPrimaryContact(tx *gorm.DB, clientUUID string) error {
+	err := tx.Model(&models.ClientContact{}).
+		Where("client_uuid = ? AND is_primary", clientUUID).
+		Update("is_primary", false).Error
+	if err != nil {
+		return fmt.Errorf("%s", "please try again later")
+	}
+	return nil
+}
+
+// syncContactPerson copies the name of the primary contact to clients.contact_person, which
+// list, search and export read. A rename is a new version of the client and is recorded in its
+// history.
+func syncContactPerson(tx *gorm.DB, clientUUID string, actor dtos.ChangeActor) error {
+	var primary models.ClientContact
+	if err := tx.Where("client_uuid = ? AND is_primary", clientUUID).First(&primary).Error; err != nil {
+		return fmt.Errorf("%s", "please try again later")
+	}
+
+	var client models.Client
+	if err := tx.Select("uuid", "contact_person").Where("uuid = ?", clientUUID).First(&client).Error; err != nil {
+		return fmt.Errorf("%s", "please try again later")
+	}
+	if client.ContactPerson == primary.Name {
+		return nil
+	}
+
+	err := tx.Model(&client).UpdateColumns(map[string]interface{}{
+		"contact_person": primary.Name,
+		"updated_at":     tx.NowFunc(),
+	}).Error
+	if err != nil {
+		return fmt.Errorf("%s", "please try again later")
+	}
+
+	changes := map[string]dtos.FieldChange{"contact_person": {From: client.ContactPerson, To: primary.Name}}
+	return recordChange(tx, models.ChangeLogEntityClient, clientUUID, models.ChangeLogActionUpdate, actor, changes)
+}
+
+// renamePrimaryContact keeps the primary contact in step with a contact_person written through
+// the client endpoints, creating it for a client that has none yet
+func renamePrimaryContact(tx *gorm.DB, clientUUID string, name string) error {
+	result := tx.Model(&models.ClientContact{}).
+		Where("client_uuid = ? AND is_primary", clientUUID).
+		Update("name", name)
+	if result.Error != nil {
+		return fmt.Errorf("%s", "please try again later")
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	contact := models.ClientContact{ClientUUID: clientUUID, Name: name, Role: "general", IsPrimary: true}
+	if err := tx.Create(&contact).Error; err != nil {
+		return fmt.Errorf("%s", "please try again later")
+	}
+	return nil
+}
+
+func applyClientContactRequest(contact *models.ClientContact, request dtos.ClientContactRequest) {
+	contact.Name = request.Name
+	contact.Role = request.Role
+	contact.Email = request.Email
+	contact.PhoneNumber = request.PhoneNumber
+	contact.PreferredChannel = request.PreferredChannel
+	contact.IsPrimary = request.IsPrimary
+}
+
+// orderClientContacts lists the primary contact first, then the others by name
+func orderClientContacts(db *gorm.DB) *gorm.DB {
+	return db.Order("is_primary desc, name asc")
+}
+
+func toClientContactResponse(contact models.ClientContact) *dtos.ClientContactResponse {
+	return &dtos.ClientContactResponse{
+		UUID:             contact.UUID,
+		ClientUUID:       contact.ClientUUID,
+		Name:             contact.Name,
+		Role:             contact.Role,
+		Email:            contact.Email,
+		PhoneNumber:      contact.PhoneNumber,
+		PreferredChannel: contact.PreferredChannel,
+		IsPrimary:        contact.IsPrimary,
+		CreatedAt:        contact.CreatedAt,
+		UpdatedAt:        contact.UpdatedAt,
+	}
+}
+
+func NewClientContactRepository(db *gorm.DB) ClientContactRepository {
+	return &clientContactRepositoryImpl{db: db}
+}
@@ -0,0 +1,228 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/models"
+)
+
+type ClientDocumentRepository interface {
+	Create(document *models.ClientDocument, actor dtos.ChangeActor) error
+	GetByClient(clientUUID string) ([]models.ClientDocument, error)
+	FindByUUID(clientUUID string, uuid string) (models.ClientDocument, error)
+	Review(request dtos.ClientDocumentReviewRequest) (models.ClientDocument, error)
+	RefreshExpired(today time.Time) (int64, error)
+}
+
+type clientDocumentRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// Create implements ClientDocumentRepository.
+func (r *clientDocumentRepositoryImpl) Create(document *models.ClientDocument, actor dtos.ChangeActor) error {
+	if err := r.findClient(r.db, document.ClientUUID); err != nil {
+		return err
+	}
+
+	document.UploadedBy = authorUUID(actor.UserUUID)
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(document).Error; err != nil {
+			return fmt.Errorf("%s", "please try again later")
+		}
+		_, err := refreshKycStatus(tx, document.ClientUUID, actor)
+		return err
+	})
+}
+
+// GetByClient implements ClientDocumentRepository.
+// Documents are listed newest first, superseded uploads included.
+func (r *clientDocumentRepositoryImpl) GetByClient(clientUUID string) ([]models.ClientDocument, error) {
+	if err := r.findClient(r.db, clientUUID); err != nil {
+		return nil, err
+	}
+
+	documents := []models.ClientDocument{}
+	if err := r.db.Where("client_uuid = ?", clientUUID).Order("created_at desc").Find(&documents).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch documents: %w", err)
+	}
+	return documents, nil
+}
+
+// FindByUUID implements ClientDocumentRepository.
+func (r *clientDocumentRepositoryImpl) FindByUUID(clientUUID string, uuid string) (models.ClientDocument, error) {
+	var document models.ClientDocument
+	if err := r.db.Where("uuid = ? AND client_uuid = ?", uuid, clientUUID).First(&document).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return document, fmt.Errorf("%s", "document not found")
+		}
+		return document, fmt.Errorf("%s", "please try again later")
+	}
+	return document, nil
+}
+
+// Review implements ClientDocumentRepository.
+func (r *clientDocumentRepositoryImpl) Review(request dtos.ClientDocumentReviewRequest) (models.ClientDocument, error) {
+	var document models.ClientDocument
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("uuid = ? AND client_uuid = ?", request.DocumentUUID, request.ClientUUID).First(&document).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%s", "document not found")
+			}
+			return fmt.Errorf("%s", "please try again later")
+		}
+		if document.Status != models.ClientDocumentStatusPending {
+			return fmt.Errorf("document has already been %s", document.Status)
+		}
+
+		reviewedAt := tx.NowFunc()
+		document.Status = request.Status
+		document.RejectionReason = ""
+		if request.Status == models.ClientDocumentStatusRejected {
+			document.RejectionReason = request.Reason
+		}
+		document.ReviewedBy = authorUUID(request.Actor.UserUUID)
+		document.ReviewedAt = &reviewedAt
+
+		result := tx.Model(&document).
+			Where("status = ?", models.ClientDocumentStatusPending).
+			Select("status", "rejection_reason", "reviewed_by", "reviewed_at").
+			Updates(&document)
+		if result.Error != nil {
+			return fmt.Errorf("%s", "please try again later")
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%s", "document has already been reviewed")
+		}
+
+		_, err = refreshKycStatus(tx, document.ClientUUID, request.Actor)
+		return err
+	})
+	return document, err
+}
+
+// RefreshExpired implements ClientDocumentRepository.
+// A verified client whose document expired before today is re-derived, and the number of
+// clients whose status changed is returned.
+func (r *clientDocumentRepositoryImpl) RefreshExpired(today time.Time) (int64, error) {
+	var clientUUIDs []string
+	err := r.db.Model(&models.ClientDocument{}).
+		Distinct("client_documents.client_uuid").
+		Joins("JOIN clients ON clients.uuid = client_documents.client_uuid AND clients.deleted_at IS NULL").
+		Where("clients.kyc_status = ?", models.KycStatusVerified).
+		Where("client_documents.status = ? AND client_documents.expires_at < ?", models.ClientDocumentStatusVerified, today).
+		Pluck("client_documents.client_uuid", &clientUUIDs).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to find expired documents: %w", err)
+	}
+
+	var changed int64
+	for _, clientUUID := range clientUUIDs {
+		var updated bool
+		err := r.db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			updated, err = refreshKycStatus(tx, clientUUID, dtos.ChangeActor{})
+			return err
+		})
+		if err != nil {
+			return changed, fmt.Errorf("failed to refresh kyc status of %s: %w", clientUUID, err)
+		}
+		if updated {
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+func (r *clientDocumentRepositoryImpl) findClient(tx *gorm.DB, uuid string) error {
+	var client models.Client
+	if err := tx.Select("uuid").Where("uuid = ?", uuid).First(&client).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%s", "client not found")
+		}
+		return fmt.Errorf("%s", "please try again later")
+	}
+	return nil
+}
+
+// refreshKycStatus derives the KYC status of a client from its documents and stores it. It
+// reports whether the status changed, a change is recorded in the client history.
+func refreshKycStatus(tx *gorm.DB, clientUUID string, actor dtos.ChangeActor) (bool, error) {
+	var client models.Client
+	if err := tx.Select("uuid", "kyc_status").Where("uuid = ?", clientUUID).First(&client).Error; err != nil {
+		return false, fmt.Errorf("%s", "please try again later")
+	}
+
+	var documents []models.ClientDocument
+	if err := tx.Where("client_uuid = ?", clientUUID).Order("created_at desc").Find(&documents).Error; err != nil {
+		return false, fmt.Errorf("%s", "please try again later")
+	}
+
+	status := deriveKycStatus(documents, tx.NowFunc())
+	if status == client.KycStatus {
+		return false, nil
+	}
+	if err := tx.Model(&client).UpdateColumn("kyc_status", status).Error; err != nil {
+		return false, fmt.Errorf("%s", "please try again later")
+	}
+
+	changes := map[string]dtos.FieldChange{"kyc_status": {From: client.KycStatus, To: status}}
+	return true, recordChange(tx, models.ChangeLogEntityClient, clientUUID, models.ChangeLogActionUpdate, actor, changes)
+}
+
+// deriveKycStatus works out the KYC status from documents ordered newest first. Only the newest
+// document of each type counts. A client is verified with a valid KTP and NPWP, or with a valid
+// passport for foreigners, where valid means verified and not expired on now.
+func deriveKycStatus(documents []models.ClientDocument, now time.Time) string {
+	if len(documents) == 0 {
+		return models.KycStatusNone
+	}
+
+	current := make(map[string]models.ClientDocument)
+	for _, document := range documents {
+		if _, ok := current[document.Type]; !ok {
+			current[document.Type] = document
+		}
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	valid := func(documentType string) bool {
+		document, ok := current[documentType]
+		return ok && document.Status == models.ClientDocumentStatusVerified &&
+			(document.ExpiresAt == nil || !document.ExpiresAt.Before(today))
+	}
+	if (valid(models.ClientDocumentTypeKTP) && valid(models.ClientDocumentTypeNPWP)) || valid(models.ClientDocumentTypePassport) {
+		return models.KycStatusVerified
+	}
+
+	var pending, rejected, expired bool
+	for documentType, document := range current {
+		switch {
+		case document.Status == models.ClientDocumentStatusPending:
+			pending = true
+		case document.Status == models.ClientDocumentStatusRejected:
+			rejected = true
+		case !valid(documentType):
+			expired = true
+		}
+	}
+
+	switch {
+	case pending:
+		return models.KycStatusPending
+	case rejected:
+		return models.KycStatusRejected
+	case expired:
+		return models.KycStatusExpired
+	}
+	return models.KycStatusIncomplete
+}
+
+func NewClientDocumentRepository(db *gorm.DB) ClientDocumentRepository {
+	return &clientDocumentRepositoryImpl{db: db}
+}
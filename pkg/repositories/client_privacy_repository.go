@@ -0,0 +1,289 @@
+package repositories
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/models"
+)
+
+// erasedClientName replaces the name of an erased client
+const erasedClientName = "Erased client"
+
+// clientPersonalFields are the fields of the client history that hold personal data
+var clientPersonalFields = []string{"name", "email", "phone_number", "address", "contact_person"}
+
+// ClientRecords is everything stored about a client, deleted rows included. Documents and
+// deposits are left as models, the service renders them.
+type ClientRecords struct {
+	Client    *dtos.ClientResponse
+	Timeline  []*dtos.ClientActivityResponse
+	History   []*dtos.ChangeLogResponse
+	Documents []models.ClientDocument
+	Deposits  []models.Deposit
+}
+
+type ClientPrivacyRepository interface {
+	GetRecords(uuid string) (*ClientRecords, error)
+	Erase(uuid string, actor dtos.ChangeActor) (*dtos.ClientErasureResponse, []string, error)
+}
+
+type clientPrivacyRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// GetRecords implements ClientPrivacyRepository.
+func (r *clientPrivacyRepositoryImpl) GetRecords(uuid string) (*ClientRecords, error) {
+	var client models.Client
+	err := r.db.Unscoped().
+		Preload("Tags", orderClientTags).
+		Preload("Contacts", orderClientContacts).
+		Where("uuid = ?", uuid).
+		First(&client).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%s", "client not found")
+		}
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+	records := &ClientRecords{Client: toClientResponse(client)}
+
+	var activities []models.ClientActivity
+	if err := r.db.Unscoped().Where("client_uuid = ?", uuid).Order("occurred_at desc").Find(&activities).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch timeline: %w", err)
+	}
+	records.Timeline = make([]*dtos.ClientActivityResponse, len(activities))
+	for i, activity := range activities {
+		records.Timeline[i] = toClientActivityResponse(activity)
+	}
+
+	var entries []models.ChangeLog
+	err = r.db.Where("entity_type = ? AND entity_uuid = ?", models.ChangeLogEntityClient, uuid).
+		Order("created_at desc, uuid desc").
+		Find(&entries).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch history: %w", err)
+	}
+	records.History = make([]*dtos.ChangeLogResponse, len(entries))
+	for i, entry := range entries {
+		if records.History[i], err = toChangeLogResponse(entry); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := r.db.Unscoped().Where("client_uuid = ?", uuid).Order("created_at desc").Find(&records.Documents).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch documents: %w", err)
+	}
+	err = r.db.Unscoped().Preload("Deductions.Photos").
+		Where("client_uuid = ?", uuid).
+		Order("received_at desc").
+		Find(&records.Deposits).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch deposits: %w", err)
+	}
+
+	return records, nil
+}
+
+// Erase implements ClientPrivacyRepository.
+// The client row is anonymized and moved to the trash, its contacts, tags, documents and
+// timeline are removed and the personal values in its history are blanked. Clients merged into
+// it are erased the same way, as their deleted rows still hold what they were merged with.
+// Deposits are financial records and stay, pointing at the anonymized client, but lose their
+// notes and deduction photos. The paths of the document scans and photos are returned so the
+// files can be removed once the erasure committed.
+func (r *clientPrivacyRepositoryImpl) Erase(uuid string, actor dtos.ChangeActor) (*dtos.ClientErasureResponse, []string, error) {
+	var client models.Client
+	if err := r.db.Unscoped().Where("uuid = ?", uuid).First(&client).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("%s", "client not found")
+		}
+		return nil, nil, fmt.Errorf("%s", "please try again later")
+	}
+	if client.ErasedAt != nil {
+		return nil, nil, fmt.Errorf("%s", "client has already been erased")
+	}
+
+	response := &dtos.ClientErasureResponse{UUID: client.UUID}
+	var files []string
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		response.ErasedAt = tx.NowFunc()
+
+		sources, err := mergedSources(tx, uuid)
+		if err != nil {
+			return err
+		}
+		var merged []models.Client
+		if err := tx.Unscoped().Where("uuid IN ? AND erased_at IS NULL", sources).Find(&merged).Error; err != nil {
+			return fmt.Errorf("%s", "please try again later")
+		}
+		response.MergedClients = int64(len(merged))
+
+		erased := append([]string{uuid}, sources...)
+		for _, target := range append([]models.Client{client}, merged...) {
+			if err := anonymizeClient(tx, target, response.ErasedAt); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Unscoped().Model(&models.ClientDocument{}).Where("client_uuid IN ?", erased).Pluck("file_path", &files).Error; err != nil {
+			return fmt.Errorf("%s", "please try again later")
+		}
+		for _, owned := range []interface{}{&models.ClientDocument{}, &models.ClientContact{}, &models.ClientActivity{}, &models.ClientTag{}} {
+			if err := tx.Unscoped().Where("client_uuid IN ?", erased).Delete(owned).Error; err != nil {
+				return fmt.Errorf("%s", "please try again later")
+			}
+		}
+
+		photos, err := eraseDepositDetails(tx, erased)
+		if err != nil {
+			return err
+		}
+		files = append(files, photos...)
+
+		for _, target := range erased {
+			if err := eraseClientHistory(tx, target); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Unscoped().Model(&models.Deposit{}).Where("client_uuid IN ?", erased).Count(&response.KeptDeposits).Error; err != nil {
+			return fmt.Errorf("%s", "please try again later")
+		}
+
+		changes := map[string]dtos.FieldChange{"erased_at": {To: response.ErasedAt}}
+		for _, target := range merged {
+			if err := recordChange(tx, models.ChangeLogEntityClient, target.UUID, models.ChangeLogActionErase, actor, changes); err != nil {
+				return err
+			}
+		}
+		return recordChange(tx, models.ChangeLogEntityClient, uuid, models.ChangeLogActionErase, actor, changes)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return response, files, nil
+}
+
+// anonymizeClient replaces the personal values of a client row and moves it to the trash.
+// Email and phone number have unique indexes, so they are replaced by values derived from the
+// uuid rather than emptied.
+func anonymizeClient(tx *gorm.DB, client models.Client, erasedAt time.Time) error {
+	id := strings.ReplaceAll(client.UUID, "-", "")
+	err := tx.Unscoped().Model(&client).UpdateColumns(map[string]interface{}{
+		"name":           erasedClientName,
+		"email":          fmt.Sprintf("erased-%s@erased.invalid", id),
+		"phone_number":   "x" + id[:14],
+		"address":        "",
+		"contact_person": "",
+		"kyc_status":     models.KycStatusNone,
+		"erased_at":      erasedAt,
+		"updated_at":     erasedAt,
+		"deleted_at":     gorm.Expr("COALESCE(deleted_at, ?)", erasedAt),
+	}).Error
+	if err != nil {
+		return fmt.Errorf("%s", "please try again later")
+	}
+	return nil
+}
+
+// mergedSources returns the clients merged into uuid, directly or through a client that was
+// merged into it in turn. Merges are found through the merged_into entry of their history.
+func mergedSources(tx *gorm.DB, uuid string) ([]string, error) {
+	seen := map[string]bool{uuid: true}
+	var sources []string
+	for pending := []string{uuid}; len(pending) > 0; {
+		var found []string
+		err := tx.Model(&models.ChangeLog{}).
+			Where("entity_type = ? AND action = ? AND changes->'merged_into'->>'to' IN ?",
+				models.ChangeLogEntityClient, models.ChangeLogActionMerge, pending).
+			Distinct().
+			Pluck("entity_uuid", &found).Error
+		if err != nil {
+			return nil, fmt.Errorf("%s", "please try again later")
+		}
+
+		pending = nil
+		for _, source := range found {
+			if !seen[source] {
+				seen[source] = true
+				sources = append(sources, source)
+				pending = append(pending, source)
+			}
+		}
+	}
+	return sources, nil
+}
+
+// eraseDepositDetails blanks the free-text notes of the deposits of the clients and removes the
+// photos of their deductions. Amounts and refunds stay as financial records. The paths of the
+// photos are returned so the files can be removed.
+func eraseDepositDetails(tx *gorm.DB, clients []string) ([]string, error) {
+	deposits := tx.Session(&gorm.Session{NewDB: true}).Unscoped().Model(&models.Deposit{}).
+		Select("uuid").Where("client_uuid IN ?", clients)
+	deductions := tx.Session(&gorm.Session{NewDB: true}).Unscoped().Model(&models.DepositDeduction{}).
+		Select("uuid").Where("deposit_uuid IN (?)", deposits)
+
+	var photos []string
+	err := tx.Unscoped().Model(&models.DepositDeductionPhoto{}).
+		Where("deduction_uuid IN (?)", deductions).
+		Pluck("photo_url", &photos).Error
+	if err != nil {
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+	if err := tx.Unscoped().Where("deduction_uuid IN (?)", deductions).Delete(&models.DepositDeductionPhoto{}).Error; err != nil {
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+
+	if err := tx.Unscoped().Model(&models.Deposit{}).Where("client_uuid IN ?", clients).UpdateColumn("notes", "").Error; err != nil {
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+	return photos, nil
+}
+
+// eraseClientHistory blanks the personal values in the history of a client. The entries stay,
+// so it remains visible when and by whom the client was changed.
+func eraseClientHistory(tx *gorm.DB, uuid string) error {
+	var entries []models.ChangeLog
+	if err := tx.Where("entity_type = ? AND entity_uuid = ?", models.ChangeLogEntityClient, uuid).Find(&entries).Error; err != nil {
+		return fmt.Errorf("%s", "please try again later")
+	}
+
+	for _, entry := range entries {
+		changes := map[string]dtos.FieldChange{}
+		if err := json.Unmarshal([]byte(entry.Changes), &changes); err != nil {
+			return fmt.Errorf("failed to read history: %w", err)
+		}
+
+		erased := false
+		for _, field := range clientPersonalFields {
+			if change, ok := changes[field]; ok && (change.From != nil || change.To != nil) {
+				changes[field] = dtos.FieldChange{}
+				erased = true
+			}
+		}
+		if !erased {
+			continue
+		}
+
+		raw, err := json.Marshal(changes)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&entry).UpdateColumn("changes", string(raw)).Error; err != nil {
+			return fmt.Errorf("%s", "please try again later")
+		}
+	}
+	return nil
+}
+
+func NewClientPrivacyRepository(db *gorm.DB) ClientPrivacyRepository {
+	return &clientPrivacyRepositoryImpl{db: db}
+}
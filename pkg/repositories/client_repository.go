@@ -4,19 +4,50 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"slices"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/helpers"
 	"alfredo/ruu-properties/pkg/models"
 )
 
 type ClientRepository interface {
 	Create(request dtos.ClientRequest) (*dtos.ClientResponse, error)
 	GetAll(request dtos.ClientGetRequest) ([]*dtos.ClientResponse, *dtos.PaginationMeta, error)
-	Delete(uuid string) error
+	GetAllByCursor(request dtos.ClientGetRequest) ([]*dtos.ClientResponse, *dtos.CursorMeta, error)
+	Delete(uuid string, ifMatch string, actor dtos.ChangeActor) error
 	GetByID(uuid string) (*dtos.ClientResponse, error)
 	Update(request dtos.ClientUpdateRequest) (*dtos.ClientResponse, error)
+	Patch(request dtos.ClientPatchRequest) (*dtos.ClientResponse, error)
+	Export(request dtos.ClientGetRequest, fn func(client *dtos.ClientResponse) error) error
+	GetDuplicateCandidates(visibility *dtos.ClientVisibility) ([]*dtos.ClientResponse, error)
+	Merge(request dtos.ClientMergeRequest) (*dtos.ClientResponse, error)
+	SetTags(request dtos.ClientTagRequest) (*dtos.ClientResponse, error)
+	GetTags(visibility *dtos.ClientVisibility) ([]*dtos.ClientTagCountResponse, error)
+	GetTrash(request dtos.TrashGetRequest) ([]*dtos.ClientResponse, *dtos.PaginationMeta, error)
+	Restore(uuid string, actor dtos.ChangeActor) (*dtos.ClientResponse, error)
+	CheckVisible(uuid string, visibility dtos.ClientVisibility) error
+	SetOwner(request dtos.ClientOwnerRequest) (*dtos.ClientResponse, error)
+	Reassign(request dtos.ClientReassignRequest) (int, error)
+}
+
+// clientReferences lists every column pointing at a client. Merging moves these rows onto the
+// surviving client, so new client-owned tables have to be added here.
+var clientReferences = []struct {
+	table  string
+	column string
+}{
+	{table: "deposits", column: "client_uuid"},
+	{table: "properties", column: "owner_client_uuid"},
+	{table: "client_tags", column: "client_uuid"},
+	{table: "client_activities", column: "client_uuid"},
+	{table: "client_documents", column: "client_uuid"},
+	{table: "client_contacts", column: "client_uuid"},
 }
 
 type clientRepositoryImpl struct {
@@ -24,14 +55,12 @@ type clientRepositoryImpl struct {
 }
 
 func (r *clientRepositoryImpl) Update(request dtos.ClientUpdateRequest) (*dtos.ClientResponse, error) {
-	var client models.Client
-	if err := r.db.Where("uuid = ?", request.UUID).First(&client).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return &dtos.ClientResponse{}, fmt.Errorf("%s", "client not found")
-		} else {
-			return &dtos.ClientResponse{}, fmt.Errorf("%s", err.Error())
-		}
+	client, err := r.findForUpdate(request.UUID, request.IfMatch)
+	if err != nil {
+		return &dtos.ClientResponse{}, err
 	}
+	before := clientChangeFields(*client)
+	version := client.UpdatedAt
 
 	if request.Name != "" {
 		client.Name = request.Name
@@ -49,23 +78,81 @@ func (r *clientRepositoryImpl) Update(request dtos.ClientUpdateRequest) (*dtos.C
 		client.ContactPerson = request.ContactPerson
 	}
 
-	if err := r.db.Save(&client).Error; err != nil {
-		return &dtos.ClientResponse{}, fmt.Errorf("%s", "please try again later")
+	return r.saveClient(client, before, version, request.IfMatch != "", request.Actor)
+}
+
+// Patch implements ClientRepository.
+// Unlike Update every editable column is written, the request already holds the patched client.
+func (r *clientRepositoryImpl) Patch(request dtos.ClientPatchRequest) (*dtos.ClientResponse, error) {
+	client, err := r.findForUpdate(request.UUID, request.IfMatch)
+	if err != nil {
+		return nil, err
 	}
+	before := clientChangeFields(*client)
+	version := client.UpdatedAt
 
-	return &dtos.ClientResponse{
-		UUID:          client.UUID,
-		Name:          client.Name,
-		Email:         client.Email,
-		PhoneNumber:   client.PhoneNumber,
-		Address:       client.Address,
-		ContactPerson: client.ContactPerson,
-		CreatedAt:     client.CreatedAt,
-		UpdatedAt:     client.UpdatedAt,
-	}, nil
+	client.Name = request.Name
+	client.Email = request.Email
+	client.PhoneNumber = request.PhoneNumber
+	client.Address = request.Address
+	client.ContactPerson = request.ContactPerson
+
+	return r.saveClient(client, before, version, request.IfMatch != "", request.Actor)
 }
 
-func (r *clientRepositoryImpl) Delete(uuid string) error {
+// findForUpdate loads a client that is about to change and checks it is still the version
+// named by ifMatch, when one is given
+func (r *clientRepositoryImpl) findForUpdate(uuid string, ifMatch string) (*models.Client, error) {
+	var client models.Client
+	if err := r.db.Preload("Tags", orderClientTags).Where("uuid = ?", uuid).First(&client).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%s", "client not found")
+		}
+		return nil, fmt.Errorf("%s", err.Error())
+	}
+
+	if ifMatch != "" && !helpers.MatchesETag(ifMatch, helpers.ETag(client.UpdatedAt)) {
+		return nil, fmt.Errorf("%s", "client has been modified")
+	}
+	return &client, nil
+}
+
+// saveClient writes the editable columns of a client and records how they differ from before.
+// A conditional write only applies while the row is still at version, so an edit saved by
+// someone else in between is not overwritten.
+func (r *clientRepositoryImpl) saveClient(client *models.Client, before map[string]interface{}, version time.Time, conditional bool, actor dtos.ChangeActor) (*dtos.ClientResponse, error) {
+	client.UpdatedAt = r.db.NowFunc()
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(client)
+		if conditional {
+			query = query.Where("updated_at = ?", version)
+		}
+		result := query.Select("name", "email", "phone_number", "address", "contact_person", "updated_at").Updates(client)
+		if result.Error != nil {
+			return fmt.Errorf("%s", "please try again later")
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%s", "client has been modified")
+		}
+
+		if client.ContactPerson != before["contact_person"] {
+			if err := renamePrimaryContact(tx, client.UUID, client.ContactPerson); err != nil {
+				return err
+			}
+		}
+
+		changes := diffFields(before, clientChangeFields(*client))
+		return recordChange(tx, models.ChangeLogEntityClient, client.UUID, models.ChangeLogActionUpdate, actor, changes)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toClientResponse(*client), nil
+}
+
+func (r *clientRepositoryImpl) Delete(uuid string, ifMatch string, actor dtos.ChangeActor) error {
 	var client models.Client
 	if err := r.db.Where("uuid = ?", uuid).First(&client).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -74,17 +161,40 @@ func (r *clientRepositoryImpl) Delete(uuid string) error {
 			return fmt.Errorf("%s", "please try again later")
 		}
 	}
-	if err := r.db.Delete(&client).Error; err != nil {
-		return fmt.Errorf("%s", "please try again later")
+
+	if ifMatch != "" && !helpers.MatchesETag(ifMatch, helpers.ETag(client.UpdatedAt)) {
+		return fmt.Errorf("%s", "client has been modified")
 	}
-	return nil
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		query := tx
+		if ifMatch != "" {
+			query = query.Where("updated_at = ?", client.UpdatedAt)
+		}
+
+		result := query.Delete(&client)
+		if result.Error != nil {
+			return fmt.Errorf("%s", "please try again later")
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%s", "client has been modified")
+		}
+
+		changes := diffFields(clientChangeFields(client), nil)
+		return recordChange(tx, models.ChangeLogEntityClient, client.UUID, models.ChangeLogActionDelete, actor, changes)
+	})
 }
 
 // GetByID implements ClientRepository.
 func (r *clientRepositoryImpl) GetByID(uuid string) (*dtos.ClientResponse, error) {
 	var client models.Client
 
-	if err := r.db.Model(&models.Client{}).Where("uuid = ? ", uuid).First(&client).Error; err != nil {
+	err := r.db.Model(&models.Client{}).
+		Preload("Tags", orderClientTags).
+		Preload("Contacts", orderClientContacts).
+		Where("uuid = ? ", uuid).
+		First(&client).Error
+	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return &dtos.ClientResponse{}, fmt.Errorf("%s", "client not found")
 		} else {
@@ -92,68 +202,20 @@ func (r *clientRepositoryImpl) GetByID(uuid string) (*dtos.ClientResponse, error
 		}
 	}
 
-	return &dtos.ClientResponse{
-		UUID:          client.UUID,
-		Name:          client.Name,
-		Email:         client.Email,
-		PhoneNumber:   client.PhoneNumber,
-		Address:       client.Address,
-		ContactPerson: client.ContactPerson,
-		CreatedAt:     client.CreatedAt,
-		UpdatedAt:     client.UpdatedAt,
-	}, nil
+	return toClientResponse(client), nil
 }
 
 // GetAll implements ClientRepository.
 func (r *clientRepositoryImpl) GetAll(request dtos.ClientGetRequest) ([]*dtos.ClientResponse, *dtos.PaginationMeta, error) {
-	if request.SortBy == "" {
-		request.SortBy = "created_at"
-	}
-	if request.SortOrder == "" {
-		request.SortOrder = "desc"
-	}
-
-	// Validate sort from frontend if the sort is not asc or desc
-	if request.SortOrder != "asc" && request.SortOrder != "desc" {
-		request.SortOrder = "desc"
-	}
-
-	// Validate sort field (turn on or disabled)
-	allowedSortFields := map[string]bool{
-		"name":       true,
-		"email":      true,
-		"created_at": true,
-		"updated_at": true,
-	}
-	if !allowedSortFields[request.SortBy] {
-		request.SortBy = "created_at"
-	}
+	request = normalizeClientSort(request)
 
 	var clients []models.Client
 	var total int64
 
 	query := r.db.Model(&models.Client{})
 
-	// Apply search filter if not null
-	if request.Search != "" {
-		searchPattern := "%" + request.Search + "%"
-		switch request.SearchBy {
-		case "name":
-			query = query.Where("name ILIKE ?", searchPattern)
-		case "email":
-			query = query.Where("email ILIKE ?", searchPattern)
-		case "phone_number":
-			query = query.Where("phone_number ILIKE ?", searchPattern)
-		case "contact_person":
-			query = query.Where("contact_person ILIKE ?", searchPattern)
-		default:
-			// Global search across multiple fields
-			query = query.Where(
-				"name ILIKE ? OR email ILIKE ? OR phone_number ILIKE ? OR contact_person ILIKE ? OR address ILIKE ?",
-				searchPattern, searchPattern, searchPattern, searchPattern, searchPattern,
-			)
-		}
-	}
+	query = applyClientSearch(query, request)
+	query = applyClientFilter(query, request)
 
 	// Count total records
 	err := query.Count(&total).Error
@@ -166,7 +228,7 @@ func (r *clientRepositoryImpl) GetAll(request dtos.ClientGetRequest) ([]*dtos.Cl
 
 	// Apply pagination and sorting
 	sortClause := fmt.Sprintf("%s %s", request.SortBy, request.SortOrder)
-	err = query.Order(sortClause).Offset(offset).Limit(request.Limit).Find(&clients).Error
+	err = query.Order(sortClause).Offset(offset).Limit(request.Limit).Preload("Tags", orderClientTags).Find(&clients).Error
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to fetch clients: %w", err)
 	}
@@ -174,16 +236,7 @@ func (r *clientRepositoryImpl) GetAll(request dtos.ClientGetRequest) ([]*dtos.Cl
 	// Convert to response DTOs
 	clientResponses := make([]*dtos.ClientResponse, len(clients))
 	for i, client := range clients {
-		clientResponses[i] = &dtos.ClientResponse{
-			UUID:          client.UUID,
-			Name:          client.Name,
-			Email:         client.Email,
-			PhoneNumber:   client.PhoneNumber,
-			Address:       client.Address,
-			ContactPerson: client.ContactPerson,
-			CreatedAt:     client.CreatedAt,
-			UpdatedAt:     client.UpdatedAt,
-		}
+		clientResponses[i] = toClientResponse(client)
 	}
 
 	// Calculate pagination metadata
@@ -199,6 +252,655 @@ func (r *clientRepositoryImpl) GetAll(request dtos.ClientGetRequest) ([]*dtos.Cl
 
 }
 
+// GetAllByCursor implements ClientRepository.
+// Rows are read in (sort column, uuid) order and a page starts right after the cursor, so deep
+// pages cost the same as the first one. Counting is skipped when with_total=false.
+func (r *clientRepositoryImpl) GetAllByCursor(request dtos.ClientGetRequest) ([]*dtos.ClientResponse, *dtos.CursorMeta, error) {
+	request = normalizeClientSort(request)
+
+	query := r.db.Model(&models.Client{})
+	query = applyClientSearch(query, request)
+	query = applyClientFilter(query, request)
+
+	cursorMeta := &dtos.CursorMeta{Limit: request.Limit}
+	if request.WithTotal == nil || *request.WithTotal {
+		var total int64
+		if err := query.Count(&total).Error; err != nil {
+			return nil, nil, fmt.Errorf("failed to count clients: %w", err)
+		}
+		count := int(total)
+		cursorMeta.Total = &count
+	}
+
+	if request.After != nil {
+		value, err := clientCursorValue(request.SortBy, request.After.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		comparator := ">"
+		if request.SortOrder == "desc" {
+			comparator = "<"
+		}
+		query = query.Where(fmt.Sprintf("(%s, uuid) %s (?, ?)", request.SortBy, comparator), value, request.After.UUID)
+	}
+
+	// One extra row tells whether there is a next page
+	var clients []models.Client
+	sortClause := fmt.Sprintf("%s %s, uuid %s", request.SortBy, request.SortOrder, request.SortOrder)
+	err := query.Order(sortClause).Limit(request.Limit+1).Preload("Tags", orderClientTags).Find(&clients).Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch clients: %w", err)
+	}
+
+	if len(clients) > request.Limit {
+		clients = clients[:request.Limit]
+		last := clients[len(clients)-1]
+		cursorMeta.HasMore = true
+		cursorMeta.NextCursor = helpers.EncodeCursor(dtos.Cursor{
+			SortBy:    request.SortBy,
+			SortOrder: request.SortOrder,
+			Value:     clientSortValue(last, request.SortBy),
+			UUID:      last.UUID,
+		})
+	}
+
+	clientResponses := make([]*dtos.ClientResponse, len(clients))
+	for i, client := range clients {
+		clientResponses[i] = toClientResponse(client)
+	}
+
+	return clientResponses, cursorMeta, nil
+}
+
+// Export implements ClientRepository.
+// Rows are read from a database cursor and handed to fn one at a time, so the whole result
+// set is never loaded into memory.
+func (r *clientRepositoryImpl) Export(request dtos.ClientGetRequest, fn func(client *dtos.ClientResponse) error) error {
+	request = normalizeClientSort(request)
+
+	query := applyClientSearch(r.db.Model(&models.Client{}), request)
+	query = applyClientFilter(query, request)
+
+	// uuid keeps the order stable for rows sharing the same sort value
+	sortClause := fmt.Sprintf("%s %s, uuid %s", request.SortBy, request.SortOrder, request.SortOrder)
+	rows, err := query.Order(sortClause).Rows()
+	if err != nil {
+		return fmt.Errorf("failed to fetch clients: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var client models.Client
+		if err := r.db.ScanRows(rows, &client); err != nil {
+			return fmt.Errorf("failed to read client: %w", err)
+		}
+
+		if err := fn(toClientResponse(client)); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// GetDuplicateCandidates implements ClientRepository.
+// Only the identifying columns are loaded, scoring the pairs is left to the service.
+func (r *clientRepositoryImpl) GetDuplicateCandidates(visibility *dtos.ClientVisibility) ([]*dtos.ClientResponse, error) {
+	var clients []models.Client
+	err := applyClientVisibility(r.db.Model(&models.Client{}), visibility).
+		Select("uuid", "name", "email", "phone_number", "created_at").
+		Order("created_at asc").
+		Find(&clients).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch clients: %w", err)
+	}
+
+	candidates := make([]*dtos.ClientResponse, len(clients))
+	for i, client := range clients {
+		candidates[i] = &dtos.ClientResponse{
+			UUID:        client.UUID,
+			Name:        client.Name,
+			Email:       client.Email,
+			PhoneNumber: client.PhoneNumber,
+			CreatedAt:   client.CreatedAt,
+		}
+	}
+
+	return candidates, nil
+}
+
+// Merge implements ClientRepository.
+func (r *clientRepositoryImpl) Merge(request dtos.ClientMergeRequest) (*dtos.ClientResponse, error) {
+	var survivor models.Client
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var duplicate models.Client
+		locks := []struct {
+			uuid     string
+			client   *models.Client
+			notFound string
+		}{
+			{uuid: request.SurvivorUUID, client: &survivor, notFound: "client not found"},
+			{uuid: request.DuplicateUUID, client: &duplicate, notFound: "duplicate client not found"},
+		}
+		// Both rows are locked in uuid order, so merges of the same pair in opposite directions
+		// wait for each other and the second one finds its duplicate already gone
+		if locks[1].uuid < locks[0].uuid {
+			locks[0], locks[1] = locks[1], locks[0]
+		}
+		for _, lock := range locks {
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("uuid = ?", lock.uuid).First(lock.client).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%s", lock.notFound)
+			}
+			if err != nil {
+				return fmt.Errorf("%s", "please try again later")
+			}
+		}
+
+		// A tag both clients carry would break the unique index once moved
+		err := tx.Where("client_uuid = ? AND tag IN (?)", duplicate.UUID,
+			tx.Model(&models.ClientTag{}).Select("tag").Where("client_uuid = ?", survivor.UUID)).
+			Delete(&models.ClientTag{}).Error
+		if err != nil {
+			return fmt.Errorf("failed to move client_tags: %w", err)
+		}
+
+		// The survivor keeps its primary contact, the contacts of the duplicate join as secondary ones
+		if err := clearPrimaryContact(tx, duplicate.UUID); err != nil {
+			return err
+		}
+
+		for _, reference := range clientReferences {
+			err := tx.Table(reference.table).
+				Where(fmt.Sprintf("%s = ?", reference.column), duplicate.UUID).
+				Update(reference.column, survivor.UUID).Error
+			if err != nil {
+				return fmt.Errorf("failed to move %s: %w", reference.table, err)
+			}
+		}
+
+		if err := tx.Delete(&duplicate).Error; err != nil {
+			return fmt.Errorf("%s", "please try again later")
+		}
+
+		changes := diffFields(clientChangeFields(duplicate), nil)
+		changes["merged_into"] = dtos.FieldChange{To: survivor.UUID}
+		if err := recordChange(tx, models.ChangeLogEntityClient, duplicate.UUID, models.ChangeLogActionMerge, request.Actor, changes); err != nil {
+			return err
+		}
+
+		// The survivor may have received KYC documents from the duplicate
+		_, err = refreshKycStatus(tx, survivor.UUID, request.Actor)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(survivor.UUID)
+}
+
+// SetTags implements ClientRepository.
+// The given tags replace the current tags of the client.
+func (r *clientRepositoryImpl) SetTags(request dtos.ClientTagRequest) (*dtos.ClientResponse, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var client models.Client
+		if err := tx.Preload("Tags", orderClientTags).Where("uuid = ?", request.UUID).First(&client).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%s", "client not found")
+			}
+			return fmt.Errorf("%s", "please try again later")
+		}
+
+		if err := tx.Where("client_uuid = ?", client.UUID).Delete(&models.ClientTag{}).Error; err != nil {
+			return fmt.Errorf("%s", "please try again later")
+		}
+
+		tags := make([]models.ClientTag, len(request.Tags))
+		for i, tag := range request.Tags {
+			tags[i] = models.ClientTag{ClientUUID: client.UUID, Tag: tag}
+		}
+		if len(tags) > 0 {
+			if err := tx.Create(&tags).Error; err != nil {
+				return fmt.Errorf("%s", "please try again later")
+			}
+		}
+
+		before := map[string]interface{}{"tags": clientTagNames(client.Tags)}
+		after := map[string]interface{}{"tags": clientTagNames(tags)}
+		return recordChange(tx, models.ChangeLogEntityClient, client.UUID, models.ChangeLogActionUpdate, request.Actor, diffFields(before, after))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(request.UUID)
+}
+
+// GetTags implements ClientRepository.
+// Only the clients the user may see are counted.
+func (r *clientRepositoryImpl) GetTags(visibility *dtos.ClientVisibility) ([]*dtos.ClientTagCountResponse, error) {
+	tags := []*dtos.ClientTagCountResponse{}
+	query := r.db.Model(&models.ClientTag{}).
+		Select("client_tags.tag, COUNT(*) AS count").
+		Joins("JOIN clients ON clients.uuid = client_tags.client_uuid AND clients.deleted_at IS NULL")
+	err := applyClientVisibility(query, visibility).
+		Group("client_tags.tag").
+		Order("client_tags.tag asc").
+		Scan(&tags).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tags: %w", err)
+	}
+	return tags, nil
+}
+
+// GetTrash implements ClientRepository.
+func (r *clientRepositoryImpl) GetTrash(request dtos.TrashGetRequest) ([]*dtos.ClientResponse, *dtos.PaginationMeta, error) {
+	var clients []models.Client
+	var total int64
+
+	query := r.db.Unscoped().Model(&models.Client{}).Where("deleted_at IS NOT NULL")
+	if request.Search != "" {
+		searchPattern := "%" + request.Search + "%"
+		query = query.Where("name ILIKE ? OR email ILIKE ? OR phone_number ILIKE ?", searchPattern, searchPattern, searchPattern)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to count deleted clients: %w", err)
+	}
+
+	offset := (request.Page - 1) * request.Limit
+	err := query.Preload("Tags", orderClientTags).Order("deleted_at desc").Offset(offset).Limit(request.Limit).Find(&clients).Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch deleted clients: %w", err)
+	}
+
+	clientResponses := make([]*dtos.ClientResponse, len(clients))
+	for i, client := range clients {
+		clientResponses[i] = toClientResponse(client)
+	}
+
+	paginationMeta := &dtos.PaginationMeta{
+		Page:       request.Page,
+		Limit:      request.Limit,
+		Total:      int(total),
+		TotalPages: int(math.Ceil(float64(total) / float64(request.Limit))),
+	}
+
+	return clientResponses, paginationMeta, nil
+}
+
+// Restore implements ClientRepository.
+func (r *clientRepositoryImpl) Restore(uuid string, actor dtos.ChangeActor) (*dtos.ClientResponse, error) {
+	var client models.Client
+	if err := r.db.Unscoped().Where("uuid = ? AND deleted_at IS NOT NULL", uuid).First(&client).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%s", "client not found")
+		}
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+	if client.ErasedAt != nil {
+		return nil, fmt.Errorf("%s", "client has been erased")
+	}
+
+	// The unique indexes are case sensitive, so a live client can still hold the same email in
+	// different casing, e.g. one created again after the original was deleted
+	var existing models.Client
+	err := r.db.Where("LOWER(email) = LOWER(?) OR phone_number = ?", client.Email, client.PhoneNumber).First(&existing).Error
+	if err == nil {
+		if existing.PhoneNumber == client.PhoneNumber {
+			return nil, fmt.Errorf("client with phone number %s already exists", client.PhoneNumber)
+		}
+		return nil, fmt.Errorf("client with email %s already exists", client.Email)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&client).Update("deleted_at", nil).Error; err != nil {
+			return fmt.Errorf("%s", "please try again later")
+		}
+
+		changes := diffFields(nil, clientChangeFields(client))
+		return recordChange(tx, models.ChangeLogEntityClient, client.UUID, models.ChangeLogActionRestore, actor, changes)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(uuid)
+}
+
+// CheckVisible implements ClientRepository.
+// A client outside the visibility is reported as not found, so its existence is not revealed.
+// Deleted clients are included, their history stays readable.
+func (r *clientRepositoryImpl) CheckVisible(uuid string, visibility dtos.ClientVisibility) error {
+	var count int64
+	err := applyClientVisibility(r.db.Unscoped().Model(&models.Client{}), &visibility).
+		Where("clients.uuid = ?", uuid).
+		Count(&count).Error
+	if err != nil {
+		return fmt.Errorf("%s", "please try again later")
+	}
+	if count == 0 {
+		return fmt.Errorf("%s", "client not found")
+	}
+	return nil
+}
+
+// SetOwner implements ClientRepository.
+func (r *clientRepositoryImpl) SetOwner(request dtos.ClientOwnerRequest) (*dtos.ClientResponse, error) {
+	var client models.Client
+	if err := r.db.Where("uuid = ?", request.UUID).First(&client).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%s", "client not found")
+		}
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+	if err := r.findOwner(request.OwnerUUID); err != nil {
+		return nil, err
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		before := optionalString(client.OwnerUUID)
+		if err := tx.Model(&client).Update("owner_uuid", request.OwnerUUID).Error; err != nil {
+			return fmt.Errorf("%s", "please try again later")
+		}
+
+		changes := diffFields(map[string]interface{}{"owner_uuid": before}, map[string]interface{}{"owner_uuid": request.OwnerUUID})
+		return recordChange(tx, models.ChangeLogEntityClient, client.UUID, models.ChangeLogActionUpdate, request.Actor, changes)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(request.UUID)
+}
+
+// Reassign implements ClientRepository.
+// Deleted clients move along, so a restored client does not come back to an agent who left.
+func (r *clientRepositoryImpl) Reassign(request dtos.ClientReassignRequest) (int, error) {
+	if err := r.findOwner(request.ToOwnerUUID); err != nil {
+		return 0, err
+	}
+
+	var uuids []string
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Unscoped().Model(&models.Client{})
+		if request.FromOwnerUUID == "" {
+			query = query.Where("owner_uuid IS NULL")
+		} else {
+			query = query.Where("owner_uuid = ?", request.FromOwnerUUID)
+		}
+		if err := query.Pluck("uuid", &uuids).Error; err != nil {
+			return fmt.Errorf("%s", "please try again later")
+		}
+		if len(uuids) == 0 {
+			return nil
+		}
+
+		err := tx.Unscoped().Model(&models.Client{}).Where("uuid IN ?", uuids).
+			Updates(map[string]interface{}{"owner_uuid": request.ToOwnerUUID}).Error
+		if err != nil {
+			return fmt.Errorf("%s", "please try again later")
+		}
+
+		var from interface{}
+		if request.FromOwnerUUID != "" {
+			from = request.FromOwnerUUID
+		}
+		changes := map[string]dtos.FieldChange{"owner_uuid": {From: from, To: request.ToOwnerUUID}}
+		for _, uuid := range uuids {
+			if err := recordChange(tx, models.ChangeLogEntityClient, uuid, models.ChangeLogActionUpdate, request.Actor, changes); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(uuids), nil
+}
+
+// findOwner checks the agent a client is handed to exists
+func (r *clientRepositoryImpl) findOwner(uuid string) error {
+	var user models.User
+	if err := r.db.Select("uuid").Where("uuid = ?", uuid).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%s", "owner not found")
+		}
+		return fmt.Errorf("%s", "please try again later")
+	}
+	return nil
+}
+
+// normalizeClientSort falls back to the default ordering when sort_by or sort_order is missing or not allowed
+func normalizeClientSort(request dtos.ClientGetRequest) dtos.ClientGetRequest {
+	if request.SortBy == "" {
+		request.SortBy = "created_at"
+	}
+	if request.SortOrder == "" {
+		request.SortOrder = "desc"
+	}
+
+	// Validate sort from frontend if the sort is not asc or desc
+	if request.SortOrder != "asc" && request.SortOrder != "desc" {
+		request.SortOrder = "desc"
+	}
+
+	if !slices.Contains(dtos.ClientSortFields, request.SortBy) {
+		request.SortBy = "created_at"
+	}
+
+	return request
+}
+
+// clientSortValue renders the sort column of a client as stored in a cursor. Timestamps keep
+// their full precision so no row is skipped or repeated at a page boundary.
+func clientSortValue(client models.Client, sortBy string) string {
+	switch sortBy {
+	case "name":
+		return client.Name
+	case "email":
+		return client.Email
+	case "updated_at":
+		return client.UpdatedAt.Format(time.RFC3339Nano)
+	default:
+		return client.CreatedAt.Format(time.RFC3339Nano)
+	}
+}
+
+// clientCursorValue turns the value of a cursor back into a query argument for the sort column.
+// Postgres rejects NUL bytes in text, so a value carrying one cannot come from a real row.
+func clientCursorValue(sortBy string, value string) (interface{}, error) {
+	switch sortBy {
+	case "name", "email":
+		if strings.ContainsRune(value, 0) {
+			return nil, fmt.Errorf("%s", "invalid cursor")
+		}
+		return value, nil
+	default:
+		at, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s", "invalid cursor")
+		}
+		return at, nil
+	}
+}
+
+// applyClientSearch applies the search and search_by parameters shared by the list and export queries
+func applyClientSearch(query *gorm.DB, request dtos.ClientGetRequest) *gorm.DB {
+	// Apply search filter if not null
+	if request.Search != "" {
+		searchPattern := "%" + request.Search + "%"
+		if slices.Contains(dtos.ClientSearchFields, request.SearchBy) {
+			query = query.Where(fmt.Sprintf("%s ILIKE ?", request.SearchBy), searchPattern)
+		} else {
+			// Global search across multiple fields
+			query = query.Where(
+				"name ILIKE ? OR email ILIKE ? OR phone_number ILIKE ? OR contact_person ILIKE ? OR address ILIKE ?",
+				searchPattern, searchPattern, searchPattern, searchPattern, searchPattern,
+			)
+		}
+	}
+
+	return query
+}
+
+// clientFilterSets applies filter[tags][in] and filter[tags][all]
+var clientFilterSets = map[string]filterSetQuery{
+	"tags": func(query *gorm.DB, condition dtos.FilterCondition) *gorm.DB {
+		if condition.Operator == "all" {
+			return applyAllTags(query, filterStrings(condition))
+		}
+		return applyAnyTags(query, filterStrings(condition))
+	},
+}
+
+// applyClientFilter applies the tags and filter parameters and the filter of the requested segment
+func applyClientFilter(query *gorm.DB, request dtos.ClientGetRequest) *gorm.DB {
+	if request.Tags != "" {
+		query = applyAllTags(query, strings.Split(request.Tags, ","))
+	}
+	query = applyFilters(query, request.Filters, clientFilterSets)
+	query = applyClientVisibility(query, request.Visibility)
+
+	filter := request.Filter
+	if filter == nil {
+		return query
+	}
+
+	if len(filter.Tags) > 0 {
+		query = applyAllTags(query, filter.Tags)
+	}
+	if len(filter.AnyTags) > 0 {
+		query = applyAnyTags(query, filter.AnyTags)
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("clients.created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("clients.created_at <= ?", *filter.CreatedTo)
+	}
+	if filter.HasHeldDeposit != nil {
+		heldDeposits := query.Session(&gorm.Session{NewDB: true}).Model(&models.Deposit{}).
+			Select("client_uuid").Where("status = ?", models.DepositStatusHeld)
+		if *filter.HasHeldDeposit {
+			query = query.Where("clients.uuid IN (?)", heldDeposits)
+		} else {
+			query = query.Where("clients.uuid NOT IN (?)", heldDeposits)
+		}
+	}
+
+	return query
+}
+
+// applyClientVisibility keeps the clients a user may see, see dtos.ClientVisibility. A client
+// without an owner is only visible to users who see all clients.
+func applyClientVisibility(query *gorm.DB, visibility *dtos.ClientVisibility) *gorm.DB {
+	if visibility == nil || visibility.All {
+		return query
+	}
+	if visibility.Team == "" {
+		return query.Where("clients.owner_uuid = ?", visibility.UserUUID)
+	}
+
+	return query.Where("clients.owner_uuid = ? OR clients.owner_uuid IN (?)", visibility.UserUUID,
+		query.Session(&gorm.Session{NewDB: true}).Model(&models.User{}).
+			Select("uuid").Where("team = ?", visibility.Team))
+}
+
+// applyAllTags keeps the clients carrying every one of the given tags
+func applyAllTags(query *gorm.DB, tags []string) *gorm.DB {
+	normalized := helpers.NormalizeTags(tags)
+	if len(normalized) == 0 {
+		return query
+	}
+
+	return query.Where("clients.uuid IN (?)",
+		query.Session(&gorm.Session{NewDB: true}).Model(&models.ClientTag{}).
+			Select("client_uuid").
+			Where("tag IN ?", normalized).
+			Group("client_uuid").
+			Having("COUNT(DISTINCT tag) = ?", len(normalized)))
+}
+
+// applyAnyTags keeps the clients carrying at least one of the given tags
+func applyAnyTags(query *gorm.DB, tags []string) *gorm.DB {
+	return query.Where("clients.uuid IN (?)",
+		query.Session(&gorm.Session{NewDB: true}).Model(&models.ClientTag{}).
+			Select("client_uuid").Where("tag IN ?", helpers.NormalizeTags(tags)))
+}
+
+// orderClientTags preloads the tags of a client in alphabetical order
+func orderClientTags(db *gorm.DB) *gorm.DB {
+	return db.Order("tag asc")
+}
+
+// clientChangeFields are the fields of a client tracked in its change history
+func clientChangeFields(client models.Client) map[string]interface{} {
+	return map[string]interface{}{
+		"name":           client.Name,
+		"email":          client.Email,
+		"phone_number":   client.PhoneNumber,
+		"address":        client.Address,
+		"contact_person": client.ContactPerson,
+		"owner_uuid":     optionalString(client.OwnerUUID),
+	}
+}
+
+// optionalString keeps a missing value as null in the change history
+func optionalString(value *string) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+func clientTagNames(clientTags []models.ClientTag) []string {
+	tags := make([]string, len(clientTags))
+	for i, tag := range clientTags {
+		tags[i] = tag.Tag
+	}
+	return tags
+}
+
+func toClientResponse(client models.Client) *dtos.ClientResponse {
+	tags := clientTagNames(client.Tags)
+
+	var contacts []dtos.ClientContactResponse
+	for _, contact := range client.Contacts {
+		contacts = append(contacts, *toClientContactResponse(contact))
+	}
+
+	var deletedAt *time.Time
+	if client.DeletedAt.Valid {
+		deletedAt = &client.DeletedAt.Time
+	}
+
+	return &dtos.ClientResponse{
+		UUID:          client.UUID,
+		Name:          client.Name,
+		Email:         client.Email,
+		PhoneNumber:   client.PhoneNumber,
+		Address:       client.Address,
+		ContactPerson: client.ContactPerson,
+		Tags:          tags,
+		KycStatus:     client.KycStatus,
+		OwnerUUID:     client.OwnerUUID,
+		CreatedAt:     client.CreatedAt,
+		UpdatedAt:     client.UpdatedAt,
+		DeletedAt:     deletedAt,
+		ErasedAt:      client.ErasedAt,
+		Contacts:      contacts,
+		ETag:          helpers.ETag(client.UpdatedAt),
+	}
+}
+
 func NewClientRepository(db *gorm.DB) ClientRepository {
 	return &clientRepositoryImpl{db: db}
 }
@@ -214,6 +916,15 @@ func (r *clientRepositoryImpl) Create(request dtos.ClientRequest) (*dtos.ClientR
 		return nil, err
 	}
 
+	// Phone numbers are unique across soft-deleted clients as well
+	err = r.db.Unscoped().Where("phone_number = ?", request.PhoneNumber).First(&existingClient).Error
+	if err == nil {
+		return nil, fmt.Errorf("client with phone number %s already exists", request.PhoneNumber)
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
 	// Create new client
 	client := models.Client{
 		Name:          request.Name,
@@ -221,21 +932,23 @@ func (r *clientRepositoryImpl) Create(request dtos.ClientRequest) (*dtos.ClientR
 		PhoneNumber:   request.PhoneNumber,
 		Address:       request.Address,
 		ContactPerson: request.ContactPerson,
+		OwnerUUID:     authorUUID(request.Actor.UserUUID),
 	}
 
-	err = r.db.Create(&client).Error
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&client).Error; err != nil {
+			return err
+		}
+		if err := renamePrimaryContact(tx, client.UUID, client.ContactPerson); err != nil {
+			return err
+		}
+
+		changes := diffFields(nil, clientChangeFields(client))
+		return recordChange(tx, models.ChangeLogEntityClient, client.UUID, models.ChangeLogActionCreate, request.Actor, changes)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return &dtos.ClientResponse{
-		UUID:          client.UUID,
-		Name:          client.Name,
-		Email:         client.Email,
-		PhoneNumber:   client.PhoneNumber,
-		Address:       client.Address,
-		ContactPerson: client.ContactPerson,
-		CreatedAt:     client.CreatedAt,
-		UpdatedAt:     client.UpdatedAt,
-	}, nil
+	return toClientResponse(client), nil
 }
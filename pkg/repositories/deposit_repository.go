@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/models"
+)
+
+type DepositRepository interface {
+	Create(deposit *models.Deposit) error
+	GetAll(request dtos.DepositGetRequest) ([]models.Deposit, int64, error)
+	FindByUUID(uuid string) (models.Deposit, error)
+	AddDeduction(deduction *models.DepositDeduction) error
+	Refund(deposit *models.Deposit) error
+}
+
+type depositRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// Create implements DepositRepository.
+func (r *depositRepositoryImpl) Create(deposit *models.Deposit) error {
+	if err := r.db.Create(deposit).Error; err != nil {
+		return fmt.Errorf("%s", "please try again later")
+	}
+	return nil
+}
+
+// GetAll implements DepositRepository.
+func (r *depositRepositoryImpl) GetAll(request dtos.DepositGetRequest) ([]models.Deposit, int64, error) {
+	var deposits []models.Deposit
+	var total int64
+
+	query := r.db.Model(&models.Deposit{})
+	if request.ClientUUID != "" {
+		query = query.Where("client_uuid = ?", request.ClientUUID)
+	}
+	if request.PropertyUUID != "" {
+		query = query.Where("property_uuid = ?", request.PropertyUUID)
+	}
+	if request.Status != "" {
+		query = query.Where("status = ?", request.Status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count deposits: %w", err)
+	}
+
+	offset := (request.Page - 1) * request.Limit
+	err := query.Preload("Deductions.Photos").
+		Order("received_at desc").
+		Offset(offset).
+		Limit(request.Limit).
+		Find(&deposits).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch deposits: %w", err)
+	}
+
+	return deposits, total, nil
+}
+
+// FindByUUID implements DepositRepository.
+func (r *depositRepositoryImpl) FindByUUID(uuid string) (models.Deposit, error) {
+	var deposit models.Deposit
+	if err := r.db.Preload("Deductions.Photos").Where("uuid = ?", uuid).First(&deposit).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return deposit, fmt.Errorf("%s", "deposit not found")
+		}
+		return deposit, fmt.Errorf("%s", "please try again later")
+	}
+	return deposit, nil
+}
+
+// AddDeduction implements DepositRepository.
+func (r *depositRepositoryImpl) AddDeduction(deduction *models.DepositDeduction) error {
+	// The deduction and its evidence photos are stored together
+	if err := r.db.Create(deduction).Error; err != nil {
+		return fmt.Errorf("%s", "please try again later")
+	}
+	return nil
+}
+
+// Refund implements DepositRepository.
+func (r *depositRepositoryImpl) Refund(deposit *models.Deposit) error {
+	// Only a deposit that is still held can be refunded, guard against double refunds
+	result := r.db.Model(&models.Deposit{}).
+		Where("uuid = ? AND status = ?", deposit.UUID, models.DepositStatusHeld).
+		Updates(map[string]interface{}{
+			"status":           models.DepositStatusRefunded,
+			"refund_amount":    deposit.RefundAmount,
+			"refund_method":    deposit.RefundMethod,
+			"refund_reference": deposit.RefundReference,
+			"refunded_at":      deposit.RefundedAt,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("%s", "please try again later")
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%s", "deposit already refunded")
+	}
+
+	deposit.Status = models.DepositStatusRefunded
+	return nil
+}
+
+func NewDepositRepository(db *gorm.DB) DepositRepository {
+	return &depositRepositoryImpl{db: db}
+}
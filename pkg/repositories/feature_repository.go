@@ -1,17 +1,29 @@
 package repositories
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 
 	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/helpers"
 	"alfredo/ruu-properties/pkg/models"
 )
 
 type FeatureRepository interface {
 	Create(request dtos.FeatureRequest) (*dtos.FeatureResponse, error)
+	GetAll(request dtos.FeatureGetRequest) ([]*dtos.FeatureResponse, *dtos.PaginationMeta, error)
+	GetByID(uuid string) (*dtos.FeatureResponse, error)
+	GetPublic() ([]*dtos.PublicFeatureResponse, error)
+	Update(request dtos.FeatureUpdateRequest) (*dtos.FeatureResponse, error)
+	Patch(request dtos.FeaturePatchRequest) (*dtos.FeatureResponse, error)
+	Delete(uuid string, ifMatch string) error
+	GetTrash(request dtos.TrashGetRequest) ([]*dtos.FeatureResponse, *dtos.PaginationMeta, error)
+	Restore(uuid string) (*dtos.FeatureResponse, error)
 }
 
 type featureRepositoryImpl struct {
@@ -24,17 +36,281 @@ func (f *featureRepositoryImpl) Create(request dtos.FeatureRequest) (*dtos.Featu
 		UUID:        uuid.New().String(),
 		Name:        request.Name,
 		Description: request.Description,
+		Icon:        request.Icon,
+		IsPublic:    request.IsPublic,
 	}
 	if err := f.db.Debug().Create(&feature).Error; err != nil {
 		return &dtos.FeatureResponse{}, fmt.Errorf("%s", "please try again later")
 	}
+	return toFeatureResponse(feature), nil
+}
+
+// GetAll implements FeatureRepository.
+func (f *featureRepositoryImpl) GetAll(request dtos.FeatureGetRequest) ([]*dtos.FeatureResponse, *dtos.PaginationMeta, error) {
+	if request.SortBy == "" {
+		request.SortBy = "created_at"
+	}
+	if request.SortOrder != "asc" && request.SortOrder != "desc" {
+		request.SortOrder = "desc"
+	}
+
+	var features []models.Feature
+	var total int64
+
+	query := f.db.Model(&models.Feature{})
+	if request.Search != "" {
+		searchPattern := "%" + request.Search + "%"
+		query = query.Where("name ILIKE ? OR description ILIKE ?", searchPattern, searchPattern)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to count features: %w", err)
+	}
+
+	offset := (request.Page - 1) * request.Limit
+	sortClause := fmt.Sprintf("%s %s", request.SortBy, request.SortOrder)
+	if err := query.Order(sortClause).Offset(offset).Limit(request.Limit).Find(&features).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch features: %w", err)
+	}
+
+	featureResponses := make([]*dtos.FeatureResponse, len(features))
+	for i, feature := range features {
+		featureResponses[i] = toFeatureResponse(feature)
+	}
+
+	paginationMeta := &dtos.PaginationMeta{
+		Page:       request.Page,
+		Limit:      request.Limit,
+		Total:      int(total),
+		TotalPages: int(math.Ceil(float64(total) / float64(request.Limit))),
+	}
+
+	return featureResponses, paginationMeta, nil
+}
+
+// GetByID implements FeatureRepository.
+func (f *featureRepositoryImpl) GetByID(uuid string) (*dtos.FeatureResponse, error) {
+	var feature models.Feature
+	if err := f.db.Where("uuid = ?", uuid).First(&feature).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &dtos.FeatureResponse{}, fmt.Errorf("%s", "feature not found")
+		}
+		return &dtos.FeatureResponse{}, fmt.Errorf("%s", "please try again later")
+	}
+	return toFeatureResponse(feature), nil
+}
+
+// GetPublic implements FeatureRepository.
+func (f *featureRepositoryImpl) GetPublic() ([]*dtos.PublicFeatureResponse, error) {
+	var features []models.Feature
+	if err := f.db.Where("is_public = ?", true).Order("name asc").Find(&features).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch features: %w", err)
+	}
+
+	featureResponses := make([]*dtos.PublicFeatureResponse, len(features))
+	for i, feature := range features {
+		featureResponses[i] = &dtos.PublicFeatureResponse{
+			UUID:        feature.UUID,
+			Name:        feature.Name,
+			Description: feature.Description,
+			Icon:        feature.Icon,
+		}
+	}
+	return featureResponses, nil
+}
+
+// Update implements FeatureRepository.
+func (f *featureRepositoryImpl) Update(request dtos.FeatureUpdateRequest) (*dtos.FeatureResponse, error) {
+	feature, err := f.findForUpdate(request.UUID, request.IfMatch)
+	if err != nil {
+		return &dtos.FeatureResponse{}, err
+	}
+	version := feature.UpdatedAt
+
+	if request.Name != "" {
+		feature.Name = request.Name
+	}
+	if request.Description != "" {
+		feature.Description = request.Description
+	}
+	if request.Icon != "" {
+		feature.Icon = request.Icon
+	}
+	if request.IsPublic != nil {
+		feature.IsPublic = *request.IsPublic
+	}
+
+	return f.saveFeature(feature, version, request.IfMatch != "")
+}
+
+// Patch implements FeatureRepository.
+// Unlike Update every editable column is written, a nil description or icon clears it.
+func (f *featureRepositoryImpl) Patch(request dtos.FeaturePatchRequest) (*dtos.FeatureResponse, error) {
+	feature, err := f.findForUpdate(request.UUID, request.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+	version := feature.UpdatedAt
+
+	feature.Name = request.Name
+	feature.IsPublic = request.IsPublic
+	feature.Description = ""
+	if request.Description != nil {
+		feature.Description = *request.Description
+	}
+	feature.Icon = ""
+	if request.Icon != nil {
+		feature.Icon = *request.Icon
+	}
+
+	return f.saveFeature(feature, version, request.IfMatch != "")
+}
+
+// findForUpdate loads a feature that is about to change and checks it is still the version
+// named by ifMatch, when one is given
+func (f *featureRepositoryImpl) findForUpdate(uuid string, ifMatch string) (*models.Feature, error) {
+	var feature models.Feature
+	if err := f.db.Where("uuid = ?", uuid).First(&feature).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%s", "feature not found")
+		}
+		return nil, fmt.Errorf("%s", err.Error())
+	}
+
+	if ifMatch != "" && !helpers.MatchesETag(ifMatch, helpers.ETag(feature.UpdatedAt)) {
+		return nil, fmt.Errorf("%s", "feature has been modified")
+	}
+	return &feature, nil
+}
+
+// saveFeature writes the editable columns of a feature. A conditional write only applies while
+// the row is still at version.
+func (f *featureRepositoryImpl) saveFeature(feature *models.Feature, version time.Time, conditional bool) (*dtos.FeatureResponse, error) {
+	feature.UpdatedAt = f.db.NowFunc()
+
+	query := f.db.Model(feature)
+	if conditional {
+		query = query.Where("updated_at = ?", version)
+	}
+	result := query.Select("name", "description", "icon", "is_public", "updated_at").Updates(feature)
+	if result.Error != nil {
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("%s", "feature has been modified")
+	}
+
+	return toFeatureResponse(*feature), nil
+}
+
+// Delete implements FeatureRepository.
+func (f *featureRepositoryImpl) Delete(uuid string, ifMatch string) error {
+	var feature models.Feature
+	if err := f.db.Where("uuid = ?", uuid).First(&feature).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%s", "feature not found")
+		}
+		return fmt.Errorf("%s", "please try again later")
+	}
+
+	query := f.db
+	if ifMatch != "" {
+		if !helpers.MatchesETag(ifMatch, helpers.ETag(feature.UpdatedAt)) {
+			return fmt.Errorf("%s", "feature has been modified")
+		}
+		query = query.Where("updated_at = ?", feature.UpdatedAt)
+	}
+
+	result := query.Delete(&feature)
+	if result.Error != nil {
+		return fmt.Errorf("%s", "please try again later")
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%s", "feature has been modified")
+	}
+	return nil
+}
+
+// GetTrash implements FeatureRepository.
+func (f *featureRepositoryImpl) GetTrash(request dtos.TrashGetRequest) ([]*dtos.FeatureResponse, *dtos.PaginationMeta, error) {
+	var features []models.Feature
+	var total int64
+
+	query := f.db.Unscoped().Model(&models.Feature{}).Where("deleted_at IS NOT NULL")
+	if request.Search != "" {
+		searchPattern := "%" + request.Search + "%"
+		query = query.Where("name ILIKE ? OR description ILIKE ?", searchPattern, searchPattern)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to count deleted features: %w", err)
+	}
+
+	offset := (request.Page - 1) * request.Limit
+	if err := query.Order("deleted_at desc").Offset(offset).Limit(request.Limit).Find(&features).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch deleted features: %w", err)
+	}
+
+	featureResponses := make([]*dtos.FeatureResponse, len(features))
+	for i, feature := range features {
+		featureResponses[i] = toFeatureResponse(feature)
+	}
+
+	paginationMeta := &dtos.PaginationMeta{
+		Page:       request.Page,
+		Limit:      request.Limit,
+		Total:      int(total),
+		TotalPages: int(math.Ceil(float64(total) / float64(request.Limit))),
+	}
+
+	return featureResponses, paginationMeta, nil
+}
+
+// Restore implements FeatureRepository.
+func (f *featureRepositoryImpl) Restore(uuid string) (*dtos.FeatureResponse, error) {
+	var feature models.Feature
+	if err := f.db.Unscoped().Where("uuid = ? AND deleted_at IS NOT NULL", uuid).First(&feature).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%s", "feature not found")
+		}
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+
+	// A replacement amenity is usually created under the same name once the original is gone
+	var existing models.Feature
+	err := f.db.Where("LOWER(name) = LOWER(?)", feature.Name).First(&existing).Error
+	if err == nil {
+		return nil, fmt.Errorf("feature with name %s already exists", feature.Name)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+
+	if err := f.db.Unscoped().Model(&feature).Update("deleted_at", nil).Error; err != nil {
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+	feature.DeletedAt = gorm.DeletedAt{}
+
+	return toFeatureResponse(feature), nil
+}
+
+func toFeatureResponse(feature models.Feature) *dtos.FeatureResponse {
+	deletedAt := ""
+	if feature.DeletedAt.Valid {
+		deletedAt = feature.DeletedAt.Time.String()
+	}
+
 	return &dtos.FeatureResponse{
 		UUID:        feature.UUID,
 		Name:        feature.Name,
 		Description: feature.Description,
+		Icon:        feature.Icon,
+		IsPublic:    feature.IsPublic,
 		CreatedAt:   feature.CreatedAt.String(),
 		UpdatedAt:   feature.UpdatedAt.String(),
-	}, nil
+		DeletedAt:   deletedAt,
+		ETag:        helpers.ETag(feature.UpdatedAt),
+	}
 }
 
 func NewFeatureRepository(db *gorm.DB) FeatureRepository {
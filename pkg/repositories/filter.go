@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"alfredo/ruu-properties/pkg/dtos"
+)
+
+// filterComparators maps the comparison operators of a filter to SQL
+var filterComparators = map[string]string{
+	"eq":  "=",
+	"ne":  "<>",
+	"gt":  ">",
+	"gte": ">=",
+	"lt":  "<",
+	"lte": "<=",
+}
+
+// likeEscaper escapes the wildcards of ILIKE, so a filter value matches itself literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// filterSetQuery applies a condition on a tags field, whose values live in a table of their own
+type filterSetQuery func(query *gorm.DB, condition dtos.FilterCondition) *gorm.DB
+
+// applyFilters adds the conditions parsed by helpers.ParseFilters to query. Columns come from
+// the whitelist of the list, never from the request, and every value is bound as a parameter.
+// sets supplies the query of each tags field and may be nil for a list without one.
+func applyFilters(query *gorm.DB, conditions []dtos.FilterCondition, sets map[string]filterSetQuery) *gorm.DB {
+	for _, condition := range conditions {
+		if condition.Type == dtos.FilterTypeTags {
+			if set, ok := sets[condition.Field]; ok {
+				query = set(query, condition)
+			}
+			continue
+		}
+
+		column := condition.Column
+		switch condition.Operator {
+		case "in":
+			query = query.Where(fmt.Sprintf("%s IN ?", column), condition.Values)
+		case "nin":
+			query = query.Where(fmt.Sprintf("%s NOT IN ?", column), condition.Values)
+		case "ilike":
+			pattern := "%" + likeEscaper.Replace(fmt.Sprint(condition.Values[0])) + "%"
+			query = query.Where(fmt.Sprintf(`%s ILIKE ? ESCAPE '\'`, column), pattern)
+		case "null":
+			if condition.Values[0] == true {
+				query = query.Where(fmt.Sprintf("%s IS NULL", column))
+			} else {
+				query = query.Where(fmt.Sprintf("%s IS NOT NULL", column))
+			}
+		default:
+			query = query.Where(fmt.Sprintf("%s %s ?", column, filterComparators[condition.Operator]), condition.Values[0])
+		}
+	}
+
+	return query
+}
+
+// filterStrings returns the values of a condition as strings
+func filterStrings(condition dtos.FilterCondition) []string {
+	values := make([]string, len(condition.Values))
+	for i, value := range condition.Values {
+		values[i] = fmt.Sprint(value)
+	}
+	return values
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
+
 	"github.com/redis/go-redis/v9"
 )
 
@@ -14,6 +16,9 @@ type redisRepositoryImpl struct {
 type RedisRepository interface {
 	GetClient() *redis.Client
 	Set(key string, value interface{}) error
+	SetWithExpiration(key string, value interface{}, expiration time.Duration) error
+	SetNX(key string, value interface{}, expiration time.Duration) (bool, error)
+	IncrWithExpiration(key string, expiration time.Duration) (int64, error)
 	Get(key string) (string, error)
 	Delete(key string) error
 }
@@ -37,6 +42,41 @@ func (r *redisRepositoryImpl) Set(key string, value interface{}) error {
 	return nil
 }
 
+func (r *redisRepositoryImpl) SetWithExpiration(key string, value interface{}, expiration time.Duration) error {
+	ctx := context.Background()
+	err := r.client.Set(ctx, key, value, expiration).Err()
+	if err != nil {
+		return errors.New(fmt.Sprint("Please contact our customer service."))
+	}
+	return nil
+}
+
+// SetNX stores the key only when it does not exist yet and reports whether it did
+func (r *redisRepositoryImpl) SetNX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	ctx := context.Background()
+	ok, err := r.client.SetNX(ctx, key, value, expiration).Result()
+	if err != nil {
+		return false, errors.New(fmt.Sprint("Please contact our customer service."))
+	}
+	return ok, nil
+}
+
+// IncrWithExpiration increments a counter and returns its new value. The expiration is set when
+// the counter is created and left alone on later increments.
+func (r *redisRepositoryImpl) IncrWithExpiration(key string, expiration time.Duration) (int64, error) {
+	ctx := context.Background()
+	count, err := r.client.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, errors.New(fmt.Sprint("Please contact our customer service."))
+	}
+	if count == 1 {
+		if err := r.client.Expire(ctx, key, expiration).Err(); err != nil {
+			return 0, errors.New(fmt.Sprint("Please contact our customer service."))
+		}
+	}
+	return count, nil
+}
+
 func (r *redisRepositoryImpl) Get(key string) (string, error) {
 	ctx := context.Background()
 	val, err := r.client.Get(ctx, key).Result()
@@ -0,0 +1,178 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/models"
+)
+
+type RoleRepository interface {
+	GetAll() ([]models.Role, error)
+	FindByName(name string) (models.Role, error)
+	Create(request dtos.RoleRequest) (models.Role, error)
+	Update(request dtos.RoleRequest) (models.Role, error)
+	Delete(name string) error
+	GetPermissionNames(role string) ([]string, error)
+	GetUserUUIDs(role string) ([]string, error)
+	SetRequireTwoFactor(request dtos.RoleTwoFactorRequest) (models.Role, error)
+}
+
+type roleRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// GetAll implements RoleRepository.
+func (r *roleRepositoryImpl) GetAll() ([]models.Role, error) {
+	var roles []models.Role
+	if err := r.db.Preload("Permissions", orderPermissions).Order("name ASC").Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch roles: %w", err)
+	}
+	return roles, nil
+}
+
+// FindByName implements RoleRepository.
+func (r *roleRepositoryImpl) FindByName(name string) (models.Role, error) {
+	var role models.Role
+	if err := r.db.Preload("Permissions", orderPermissions).Where("name = ?", name).First(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return role, fmt.Errorf("role not found")
+		}
+		return role, fmt.Errorf("failed to fetch role: %w", err)
+	}
+	return role, nil
+}
+
+// Create implements RoleRepository.
+func (r *roleRepositoryImpl) Create(request dtos.RoleRequest) (models.Role, error) {
+	role := models.Role{
+		Name:        request.Name,
+		Description: request.Description,
+		Permissions: permissionModels(request.Permissions),
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&models.Role{}).Where("name = ?", request.Name).Count(&existing).Error; err != nil {
+			return fmt.Errorf("failed to check role: %w", err)
+		}
+		if existing > 0 {
+			return fmt.Errorf("role already exists")
+		}
+
+		// The permissions exist already, only the role and its links are inserted
+		if err := tx.Omit("Permissions.*").Create(&role).Error; err != nil {
+			return fmt.Errorf("failed to create role: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return role, err
+	}
+	return r.FindByName(role.Name)
+}
+
+// Update implements RoleRepository. The permissions of the role are replaced as a whole.
+func (r *roleRepositoryImpl) Update(request dtos.RoleRequest) (models.Role, error) {
+	role, err := r.FindByName(request.Name)
+	if err != nil {
+		return role, err
+	}
+
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&role).Update("description", request.Description).Error; err != nil {
+			return fmt.Errorf("failed to update role: %w", err)
+		}
+		if err := tx.Omit("Permissions.*").Model(&role).Association("Permissions").Replace(permissionModels(request.Permissions)); err != nil {
+			return fmt.Errorf("failed to update role permissions: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return role, err
+	}
+	return r.FindByName(role.Name)
+}
+
+// Delete implements RoleRepository. Roles that users still hold cannot be deleted.
+func (r *roleRepositoryImpl) Delete(name string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		role := models.Role{Name: name}
+		if err := tx.Where("name = ?", name).First(&role).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("role not found")
+			}
+			return fmt.Errorf("failed to fetch role: %w", err)
+		}
+
+		var holders int64
+		if err := tx.Unscoped().Model(&models.User{}).Where("role = ?", name).Count(&holders).Error; err != nil {
+			return fmt.Errorf("failed to check role: %w", err)
+		}
+		if holders > 0 {
+			return fmt.Errorf("role is still assigned to users")
+		}
+
+		if err := tx.Model(&role).Association("Permissions").Clear(); err != nil {
+			return fmt.Errorf("failed to delete role: %w", err)
+		}
+		if err := tx.Delete(&role).Error; err != nil {
+			return fmt.Errorf("failed to delete role: %w", err)
+		}
+		return nil
+	})
+}
+
+// SetRequireTwoFactor implements RoleRepository.
+func (r *roleRepositoryImpl) SetRequireTwoFactor(request dtos.RoleTwoFactorRequest) (models.Role, error) {
+	role, err := r.FindByName(request.Name)
+	if err != nil {
+		return role, err
+	}
+
+	if err := r.db.Model(&role).Update("require_two_factor", request.Required).Error; err != nil {
+		return role, fmt.Errorf("failed to update role: %w", err)
+	}
+	return r.FindByName(role.Name)
+}
+
+// GetPermissionNames implements RoleRepository.
+func (r *roleRepositoryImpl) GetPermissionNames(role string) ([]string, error) {
+	var permissions []string
+	if err := r.db.Table("role_permissions").
+		Where("role_name = ?", role).
+		Order("permission_name ASC").
+		Pluck("permission_name", &permissions).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch permissions: %w", err)
+	}
+	return permissions, nil
+}
+
+// GetUserUUIDs implements RoleRepository.
+func (r *roleRepositoryImpl) GetUserUUIDs(role string) ([]string, error) {
+	var uuids []string
+	if err := r.db.Model(&models.User{}).Where("role = ?", role).Pluck("uuid", &uuids).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch users: %w", err)
+	}
+	return uuids, nil
+}
+
+// orderPermissions lists the permissions of a role by name
+func orderPermissions(db *gorm.DB) *gorm.DB {
+	return db.Order("permissions.name ASC")
+}
+
+func permissionModels(names []string) []models.Permission {
+	permissions := make([]models.Permission, len(names))
+	for i, name := range names {
+		permissions[i] = models.Permission{Name: name}
+	}
+	return permissions
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepositoryImpl{db: db}
+}
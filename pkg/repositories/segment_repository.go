@@ -0,0 +1,170 @@
+package repositories
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+
+	"gorm.io/gorm"
+
+	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/models"
+)
+
+type SegmentRepository interface {
+	Create(request dtos.SegmentRequest) (*dtos.SegmentResponse, error)
+	GetAll(request dtos.SegmentGetRequest) ([]*dtos.SegmentResponse, *dtos.PaginationMeta, error)
+	GetByID(uuid string) (*dtos.SegmentResponse, error)
+	Update(request dtos.SegmentUpdateRequest) (*dtos.SegmentResponse, error)
+	Delete(uuid string) error
+}
+
+type segmentRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// Create implements SegmentRepository.
+func (s *segmentRepositoryImpl) Create(request dtos.SegmentRequest) (*dtos.SegmentResponse, error) {
+	filter, err := json.Marshal(request.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid segment filter: %w", err)
+	}
+
+	var existing models.Segment
+	if err := s.db.Unscoped().Where("name = ?", request.Name).First(&existing).Error; err == nil {
+		return nil, fmt.Errorf("segment with name %s already exists", request.Name)
+	}
+
+	segment := models.Segment{
+		Name:        request.Name,
+		Description: request.Description,
+		Filter:      string(filter),
+	}
+	if err := s.db.Create(&segment).Error; err != nil {
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+
+	return toSegmentResponse(segment)
+}
+
+// GetAll implements SegmentRepository.
+func (s *segmentRepositoryImpl) GetAll(request dtos.SegmentGetRequest) ([]*dtos.SegmentResponse, *dtos.PaginationMeta, error) {
+	var segments []models.Segment
+	var total int64
+
+	query := s.db.Model(&models.Segment{})
+	if request.Search != "" {
+		searchPattern := "%" + request.Search + "%"
+		query = query.Where("name ILIKE ? OR description ILIKE ?", searchPattern, searchPattern)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to count segments: %w", err)
+	}
+
+	offset := (request.Page - 1) * request.Limit
+	if err := query.Order("name asc").Offset(offset).Limit(request.Limit).Find(&segments).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch segments: %w", err)
+	}
+
+	segmentResponses := make([]*dtos.SegmentResponse, len(segments))
+	for i, segment := range segments {
+		response, err := toSegmentResponse(segment)
+		if err != nil {
+			return nil, nil, err
+		}
+		segmentResponses[i] = response
+	}
+
+	paginationMeta := &dtos.PaginationMeta{
+		Page:       request.Page,
+		Limit:      request.Limit,
+		Total:      int(total),
+		TotalPages: int(math.Ceil(float64(total) / float64(request.Limit))),
+	}
+
+	return segmentResponses, paginationMeta, nil
+}
+
+// GetByID implements SegmentRepository.
+func (s *segmentRepositoryImpl) GetByID(uuid string) (*dtos.SegmentResponse, error) {
+	var segment models.Segment
+	if err := s.db.Where("uuid = ?", uuid).First(&segment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%s", "segment not found")
+		}
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+	return toSegmentResponse(segment)
+}
+
+// Update implements SegmentRepository.
+func (s *segmentRepositoryImpl) Update(request dtos.SegmentUpdateRequest) (*dtos.SegmentResponse, error) {
+	var segment models.Segment
+	if err := s.db.Where("uuid = ?", request.UUID).First(&segment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%s", "segment not found")
+		}
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+
+	if request.Name != "" && request.Name != segment.Name {
+		var existing models.Segment
+		if err := s.db.Unscoped().Where("name = ?", request.Name).First(&existing).Error; err == nil {
+			return nil, fmt.Errorf("segment with name %s already exists", request.Name)
+		}
+		segment.Name = request.Name
+	}
+	if request.Description != "" {
+		segment.Description = request.Description
+	}
+	if request.Filter != nil {
+		filter, err := json.Marshal(request.Filter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid segment filter: %w", err)
+		}
+		segment.Filter = string(filter)
+	}
+
+	if err := s.db.Save(&segment).Error; err != nil {
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+
+	return toSegmentResponse(segment)
+}
+
+// Delete implements SegmentRepository.
+func (s *segmentRepositoryImpl) Delete(uuid string) error {
+	var segment models.Segment
+	if err := s.db.Where("uuid = ?", uuid).First(&segment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%s", "segment not found")
+		}
+		return fmt.Errorf("%s", "please try again later")
+	}
+	if err := s.db.Delete(&segment).Error; err != nil {
+		return fmt.Errorf("%s", "please try again later")
+	}
+	return nil
+}
+
+func toSegmentResponse(segment models.Segment) (*dtos.SegmentResponse, error) {
+	var filter dtos.SegmentFilter
+	if err := json.Unmarshal([]byte(segment.Filter), &filter); err != nil {
+		return nil, fmt.Errorf("invalid filter stored for segment %s: %w", segment.UUID, err)
+	}
+
+	return &dtos.SegmentResponse{
+		UUID:        segment.UUID,
+		Name:        segment.Name,
+		Description: segment.Description,
+		Filter:      filter,
+		CreatedAt:   segment.CreatedAt,
+		UpdatedAt:   segment.UpdatedAt,
+	}, nil
+}
+
+func NewSegmentRepository(db *gorm.DB) SegmentRepository {
+	return &segmentRepositoryImpl{db: db}
+}
@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/models"
+)
+
+type SessionRepository interface {
+	Create(session *models.UserSession) error
+	GetActive(userUUID string) ([]models.UserSession, error)
+	Touch(family string, client dtos.SessionClient, expiresAt time.Time) error
+	Revoke(userUUID string, uuid string) (family string, err error)
+	RevokeFamily(family string) error
+	RevokeAll(userUUID string) (families []string, err error)
+}
+
+type sessionRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// Create implements SessionRepository.
+func (r *sessionRepositoryImpl) Create(session *models.UserSession) error {
+	if err := r.db.Create(session).Error; err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	return nil
+}
+
+// GetActive implements SessionRepository. The most recently used session comes first.
+func (r *sessionRepositoryImpl) GetActive(userUUID string) ([]models.UserSession, error) {
+	var sessions []models.UserSession
+	if err := r.db.Scopes(activeSessions).
+		Where("user_uuid = ?", userUUID).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// Touch implements SessionRepository. It records a refresh of the session's tokens, which
+// extends the session to the expiry of the new refresh token.
+func (r *sessionRepositoryImpl) Touch(family string, client dtos.SessionClient, expiresAt time.Time) error {
+	if err := r.db.Model(&models.UserSession{}).
+		Where("family = ? AND revoked_at IS NULL", family).
+		Updates(map[string]interface{}{
+			"last_seen_at": time.Now(),
+			"expires_at":   expiresAt,
+			"user_agent":   client.UserAgent,
+			"ip_address":   client.IPAddress,
+		}).Error; err != nil {
+		return fmt.Errorf("failed to update session: %w", err)
+	}
+	return nil
+}
+
+// Revoke implements SessionRepository. Only the user's own active sessions can be revoked.
+func (r *sessionRepositoryImpl) Revoke(userUUID string, uuid string) (string, error) {
+	var session models.UserSession
+	if err := r.db.Scopes(activeSessions).
+		Where("uuid = ? AND user_uuid = ?", uuid, userUUID).
+		First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", fmt.Errorf("session not found")
+		}
+		return "", fmt.Errorf("failed to fetch session: %w", err)
+	}
+
+	if err := r.RevokeFamily(session.Family); err != nil {
+		return "", err
+	}
+	return session.Family, nil
+}
+
+// RevokeFamily implements SessionRepository.
+func (r *sessionRepositoryImpl) RevokeFamily(family string) error {
+	if err := r.db.Model(&models.UserSession{}).
+		Where("family = ? AND revoked_at IS NULL", family).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
+
+// RevokeAll implements SessionRepository and returns the token families of the revoked sessions.
+func (r *sessionRepositoryImpl) RevokeAll(userUUID string) ([]string, error) {
+	var families []string
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.UserSession{}).Scopes(activeSessions).
+			Where("user_uuid = ?", userUUID).
+			Pluck("family", &families).Error; err != nil {
+			return fmt.Errorf("failed to fetch sessions: %w", err)
+		}
+		if len(families) == 0 {
+			return nil
+		}
+
+		if err := tx.Model(&models.UserSession{}).
+			Where("family IN ?", families).
+			Update("revoked_at", time.Now()).Error; err != nil {
+			return fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return families, nil
+}
+
+// activeSessions limits a query to sessions that are neither revoked nor expired
+func activeSessions(db *gorm.DB) *gorm.DB {
+	return db.Where("revoked_at IS NULL AND expires_at > ?", time.Now())
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepositoryImpl{db: db}
+}
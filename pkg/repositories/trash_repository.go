@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"alfredo/ruu-properties/pkg/dtos"
+)
+
+type TrashRepository interface {
+	Purge(before time.Time) ([]*dtos.TrashPurgeResult, []string, error)
+}
+
+type trashReference struct {
+	table  string
+	column string
+	// file names the column holding the path of an uploaded file the row owns
+	file string
+}
+
+// trashTables are hard-deleted by the purge job, in this order. Rows in owned tables are
+// removed together with the row they belong to, while a row still pointed at by a kept
+// reference, e.g. a deposit held for a deleted client, stays in the trash.
+var trashTables = []struct {
+	table string
+	owned []trashReference
+	kept  []trashReference
+}{
+	{
+		table: "features",
+		owned: []trashReference{{table: "property_features", column: "feature_uuid"}},
+	},
+	{
+		table: "properties",
+		owned: []trashReference{
+			{table: "property_photos", column: "property_uuid"},
+			{table: "property_features", column: "property_uuid"},
+		},
+		kept: []trashReference{{table: "deposits", column: "property_uuid"}},
+	},
+	{
+		table: "clients",
+		owned: []trashReference{
+			{table: "client_tags", column: "client_uuid"},
+			{table: "client_activities", column: "client_uuid"},
+			{table: "client_documents", column: "client_uuid", file: "file_path"},
+			{table: "client_contacts", column: "client_uuid"},
+		},
+		kept: []trashReference{
+			{table: "deposits", column: "client_uuid"},
+			{table: "properties", column: "owner_client_uuid"},
+		},
+	},
+}
+
+type trashRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// Purge implements TrashRepository. It returns the uploaded files of the purged rows, for the
+// caller to remove once the rows are gone. Tables purged before a failure stay purged, so their
+// files are returned along with the error.
+func (r *trashRepositoryImpl) Purge(before time.Time) ([]*dtos.TrashPurgeResult, []string, error) {
+	results := []*dtos.TrashPurgeResult{}
+	files := []string{}
+	for _, target := range trashTables {
+		if !r.db.Migrator().HasTable(target.table) {
+			continue
+		}
+
+		result := &dtos.TrashPurgeResult{Table: target.table}
+		var purgedFiles []string
+		err := r.db.Transaction(func(tx *gorm.DB) error {
+			expired := tx.Table(target.table).Where("deleted_at IS NOT NULL AND deleted_at < ?", before)
+
+			var total int64
+			if err := expired.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+				return err
+			}
+
+			purgeable := expired.Session(&gorm.Session{})
+			for _, reference := range target.kept {
+				if !tx.Migrator().HasColumn(reference.table, reference.column) {
+					continue
+				}
+				purgeable = purgeable.Where(fmt.Sprintf("NOT EXISTS (SELECT 1 FROM %s WHERE %s.%s = %s.uuid)",
+					reference.table, reference.table, reference.column, target.table))
+			}
+
+			var uuids []string
+			if err := purgeable.Pluck("uuid", &uuids).Error; err != nil {
+				return err
+			}
+			result.Skipped = total - int64(len(uuids))
+			if len(uuids) == 0 {
+				return nil
+			}
+
+			for _, reference := range target.owned {
+				if !tx.Migrator().HasColumn(reference.table, reference.column) {
+					continue
+				}
+				if reference.file != "" {
+					var paths []string
+					if err := tx.Table(reference.table).Where(fmt.Sprintf("%s IN ?", reference.column), uuids).
+						Pluck(reference.file, &paths).Error; err != nil {
+						return err
+					}
+					purgedFiles = append(purgedFiles, paths...)
+				}
+				query := fmt.Sprintf("DELETE FROM %s WHERE %s IN ?", reference.table, reference.column)
+				if err := tx.Exec(query, uuids).Error; err != nil {
+					return err
+				}
+			}
+
+			deleted := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE uuid IN ?", target.table), uuids)
+			if deleted.Error != nil {
+				return deleted.Error
+			}
+			result.Purged = deleted.RowsAffected
+			return nil
+		})
+		if err != nil {
+			return results, files, fmt.Errorf("failed to purge %s: %w", target.table, err)
+		}
+
+		results = append(results, result)
+		files = append(files, purgedFiles...)
+	}
+
+	return results, files, nil
+}
+
+func NewTrashRepository(db *gorm.DB) TrashRepository {
+	return &trashRepositoryImpl{db: db}
+}
@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"alfredo/ruu-properties/pkg/models"
+)
+
+type TwoFactorRepository interface {
+	SetSecret(userUuid string, secret string) error
+	Enable(userUuid string, codeHashes []string) error
+	Disable(userUuid string) error
+	ReplaceRecoveryCodes(userUuid string, codeHashes []string) error
+	UseRecoveryCode(userUuid string, codeHash string) (bool, error)
+	CountRecoveryCodes(userUuid string) (int64, error)
+}
+
+type twoFactorRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// SetSecret implements TwoFactorRepository. It stores the secret of an enrollment that is not
+// confirmed yet.
+func (r *twoFactorRepositoryImpl) SetSecret(userUuid string, secret string) error {
+	result := r.db.Model(&models.User{}).
+		Where("uuid = ? AND two_factor_enabled_at IS NULL", userUuid).
+		Update("two_factor_secret", secret)
+	if result.Error != nil {
+		return fmt.Errorf("failed to store two-factor secret: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("two-factor authentication is already enabled")
+	}
+	return nil
+}
+
+// Enable implements TwoFactorRepository. The confirmed secret starts counting along with a fresh
+// set of recovery codes.
+func (r *twoFactorRepositoryImpl) Enable(userUuid string, codeHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.User{}).
+			Where("uuid = ? AND two_factor_enabled_at IS NULL", userUuid).
+			Update("two_factor_enabled_at", time.Now())
+		if result.Error != nil {
+			return fmt.Errorf("failed to enable two-factor authentication: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("two-factor authentication is already enabled")
+		}
+
+		return replaceRecoveryCodes(tx, userUuid, codeHashes)
+	})
+}
+
+// Disable implements TwoFactorRepository.
+func (r *twoFactorRepositoryImpl) Disable(userUuid string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("uuid = ?", userUuid).Updates(map[string]interface{}{
+			"two_factor_secret":     "",
+			"two_factor_enabled_at": nil,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to disable two-factor authentication: %w", err)
+		}
+
+		if err := tx.Where("user_uuid = ?", userUuid).Delete(&models.UserRecoveryCode{}).Error; err != nil {
+			return fmt.Errorf("failed to delete recovery codes: %w", err)
+		}
+		return nil
+	})
+}
+
+// ReplaceRecoveryCodes implements TwoFactorRepository.
+func (r *twoFactorRepositoryImpl) ReplaceRecoveryCodes(userUuid string, codeHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userUuid, codeHashes)
+	})
+}
+
+// UseRecoveryCode implements TwoFactorRepository. It reports false for unknown and used codes;
+// of two racing uses of a code only one succeeds.
+func (r *twoFactorRepositoryImpl) UseRecoveryCode(userUuid string, codeHash string) (bool, error) {
+	result := r.db.Model(&models.UserRecoveryCode{}).
+		Where("user_uuid = ? AND code_hash = ? AND used_at IS NULL", userUuid, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CountRecoveryCodes implements TwoFactorRepository. Only unused codes are counted.
+func (r *twoFactorRepositoryImpl) CountRecoveryCodes(userUuid string) (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.UserRecoveryCode{}).
+		Where("user_uuid = ? AND used_at IS NULL", userUuid).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+	return count, nil
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userUuid string, codeHashes []string) error {
+	if err := tx.Where("user_uuid = ?", userUuid).Delete(&models.UserRecoveryCode{}).Error; err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	codes := make([]models.UserRecoveryCode, len(codeHashes))
+	for i, hash := range codeHashes {
+		codes[i] = models.UserRecoveryCode{UserUUID: userUuid, CodeHash: hash}
+	}
+	if err := tx.Create(&codes).Error; err != nil {
+		return fmt.Errorf("failed to create recovery codes: %w", err)
+	}
+	return nil
+}
+
+func NewTwoFactorRepository(db *gorm.DB) TwoFactorRepository {
+	return &twoFactorRepositoryImpl{db: db}
+}
@@ -3,6 +3,8 @@ package repositories
 import (
 	"errors"
 	"fmt"
+	"math"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -16,6 +18,15 @@ type UserRepository interface {
 	Register(request dtos.UserRegisterRequest) error
 	FindUserByEmail(email string) (models.User, error)
 	FindUserByUuid(uuid string) (models.User, error)
+	SetTeam(request dtos.UserTeamRequest) (models.User, error)
+	SetRole(request dtos.UserRoleRequest) (models.User, error)
+	CountByRole(role string) (int64, error)
+	GetAll(request dtos.UserGetRequest) ([]*dtos.UserResponse, *dtos.PaginationMeta, error)
+	Create(request dtos.UserCreateRequest) (*dtos.UserResponse, error)
+	SetDeactivatedAt(uuid string, deactivatedAt *time.Time) (*dtos.UserResponse, error)
+	UpdatePassword(uuid string, password string) error
+	Delete(uuid string) error
+	MarkEmailVerified(uuid string) error
 }
 
 type userRepositoryImpl struct {
@@ -35,6 +46,41 @@ func (u *userRepositoryImpl) FindUserByUuid(uuid string) (models.User, error) {
 	return user, nil
 }
 
+// SetTeam implements UserRepository. An empty team takes the user out of every team.
+func (u *userRepositoryImpl) SetTeam(request dtos.UserTeamRequest) (models.User, error) {
+	user, err := u.FindUserByUuid(request.UUID)
+	if err != nil {
+		return user, err
+	}
+
+	if err := u.db.Model(&user).Update("team", request.Team).Error; err != nil {
+		return user, fmt.Errorf("failed to update team: %w", err)
+	}
+	return user, nil
+}
+
+// SetRole implements UserRepository.
+func (u *userRepositoryImpl) SetRole(request dtos.UserRoleRequest) (models.User, error) {
+	user, err := u.FindUserByUuid(request.UUID)
+	if err != nil {
+		return user, err
+	}
+
+	if err := u.db.Model(&user).Update("role", request.Role).Error; err != nil {
+		return user, fmt.Errorf("failed to update role: %w", err)
+	}
+	return user, nil
+}
+
+// CountByRole implements UserRepository. Deactivated users are not counted.
+func (u *userRepositoryImpl) CountByRole(role string) (int64, error) {
+	var count int64
+	if err := u.db.Model(&models.User{}).Where("role = ? AND deactivated_at IS NULL", role).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count users: %w", err)
+	}
+	return count, nil
+}
+
 func (u *userRepositoryImpl) FindUserByEmail(email string) (models.User, error) {
 	var user models.User
 	if err := u.db.Unscoped().Where("email = ?", email).First(&user).Error; err != nil {
@@ -47,39 +93,191 @@ func (u *userRepositoryImpl) FindUserByEmail(email string) (models.User, error)
 }
 
 func (u *userRepositoryImpl) Register(request dtos.UserRegisterRequest) error {
+	_, err := u.create(models.User{
+		Name:        request.Name,
+		Email:       request.Email,
+		Password:    request.Password,
+		PhoneNumber: request.PhoneNumber,
+		Role:        request.Role,
+		Image:       request.Image,
+	})
+	return err
+}
+
+// Create implements UserRepository.
+func (u *userRepositoryImpl) Create(request dtos.UserCreateRequest) (*dtos.UserResponse, error) {
+	user, err := u.create(models.User{
+		Name:        request.Name,
+		Email:       request.Email,
+		Password:    request.Password,
+		PhoneNumber: request.PhoneNumber,
+		Role:        request.Role,
+		Team:        request.Team,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toUserResponse(user), nil
+}
+
+// create stores a new user, hashing the plain password it is given
+func (u *userRepositoryImpl) create(user models.User) (models.User, error) {
 	var existingUser models.User
 	// Check if email already exists
-	if err := u.db.Unscoped().Where("email = ?", request.Email).First(&existingUser).Error; err == nil {
-		return fmt.Errorf("%s", "Email already exists on database")
+	if err := u.db.Unscoped().Where("email = ?", user.Email).First(&existingUser).Error; err == nil {
+		return user, fmt.Errorf("%s", "Email already exists on database")
 	}
 
 	// Check if phone number already exists
-	if err := u.db.Unscoped().Where("phone_number = ?", request.PhoneNumber).First(&existingUser).Error; err == nil {
-		return fmt.Errorf("%s", "Phone number already exists on database")
+	if err := u.db.Unscoped().Where("phone_number = ?", user.PhoneNumber).First(&existingUser).Error; err == nil {
+		return user, fmt.Errorf("%s", "Phone number already exists on database")
 	}
 
 	// Hash the password using argon2
-	hashedPassword, err := helpers.HashPassword(request.Password)
+	hashedPassword, err := helpers.HashPassword(user.Password)
 	if err != nil {
-		return fmt.Errorf("failed to hash password: %w", err)
+		return user, fmt.Errorf("failed to hash password: %w", err)
 	}
+	user.Password = hashedPassword
 
-	return u.db.Transaction(func(tx *gorm.DB) error {
-		user := models.User{
-			Name:        request.Name,
-			Email:       request.Email,
-			Password:    hashedPassword,
-			PhoneNumber: request.PhoneNumber,
-			Role:        request.Role,
-			Image:       request.Image,
-		}
-
+	err = u.db.Transaction(func(tx *gorm.DB) error {
 		if err := tx.Create(&user).Error; err != nil {
 			return fmt.Errorf("failed to create user: %w", err)
 		}
 
 		return nil
 	})
+	return user, err
+}
+
+// GetAll implements UserRepository.
+func (u *userRepositoryImpl) GetAll(request dtos.UserGetRequest) ([]*dtos.UserResponse, *dtos.PaginationMeta, error) {
+	if request.SortBy == "" {
+		request.SortBy = "created_at"
+	}
+	if request.SortOrder != "asc" && request.SortOrder != "desc" {
+		request.SortOrder = "desc"
+	}
+
+	var users []models.User
+	var total int64
+
+	query := u.db.Model(&models.User{})
+	if request.Search != "" {
+		searchPattern := "%" + request.Search + "%"
+		query = query.Where("name ILIKE ? OR email ILIKE ? OR phone_number ILIKE ?", searchPattern, searchPattern, searchPattern)
+	}
+	if request.Role != "" {
+		query = query.Where("role = ?", request.Role)
+	}
+	switch request.Status {
+	case dtos.UserStatusActive:
+		query = query.Where("deactivated_at IS NULL")
+	case dtos.UserStatusDeactivated:
+		query = query.Where("deactivated_at IS NOT NULL")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	offset := (request.Page - 1) * request.Limit
+	sortClause := fmt.Sprintf("%s %s", request.SortBy, request.SortOrder)
+	if err := query.Order(sortClause).Offset(offset).Limit(request.Limit).Find(&users).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch users: %w", err)
+	}
+
+	userResponses := make([]*dtos.UserResponse, len(users))
+	for i, user := range users {
+		userResponses[i] = toUserResponse(user)
+	}
+
+	paginationMeta := &dtos.PaginationMeta{
+		Page:       request.Page,
+		Limit:      request.Limit,
+		Total:      int(total),
+		TotalPages: int(math.Ceil(float64(total) / float64(request.Limit))),
+	}
+
+	return userResponses, paginationMeta, nil
+}
+
+// SetDeactivatedAt implements UserRepository. A nil time reactivates the user.
+func (u *userRepositoryImpl) SetDeactivatedAt(uuid string, deactivatedAt *time.Time) (*dtos.UserResponse, error) {
+	user, err := u.FindUserByUuid(uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.db.Model(&user).Update("deactivated_at", deactivatedAt).Error; err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+	user.DeactivatedAt = deactivatedAt
+	return toUserResponse(user), nil
+}
+
+// UpdatePassword implements UserRepository. The password is hashed before it is stored.
+func (u *userRepositoryImpl) UpdatePassword(uuid string, password string) error {
+	hashedPassword, err := helpers.HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	result := u.db.Model(&models.User{}).Where("uuid = ?", uuid).Update("password", hashedPassword)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update password: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+	return nil
+}
+
+// Delete implements UserRepository. Users are soft-deleted, their email and phone number stay
+// taken.
+func (u *userRepositoryImpl) Delete(uuid string) error {
+	result := u.db.Where("uuid = ?", uuid).Delete(&models.User{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete user: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+	return nil
+}
+
+// MarkEmailVerified implements UserRepository. An address verified before keeps its time.
+func (u *userRepositoryImpl) MarkEmailVerified(uuid string) error {
+	user, err := u.FindUserByUuid(uuid)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	if err := u.db.Model(&user).Update("email_verified_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to verify email: %w", err)
+	}
+	return nil
+}
+
+// toUserResponse maps a user without its password hash
+func toUserResponse(user models.User) *dtos.UserResponse {
+	return &dtos.UserResponse{
+		UUID:            user.UUID,
+		Name:            user.Name,
+		Email:           user.Email,
+		PhoneNumber:     user.PhoneNumber,
+		Image:           user.Image,
+		Role:            user.Role,
+		Team:            user.Team,
+		Active:          user.DeactivatedAt == nil,
+		DeactivatedAt:   user.DeactivatedAt,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+	}
 }
 
 func NewUserRepository(db *gorm.DB) UserRepository {
@@ -5,6 +5,7 @@ import (
 	"github.com/gofiber/swagger"
 
 	"alfredo/ruu-properties/config"
+	"alfredo/ruu-properties/pkg/helpers"
 	"alfredo/ruu-properties/pkg/injectors"
 
 	_ "alfredo/ruu-properties/docs" // Import ini penting untuk swagger
@@ -22,6 +23,8 @@ func InitializeRouterV1(server *config.Application) {
 		Layout:       "BaseLayout",
 		DocExpansion: "none",
 	}))
+	serveIcons(server.App)
+
 	api := server.App.Group("/api")
 	{
 		v1 := api.Group("/v1")
@@ -65,6 +68,24 @@ func InitializeRouterV1(server *config.Application) {
 				featureController.Router(feature)
 			}
 
+			deposit := v1.Group("/deposits")
+			{
+				depositController := injectors.InitializeDepositController()
+				depositController.Router(deposit)
+			}
+
+			segment := v1.Group("/segments")
+			{
+				segmentController := injectors.InitializeSegmentController()
+				segmentController.Router(segment)
+			}
+
+			role := v1.Group("/roles")
+			{
+				roleController := injectors.InitializeRoleController()
+				roleController.Router(role)
+			}
+
 		}
 
 	}
@@ -72,6 +93,8 @@ func InitializeRouterV1(server *config.Application) {
 
 // SetupRoutes sets up all routes for testing purposes
 func SetupRoutes(app *fiber.App) {
+	serveIcons(app)
+
 	api := app.Group("/api")
 	{
 		v1 := api.Group("/v1")
@@ -106,6 +129,38 @@ func SetupRoutes(app *fiber.App) {
 				featureController := injectors.InitializeFeatureController()
 				featureController.Router(feature)
 			}
+
+			deposit := v1.Group("/deposits")
+			{
+				depositController := injectors.InitializeDepositController()
+				depositController.Router(deposit)
+			}
+
+			segment := v1.Group("/segments")
+			{
+				segmentController := injectors.InitializeSegmentController()
+				segmentController.Router(segment)
+			}
+
+			role := v1.Group("/roles")
+			{
+				roleController := injectors.InitializeRoleController()
+				roleController.Router(role)
+			}
 		}
 	}
 }
+
+// serveIcons serves the feature icons shown on the public site. Only the icon directory is
+// served, the rest of the uploads holds client documents and deposit photos.
+func serveIcons(app *fiber.App) {
+	app.Static(helpers.IconURLPath, helpers.IconDirectory, fiber.Static{
+		MaxAge: 86400,
+		ModifyResponse: func(c *fiber.Ctx) error {
+			// SVG icons are opened as documents by browsers, they must not run anything
+			c.Set(fiber.HeaderContentSecurityPolicy, "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+			c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+			return nil
+		},
+	})
+}
@@ -1,29 +1,69 @@
 package services
 
 import (
+	"errors"
 	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
+
+	"alfredo/ruu-properties/config"
 	"alfredo/ruu-properties/pkg/dtos"
 	"alfredo/ruu-properties/pkg/helpers"
+	"alfredo/ruu-properties/pkg/models"
 	"alfredo/ruu-properties/pkg/repositories"
 )
 
+var (
+	// ErrAccountDeactivated is returned when a deactivated user signs in
+	ErrAccountDeactivated = errors.New("account is deactivated")
+	// ErrEmailNotVerified is returned when verification is required and the user has not verified
+	ErrEmailNotVerified = errors.New("email address is not verified")
+	// ErrInvalidResetToken is returned for unknown, used and expired password reset tokens alike
+	ErrInvalidResetToken = errors.New("invalid or expired reset token")
+)
+
+const (
+	// passwordResetKey maps the hash of a reset token to the user it resets
+	passwordResetKey = "password_reset:"
+	// passwordResetUserKey holds the hash of the latest reset token of a user
+	passwordResetUserKey = "password_reset_user:"
+	// passwordResetUsedKey marks a reset token as consumed
+	passwordResetUsedKey = "password_reset_used:"
+
+	defaultPasswordResetTtlMinutes = 30
+)
+
 type AuthService interface {
-	Login(request dtos.LoginRequest) (response dtos.LoginResponse, err error)
+	Login(request dtos.LoginRequest, client dtos.SessionClient) (response dtos.LoginResponse, err error)
+	RefreshToken(request dtos.RefreshTokenRequest, client dtos.SessionClient) (response dtos.GenerateTokenResponse, err error)
+	Logout(request dtos.LogoutRequest) error
+	LogoutAll(userUuid string) error
+	ForgotPassword(request dtos.ForgotPasswordRequest) error
+	ResetPassword(request dtos.ResetPasswordRequest) error
+	VerifyTwoFactor(request dtos.TwoFactorVerifyRequest, client dtos.SessionClient) (response dtos.LoginResponse, err error)
 }
 
 type authServiceImpl struct {
-	jwtService     JwtService
-	userService    UserService
-	userRepository repositories.UserRepository
-	redisService   RedisService
+	jwtService       JwtService
+	userService      UserService
+	userRepository   repositories.UserRepository
+	redisService     RedisService
+	sessionService   SessionService
+	mailer           Mailer
+	twoFactorService TwoFactorService
 }
 
-func (a *authServiceImpl) Login(request dtos.LoginRequest) (response dtos.LoginResponse, err error) {
-	// Find user by email
+func (a *authServiceImpl) Login(request dtos.LoginRequest, client dtos.SessionClient) (response dtos.LoginResponse, err error) {
+	// Find user by email. Unknown emails get the answer and the hashing time of a wrong
+	// password, so the response does not tell which emails are registered.
 	user, err := a.userRepository.FindUserByEmail(request.Email)
-	if err != nil {
-		return response, fmt.Errorf("user not found")
+	if err != nil || user.DeletedAt.Valid {
+		_, _ = helpers.CheckPasswordHashWithArgon2(request.Password, dummyPasswordHash())
+		return response, fmt.Errorf("invalid email or password")
 	}
 
 	// Check if the password same with the hash password
@@ -31,12 +71,64 @@ func (a *authServiceImpl) Login(request dtos.LoginRequest) (response dtos.LoginR
 		return response, fmt.Errorf("invalid email or password")
 	}
 
+	if user.DeactivatedAt != nil {
+		return response, ErrAccountDeactivated
+	}
+
+	if user.EmailVerifiedAt == nil && emailVerificationRequired() {
+		return response, ErrEmailNotVerified
+	}
+
+	// The password alone is not enough, the login waits for the second factor
+	if user.TwoFactorEnabledAt != nil {
+		challenge, err := a.twoFactorService.CreateChallenge(user, request.Device)
+		if err != nil {
+			return response, err
+		}
+		return dtos.LoginResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    challenge,
+			Email:             user.Email,
+			UserUuid:          user.UUID,
+			Name:              user.Name,
+		}, nil
+	}
+
+	return a.issueLogin(user, request.Device, client)
+}
+
+// VerifyTwoFactor completes a login that was held for its second factor
+func (a *authServiceImpl) VerifyTwoFactor(request dtos.TwoFactorVerifyRequest, client dtos.SessionClient) (response dtos.LoginResponse, err error) {
+	challenge, err := a.twoFactorService.CompleteChallenge(request)
+	if err != nil {
+		return response, err
+	}
+
+	// The account may have changed since the password was checked
+	user, err := a.userRepository.FindUserByUuid(challenge.UserUuid)
+	if err != nil || user.DeletedAt.Valid {
+		return response, ErrInvalidChallenge
+	}
+	if user.DeactivatedAt != nil {
+		return response, ErrAccountDeactivated
+	}
+
+	return a.issueLogin(user, challenge.Device, client)
+}
+
+// issueLogin starts a session for the user and hands out its token pair
+func (a *authServiceImpl) issueLogin(user models.User, device string, client dtos.SessionClient) (response dtos.LoginResponse, err error) {
 	generateToken := helpers.GenerateToken(32)
 	token, err := a.jwtService.GenerateToken(user.UUID, generateToken)
 	if err != nil {
 		return response, fmt.Errorf("failed to generate token")
 	}
 
+	// Every login is a session of its own, identified by the token family
+	if err := a.sessionService.Start(user.UUID, generateToken, device, client, token.RefreshToken); err != nil {
+		return response, fmt.Errorf("failed to generate token")
+	}
+
 	return dtos.LoginResponse{
 		TokenType:    "Bearer",
 		ExpiresIn:    int64(token.ExpiresIn),
@@ -48,16 +140,209 @@ func (a *authServiceImpl) Login(request dtos.LoginRequest) (response dtos.LoginR
 	}, nil
 }
 
+// RefreshToken rotates a refresh token into a new token pair of the same family
+func (a *authServiceImpl) RefreshToken(request dtos.RefreshTokenRequest, client dtos.SessionClient) (response dtos.GenerateTokenResponse, err error) {
+	userUuid, family, err := a.jwtService.ConsumeRefreshToken(request.RefreshToken)
+	if err != nil {
+		return response, err
+	}
+
+	// Deleted and deactivated users keep no session
+	if user, err := a.userRepository.FindUserByUuid(userUuid); err != nil || user.DeactivatedAt != nil {
+		if err := a.jwtService.RevokeFamily(family); err != nil {
+			return response, fmt.Errorf("failed to generate token")
+		}
+		return response, ErrInvalidToken
+	}
+
+	response, err = a.jwtService.GenerateToken(userUuid, family)
+	if err != nil {
+		return response, fmt.Errorf("failed to generate token")
+	}
+
+	if err := a.sessionService.Refresh(family, client, response.RefreshToken); err != nil {
+		return response, fmt.Errorf("failed to generate token")
+	}
+
+	return response, nil
+}
+
+// Logout revokes the access token of the request and, when given, the refresh token of the
+// same login so it cannot be rotated into a new session. The session of the login ends as well.
+func (a *authServiceImpl) Logout(request dtos.LogoutRequest) error {
+	if err := a.jwtService.Revoke(request.AccessToken); err != nil {
+		return fmt.Errorf("failed to logout")
+	}
+
+	if err := a.sessionService.End(request.AccessToken); err != nil {
+		return fmt.Errorf("failed to logout")
+	}
+
+	if request.RefreshToken == "" {
+		return nil
+	}
+
+	userUuid, err := a.jwtService.GetUserIdFromToken(request.RefreshToken)
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		// Nothing left to revoke
+		return nil
+	}
+	if err != nil || userUuid != request.UserUuid {
+		return ErrInvalidToken
+	}
+
+	if err := a.jwtService.Revoke(request.RefreshToken); err != nil {
+		return fmt.Errorf("failed to logout")
+	}
+
+	return nil
+}
+
+// LogoutAll revokes every session and token of the user, signing them out on every device
+func (a *authServiceImpl) LogoutAll(userUuid string) error {
+	if _, err := a.sessionService.RevokeAll(userUuid); err != nil {
+		return fmt.Errorf("failed to logout")
+	}
+
+	return nil
+}
+
+// ForgotPassword mails a reset link to the user of the email. Unknown, deleted and deactivated
+// accounts are skipped silently, and the link is created and sent in the background, so the
+// caller cannot tell which emails are registered, not even from the response time.
+func (a *authServiceImpl) ForgotPassword(request dtos.ForgotPasswordRequest) error {
+	user, err := a.userRepository.FindUserByEmail(request.Email)
+	if err != nil || user.DeletedAt.Valid || user.DeactivatedAt != nil {
+		return nil
+	}
+
+	go a.sendPasswordReset(user.UUID, user.Email)
+	return nil
+}
+
+// sendPasswordReset creates a reset token for the user and mails its link. Failures are only
+// logged, reporting them would reveal that the account exists.
+func (a *authServiceImpl) sendPasswordReset(userUuid string, email string) {
+	token, err := helpers.GenerateSecureToken(32)
+	if err != nil {
+		log.Println("Error while creating the password reset token", "error", err)
+		return
+	}
+	hash := helpers.HashToken(token)
+	ttl := passwordResetTtl()
+
+	// Only the latest link of a user works
+	if previous, err := a.redisService.Get(passwordResetUserKey + userUuid); err == nil && previous != "" {
+		if err := a.redisService.Delete(passwordResetKey + previous); err != nil {
+			log.Println("Error while replacing the password reset token", "error", err)
+			return
+		}
+	}
+	if err := a.redisService.SetWithExpiration(passwordResetKey+hash, userUuid, ttl); err != nil {
+		log.Println("Error while storing the password reset token", "error", err)
+		return
+	}
+	if err := a.redisService.SetWithExpiration(passwordResetUserKey+userUuid, hash, ttl); err != nil {
+		log.Println("Error while storing the password reset token", "error", err)
+		return
+	}
+
+	if err := a.mailer.Send(passwordResetMail(email, token, ttl)); err != nil {
+		log.Println("Error while sending the password reset mail", "error", err)
+	}
+}
+
+// ResetPassword sets a new password with a reset token. The token works once, and every
+// session of the user ends.
+func (a *authServiceImpl) ResetPassword(request dtos.ResetPasswordRequest) error {
+	hash := helpers.HashToken(request.Token)
+
+	userUuid, err := a.redisService.Get(passwordResetKey + hash)
+	if err != nil || userUuid == "" {
+		return ErrInvalidResetToken
+	}
+
+	// Two requests racing with the same token: only the first one resets the password
+	claimed, err := a.redisService.SetNX(passwordResetUsedKey+hash, userUuid, passwordResetTtl())
+	if err != nil {
+		return fmt.Errorf("%s", "please try again later")
+	}
+	if !claimed {
+		return ErrInvalidResetToken
+	}
+	if err := a.redisService.Delete(passwordResetKey + hash); err != nil {
+		return fmt.Errorf("%s", "please try again later")
+	}
+	if err := a.redisService.Delete(passwordResetUserKey + userUuid); err != nil {
+		return fmt.Errorf("%s", "please try again later")
+	}
+
+	if err := a.userRepository.UpdatePassword(userUuid, request.Password); err != nil {
+		if err.Error() == "user not found" {
+			return ErrInvalidResetToken
+		}
+		return fmt.Errorf("%s", "please try again later")
+	}
+
+	if _, err := a.sessionService.RevokeAll(userUuid); err != nil {
+		return fmt.Errorf("%s", "please try again later")
+	}
+	return nil
+}
+
+var (
+	dummyHash     string
+	dummyHashOnce sync.Once
+)
+
+// dummyPasswordHash is checked against for unknown emails, so they take as long as a wrong password
+func dummyPasswordHash() string {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = helpers.HashPassword(helpers.GenerateToken(32))
+	})
+	return dummyHash
+}
+
+// passwordResetTtl reads password_reset.ttl_minutes, falling back to the default when it is
+// unset or not a positive number
+func passwordResetTtl() time.Duration {
+	minutes, err := strconv.Atoi(config.PasswordResetTtl)
+	if err != nil || minutes < 1 {
+		minutes = defaultPasswordResetTtlMinutes
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// passwordResetMail links to the reset page of the frontend
+func passwordResetMail(email string, token string, ttl time.Duration) dtos.MailMessage {
+	return dtos.MailMessage{
+		To:      email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Someone asked to reset the password of your Ruu Properties account.\n\n"+
+				"Use this link within %d minutes to choose a new password:\n%s\n\n"+
+				"If it was not you, ignore this mail, your password stays the same.\n",
+			int(ttl.Minutes()), mailLink(config.PasswordResetUrl, token),
+		),
+	}
+}
+
 func NewAuthService(
 	jwtService JwtService,
 	userService UserService,
 	userRepository repositories.UserRepository,
 	redisService RedisService,
+	sessionService SessionService,
+	mailer Mailer,
+	twoFactorService TwoFactorService,
 ) AuthService {
 	return &authServiceImpl{
-		jwtService:     jwtService,
-		userService:    userService,
-		userRepository: userRepository,
-		redisService:   redisService,
+		jwtService:       jwtService,
+		userService:      userService,
+		userRepository:   userRepository,
+		redisService:     redisService,
+		sessionService:   sessionService,
+		mailer:           mailer,
+		twoFactorService: twoFactorService,
 	}
 }
@@ -0,0 +1,23 @@
+package services
+
+import (
+	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/repositories"
+)
+
+type ChangeLogService interface {
+	GetHistory(request dtos.ChangeLogGetRequest) ([]*dtos.ChangeLogResponse, *dtos.PaginationMeta, error)
+}
+
+type changeLogServiceImpl struct {
+	repo repositories.ChangeLogRepository
+}
+
+// GetHistory implements ChangeLogService.
+func (s *changeLogServiceImpl) GetHistory(request dtos.ChangeLogGetRequest) ([]*dtos.ChangeLogResponse, *dtos.PaginationMeta, error) {
+	return s.repo.GetHistory(request)
+}
+
+func NewChangeLogService(repo repositories.ChangeLogRepository) ChangeLogService {
+	return &changeLogServiceImpl{repo: repo}
+}
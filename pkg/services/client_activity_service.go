@@ -0,0 +1,41 @@
+package services
+
+import (
+	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/repositories"
+)
+
+type ClientActivityService interface {
+	CreateNote(request dtos.ClientNoteRequest) (*dtos.ClientActivityResponse, error)
+	CreateCall(request dtos.ClientCallRequest) (*dtos.ClientActivityResponse, error)
+	CreateEmail(request dtos.ClientEmailRequest) (*dtos.ClientActivityResponse, error)
+	GetTimeline(request dtos.ClientTimelineRequest) ([]*dtos.ClientTimelineItem, *dtos.PaginationMeta, error)
+}
+
+type clientActivityServiceImpl struct {
+	repo repositories.ClientActivityRepository
+}
+
+// CreateNote implements ClientActivityService.
+func (s *clientActivityServiceImpl) CreateNote(request dtos.ClientNoteRequest) (*dtos.ClientActivityResponse, error) {
+	return s.repo.CreateNote(request)
+}
+
+// CreateCall implements ClientActivityService.
+func (s *clientActivityServiceImpl) CreateCall(request dtos.ClientCallRequest) (*dtos.ClientActivityResponse, error) {
+	return s.repo.CreateCall(request)
+}
+
+// CreateEmail implements ClientActivityService.
+func (s *clientActivityServiceImpl) CreateEmail(request dtos.ClientEmailRequest) (*dtos.ClientActivityResponse, error) {
+	return s.repo.CreateEmail(request)
+}
+
+// GetTimeline implements ClientActivityService.
+func (s *clientActivityServiceImpl) GetTimeline(request dtos.ClientTimelineRequest) ([]*dtos.ClientTimelineItem, *dtos.PaginationMeta, error) {
+	return s.repo.GetTimeline(request)
+}
+
+func NewClientActivityService(repo repositories.ClientActivityRepository) ClientActivityService {
+	return &clientActivityServiceImpl{repo: repo}
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"fmt"
+
+	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/helpers"
+	"alfredo/ruu-properties/pkg/models"
+	"alfredo/ruu-properties/pkg/repositories"
+)
+
+type ClientContactService interface {
+	GetByClient(clientUUID string) ([]*dtos.ClientContactResponse, error)
+	Create(request dtos.ClientContactRequest) (*dtos.ClientContactResponse, error)
+	Update(request dtos.ClientContactRequest) (*dtos.ClientContactResponse, error)
+	Delete(clientUUID string, uuid string) error
+}
+
+type clientContactServiceImpl struct {
+	repo repositories.ClientContactRepository
+}
+
+// GetByClient implements ClientContactService.
+func (s *clientContactServiceImpl) GetByClient(clientUUID string) ([]*dtos.ClientContactResponse, error) {
+	return s.repo.GetByClient(clientUUID)
+}
+
+// Create implements ClientContactService.
+func (s *clientContactServiceImpl) Create(request dtos.ClientContactRequest) (*dtos.ClientContactResponse, error) {
+	if err := normalizeClientContact(&request); err != nil {
+		return nil, err
+	}
+	return s.repo.Create(request)
+}
+
+// Update implements ClientContactService.
+func (s *clientContactServiceImpl) Update(request dtos.ClientContactRequest) (*dtos.ClientContactResponse, error) {
+	if err := normalizeClientContact(&request); err != nil {
+		return nil, err
+	}
+	return s.repo.Update(request)
+}
+
+// Delete implements ClientContactService.
+func (s *clientContactServiceImpl) Delete(clientUUID string, uuid string) error {
+	return s.repo.Delete(clientUUID, uuid)
+}
+
+// normalizeClientContact stores the phone number as E.164 and checks the preferred channel
+// can actually reach the contact
+func normalizeClientContact(request *dtos.ClientContactRequest) error {
+	if request.PhoneNumber != "" {
+		phone, err := helpers.NormalizePhoneNumber(request.PhoneNumber, helpers.PhoneDefaultRegion())
+		if err != nil {
+			return err
+		}
+		request.PhoneNumber = phone
+	}
+
+	switch request.PreferredChannel {
+	case models.ClientContactChannelEmail:
+		if request.Email == "" {
+			return fmt.Errorf("%s", "preferred channel email needs an email address")
+		}
+	case models.ClientContactChannelPhone, models.ClientContactChannelWhatsApp:
+		if request.PhoneNumber == "" {
+			return fmt.Errorf("preferred channel %s needs a phone number", request.PreferredChannel)
+		}
+	}
+	return nil
+}
+
+func NewClientContactService(repo repositories.ClientContactRepository) ClientContactService {
+	return &clientContactServiceImpl{repo: repo}
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"alfredo/ruu-properties/config"
+	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/models"
+	"alfredo/ruu-properties/pkg/repositories"
+)
+
+// KycPolicyRequireVerified blocks leases for clients whose KYC is not verified. Any other value
+// of kyc.lease_policy, including none, lets them through.
+const KycPolicyRequireVerified = "require_verified"
+
+var passportNumberPattern = regexp.MustCompile(`^[A-Z0-9]{6,9}$`)
+
+type ClientDocumentService interface {
+	Upload(request dtos.ClientDocumentRequest) (*dtos.ClientDocumentResponse, error)
+	GetByClient(clientUUID string) ([]*dtos.ClientDocumentResponse, error)
+	GetByID(clientUUID string, uuid string) (*dtos.ClientDocumentResponse, error)
+	Review(request dtos.ClientDocumentReviewRequest) (*dtos.ClientDocumentResponse, error)
+	RefreshExpired() (int64, error)
+}
+
+type clientDocumentServiceImpl struct {
+	repo repositories.ClientDocumentRepository
+}
+
+// Upload implements ClientDocumentService.
+func (s *clientDocumentServiceImpl) Upload(request dtos.ClientDocumentRequest) (*dtos.ClientDocumentResponse, error) {
+	number, err := normalizeDocumentNumber(request.Type, request.Number)
+	if err != nil {
+		return nil, err
+	}
+
+	var expiresAt *time.Time
+	if request.ExpiresAt != "" {
+		date, err := time.Parse("2006-01-02", request.ExpiresAt)
+		if err != nil {
+			return nil, fmt.Errorf("%s", "expires_at must be a date formatted as YYYY-MM-DD")
+		}
+		expiresAt = &date
+	}
+
+	document := models.ClientDocument{
+		ClientUUID: request.ClientUUID,
+		Type:       request.Type,
+		Number:     number,
+		ExpiresAt:  expiresAt,
+		FilePath:   request.FilePath,
+		Status:     models.ClientDocumentStatusPending,
+	}
+	if err := s.repo.Create(&document, request.Actor); err != nil {
+		return nil, err
+	}
+	return toClientDocumentResponse(document), nil
+}
+
+// GetByClient implements ClientDocumentService.
+func (s *clientDocumentServiceImpl) GetByClient(clientUUID string) ([]*dtos.ClientDocumentResponse, error) {
+	documents, err := s.repo.GetByClient(clientUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dtos.ClientDocumentResponse, len(documents))
+	for i, document := range documents {
+		responses[i] = toClientDocumentResponse(document)
+	}
+	return responses, nil
+}
+
+// GetByID implements ClientDocumentService.
+func (s *clientDocumentServiceImpl) GetByID(clientUUID string, uuid string) (*dtos.ClientDocumentResponse, error) {
+	document, err := s.repo.FindByUUID(clientUUID, uuid)
+	if err != nil {
+		return nil, err
+	}
+	return toClientDocumentResponse(document), nil
+}
+
+// Review implements ClientDocumentService.
+func (s *clientDocumentServiceImpl) Review(request dtos.ClientDocumentReviewRequest) (*dtos.ClientDocumentResponse, error) {
+	document, err := s.repo.Review(request)
+	if err != nil {
+		return nil, err
+	}
+	return toClientDocumentResponse(document), nil
+}
+
+// RefreshExpired implements ClientDocumentService.
+func (s *clientDocumentServiceImpl) RefreshExpired() (int64, error) {
+	return s.repo.RefreshExpired(time.Now())
+}
+
+// normalizeDocumentNumber strips the separators people type into document numbers and checks
+// the number has the length of its document type
+func normalizeDocumentNumber(documentType string, number string) (string, error) {
+	switch documentType {
+	case models.ClientDocumentTypeKTP:
+		digits := onlyDigits(number)
+		if len(digits) != 16 {
+			return "", fmt.Errorf("%s", "KTP number (NIK) must have 16 digits")
+		}
+		return digits, nil
+	case models.ClientDocumentTypeNPWP:
+		digits := onlyDigits(number)
+		if len(digits) != 15 && len(digits) != 16 {
+			return "", fmt.Errorf("%s", "NPWP number must have 15 or 16 digits")
+		}
+		return digits, nil
+	case models.ClientDocumentTypePassport:
+		passport := strings.ToUpper(strings.ReplaceAll(number, " ", ""))
+		if !passportNumberPattern.MatchString(passport) {
+			return "", fmt.Errorf("%s", "passport number must have 6 to 9 letters or digits")
+		}
+		return passport, nil
+	}
+	return "", fmt.Errorf("unknown document type %s", documentType)
+}
+
+// onlyDigits drops everything but the digits of s, e.g. the dots and dash of a formatted NPWP
+func onlyDigits(s string) string {
+	var digits strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	return digits.String()
+}
+
+// checkKycPolicy returns an error when kyc.lease_policy does not allow a lease for a client
+// with the given KYC status
+func checkKycPolicy(kycStatus string) error {
+	if config.KycLeasePolicy == KycPolicyRequireVerified && kycStatus != models.KycStatusVerified {
+		return fmt.Errorf("%s", "client KYC is not verified")
+	}
+	return nil
+}
+
+func toClientDocumentResponse(document models.ClientDocument) *dtos.ClientDocumentResponse {
+	return &dtos.ClientDocumentResponse{
+		UUID:            document.UUID,
+		ClientUUID:      document.ClientUUID,
+		Type:            document.Type,
+		Number:          document.Number,
+		ExpiresAt:       document.ExpiresAt,
+		Status:          document.Status,
+		RejectionReason: document.RejectionReason,
+		UploadedBy:      document.UploadedBy,
+		ReviewedBy:      document.ReviewedBy,
+		ReviewedAt:      document.ReviewedAt,
+		CreatedAt:       document.CreatedAt,
+		FilePath:        document.FilePath,
+	}
+}
+
+func NewClientDocumentService(repo repositories.ClientDocumentRepository) ClientDocumentService {
+	return &clientDocumentServiceImpl{repo: repo}
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/repositories"
+)
+
+type ClientPrivacyService interface {
+	Export(uuid string) (*dtos.ClientDataExport, error)
+	Erase(uuid string, actor dtos.ChangeActor) (*dtos.ClientErasureResponse, error)
+}
+
+type clientPrivacyServiceImpl struct {
+	repo repositories.ClientPrivacyRepository
+}
+
+// Export implements ClientPrivacyService.
+func (s *clientPrivacyServiceImpl) Export(uuid string) (*dtos.ClientDataExport, error) {
+	records, err := s.repo.GetRecords(uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &dtos.ClientDataExport{
+		ExportedAt: time.Now(),
+		Client:     *records.Client,
+		Timeline:   records.Timeline,
+		History:    records.History,
+		Documents:  make([]*dtos.ClientDocumentResponse, len(records.Documents)),
+		Deposits:   make([]*dtos.DepositResponse, len(records.Deposits)),
+	}
+	for i, document := range records.Documents {
+		export.Documents[i] = toClientDocumentResponse(document)
+	}
+	for i, deposit := range records.Deposits {
+		export.Deposits[i] = toDepositResponse(deposit)
+	}
+	return export, nil
+}
+
+// Erase implements ClientPrivacyService.
+// The document scans and deduction photos are removed after the erasure committed. A file that
+// cannot be removed is logged, the database no longer points at it.
+func (s *clientPrivacyServiceImpl) Erase(uuid string, actor dtos.ChangeActor) (*dtos.ClientErasureResponse, error) {
+	response, files, err := s.repo.Erase(uuid, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			log.Println("Error while removing an erased file", "file", file, "error", err)
+		}
+	}
+	return response, nil
+}
+
+func NewClientPrivacyService(repo repositories.ClientPrivacyRepository) ClientPrivacyService {
+	return &clientPrivacyServiceImpl{repo: repo}
+}
@@ -1,26 +1,167 @@
 package services
 
 import (
+	"math"
+	"sort"
+
 	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/helpers"
 	"alfredo/ruu-properties/pkg/repositories"
 )
 
 type ClientService interface {
 	Create(request dtos.ClientRequest) (*dtos.ClientResponse, error)
 	GetAll(request dtos.ClientGetRequest) ([]*dtos.ClientResponse, *dtos.PaginationMeta, error)
+	GetAllByCursor(request dtos.ClientGetRequest) ([]*dtos.ClientResponse, *dtos.CursorMeta, error)
 	GetByID(uuid string) (*dtos.ClientResponse, error)
-	Delete(uuid string) error
+	Delete(uuid string, ifMatch string, actor dtos.ChangeActor) error
 	Update(request dtos.ClientUpdateRequest) (*dtos.ClientResponse, error)
+	Patch(request dtos.ClientPatchRequest) (*dtos.ClientResponse, error)
+	Export(request dtos.ClientGetRequest, fn func(client *dtos.ClientResponse) error) error
+	GetDuplicates(request dtos.ClientDuplicateRequest) ([]*dtos.ClientDuplicateResponse, error)
+	Merge(request dtos.ClientMergeRequest) (*dtos.ClientResponse, error)
+	SetTags(request dtos.ClientTagRequest) (*dtos.ClientResponse, error)
+	GetTags(visibility *dtos.ClientVisibility) ([]*dtos.ClientTagCountResponse, error)
+	GetTrash(request dtos.TrashGetRequest) ([]*dtos.ClientResponse, *dtos.PaginationMeta, error)
+	Restore(uuid string, actor dtos.ChangeActor) (*dtos.ClientResponse, error)
+	CheckVisible(uuid string, visibility dtos.ClientVisibility) error
+	SetOwner(request dtos.ClientOwnerRequest) (*dtos.ClientResponse, error)
+	Reassign(request dtos.ClientReassignRequest) (*dtos.ClientReassignResponse, error)
 }
 
+// Weights of the duplicate signals, a pair matching on all three scores 1
+const (
+	duplicatePhoneWeight = 0.5
+	duplicateEmailWeight = 0.2
+	duplicateNameWeight  = 0.3
+)
+
 type clientServiceImpl struct {
 	clientRepository repositories.ClientRepository
 }
 
 func (s *clientServiceImpl) Update(request dtos.ClientUpdateRequest) (*dtos.ClientResponse, error) {
+	if request.PhoneNumber != "" {
+		phone, err := helpers.NormalizePhoneNumber(request.PhoneNumber, helpers.PhoneDefaultRegion())
+		if err != nil {
+			return nil, err
+		}
+		request.PhoneNumber = phone
+	}
+
 	return s.clientRepository.Update(request)
 }
 
+// Patch implements ClientService.
+func (s *clientServiceImpl) Patch(request dtos.ClientPatchRequest) (*dtos.ClientResponse, error) {
+	phone, err := helpers.NormalizePhoneNumber(request.PhoneNumber, helpers.PhoneDefaultRegion())
+	if err != nil {
+		return nil, err
+	}
+	request.PhoneNumber = phone
+
+	return s.clientRepository.Patch(request)
+}
+
+// Export implements ClientService.
+func (s *clientServiceImpl) Export(request dtos.ClientGetRequest, fn func(client *dtos.ClientResponse) error) error {
+	return s.clientRepository.Export(request, fn)
+}
+
+// GetDuplicates implements ClientService.
+// Clients are only compared when they share a normalized phone, an email local-part or the
+// first word of their name, which keeps the number of scored pairs small.
+func (s *clientServiceImpl) GetDuplicates(request dtos.ClientDuplicateRequest) ([]*dtos.ClientDuplicateResponse, error) {
+	clients, err := s.clientRepository.GetDuplicateCandidates(request.Visibility)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := make(map[string][]int)
+	for i, client := range clients {
+		if phone := normalizedPhone(client.PhoneNumber); phone != "" {
+			blocks["phone:"+phone] = append(blocks["phone:"+phone], i)
+		}
+		if local := helpers.EmailLocalPart(client.Email); local != "" {
+			blocks["email:"+local] = append(blocks["email:"+local], i)
+		}
+		if tokens := helpers.NameTokens(client.Name); len(tokens) > 0 {
+			blocks["name:"+tokens[0]] = append(blocks["name:"+tokens[0]], i)
+		}
+	}
+
+	seen := make(map[[2]int]bool)
+	duplicates := []*dtos.ClientDuplicateResponse{}
+	for _, members := range blocks {
+		for i := 0; i < len(members); i++ {
+			for j := i + 1; j < len(members); j++ {
+				pair := [2]int{members[i], members[j]}
+				if seen[pair] {
+					continue
+				}
+				seen[pair] = true
+
+				duplicate := scoreDuplicate(clients[pair[0]], clients[pair[1]])
+				if duplicate.Score >= request.MinScore {
+					duplicates = append(duplicates, duplicate)
+				}
+			}
+		}
+	}
+
+	sort.Slice(duplicates, func(i, j int) bool {
+		if duplicates[i].Score != duplicates[j].Score {
+			return duplicates[i].Score > duplicates[j].Score
+		}
+		return duplicates[i].Clients[0].UUID < duplicates[j].Clients[0].UUID
+	})
+	if len(duplicates) > request.Limit {
+		duplicates = duplicates[:request.Limit]
+	}
+
+	return duplicates, nil
+}
+
+// scoreDuplicate combines the phone, email and name signals of two clients
+func scoreDuplicate(a, b *dtos.ClientResponse) *dtos.ClientDuplicateResponse {
+	duplicate := &dtos.ClientDuplicateResponse{
+		Reasons: []string{},
+		Clients: [2]dtos.ClientResponse{*a, *b},
+	}
+
+	if phone := normalizedPhone(a.PhoneNumber); phone != "" && phone == normalizedPhone(b.PhoneNumber) {
+		duplicate.Score += duplicatePhoneWeight
+		duplicate.Reasons = append(duplicate.Reasons, "phone_number")
+	}
+	if local := helpers.EmailLocalPart(a.Email); local != "" && local == helpers.EmailLocalPart(b.Email) {
+		duplicate.Score += duplicateEmailWeight
+		duplicate.Reasons = append(duplicate.Reasons, "email_local_part")
+	}
+	if similarity := helpers.NameSimilarity(a.Name, b.Name); similarity > 0 {
+		duplicate.Score += duplicateNameWeight * similarity
+		if similarity >= 0.8 {
+			duplicate.Reasons = append(duplicate.Reasons, "name")
+		}
+	}
+
+	duplicate.Score = math.Round(duplicate.Score*100) / 100
+	return duplicate
+}
+
+// normalizedPhone returns the E.164 form of a phone number, or an empty string when it cannot be parsed
+func normalizedPhone(phone string) string {
+	normalized, err := helpers.NormalizePhoneNumber(phone, helpers.PhoneDefaultRegion())
+	if err != nil {
+		return ""
+	}
+	return normalized
+}
+
+// Merge implements ClientService.
+func (s *clientServiceImpl) Merge(request dtos.ClientMergeRequest) (*dtos.ClientResponse, error) {
+	return s.clientRepository.Merge(request)
+}
+
 // GetByID implements ClientService.
 func (s *clientServiceImpl) GetByID(uuid string) (*dtos.ClientResponse, error) {
 	return s.clientRepository.GetByID(uuid)
@@ -31,14 +172,65 @@ func (s *clientServiceImpl) GetAll(request dtos.ClientGetRequest) ([]*dtos.Clien
 	return s.clientRepository.GetAll(request)
 }
 
+// GetAllByCursor implements ClientService.
+func (s *clientServiceImpl) GetAllByCursor(request dtos.ClientGetRequest) ([]*dtos.ClientResponse, *dtos.CursorMeta, error) {
+	return s.clientRepository.GetAllByCursor(request)
+}
+
+// SetTags implements ClientService.
+func (s *clientServiceImpl) SetTags(request dtos.ClientTagRequest) (*dtos.ClientResponse, error) {
+	request.Tags = helpers.NormalizeTags(request.Tags)
+	return s.clientRepository.SetTags(request)
+}
+
+// GetTags implements ClientService.
+func (s *clientServiceImpl) GetTags(visibility *dtos.ClientVisibility) ([]*dtos.ClientTagCountResponse, error) {
+	return s.clientRepository.GetTags(visibility)
+}
+
+// GetTrash implements ClientService.
+func (s *clientServiceImpl) GetTrash(request dtos.TrashGetRequest) ([]*dtos.ClientResponse, *dtos.PaginationMeta, error) {
+	return s.clientRepository.GetTrash(request)
+}
+
+// Restore implements ClientService.
+func (s *clientServiceImpl) Restore(uuid string, actor dtos.ChangeActor) (*dtos.ClientResponse, error) {
+	return s.clientRepository.Restore(uuid, actor)
+}
+
+// CheckVisible implements ClientService.
+func (s *clientServiceImpl) CheckVisible(uuid string, visibility dtos.ClientVisibility) error {
+	return s.clientRepository.CheckVisible(uuid, visibility)
+}
+
+// SetOwner implements ClientService.
+func (s *clientServiceImpl) SetOwner(request dtos.ClientOwnerRequest) (*dtos.ClientResponse, error) {
+	return s.clientRepository.SetOwner(request)
+}
+
+// Reassign implements ClientService.
+func (s *clientServiceImpl) Reassign(request dtos.ClientReassignRequest) (*dtos.ClientReassignResponse, error) {
+	reassigned, err := s.clientRepository.Reassign(request)
+	if err != nil {
+		return nil, err
+	}
+	return &dtos.ClientReassignResponse{Reassigned: reassigned}, nil
+}
+
 func NewClientService(clientRepository repositories.ClientRepository) ClientService {
 	return &clientServiceImpl{clientRepository: clientRepository}
 }
 
 func (s *clientServiceImpl) Create(request dtos.ClientRequest) (*dtos.ClientResponse, error) {
+	phone, err := helpers.NormalizePhoneNumber(request.PhoneNumber, helpers.PhoneDefaultRegion())
+	if err != nil {
+		return nil, err
+	}
+	request.PhoneNumber = phone
+
 	return s.clientRepository.Create(request)
 }
 
-func (s *clientServiceImpl) Delete(uuid string) error {
-	return s.clientRepository.Delete(uuid)
+func (s *clientServiceImpl) Delete(uuid string, ifMatch string, actor dtos.ChangeActor) error {
+	return s.clientRepository.Delete(uuid, ifMatch, actor)
 }
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/models"
+	"alfredo/ruu-properties/pkg/repositories"
+)
+
+type DepositService interface {
+	Create(request dtos.DepositRequest) (*dtos.DepositResponse, error)
+	GetAll(request dtos.DepositGetRequest) ([]*dtos.DepositResponse, *dtos.PaginationMeta, error)
+	GetByID(uuid string) (*dtos.DepositResponse, error)
+	AddDeduction(request dtos.DepositDeductionRequest) (*dtos.DepositResponse, error)
+	Refund(request dtos.DepositRefundRequest) (*dtos.DepositResponse, error)
+	GetSettlement(uuid string) (*dtos.DepositSettlementResponse, error)
+}
+
+type depositServiceImpl struct {
+	depositRepository repositories.DepositRepository
+	clientRepository  repositories.ClientRepository
+}
+
+// Create implements DepositService.
+func (s *depositServiceImpl) Create(request dtos.DepositRequest) (*dtos.DepositResponse, error) {
+	client, err := s.clientRepository.GetByID(request.ClientUUID)
+	if err != nil {
+		return nil, err
+	}
+	// The deposit is taken when the lease is signed, so it is where the lease KYC policy applies
+	if err := checkKycPolicy(client.KycStatus); err != nil {
+		return nil, err
+	}
+
+	receivedAt := time.Now()
+	if request.ReceivedAt != nil {
+		receivedAt = *request.ReceivedAt
+	}
+
+	deposit := models.Deposit{
+		ClientUUID:    request.ClientUUID,
+		PropertyUUID:  request.PropertyUUID,
+		Amount:        roundAmount(request.Amount),
+		PaymentMethod: request.PaymentMethod,
+		ReceivedAt:    receivedAt,
+		Status:        models.DepositStatusHeld,
+		Notes:         request.Notes,
+	}
+	if err := s.depositRepository.Create(&deposit); err != nil {
+		return nil, err
+	}
+
+	return toDepositResponse(deposit), nil
+}
+
+// GetAll implements DepositService.
+func (s *depositServiceImpl) GetAll(request dtos.DepositGetRequest) ([]*dtos.DepositResponse, *dtos.PaginationMeta, error) {
+	deposits, total, err := s.depositRepository.GetAll(request)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	responses := make([]*dtos.DepositResponse, len(deposits))
+	for i, deposit := range deposits {
+		responses[i] = toDepositResponse(deposit)
+	}
+
+	return responses, &dtos.PaginationMeta{
+		Page:       request.Page,
+		Limit:      request.Limit,
+		Total:      int(total),
+		TotalPages: int(math.Ceil(float64(total) / float64(request.Limit))),
+	}, nil
+}
+
+// GetByID implements DepositService.
+func (s *depositServiceImpl) GetByID(uuid string) (*dtos.DepositResponse, error) {
+	deposit, err := s.depositRepository.FindByUUID(uuid)
+	if err != nil {
+		return nil, err
+	}
+	return toDepositResponse(deposit), nil
+}
+
+// AddDeduction implements DepositService.
+func (s *depositServiceImpl) AddDeduction(request dtos.DepositDeductionRequest) (*dtos.DepositResponse, error) {
+	deposit, err := s.depositRepository.FindByUUID(request.DepositUUID)
+	if err != nil {
+		return nil, err
+	}
+	if deposit.Status != models.DepositStatusHeld {
+		return nil, fmt.Errorf("%s", "deposit already refunded")
+	}
+
+	deduction := models.DepositDeduction{
+		DepositUUID: deposit.UUID,
+		Category:    request.Category,
+		Description: request.Description,
+		Amount:      roundAmount(request.Amount),
+	}
+	for _, photo := range request.Photos {
+		deduction.Photos = append(deduction.Photos, models.DepositDeductionPhoto{PhotoURL: photo})
+	}
+
+	if err := s.depositRepository.AddDeduction(&deduction); err != nil {
+		return nil, err
+	}
+
+	deposit.Deductions = append(deposit.Deductions, deduction)
+	return toDepositResponse(deposit), nil
+}
+
+// Refund implements DepositService.
+func (s *depositServiceImpl) Refund(request dtos.DepositRefundRequest) (*dtos.DepositResponse, error) {
+	deposit, err := s.depositRepository.FindByUUID(request.DepositUUID)
+	if err != nil {
+		return nil, err
+	}
+	if deposit.Status != models.DepositStatusHeld {
+		return nil, fmt.Errorf("%s", "deposit already refunded")
+	}
+
+	refundable, _ := settleDeposit(deposit)
+	if refundable > 0 && request.RefundMethod == "" {
+		return nil, fmt.Errorf("%s", "refund method is required")
+	}
+
+	refundedAt := time.Now()
+	if request.RefundedAt != nil {
+		refundedAt = *request.RefundedAt
+	}
+
+	deposit.RefundAmount = refundable
+	deposit.RefundMethod = request.RefundMethod
+	deposit.RefundReference = request.RefundReference
+	deposit.RefundedAt = &refundedAt
+
+	if err := s.depositRepository.Refund(&deposit); err != nil {
+		return nil, err
+	}
+
+	return toDepositResponse(deposit), nil
+}
+
+// GetSettlement implements DepositService.
+func (s *depositServiceImpl) GetSettlement(uuid string) (*dtos.DepositSettlementResponse, error) {
+	deposit, err := s.depositRepository.FindByUUID(uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := s.clientRepository.GetByID(deposit.ClientUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := toDepositResponse(deposit)
+	refundable, owed := settleDeposit(deposit)
+
+	return &dtos.DepositSettlementResponse{
+		DepositUUID:        deposit.UUID,
+		Client:             *client,
+		PropertyUUID:       deposit.PropertyUUID,
+		Status:             deposit.Status,
+		DepositAmount:      deposit.Amount,
+		ReceivedAt:         deposit.ReceivedAt,
+		Deductions:         response.Deductions,
+		TotalDeductions:    response.TotalDeductions,
+		RefundableAmount:   refundable,
+		AmountOwedByClient: owed,
+		RefundAmount:       deposit.RefundAmount,
+		RefundMethod:       deposit.RefundMethod,
+		RefundReference:    deposit.RefundReference,
+		RefundedAt:         deposit.RefundedAt,
+		GeneratedAt:        time.Now(),
+	}, nil
+}
+
+// settleDeposit returns the amount that goes back to the client and, when the deductions
+// exceed the deposit, the remainder the client still owes.
+func settleDeposit(deposit models.Deposit) (refundable float64, owed float64) {
+	balance := roundAmount(deposit.Amount - totalDeductions(deposit))
+	if balance < 0 {
+		return 0, -balance
+	}
+	return balance, 0
+}
+
+func totalDeductions(deposit models.Deposit) float64 {
+	var total float64
+	for _, deduction := range deposit.Deductions {
+		total += deduction.Amount
+	}
+	return roundAmount(total)
+}
+
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+func toDepositResponse(deposit models.Deposit) *dtos.DepositResponse {
+	deductions := make([]*dtos.DepositDeductionResponse, len(deposit.Deductions))
+	for i, deduction := range deposit.Deductions {
+		photos := make([]string, len(deduction.Photos))
+		for j, photo := range deduction.Photos {
+			photos[j] = photo.PhotoURL
+		}
+
+		deductions[i] = &dtos.DepositDeductionResponse{
+			UUID:        deduction.UUID,
+			Category:    deduction.Category,
+			Description: deduction.Description,
+			Amount:      deduction.Amount,
+			Photos:      photos,
+			CreatedAt:   deduction.CreatedAt,
+		}
+	}
+
+	refundable, _ := settleDeposit(deposit)
+
+	return &dtos.DepositResponse{
+		UUID:             deposit.UUID,
+		ClientUUID:       deposit.ClientUUID,
+		PropertyUUID:     deposit.PropertyUUID,
+		Amount:           deposit.Amount,
+		PaymentMethod:    deposit.PaymentMethod,
+		ReceivedAt:       deposit.ReceivedAt,
+		Status:           deposit.Status,
+		TotalDeductions:  totalDeductions(deposit),
+		RefundableAmount: refundable,
+		RefundAmount:     deposit.RefundAmount,
+		RefundMethod:     deposit.RefundMethod,
+		RefundReference:  deposit.RefundReference,
+		RefundedAt:       deposit.RefundedAt,
+		Notes:            deposit.Notes,
+		Deductions:       deductions,
+		CreatedAt:        deposit.CreatedAt,
+		UpdatedAt:        deposit.UpdatedAt,
+	}
+}
+
+func NewDepositService(
+	depositRepository repositories.DepositRepository,
+	clientRepository repositories.ClientRepository,
+) DepositService {
+	return &depositServiceImpl{
+		depositRepository: depositRepository,
+		clientRepository:  clientRepository,
+	}
+}
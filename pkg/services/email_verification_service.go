@@ -0,0 +1,141 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"alfredo/ruu-properties/config"
+	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/helpers"
+	"alfredo/ruu-properties/pkg/models"
+	"alfredo/ruu-properties/pkg/repositories"
+)
+
+// ErrInvalidVerificationToken is returned for unknown, used and expired verification tokens alike
+var ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+
+const (
+	// emailVerificationKey maps the hash of a verification token to the user it verifies
+	emailVerificationKey = "email_verification:"
+	// emailVerificationUserKey holds the hash of the latest verification token of a user
+	emailVerificationUserKey = "email_verification_user:"
+
+	defaultEmailVerificationTtlMinutes = 24 * 60
+)
+
+type EmailVerificationService interface {
+	Send(user models.User) error
+	Verify(request dtos.VerifyEmailRequest) error
+	Resend(request dtos.ResendVerificationRequest) error
+}
+
+type emailVerificationServiceImpl struct {
+	userRepository repositories.UserRepository
+	redisService   RedisService
+	mailer         Mailer
+}
+
+// emailVerificationRequired tells whether unverified users are refused at login
+func emailVerificationRequired() bool {
+	required, _ := strconv.ParseBool(config.EmailVerifyRequired)
+	return required
+}
+
+// emailVerificationTtl reads email_verification.ttl_minutes, falling back to the default when it
+// is unset or not a positive number
+func emailVerificationTtl() time.Duration {
+	minutes, err := strconv.Atoi(config.EmailVerifyTtl)
+	if err != nil || minutes < 1 {
+		minutes = defaultEmailVerificationTtlMinutes
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// Send implements EmailVerificationService. It mails a new verification link, the links sent
+// before stop working.
+func (e *emailVerificationServiceImpl) Send(user models.User) error {
+	token, err := helpers.GenerateSecureToken(32)
+	if err != nil {
+		return fmt.Errorf("%s", "please try again later")
+	}
+	hash := helpers.HashToken(token)
+	ttl := emailVerificationTtl()
+
+	if previous, err := e.redisService.Get(emailVerificationUserKey + user.UUID); err == nil && previous != "" {
+		if err := e.redisService.Delete(emailVerificationKey + previous); err != nil {
+			return fmt.Errorf("%s", "please try again later")
+		}
+	}
+	if err := e.redisService.SetWithExpiration(emailVerificationKey+hash, user.UUID, ttl); err != nil {
+		return fmt.Errorf("%s", "please try again later")
+	}
+	if err := e.redisService.SetWithExpiration(emailVerificationUserKey+user.UUID, hash, ttl); err != nil {
+		return fmt.Errorf("%s", "please try again later")
+	}
+
+	return e.mailer.Send(dtos.MailMessage{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Welcome to Ruu Properties, %s.\n\n"+
+				"Use this link within %d hours to verify your email address:\n%s\n\n"+
+				"If you did not create an account, ignore this mail.\n",
+			user.Name, int(ttl.Hours()), mailLink(config.EmailVerifyUrl, token),
+		),
+	})
+}
+
+// Verify implements EmailVerificationService. A token works once.
+func (e *emailVerificationServiceImpl) Verify(request dtos.VerifyEmailRequest) error {
+	hash := helpers.HashToken(request.Token)
+
+	userUuid, err := e.redisService.Get(emailVerificationKey + hash)
+	if err != nil || userUuid == "" {
+		return ErrInvalidVerificationToken
+	}
+
+	if err := e.userRepository.MarkEmailVerified(userUuid); err != nil {
+		if err.Error() == "user not found" {
+			return ErrInvalidVerificationToken
+		}
+		return fmt.Errorf("%s", "please try again later")
+	}
+
+	// Verifying twice changes nothing, so a racing second use needs no guard
+	if err := e.redisService.Delete(emailVerificationKey + hash); err != nil {
+		return fmt.Errorf("%s", "please try again later")
+	}
+	if err := e.redisService.Delete(emailVerificationUserKey + userUuid); err != nil {
+		return fmt.Errorf("%s", "please try again later")
+	}
+	return nil
+}
+
+// Resend implements EmailVerificationService. Unknown, deleted, deactivated and already verified
+// accounts are skipped silently, so the caller cannot tell which emails are registered.
+func (e *emailVerificationServiceImpl) Resend(request dtos.ResendVerificationRequest) error {
+	user, err := e.userRepository.FindUserByEmail(request.Email)
+	if err != nil || user.DeletedAt.Valid || user.DeactivatedAt != nil || user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	if err := e.Send(user); err != nil {
+		log.Println("Error while sending the verification mail", "error", err)
+	}
+	return nil
+}
+
+func NewEmailVerificationService(
+	userRepository repositories.UserRepository,
+	redisService RedisService,
+	mailer Mailer,
+) EmailVerificationService {
+	return &emailVerificationServiceImpl{
+		userRepository: userRepository,
+		redisService:   redisService,
+		mailer:         mailer,
+	}
+}
@@ -7,6 +7,14 @@ import (
 
 type FeatureService interface {
 	Create(request dtos.FeatureRequest) (*dtos.FeatureResponse, error)
+	GetAll(request dtos.FeatureGetRequest) ([]*dtos.FeatureResponse, *dtos.PaginationMeta, error)
+	GetByID(uuid string) (*dtos.FeatureResponse, error)
+	GetPublic() ([]*dtos.PublicFeatureResponse, error)
+	Update(request dtos.FeatureUpdateRequest) (*dtos.FeatureResponse, error)
+	Patch(request dtos.FeaturePatchRequest) (*dtos.FeatureResponse, error)
+	Delete(uuid string, ifMatch string) error
+	GetTrash(request dtos.TrashGetRequest) ([]*dtos.FeatureResponse, *dtos.PaginationMeta, error)
+	Restore(uuid string) (*dtos.FeatureResponse, error)
 }
 
 type featureServiceImpl struct {
@@ -18,6 +26,46 @@ func (f *featureServiceImpl) Create(request dtos.FeatureRequest) (*dtos.FeatureR
 	return f.repo.Create(request)
 }
 
+// GetAll implements FeatureService.
+func (f *featureServiceImpl) GetAll(request dtos.FeatureGetRequest) ([]*dtos.FeatureResponse, *dtos.PaginationMeta, error) {
+	return f.repo.GetAll(request)
+}
+
+// GetByID implements FeatureService.
+func (f *featureServiceImpl) GetByID(uuid string) (*dtos.FeatureResponse, error) {
+	return f.repo.GetByID(uuid)
+}
+
+// Patch implements FeatureService.
+func (f *featureServiceImpl) Patch(request dtos.FeaturePatchRequest) (*dtos.FeatureResponse, error) {
+	return f.repo.Patch(request)
+}
+
+// GetTrash implements FeatureService.
+func (f *featureServiceImpl) GetTrash(request dtos.TrashGetRequest) ([]*dtos.FeatureResponse, *dtos.PaginationMeta, error) {
+	return f.repo.GetTrash(request)
+}
+
+// Restore implements FeatureService.
+func (f *featureServiceImpl) Restore(uuid string) (*dtos.FeatureResponse, error) {
+	return f.repo.Restore(uuid)
+}
+
+// GetPublic implements FeatureService.
+func (f *featureServiceImpl) GetPublic() ([]*dtos.PublicFeatureResponse, error) {
+	return f.repo.GetPublic()
+}
+
+// Update implements FeatureService.
+func (f *featureServiceImpl) Update(request dtos.FeatureUpdateRequest) (*dtos.FeatureResponse, error) {
+	return f.repo.Update(request)
+}
+
+// Delete implements FeatureService.
+func (f *featureServiceImpl) Delete(uuid string, ifMatch string) error {
+	return f.repo.Delete(uuid, ifMatch)
+}
+
 func NewFeatureService(repo repositories.FeatureRepository) FeatureService {
 	return &featureServiceImpl{repo: repo}
 }
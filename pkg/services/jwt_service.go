@@ -3,6 +3,7 @@ package services
 import (
 	"errors"
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 	"time"
@@ -11,6 +12,7 @@ import (
 
 	"alfredo/ruu-properties/config"
 	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/helpers"
 )
 
 type TokenType string
@@ -23,6 +25,19 @@ const (
 	defaultRefreshTokenExpiry = 60 * 24
 
 	revokedTokenExpiration = 24 * 7
+
+	// A token family is every token pair minted from one login. The family record lives as long
+	// as its newest refresh token, each refresh token is marked used once it has been rotated,
+	// and a revoked family rejects every token it ever issued.
+	tokenFamilyKey        = "token_family:"
+	revokedTokenFamilyKey = "token_family_revoked:"
+	usedRefreshTokenKey   = "refresh_token_used:"
+
+	revokedTokenKey      = "revoked_token:"
+	revokedUserTokensKey = "revoked_user_tokens:"
+
+	// Cutoffs below this were stored in seconds, before they were stored in milliseconds
+	revokedBeforeMillis = 1e12
 )
 
 var (
@@ -30,6 +45,8 @@ var (
 	ErrMalformedToken   = errors.New("malformed token")
 	ErrTokenSigning     = errors.New("error signing token")
 	ErrRefreshTokenSign = errors.New("error signing refresh token")
+	ErrRevokedToken     = errors.New("token has been revoked")
+	ErrTokenReused      = errors.New("refresh token reuse detected")
 )
 
 type jwtServiceImpl struct {
@@ -52,26 +69,157 @@ func (j *jwtServiceImpl) IsTokenExpired(token string) bool {
 	return expiredTime.Before(time.Now())
 }
 
-// Revoke invalidates a token by storing it in Redis with an expiration time
+// Revoke invalidates a token by storing its jti in Redis until the token expires on its own
 func (j *jwtServiceImpl) Revoke(token string) error {
-	// Store token in Redis with expiration to automatically clean up old tokens
-	if err := j.redisService.Set(token, true); err != nil {
+	parsed, err := j.ValidateToken(token)
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		// An expired token is rejected anyway
+		return nil
+	}
+	if err != nil || !parsed.Valid {
+		return ErrInvalidToken
+	}
+
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	if !ok {
+		return ErrInvalidToken
+	}
+
+	// Tokens issued before they carried a jti are revoked by their raw value
+	key := token
+	if jti, _ := claims["jti"].(string); jti != "" {
+		key = revokedTokenKey + jti
+	}
+
+	expiration := time.Hour * revokedTokenExpiration
+	if expiresAt, err := claims.GetExpirationTime(); err == nil && expiresAt != nil {
+		expiration = time.Until(expiresAt.Time)
+	}
+
+	if err := j.redisService.SetWithExpiration(key, true, expiration); err != nil {
 		return fmt.Errorf("failed to revoke token: %w", err)
 	}
 
 	return nil
 }
 
-// IsTokenRevoked checks if a token has been revoked
+// RevokeAll invalidates every token the user has been issued so far, on every device. The
+// cutoff is kept in milliseconds, so a login right after it gets tokens that are still valid.
+func (j *jwtServiceImpl) RevokeAll(userUuid string) error {
+	// Outlives the longest lived token issued before now
+	_, refreshExpiry := j.getTokenExpiryTimes()
+	if err := j.redisService.SetWithExpiration(revokedUserTokensKey+userUuid, time.Now().UnixMilli(), time.Duration(refreshExpiry)*time.Minute); err != nil {
+		return fmt.Errorf("failed to revoke tokens: %w", err)
+	}
+
+	return nil
+}
+
+// IsTokenRevoked checks if a token has been revoked, on its own or by a logout everywhere. The
+// signature is not verified here, ValidateToken does that.
 func (j *jwtServiceImpl) IsTokenRevoked(token string) bool {
-	res, err := j.redisService.Get(token)
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil {
+		return false
+	}
+
+	key := token
+	if jti, _ := claims["jti"].(string); jti != "" {
+		key = revokedTokenKey + jti
+	}
+	if res, err := j.redisService.Get(key); err == nil && res != "" {
+		return true
+	}
+
+	userUuid, _ := claims["user_id"].(string)
+	revokedBefore, err := j.redisService.Get(revokedUserTokensKey + userUuid)
+	if userUuid == "" || err != nil || revokedBefore == "" {
+		return false
+	}
+
+	before, _ := strconv.ParseInt(revokedBefore, 10, 64)
+	if before < revokedBeforeMillis {
+		before *= 1000
+	}
+	// GetIssuedAt truncates to seconds, the raw claim keeps the milliseconds
+	issuedAt, ok := claims["iat"].(float64)
+	return !ok || int64(math.Round(issuedAt*1000)) <= before
+}
+
+// RevokeFamily invalidates every token issued to the family, including access tokens that have
+// not expired yet
+func (j *jwtServiceImpl) RevokeFamily(family string) error {
+	_, refreshExpiry := j.getTokenExpiryTimes()
+	if err := j.redisService.SetWithExpiration(revokedTokenFamilyKey+family, true, time.Duration(refreshExpiry)*time.Minute); err != nil {
+		return fmt.Errorf("failed to revoke token family: %w", err)
+	}
+	if err := j.redisService.Delete(tokenFamilyKey + family); err != nil {
+		return fmt.Errorf("failed to revoke token family: %w", err)
+	}
+
+	return nil
+}
+
+// IsFamilyRevoked checks if the family of a token has been revoked
+func (j *jwtServiceImpl) IsFamilyRevoked(family string) bool {
+	res, err := j.redisService.Get(revokedTokenFamilyKey + family)
 	if err != nil {
 		return false
 	}
 	return res != ""
 }
 
-// GenerateToken creates both access and refresh tokens for a user
+// ConsumeRefreshToken validates a refresh token and marks it used, so it can be rotated exactly
+// once. Presenting a used refresh token again revokes its whole family, as either the client or
+// an attacker holds a stolen copy.
+func (j *jwtServiceImpl) ConsumeRefreshToken(token string) (userUuid string, family string, err error) {
+	parsed, err := j.ValidateToken(token)
+	if err != nil || !parsed.Valid {
+		return "", "", ErrInvalidToken
+	}
+
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	if !ok || claims["type"] != string(RefreshToken) {
+		return "", "", ErrInvalidToken
+	}
+
+	userUuid, _ = claims["user_id"].(string)
+	family, _ = claims["tokens"].(string)
+	jti, _ := claims["jti"].(string)
+	if userUuid == "" || family == "" || jti == "" {
+		return "", "", ErrInvalidToken
+	}
+
+	if j.IsFamilyRevoked(family) || j.IsTokenRevoked(token) {
+		return "", "", ErrRevokedToken
+	}
+
+	owner, err := j.redisService.Get(tokenFamilyKey + family)
+	if err != nil || owner != userUuid {
+		return "", "", ErrInvalidToken
+	}
+
+	expiresAt, err := claims.GetExpirationTime()
+	if err != nil || expiresAt == nil {
+		return "", "", ErrInvalidToken
+	}
+
+	first, err := j.redisService.SetNX(usedRefreshTokenKey+jti, family, time.Until(expiresAt.Time))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	if !first {
+		if err := j.RevokeFamily(family); err != nil {
+			return "", "", err
+		}
+		return "", "", ErrTokenReused
+	}
+
+	return userUuid, family, nil
+}
+
+// GenerateToken creates both access and refresh tokens for a user. tokens identifies the token
+// family: a new one for a login, the family of the rotated refresh token for a refresh.
 func (j *jwtServiceImpl) GenerateToken(userUuid string, tokens string) (dtos.GenerateTokenResponse, error) {
 	// Get JWT configuration
 	secretKey := []byte(config.JwtSecret)
@@ -91,6 +239,11 @@ func (j *jwtServiceImpl) GenerateToken(userUuid string, tokens string) (dtos.Gen
 		return dtos.GenerateTokenResponse{}, fmt.Errorf("failed to create refresh token: %w", err)
 	}
 
+	// Record the family for as long as the new refresh token is valid
+	if err := j.redisService.SetWithExpiration(tokenFamilyKey+tokens, userUuid, time.Duration(refreshExpiry)*time.Minute); err != nil {
+		return dtos.GenerateTokenResponse{}, fmt.Errorf("failed to record token family: %w", err)
+	}
+
 	return dtos.GenerateTokenResponse{
 		TokenType:    "Bearer",
 		ExpiresIn:    int(accessExpiry) * 60, // Convert minutes to seconds
@@ -157,12 +310,14 @@ func (j *jwtServiceImpl) getTokenExpiryTimes() (accessExpiry, refreshExpiry int6
 
 // createToken generates a signed JWT token with the given parameters
 func (j *jwtServiceImpl) createToken(userUuid string, tokens string, expiry int64, tokenType string, secretKey []byte) (string, error) {
+	// iat carries milliseconds, the precision RevokeAll compares it at
 	claims := jwt.MapClaims{
 		"user_id": userUuid,
 		"tokens":  tokens,
 		"exp":     jwt.NewNumericDate(time.Now().Add(time.Minute * time.Duration(expiry))).Unix(),
-		"iat":     time.Now().Unix(),
+		"iat":     float64(time.Now().UnixMilli()) / 1000,
 		"type":    tokenType,
+		"jti":     helpers.GenerateToken(32),
 	}
 
 	// Add exp_in only for access tokens
@@ -182,7 +337,11 @@ func (j *jwtServiceImpl) createToken(userUuid string, tokens string, expiry int6
 type JwtService interface {
 	IsTokenExpired(token string) bool
 	Revoke(token string) error
+	RevokeAll(userUuid string) error
 	IsTokenRevoked(token string) bool
+	RevokeFamily(family string) error
+	IsFamilyRevoked(family string) bool
+	ConsumeRefreshToken(token string) (userUuid string, family string, err error)
 	GenerateToken(userUuid string, tokens string) (dtos.GenerateTokenResponse, error)
 	ValidateToken(token string) (*jwt.Token, error)
 	GetUserIdFromToken(token string) (string, error)
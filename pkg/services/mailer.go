@@ -0,0 +1,115 @@
+package services
+
+import (
+	"fmt"
+	"net/smtp"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"alfredo/ruu-properties/config"
+	"alfredo/ruu-properties/pkg/dtos"
+)
+
+// Mail transports selectable with mail.transport
+const (
+	MailTransportSMTP   = "smtp"
+	MailTransportOutbox = "outbox"
+)
+
+// defaultOutboxDirectory keeps the mails of the outbox transport when no directory is configured
+const defaultOutboxDirectory = "./storage/outbox"
+
+// Mailer delivers mails to users. The outbox transport keeps them on disk so development and
+// tests run without a mail server.
+type Mailer interface {
+	Send(message dtos.MailMessage) error
+}
+
+type smtpMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// Send implements Mailer.
+func (m *smtpMailer) Send(message dtos.MailMessage) error {
+	if err := smtp.SendMail(m.addr, m.auth, m.from, []string{message.To}, formatMail(m.from, message)); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	return nil
+}
+
+type outboxMailer struct {
+	dir  string
+	from string
+}
+
+// Send implements Mailer. Every mail becomes an .eml file of its own.
+func (m *outboxMailer) Send(message dtos.MailMessage) error {
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create outbox directory: %w", err)
+	}
+
+	filename := fmt.Sprintf("%d_%s.eml", time.Now().UnixNano(), uuid.New().String())
+	if err := os.WriteFile(filepath.Join(m.dir, filename), formatMail(m.from, message), 0644); err != nil {
+		return fmt.Errorf("failed to write mail: %w", err)
+	}
+	return nil
+}
+
+// formatMail renders a message with the headers every transport sends
+func formatMail(from string, message dtos.MailMessage) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + message.To + "\r\n")
+	b.WriteString("Subject: " + message.Subject + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// mailLink points the page at pageUrl to token, or is the bare token when no page is configured
+func mailLink(pageUrl string, token string) string {
+	link, err := url.Parse(pageUrl)
+	if err != nil || pageUrl == "" {
+		return token
+	}
+
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String()
+}
+
+// NewSMTPMailer sends mails through an SMTP server, authenticating when a username is given
+func NewSMTPMailer(host string, port string, username string, password string, from string) Mailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &smtpMailer{addr: host + ":" + port, auth: auth, from: from}
+}
+
+// NewOutboxMailer writes mails into dir instead of sending them
+func NewOutboxMailer(dir string, from string) Mailer {
+	if dir == "" {
+		dir = defaultOutboxDirectory
+	}
+	return &outboxMailer{dir: dir, from: from}
+}
+
+// NewMailer builds the transport configured with mail.transport, the outbox unless smtp is asked for
+func NewMailer() Mailer {
+	if config.MailTransport == MailTransportSMTP {
+		return NewSMTPMailer(config.MailSmtpHost, config.MailSmtpPort, config.MailSmtpUser, config.MailSmtpPass, config.MailFrom)
+	}
+	return NewOutboxMailer(config.MailOutboxDir, config.MailFrom)
+}
@@ -1,9 +1,16 @@
 package services
 
-import "alfredo/ruu-properties/pkg/repositories"
+import (
+	"time"
+
+	"alfredo/ruu-properties/pkg/repositories"
+)
 
 type RedisService interface {
 	Set(key string, value interface{}) error
+	SetWithExpiration(key string, value interface{}, expiration time.Duration) error
+	SetNX(key string, value interface{}, expiration time.Duration) (bool, error)
+	IncrWithExpiration(key string, expiration time.Duration) (int64, error)
 	Get(key string) (string, error)
 	Delete(key string) error
 }
@@ -20,6 +27,32 @@ func (r *redisServiceImpl) Set(key string, value interface{}) error {
 	return nil
 }
 
+func (r *redisServiceImpl) SetWithExpiration(key string, value interface{}, expiration time.Duration) error {
+	if err := r.repository.SetWithExpiration(key, value, expiration); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *redisServiceImpl) SetNX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	ok, err := r.repository.SetNX(key, value, expiration)
+	if err != nil {
+		return false, err
+	}
+
+	return ok, nil
+}
+
+func (r *redisServiceImpl) IncrWithExpiration(key string, expiration time.Duration) (int64, error) {
+	count, err := r.repository.IncrWithExpiration(key, expiration)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
 func (r *redisServiceImpl) Get(key string) (string, error) {
 	res, err := r.repository.Get(key)
 	if err != nil {
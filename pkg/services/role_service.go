@@ -0,0 +1,186 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/models"
+	"alfredo/ruu-properties/pkg/repositories"
+)
+
+type RoleService interface {
+	GetAll() ([]dtos.RoleResponse, error)
+	GetPermissions() []models.Permission
+	Create(request dtos.RoleRequest) (*dtos.RoleResponse, error)
+	Update(request dtos.RoleRequest) (*dtos.RoleResponse, error)
+	Delete(name string) error
+	SetTwoFactor(request dtos.RoleTwoFactorRequest) (*dtos.RoleResponse, error)
+}
+
+type roleServiceImpl struct {
+	repo         repositories.RoleRepository
+	redisService RedisService
+}
+
+// roleNamePattern keeps role names usable in URLs
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// GetAll implements RoleService.
+func (s *roleServiceImpl) GetAll() ([]dtos.RoleResponse, error) {
+	roles, err := s.repo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+
+	responses := make([]dtos.RoleResponse, len(roles))
+	for i, role := range roles {
+		responses[i] = toRoleResponse(role)
+	}
+	return responses, nil
+}
+
+// GetPermissions implements RoleService.
+func (s *roleServiceImpl) GetPermissions() []models.Permission {
+	return models.Permissions
+}
+
+// Create implements RoleService.
+func (s *roleServiceImpl) Create(request dtos.RoleRequest) (*dtos.RoleResponse, error) {
+	if !roleNamePattern.MatchString(request.Name) {
+		return nil, fmt.Errorf("%s", "role name may only contain lowercase letters, digits, - and _")
+	}
+	permissions, err := normalizePermissions(request.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	request.Permissions = permissions
+
+	role, err := s.repo.Create(request)
+	if err != nil {
+		if err.Error() == "role already exists" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+
+	response := toRoleResponse(role)
+	return &response, nil
+}
+
+// Update implements RoleService. Users holding the role get the new permissions on their next
+// request.
+func (s *roleServiceImpl) Update(request dtos.RoleRequest) (*dtos.RoleResponse, error) {
+	if request.Name == models.RoleAdmin {
+		return nil, fmt.Errorf("%s", "the admin role cannot be changed")
+	}
+	permissions, err := normalizePermissions(request.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	request.Permissions = permissions
+
+	role, err := s.repo.Update(request)
+	if err != nil {
+		if err.Error() == "role not found" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+
+	if err := s.forgetPermissions(role.Name); err != nil {
+		return nil, err
+	}
+
+	response := toRoleResponse(role)
+	return &response, nil
+}
+
+// Delete implements RoleService.
+func (s *roleServiceImpl) Delete(name string) error {
+	if name == models.RoleAdmin {
+		return fmt.Errorf("%s", "the admin role cannot be changed")
+	}
+
+	if err := s.repo.Delete(name); err != nil {
+		if err.Error() == "role not found" || err.Error() == "role is still assigned to users" {
+			return err
+		}
+		return fmt.Errorf("%s", "please try again later")
+	}
+	return nil
+}
+
+// SetTwoFactor implements RoleService. Unlike its permissions, the admin role can require
+// two-factor authentication. Holders without an authenticator are held at enrollment from
+// their next request on.
+func (s *roleServiceImpl) SetTwoFactor(request dtos.RoleTwoFactorRequest) (*dtos.RoleResponse, error) {
+	role, err := s.repo.SetRequireTwoFactor(request)
+	if err != nil {
+		if err.Error() == "role not found" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+
+	if err := s.redisService.Delete(twoFactorRoleCacheKey(role.Name)); err != nil {
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+
+	response := toRoleResponse(role)
+	return &response, nil
+}
+
+// forgetPermissions drops the cached permissions of every user holding the role
+func (s *roleServiceImpl) forgetPermissions(role string) error {
+	uuids, err := s.repo.GetUserUUIDs(role)
+	if err != nil {
+		return fmt.Errorf("%s", "please try again later")
+	}
+
+	for _, uuid := range uuids {
+		if err := s.redisService.Delete(permissionCacheKey(uuid)); err != nil {
+			return fmt.Errorf("%s", "please try again later")
+		}
+	}
+	return nil
+}
+
+// normalizePermissions sorts and deduplicates permissions and rejects those missing from the
+// catalogue
+func normalizePermissions(permissions []string) ([]string, error) {
+	known := allPermissionNames()
+	for _, permission := range permissions {
+		if !slices.Contains(known, permission) {
+			return nil, fmt.Errorf("unknown permission %s", permission)
+		}
+	}
+	return slices.Compact(slices.Sorted(slices.Values(permissions))), nil
+}
+
+func toRoleResponse(role models.Role) dtos.RoleResponse {
+	permissions := make([]string, len(role.Permissions))
+	for i, permission := range role.Permissions {
+		permissions[i] = permission.Name
+	}
+	if role.Name == models.RoleAdmin {
+		permissions = allPermissionNames()
+	}
+
+	return dtos.RoleResponse{
+		Name:             role.Name,
+		Description:      role.Description,
+		Permissions:      permissions,
+		RequireTwoFactor: role.RequireTwoFactor,
+		CreatedAt:        role.CreatedAt,
+		UpdatedAt:        role.UpdatedAt,
+	}
+}
+
+func NewRoleService(repo repositories.RoleRepository, redisService RedisService) RoleService {
+	return &roleServiceImpl{
+		repo:         repo,
+		redisService: redisService,
+	}
+}
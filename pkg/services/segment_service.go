@@ -0,0 +1,76 @@
+package services
+
+import (
+	"fmt"
+
+	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/helpers"
+	"alfredo/ruu-properties/pkg/repositories"
+)
+
+type SegmentService interface {
+	Create(request dtos.SegmentRequest) (*dtos.SegmentResponse, error)
+	GetAll(request dtos.SegmentGetRequest) ([]*dtos.SegmentResponse, *dtos.PaginationMeta, error)
+	GetByID(uuid string) (*dtos.SegmentResponse, error)
+	Update(request dtos.SegmentUpdateRequest) (*dtos.SegmentResponse, error)
+	Delete(uuid string) error
+}
+
+type segmentServiceImpl struct {
+	repo repositories.SegmentRepository
+}
+
+// Create implements SegmentService.
+func (s *segmentServiceImpl) Create(request dtos.SegmentRequest) (*dtos.SegmentResponse, error) {
+	filter, err := normalizeSegmentFilter(request.Filter)
+	if err != nil {
+		return nil, err
+	}
+	request.Filter = filter
+
+	return s.repo.Create(request)
+}
+
+// GetAll implements SegmentService.
+func (s *segmentServiceImpl) GetAll(request dtos.SegmentGetRequest) ([]*dtos.SegmentResponse, *dtos.PaginationMeta, error) {
+	return s.repo.GetAll(request)
+}
+
+// GetByID implements SegmentService.
+func (s *segmentServiceImpl) GetByID(uuid string) (*dtos.SegmentResponse, error) {
+	return s.repo.GetByID(uuid)
+}
+
+// Update implements SegmentService.
+func (s *segmentServiceImpl) Update(request dtos.SegmentUpdateRequest) (*dtos.SegmentResponse, error) {
+	if request.Filter != nil {
+		filter, err := normalizeSegmentFilter(*request.Filter)
+		if err != nil {
+			return nil, err
+		}
+		request.Filter = &filter
+	}
+
+	return s.repo.Update(request)
+}
+
+// Delete implements SegmentService.
+func (s *segmentServiceImpl) Delete(uuid string) error {
+	return s.repo.Delete(uuid)
+}
+
+// normalizeSegmentFilter stores tags the way clients are tagged and rejects empty date ranges
+func normalizeSegmentFilter(filter dtos.SegmentFilter) (dtos.SegmentFilter, error) {
+	filter.Tags = helpers.NormalizeTags(filter.Tags)
+	filter.AnyTags = helpers.NormalizeTags(filter.AnyTags)
+
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && filter.CreatedFrom.After(*filter.CreatedTo) {
+		return filter, fmt.Errorf("%s", "created_from must be before created_to")
+	}
+
+	return filter, nil
+}
+
+func NewSegmentService(repo repositories.SegmentRepository) SegmentService {
+	return &segmentServiceImpl{repo: repo}
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/models"
+	"alfredo/ruu-properties/pkg/repositories"
+)
+
+type SessionService interface {
+	Start(userUuid string, family string, device string, client dtos.SessionClient, refreshToken string) error
+	Refresh(family string, client dtos.SessionClient, refreshToken string) error
+	End(accessToken string) error
+	GetAll(userUuid string, currentToken string) ([]dtos.SessionResponse, error)
+	Revoke(userUuid string, uuid string) error
+	RevokeAll(userUuid string) (*dtos.SessionRevokeAllResponse, error)
+}
+
+type sessionServiceImpl struct {
+	repo       repositories.SessionRepository
+	jwtService JwtService
+}
+
+// Start implements SessionService. It records a login, identified by its token family.
+func (s *sessionServiceImpl) Start(userUuid string, family string, device string, client dtos.SessionClient, refreshToken string) error {
+	now := time.Now()
+	return s.repo.Create(&models.UserSession{
+		UserUUID:   userUuid,
+		Family:     family,
+		Device:     device,
+		UserAgent:  client.UserAgent,
+		IPAddress:  client.IPAddress,
+		LastSeenAt: now,
+		ExpiresAt:  tokenExpiry(refreshToken, now),
+	})
+}
+
+// Refresh implements SessionService. A session is seen whenever its tokens are rotated.
+func (s *sessionServiceImpl) Refresh(family string, client dtos.SessionClient, refreshToken string) error {
+	return s.repo.Touch(family, client, tokenExpiry(refreshToken, time.Now()))
+}
+
+// End implements SessionService. It closes the session of the access token along with every
+// token of its family.
+func (s *sessionServiceImpl) End(accessToken string) error {
+	family := tokenFamily(accessToken)
+	if family == "" {
+		return nil
+	}
+
+	if err := s.repo.RevokeFamily(family); err != nil {
+		return err
+	}
+	return s.jwtService.RevokeFamily(family)
+}
+
+// GetAll implements SessionService. The session of currentToken is flagged as current.
+func (s *sessionServiceImpl) GetAll(userUuid string, currentToken string) ([]dtos.SessionResponse, error) {
+	sessions, err := s.repo.GetActive(userUuid)
+	if err != nil {
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+
+	current := tokenFamily(currentToken)
+	responses := make([]dtos.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		responses[i] = dtos.SessionResponse{
+			UUID:       session.UUID,
+			Device:     session.Device,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.Family == current,
+		}
+	}
+	return responses, nil
+}
+
+// Revoke implements SessionService.
+func (s *sessionServiceImpl) Revoke(userUuid string, uuid string) error {
+	family, err := s.repo.Revoke(userUuid, uuid)
+	if err != nil {
+		if err.Error() == "session not found" {
+			return err
+		}
+		return fmt.Errorf("%s", "please try again later")
+	}
+
+	if err := s.jwtService.RevokeFamily(family); err != nil {
+		return fmt.Errorf("%s", "please try again later")
+	}
+	return nil
+}
+
+// RevokeAll implements SessionService. Tokens of logins from before sessions were recorded are
+// revoked as well.
+func (s *sessionServiceImpl) RevokeAll(userUuid string) (*dtos.SessionRevokeAllResponse, error) {
+	families, err := s.repo.RevokeAll(userUuid)
+	if err != nil {
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+
+	for _, family := range families {
+		if err := s.jwtService.RevokeFamily(family); err != nil {
+			return nil, fmt.Errorf("%s", "please try again later")
+		}
+	}
+	if err := s.jwtService.RevokeAll(userUuid); err != nil {
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+
+	return &dtos.SessionRevokeAllResponse{Revoked: len(families)}, nil
+}
+
+// tokenFamily reads the token family of a token this service issued. The signature is verified
+// by whoever accepted the token.
+func tokenFamily(token string) string {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil {
+		return ""
+	}
+	family, _ := claims["tokens"].(string)
+	return family
+}
+
+// tokenExpiry reads the expiry of a token this service just issued, or fallback when it has none
+func tokenExpiry(token string, fallback time.Time) time.Time {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil {
+		return fallback
+	}
+	expiresAt, err := claims.GetExpirationTime()
+	if err != nil || expiresAt == nil {
+		return fallback
+	}
+	return expiresAt.Time
+}
+
+func NewSessionService(repo repositories.SessionRepository, jwtService JwtService) SessionService {
+	return &sessionServiceImpl{
+		repo:       repo,
+		jwtService: jwtService,
+	}
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/repositories"
+)
+
+type TrashService interface {
+	Purge(retentionDays int) ([]*dtos.TrashPurgeResult, error)
+}
+
+type trashServiceImpl struct {
+	repo repositories.TrashRepository
+}
+
+// Purge implements TrashService.
+// The uploaded files of the purged rows, e.g. KYC scans, are removed after their rows are gone.
+// A file that cannot be removed is logged, the database no longer points at it.
+func (t *trashServiceImpl) Purge(retentionDays int) ([]*dtos.TrashPurgeResult, error) {
+	results, files, err := t.repo.Purge(time.Now().AddDate(0, 0, -retentionDays))
+
+	for _, file := range files {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			log.Println("Error while removing a purged file", "file", file, "error", err)
+		}
+	}
+	return results, err
+}
+
+func NewTrashService(repo repositories.TrashRepository) TrashService {
+	return &trashServiceImpl{repo: repo}
+}
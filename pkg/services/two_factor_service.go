@@ -0,0 +1,402 @@
+package services
+
+import (
+	cryptorand "crypto/rand"
+	"encoding/base32"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"alfredo/ruu-properties/config"
+	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/helpers"
+	"alfredo/ruu-properties/pkg/models"
+	"alfredo/ruu-properties/pkg/repositories"
+)
+
+var (
+	// ErrInvalidChallenge is returned for unknown, expired and exhausted login challenges alike
+	ErrInvalidChallenge = errors.New("invalid or expired two-factor challenge")
+	// ErrInvalidTwoFactorCode is returned for wrong, replayed and used codes alike
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+	// ErrTwoFactorLocked is returned while a user is locked out after too many wrong codes
+	ErrTwoFactorLocked = errors.New("too many failed two-factor attempts, try again later")
+)
+
+const (
+	// twoFactorChallengeKey maps the hash of a challenge token to its pending login
+	twoFactorChallengeKey = "two_factor_challenge:"
+	// twoFactorUsedCodeKey marks a TOTP period of a user as used, so a code works once
+	twoFactorUsedCodeKey = "two_factor_used:"
+	// twoFactorAttemptsKey counts the codes tried against a challenge
+	twoFactorAttemptsKey = "two_factor_attempts:"
+	// twoFactorFailuresKey counts the wrong codes of a user across challenges
+	twoFactorFailuresKey = "two_factor_failures:"
+
+	twoFactorChallengeExpiration = 5 * time.Minute
+	// twoFactorMaxAttempts bounds the codes tried against one challenge
+	twoFactorMaxAttempts = 5
+	// twoFactorMaxFailures wrong codes lock the user out of two-factor checks for twoFactorLockout,
+	// so fresh logins cannot be used to keep guessing
+	twoFactorMaxFailures = 10
+	twoFactorLockout     = 15 * time.Minute
+	recoveryCodeCount    = 10
+	defaultTotpIssuer    = "Ruu Properties"
+)
+
+type TwoFactorService interface {
+	Status(user *models.User) (*dtos.TwoFactorStatusResponse, error)
+	Enroll(user *models.User) (*dtos.TwoFactorEnrollResponse, error)
+	Confirm(user *models.User, request dtos.TwoFactorCodeRequest) (*dtos.TwoFactorRecoveryCodesResponse, error)
+	Disable(user *models.User, request dtos.TwoFactorCodeRequest) error
+	RegenerateRecoveryCodes(user *models.User, request dtos.TwoFactorCodeRequest) (*dtos.TwoFactorRecoveryCodesResponse, error)
+	CreateChallenge(user models.User, device string) (string, error)
+	CompleteChallenge(request dtos.TwoFactorVerifyRequest) (*dtos.TwoFactorChallenge, error)
+}
+
+type twoFactorServiceImpl struct {
+	repo         repositories.TwoFactorRepository
+	userService  UserService
+	redisService RedisService
+}
+
+// twoFactorKey encrypts the TOTP secrets, the jwt secret unless a key of its own is configured
+func twoFactorKey() string {
+	if config.TwoFactorKey != "" {
+		return config.TwoFactorKey
+	}
+	return config.JwtSecret
+}
+
+func totpIssuer() string {
+	if config.TwoFactorIssuer != "" {
+		return config.TwoFactorIssuer
+	}
+	return defaultTotpIssuer
+}
+
+// Status implements TwoFactorService.
+func (t *twoFactorServiceImpl) Status(user *models.User) (*dtos.TwoFactorStatusResponse, error) {
+	required, err := t.roleRequiresTwoFactor(user)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dtos.TwoFactorStatusResponse{
+		Enabled:   user.TwoFactorEnabledAt != nil,
+		EnabledAt: user.TwoFactorEnabledAt,
+		Required:  required,
+	}
+	if response.Enabled {
+		left, err := t.repo.CountRecoveryCodes(user.UUID)
+		if err != nil {
+			return nil, fmt.Errorf("%s", "please try again later")
+		}
+		response.RecoveryCodesLeft = left
+	}
+	return response, nil
+}
+
+// Enroll implements TwoFactorService. The new secret only counts once a code of it is confirmed,
+// enrolling again before that replaces it.
+func (t *twoFactorServiceImpl) Enroll(user *models.User) (*dtos.TwoFactorEnrollResponse, error) {
+	if user.TwoFactorEnabledAt != nil {
+		return nil, fmt.Errorf("%s", "two-factor authentication is already enabled")
+	}
+
+	secret, err := helpers.GenerateTotpSecret()
+	if err != nil {
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+	encrypted, err := helpers.EncryptSecret(twoFactorKey(), secret)
+	if err != nil {
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+
+	if err := t.repo.SetSecret(user.UUID, encrypted); err != nil {
+		if err.Error() == "two-factor authentication is already enabled" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+
+	return &dtos.TwoFactorEnrollResponse{
+		Secret:          secret,
+		ProvisioningURI: helpers.TotpProvisioningURI(totpIssuer(), user.Email, secret),
+	}, nil
+}
+
+// Confirm implements TwoFactorService. A code of the enrolled secret enables two-factor
+// authentication and hands out the recovery codes.
+func (t *twoFactorServiceImpl) Confirm(user *models.User, request dtos.TwoFactorCodeRequest) (*dtos.TwoFactorRecoveryCodesResponse, error) {
+	if user.TwoFactorEnabledAt != nil {
+		return nil, fmt.Errorf("%s", "two-factor authentication is already enabled")
+	}
+	if user.TwoFactorSecret == "" {
+		return nil, fmt.Errorf("%s", "two-factor authentication is not enrolled")
+	}
+
+	if err := t.verify(user, request.Code, false); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+	if err := t.repo.Enable(user.UUID, hashes); err != nil {
+		if err.Error() == "two-factor authentication is already enabled" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+	return &dtos.TwoFactorRecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Disable implements TwoFactorService. It takes a TOTP or recovery code, and is refused while
+// the role of the user requires two-factor authentication.
+func (t *twoFactorServiceImpl) Disable(user *models.User, request dtos.TwoFactorCodeRequest) error {
+	if user.TwoFactorEnabledAt == nil {
+		return fmt.Errorf("%s", "two-factor authentication is not enabled")
+	}
+
+	required, err := t.roleRequiresTwoFactor(user)
+	if err != nil {
+		return err
+	}
+	if required {
+		return fmt.Errorf("%s", "two-factor authentication is required for your role")
+	}
+
+	if err := t.verify(user, request.Code, true); err != nil {
+		return err
+	}
+
+	if err := t.repo.Disable(user.UUID); err != nil {
+		return fmt.Errorf("%s", "please try again later")
+	}
+	return nil
+}
+
+// RegenerateRecoveryCodes implements TwoFactorService. The codes handed out before stop working.
+func (t *twoFactorServiceImpl) RegenerateRecoveryCodes(user *models.User, request dtos.TwoFactorCodeRequest) (*dtos.TwoFactorRecoveryCodesResponse, error) {
+	if user.TwoFactorEnabledAt == nil {
+		return nil, fmt.Errorf("%s", "two-factor authentication is not enabled")
+	}
+
+	if err := t.verify(user, request.Code, false); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+	if err := t.repo.ReplaceRecoveryCodes(user.UUID, hashes); err != nil {
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+	return &dtos.TwoFactorRecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// CreateChallenge implements TwoFactorService. It parks a password login until the second
+// factor arrives and returns the token that identifies it. Users locked out after too many
+// wrong codes get no challenge.
+func (t *twoFactorServiceImpl) CreateChallenge(user models.User, device string) (string, error) {
+	locked, err := t.lockedOut(user.UUID)
+	if err != nil {
+		return "", err
+	}
+	if locked {
+		return "", ErrTwoFactorLocked
+	}
+
+	token, err := helpers.GenerateSecureToken(32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token")
+	}
+
+	encoded, err := json.Marshal(dtos.TwoFactorChallenge{UserUuid: user.UUID, Device: device})
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token")
+	}
+	if err := t.redisService.SetWithExpiration(twoFactorChallengeKey+helpers.HashToken(token), string(encoded), twoFactorChallengeExpiration); err != nil {
+		return "", fmt.Errorf("failed to generate token")
+	}
+	return token, nil
+}
+
+// CompleteChallenge implements TwoFactorService. A challenge completes once; after too many
+// wrong codes it is dropped and the login has to start over.
+func (t *twoFactorServiceImpl) CompleteChallenge(request dtos.TwoFactorVerifyRequest) (*dtos.TwoFactorChallenge, error) {
+	hash := helpers.HashToken(request.ChallengeToken)
+
+	raw, err := t.redisService.Get(twoFactorChallengeKey + hash)
+	if err != nil || raw == "" {
+		return nil, ErrInvalidChallenge
+	}
+	var challenge dtos.TwoFactorChallenge
+	if err := json.Unmarshal([]byte(raw), &challenge); err != nil {
+		return nil, ErrInvalidChallenge
+	}
+
+	// Counted before the code is checked, so parallel requests cannot share an attempt
+	attempts, err := t.redisService.IncrWithExpiration(twoFactorAttemptsKey+hash, twoFactorChallengeExpiration)
+	if err != nil {
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+	if attempts > twoFactorMaxAttempts {
+		t.dropChallenge(hash)
+		return nil, ErrInvalidChallenge
+	}
+
+	user, err := t.userService.FindUserByUuid(challenge.UserUuid)
+	if err != nil || user.TwoFactorEnabledAt == nil {
+		t.dropChallenge(hash)
+		return nil, ErrInvalidChallenge
+	}
+
+	if err := t.verify(user, request.Code, true); err != nil {
+		switch {
+		case errors.Is(err, ErrTwoFactorLocked):
+			t.dropChallenge(hash)
+		case errors.Is(err, ErrInvalidTwoFactorCode) && attempts == twoFactorMaxAttempts:
+			t.dropChallenge(hash)
+			return nil, ErrInvalidChallenge
+		}
+		return nil, err
+	}
+
+	if err := t.redisService.Delete(twoFactorChallengeKey + hash); err != nil {
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+	_ = t.redisService.Delete(twoFactorAttemptsKey + hash)
+	return &challenge, nil
+}
+
+// roleRequiresTwoFactor tells whether the role of the user requires two-factor authentication.
+// TwoFactorRequired answers for users still to enroll, so it is asked about one.
+func (t *twoFactorServiceImpl) roleRequiresTwoFactor(user *models.User) (bool, error) {
+	return t.userService.TwoFactorRequired(&models.User{UUID: user.UUID, Role: user.Role})
+}
+
+// dropChallenge ends a challenge, the login has to start over
+func (t *twoFactorServiceImpl) dropChallenge(hash string) {
+	_ = t.redisService.Delete(twoFactorChallengeKey + hash)
+	_ = t.redisService.Delete(twoFactorAttemptsKey + hash)
+}
+
+// lockedOut tells whether the user made too many wrong codes lately
+func (t *twoFactorServiceImpl) lockedOut(userUuid string) (bool, error) {
+	failures, err := t.redisService.Get(twoFactorFailuresKey + userUuid)
+	if err != nil || failures == "" {
+		return false, nil
+	}
+	count, err := strconv.Atoi(failures)
+	if err != nil {
+		return false, fmt.Errorf("%s", "please try again later")
+	}
+	return count >= twoFactorMaxFailures, nil
+}
+
+// verify checks a TOTP code, or a recovery code when allowed, and counts wrong codes towards
+// the lockout of the user
+func (t *twoFactorServiceImpl) verify(user *models.User, code string, allowRecovery bool) error {
+	locked, err := t.lockedOut(user.UUID)
+	if err != nil {
+		return err
+	}
+	if locked {
+		return ErrTwoFactorLocked
+	}
+
+	if allowRecovery {
+		err = t.checkCode(user, code)
+	} else {
+		err = t.checkTotp(user, code)
+	}
+	if errors.Is(err, ErrInvalidTwoFactorCode) {
+		if _, err := t.redisService.IncrWithExpiration(twoFactorFailuresKey+user.UUID, twoFactorLockout); err != nil {
+			return fmt.Errorf("%s", "please try again later")
+		}
+	}
+	return err
+}
+
+// checkCode accepts a TOTP code or an unused recovery code, which is used up
+func (t *twoFactorServiceImpl) checkCode(user *models.User, code string) error {
+	code = strings.TrimSpace(code)
+	if len(code) == helpers.TotpDigits {
+		return t.checkTotp(user, code)
+	}
+
+	used, err := t.repo.UseRecoveryCode(user.UUID, helpers.HashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return fmt.Errorf("%s", "please try again later")
+	}
+	if !used {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// checkTotp accepts a code of the user's secret that was not used before
+func (t *twoFactorServiceImpl) checkTotp(user *models.User, code string) error {
+	secret, err := helpers.DecryptSecret(twoFactorKey(), user.TwoFactorSecret)
+	if err != nil {
+		return fmt.Errorf("%s", "please try again later")
+	}
+
+	counter, ok := helpers.ValidateTotp(secret, strings.TrimSpace(code), time.Now())
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+
+	// The code stays valid for the periods around it, it must not be replayed meanwhile
+	fresh, err := t.redisService.SetNX(fmt.Sprintf("%s%s:%d", twoFactorUsedCodeKey, user.UUID, counter), 1, 3*helpers.TotpPeriod*time.Second)
+	if err != nil {
+		return fmt.Errorf("%s", "please try again later")
+	}
+	if !fresh {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// newRecoveryCodes returns recovery codes as shown to the user and the hashes to store
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 7)
+		if _, err := cryptorand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		// Lowercase base32 reads well and leaves out 0, 1 and 8
+		code := strings.ToLower(base32.StdEncoding.EncodeToString(b))[:10]
+		codes[i] = code[:5] + "-" + code[5:]
+		hashes[i] = helpers.HashToken(code)
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode drops the separator and case users may type a recovery code with
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
+
+func NewTwoFactorService(
+	repo repositories.TwoFactorRepository,
+	userService UserService,
+	redisService RedisService,
+) TwoFactorService {
+	return &twoFactorServiceImpl{
+		repo:         repo,
+		userService:  userService,
+		redisService: redisService,
+	}
+}
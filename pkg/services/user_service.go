@@ -1,11 +1,16 @@
 package services
 
 import (
+	"encoding/json"
 	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/go-playground/validator/v10"
-
+	"alfredo/ruu-properties/config"
 	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/helpers"
 	"alfredo/ruu-properties/pkg/models"
 	"alfredo/ruu-properties/pkg/repositories"
 )
@@ -13,10 +18,296 @@ import (
 type UserService interface {
 	Register(request dtos.UserRegisterRequest) error
 	FindUserByUuid(uuid string) (*models.User, error)
+	SetTeam(request dtos.UserTeamRequest) (*dtos.UserTeamResponse, error)
+	SetRole(request dtos.UserRoleRequest) (*dtos.UserRoleResponse, error)
+	GetPermissions(user *models.User) ([]string, error)
+	GetAll(request dtos.UserGetRequest) ([]*dtos.UserResponse, *dtos.PaginationMeta, error)
+	Create(request dtos.UserCreateRequest) (*dtos.UserResponse, error)
+	Deactivate(actorUuid string, uuid string) (*dtos.UserResponse, error)
+	Reactivate(uuid string) (*dtos.UserResponse, error)
+	ResetPassword(request dtos.UserPasswordResetRequest) error
+	Delete(actorUuid string, uuid string) error
+	TwoFactorRequired(user *models.User) (bool, error)
 }
 
 type userServiceImpl struct {
 	userRepository repositories.UserRepository
+	roleRepository repositories.RoleRepository
+	redisService   RedisService
+	sessionService SessionService
+	verification   EmailVerificationService
+}
+
+// defaultRegistrationRole is given to self-registered users when no role is configured
+const defaultRegistrationRole = "user"
+
+// registrationEnabled tells whether the public sign up is open. It is closed unless configured
+// as true, so a missing or misspelled setting does not open it.
+func registrationEnabled() bool {
+	enabled, err := strconv.ParseBool(config.RegistrationEnabled)
+	return err == nil && enabled
+}
+
+// registrationRole is the role every self-registered user gets
+func registrationRole() string {
+	if role := strings.TrimSpace(config.RegistrationRole); role != "" {
+		return role
+	}
+	return defaultRegistrationRole
+}
+
+// permissionCacheExpiration bounds how long a missed invalidation can keep stale permissions
+const permissionCacheExpiration = 10 * time.Minute
+
+// permissionCacheKey is where the resolved permissions of a user are cached
+func permissionCacheKey(userUuid string) string {
+	return "user_permissions:" + userUuid
+}
+
+// twoFactorRoleCacheKey is where the two-factor requirement of a role is cached
+func twoFactorRoleCacheKey(role string) string {
+	return "role_two_factor:" + role
+}
+
+// TwoFactorRequired implements UserService. It tells whether the role of the user requires
+// two-factor authentication the user has not enabled yet.
+func (u *userServiceImpl) TwoFactorRequired(user *models.User) (bool, error) {
+	if user.TwoFactorEnabledAt != nil {
+		return false, nil
+	}
+
+	if cached, err := u.redisService.Get(twoFactorRoleCacheKey(user.Role)); err == nil && cached != "" {
+		return cached == "1", nil
+	}
+
+	role, err := u.roleRepository.FindByName(user.Role)
+	if err != nil {
+		return false, fmt.Errorf("%s", "please try again later")
+	}
+
+	cached := "0"
+	if role.RequireTwoFactor {
+		cached = "1"
+	}
+	_ = u.redisService.SetWithExpiration(twoFactorRoleCacheKey(user.Role), cached, permissionCacheExpiration)
+	return role.RequireTwoFactor, nil
+}
+
+// GetPermissions implements UserService. The permissions of the user's role are cached per
+// user; admins hold every permission of the catalogue.
+func (u *userServiceImpl) GetPermissions(user *models.User) ([]string, error) {
+	if user.Role == models.RoleAdmin {
+		return allPermissionNames(), nil
+	}
+
+	if cached, err := u.redisService.Get(permissionCacheKey(user.UUID)); err == nil && cached != "" {
+		var permissions []string
+		if err := json.Unmarshal([]byte(cached), &permissions); err == nil {
+			return permissions, nil
+		}
+	}
+
+	permissions, err := u.roleRepository.GetPermissionNames(user.Role)
+	if err != nil {
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+
+	// A failing cache only costs the next request a query
+	if encoded, err := json.Marshal(permissions); err == nil {
+		_ = u.redisService.SetWithExpiration(permissionCacheKey(user.UUID), string(encoded), permissionCacheExpiration)
+	}
+	return permissions, nil
+}
+
+// SetRole implements UserService. The last admin cannot give up the role, so someone can
+// always manage roles.
+func (u *userServiceImpl) SetRole(request dtos.UserRoleRequest) (*dtos.UserRoleResponse, error) {
+	if _, err := u.roleRepository.FindByName(request.Role); err != nil {
+		if err.Error() == "role not found" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+
+	user, err := u.userRepository.FindUserByUuid(request.UUID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+
+	if request.Role != models.RoleAdmin {
+		if err := u.ensureNotLastAdmin(user, "the last admin cannot lose the admin role"); err != nil {
+			return nil, err
+		}
+	}
+
+	user, err = u.userRepository.SetRole(request)
+	if err != nil {
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+
+	if err := u.redisService.Delete(permissionCacheKey(user.UUID)); err != nil {
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+	return &dtos.UserRoleResponse{UUID: user.UUID, Role: user.Role}, nil
+}
+
+// ensureNotLastAdmin fails with message when the user is the only active admin left
+func (u *userServiceImpl) ensureNotLastAdmin(user models.User, message string) error {
+	if user.Role != models.RoleAdmin || user.DeactivatedAt != nil {
+		return nil
+	}
+
+	admins, err := u.userRepository.CountByRole(models.RoleAdmin)
+	if err != nil {
+		return fmt.Errorf("%s", "please try again later")
+	}
+	if admins <= 1 {
+		return fmt.Errorf("%s", message)
+	}
+	return nil
+}
+
+// GetAll implements UserService.
+func (u *userServiceImpl) GetAll(request dtos.UserGetRequest) ([]*dtos.UserResponse, *dtos.PaginationMeta, error) {
+	return u.userRepository.GetAll(request)
+}
+
+// Create implements UserService. Unlike the public sign up, the admin picks the role.
+func (u *userServiceImpl) Create(request dtos.UserCreateRequest) (*dtos.UserResponse, error) {
+	request.Team = strings.TrimSpace(request.Team)
+
+	phone, err := helpers.NormalizePhoneNumber(request.PhoneNumber, helpers.PhoneDefaultRegion())
+	if err != nil {
+		return nil, err
+	}
+	request.PhoneNumber = phone
+
+	if _, err := u.roleRepository.FindByName(request.Role); err != nil {
+		if err.Error() == "role not found" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+
+	user, err := u.userRepository.Create(request)
+	if err != nil {
+		switch err.Error() {
+		case "Email already exists on database", "Phone number already exists on database":
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+
+	u.sendVerification(user.Email)
+	return user, nil
+}
+
+// Deactivate implements UserService. The user is signed out everywhere and cannot sign in
+// again until reactivated.
+func (u *userServiceImpl) Deactivate(actorUuid string, uuid string) (*dtos.UserResponse, error) {
+	if actorUuid == uuid {
+		return nil, fmt.Errorf("%s", "you cannot deactivate your own account")
+	}
+
+	user, err := u.userRepository.FindUserByUuid(uuid)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+
+	// Deactivating twice keeps the original time
+	deactivatedAt := user.DeactivatedAt
+	if deactivatedAt == nil {
+		if err := u.ensureNotLastAdmin(user, "the last admin cannot be deactivated"); err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		deactivatedAt = &now
+	}
+
+	response, err := u.userRepository.SetDeactivatedAt(uuid, deactivatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+
+	if _, err := u.sessionService.RevokeAll(uuid); err != nil {
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+	return response, nil
+}
+
+// Reactivate implements UserService.
+func (u *userServiceImpl) Reactivate(uuid string) (*dtos.UserResponse, error) {
+	response, err := u.userRepository.SetDeactivatedAt(uuid, nil)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+	return response, nil
+}
+
+// ResetPassword implements UserService. Every session of the user ends, so the old password
+// cannot keep a login alive.
+func (u *userServiceImpl) ResetPassword(request dtos.UserPasswordResetRequest) error {
+	if err := u.userRepository.UpdatePassword(request.UUID, request.Password); err != nil {
+		if err.Error() == "user not found" {
+			return err
+		}
+		return fmt.Errorf("%s", "please try again later")
+	}
+
+	if _, err := u.sessionService.RevokeAll(request.UUID); err != nil {
+		return fmt.Errorf("%s", "please try again later")
+	}
+	return nil
+}
+
+// Delete implements UserService. The user is soft-deleted and signed out everywhere.
+func (u *userServiceImpl) Delete(actorUuid string, uuid string) error {
+	if actorUuid == uuid {
+		return fmt.Errorf("%s", "you cannot delete your own account")
+	}
+
+	user, err := u.userRepository.FindUserByUuid(uuid)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return err
+		}
+		return fmt.Errorf("%s", "please try again later")
+	}
+
+	if err := u.ensureNotLastAdmin(user, "the last admin cannot be deleted"); err != nil {
+		return err
+	}
+
+	if err := u.userRepository.Delete(uuid); err != nil {
+		if err.Error() == "user not found" {
+			return err
+		}
+		return fmt.Errorf("%s", "please try again later")
+	}
+
+	if _, err := u.sessionService.RevokeAll(uuid); err != nil {
+		return fmt.Errorf("%s", "please try again later")
+	}
+	_ = u.redisService.Delete(permissionCacheKey(uuid))
+	return nil
+}
+
+// allPermissionNames lists the whole permission catalogue
+func allPermissionNames() []string {
+	names := make([]string, len(models.Permissions))
+	for i, permission := range models.Permissions {
+		names[i] = permission.Name
+	}
+	return names
 }
 
 // FindUserByUuid implements UserService.
@@ -28,19 +319,71 @@ func (u *userServiceImpl) FindUserByUuid(uuid string) (*models.User, error) {
 	return &user, nil
 }
 
+// SetTeam implements UserService. Members of a team see each other's clients.
+func (u *userServiceImpl) SetTeam(request dtos.UserTeamRequest) (*dtos.UserTeamResponse, error) {
+	request.Team = strings.TrimSpace(request.Team)
+
+	user, err := u.userRepository.SetTeam(request)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s", "please try again later")
+	}
+	return &dtos.UserTeamResponse{UUID: user.UUID, Team: user.Team}, nil
+}
+
 func (u userServiceImpl) Register(request dtos.UserRegisterRequest) error {
-	validate := validator.New()
+	if !registrationEnabled() {
+		return fmt.Errorf("%s", "registration is disabled")
+	}
+
+	validate := helpers.NewValidator()
 	if err := validate.Struct(request); err != nil {
 		return err
 	}
 
+	// The public sign up never chooses its own role
+	request.Role = registrationRole()
+
+	phone, err := helpers.NormalizePhoneNumber(request.PhoneNumber, helpers.PhoneDefaultRegion())
+	if err != nil {
+		return err
+	}
+	request.PhoneNumber = phone
+
 	if err := u.userRepository.Register(request); err != nil {
 		return err
 	}
 
+	u.sendVerification(request.Email)
 	return nil
 }
 
-func NewUserService(userRepository repositories.UserRepository) UserService {
-	return &userServiceImpl{userRepository: userRepository}
+// sendVerification mails a verification link to a new user. A failure only costs the user a
+// resend, so the account stays created.
+func (u *userServiceImpl) sendVerification(email string) {
+	user, err := u.userRepository.FindUserByEmail(email)
+	if err == nil {
+		err = u.verification.Send(user)
+	}
+	if err != nil {
+		log.Println("Error while sending the verification mail", "error", err)
+	}
+}
+
+func NewUserService(
+	userRepository repositories.UserRepository,
+	roleRepository repositories.RoleRepository,
+	redisService RedisService,
+	sessionService SessionService,
+	verification EmailVerificationService,
+) UserService {
+	return &userServiceImpl{
+		userRepository: userRepository,
+		roleRepository: roleRepository,
+		redisService:   redisService,
+		sessionService: sessionService,
+		verification:   verification,
+	}
 }
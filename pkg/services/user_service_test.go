@@ -0,0 +1,33 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"alfredo/ruu-properties/config"
+	"alfredo/ruu-properties/pkg/dtos"
+)
+
+func TestRegistrationEnabled(t *testing.T) {
+	defer func(enabled string) { config.RegistrationEnabled = enabled }(config.RegistrationEnabled)
+
+	for value, enabled := range map[string]bool{
+		"":      false,
+		"ture":  false,
+		"false": false,
+		"true":  true,
+		"1":     true,
+	} {
+		config.RegistrationEnabled = value
+		assert.Equal(t, enabled, registrationEnabled(), "registration.enabled = %q", value)
+	}
+}
+
+func TestRegister_ClosedWhenNotConfigured(t *testing.T) {
+	defer func(enabled string) { config.RegistrationEnabled = enabled }(config.RegistrationEnabled)
+	config.RegistrationEnabled = ""
+
+	err := userServiceImpl{}.Register(dtos.UserRegisterRequest{Email: "someone@test.com"})
+	assert.EqualError(t, err, "registration is disabled")
+}
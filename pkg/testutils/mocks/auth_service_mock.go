@@ -10,7 +10,37 @@ type MockAuthService struct {
 	mock.Mock
 }
 
-func (m *MockAuthService) Login(request dtos.LoginRequest) (dtos.LoginResponse, error) {
+func (m *MockAuthService) Login(request dtos.LoginRequest, client dtos.SessionClient) (dtos.LoginResponse, error) {
+	args := m.Called(request, client)
+	return args.Get(0).(dtos.LoginResponse), args.Error(1)
+}
+
+func (m *MockAuthService) RefreshToken(request dtos.RefreshTokenRequest, client dtos.SessionClient) (dtos.GenerateTokenResponse, error) {
+	args := m.Called(request, client)
+	return args.Get(0).(dtos.GenerateTokenResponse), args.Error(1)
+}
+
+func (m *MockAuthService) Logout(request dtos.LogoutRequest) error {
 	args := m.Called(request)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) LogoutAll(userUuid string) error {
+	args := m.Called(userUuid)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) ForgotPassword(request dtos.ForgotPasswordRequest) error {
+	args := m.Called(request)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) ResetPassword(request dtos.ResetPasswordRequest) error {
+	args := m.Called(request)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) VerifyTwoFactor(request dtos.TwoFactorVerifyRequest, client dtos.SessionClient) (dtos.LoginResponse, error) {
+	args := m.Called(request, client)
 	return args.Get(0).(dtos.LoginResponse), args.Error(1)
 }
@@ -0,0 +1,27 @@
+package mocks
+
+import (
+	"github.com/stretchr/testify/mock"
+
+	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/models"
+)
+
+type MockEmailVerificationService struct {
+	mock.Mock
+}
+
+func (m *MockEmailVerificationService) Send(user models.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *MockEmailVerificationService) Verify(request dtos.VerifyEmailRequest) error {
+	args := m.Called(request)
+	return args.Error(0)
+}
+
+func (m *MockEmailVerificationService) Resend(request dtos.ResendVerificationRequest) error {
+	args := m.Called(request)
+	return args.Error(0)
+}
@@ -21,11 +21,31 @@ func (m *MockJWTService) Revoke(token string) error {
 	return args.Error(0)
 }
 
+func (m *MockJWTService) RevokeAll(userUuid string) error {
+	args := m.Called(userUuid)
+	return args.Error(0)
+}
+
 func (m *MockJWTService) IsTokenRevoked(token string) bool {
 	args := m.Called(token)
 	return args.Bool(0)
 }
 
+func (m *MockJWTService) RevokeFamily(family string) error {
+	args := m.Called(family)
+	return args.Error(0)
+}
+
+func (m *MockJWTService) IsFamilyRevoked(family string) bool {
+	args := m.Called(family)
+	return args.Bool(0)
+}
+
+func (m *MockJWTService) ConsumeRefreshToken(token string) (string, string, error) {
+	args := m.Called(token)
+	return args.String(0), args.String(1), args.Error(2)
+}
+
 func (m *MockJWTService) GenerateToken(userUuid string, tokens string) (dtos.GenerateTokenResponse, error) {
 	args := m.Called(userUuid, tokens)
 	if args.Get(0) == nil {
@@ -16,6 +16,11 @@ func (m *MockRedisService) Set(key string, value interface{}) error {
 	return args.Error(0)
 }
 
+func (m *MockRedisService) SetWithExpiration(key string, value interface{}, expiration time.Duration) error {
+	args := m.Called(key, value, expiration)
+	return args.Error(0)
+}
+
 func (m *MockRedisService) Get(key string) (string, error) {
 	args := m.Called(key)
 	return args.String(0), args.Error(1)
@@ -46,6 +51,11 @@ func (m *MockRedisService) Incr(key string) (int64, error) {
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockRedisService) IncrWithExpiration(key string, expiration time.Duration) (int64, error) {
+	args := m.Called(key, expiration)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *MockRedisService) Decr(key string) (int64, error) {
 	args := m.Called(key)
 	return args.Get(0).(int64), args.Error(1)
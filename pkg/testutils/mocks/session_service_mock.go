@@ -0,0 +1,47 @@
+package mocks
+
+import (
+	"github.com/stretchr/testify/mock"
+
+	"alfredo/ruu-properties/pkg/dtos"
+)
+
+type MockSessionService struct {
+	mock.Mock
+}
+
+func (m *MockSessionService) Start(userUuid string, family string, device string, client dtos.SessionClient, refreshToken string) error {
+	args := m.Called(userUuid, family, device, client, refreshToken)
+	return args.Error(0)
+}
+
+func (m *MockSessionService) Refresh(family string, client dtos.SessionClient, refreshToken string) error {
+	args := m.Called(family, client, refreshToken)
+	return args.Error(0)
+}
+
+func (m *MockSessionService) End(accessToken string) error {
+	args := m.Called(accessToken)
+	return args.Error(0)
+}
+
+func (m *MockSessionService) GetAll(userUuid string, currentToken string) ([]dtos.SessionResponse, error) {
+	args := m.Called(userUuid, currentToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dtos.SessionResponse), args.Error(1)
+}
+
+func (m *MockSessionService) Revoke(userUuid string, uuid string) error {
+	args := m.Called(userUuid, uuid)
+	return args.Error(0)
+}
+
+func (m *MockSessionService) RevokeAll(userUuid string) (*dtos.SessionRevokeAllResponse, error) {
+	args := m.Called(userUuid)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.SessionRevokeAllResponse), args.Error(1)
+}
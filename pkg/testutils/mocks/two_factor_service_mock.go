@@ -0,0 +1,62 @@
+package mocks
+
+import (
+	"github.com/stretchr/testify/mock"
+
+	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/models"
+)
+
+type MockTwoFactorService struct {
+	mock.Mock
+}
+
+func (m *MockTwoFactorService) Status(user *models.User) (*dtos.TwoFactorStatusResponse, error) {
+	args := m.Called(user)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.TwoFactorStatusResponse), args.Error(1)
+}
+
+func (m *MockTwoFactorService) Enroll(user *models.User) (*dtos.TwoFactorEnrollResponse, error) {
+	args := m.Called(user)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.TwoFactorEnrollResponse), args.Error(1)
+}
+
+func (m *MockTwoFactorService) Confirm(user *models.User, request dtos.TwoFactorCodeRequest) (*dtos.TwoFactorRecoveryCodesResponse, error) {
+	args := m.Called(user, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.TwoFactorRecoveryCodesResponse), args.Error(1)
+}
+
+func (m *MockTwoFactorService) Disable(user *models.User, request dtos.TwoFactorCodeRequest) error {
+	args := m.Called(user, request)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorService) RegenerateRecoveryCodes(user *models.User, request dtos.TwoFactorCodeRequest) (*dtos.TwoFactorRecoveryCodesResponse, error) {
+	args := m.Called(user, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.TwoFactorRecoveryCodesResponse), args.Error(1)
+}
+
+func (m *MockTwoFactorService) CreateChallenge(user models.User, device string) (string, error) {
+	args := m.Called(user, device)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockTwoFactorService) CompleteChallenge(request dtos.TwoFactorVerifyRequest) (*dtos.TwoFactorChallenge, error) {
+	args := m.Called(request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.TwoFactorChallenge), args.Error(1)
+}
@@ -16,7 +16,78 @@ func (m *MockUserService) Register(request dtos.UserRegisterRequest) error {
 	return args.Error(0)
 }
 
+func (m *MockUserService) SetTeam(request dtos.UserTeamRequest) (*dtos.UserTeamResponse, error) {
+	args := m.Called(request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.UserTeamResponse), args.Error(1)
+}
+
+func (m *MockUserService) SetRole(request dtos.UserRoleRequest) (*dtos.UserRoleResponse, error) {
+	args := m.Called(request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.UserRoleResponse), args.Error(1)
+}
+
+func (m *MockUserService) GetPermissions(user *models.User) ([]string, error) {
+	args := m.Called(user)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
 func (m *MockUserService) FindUserByUuid(uuid string) (*models.User, error) {
 	args := m.Called(uuid)
 	return args.Get(0).(*models.User), args.Error(1)
 }
+
+func (m *MockUserService) GetAll(request dtos.UserGetRequest) ([]*dtos.UserResponse, *dtos.PaginationMeta, error) {
+	args := m.Called(request)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]*dtos.UserResponse), args.Get(1).(*dtos.PaginationMeta), args.Error(2)
+}
+
+func (m *MockUserService) Create(request dtos.UserCreateRequest) (*dtos.UserResponse, error) {
+	args := m.Called(request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.UserResponse), args.Error(1)
+}
+
+func (m *MockUserService) Deactivate(actorUuid string, uuid string) (*dtos.UserResponse, error) {
+	args := m.Called(actorUuid, uuid)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.UserResponse), args.Error(1)
+}
+
+func (m *MockUserService) Reactivate(uuid string) (*dtos.UserResponse, error) {
+	args := m.Called(uuid)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.UserResponse), args.Error(1)
+}
+
+func (m *MockUserService) ResetPassword(request dtos.UserPasswordResetRequest) error {
+	args := m.Called(request)
+	return args.Error(0)
+}
+
+func (m *MockUserService) Delete(actorUuid string, uuid string) error {
+	args := m.Called(actorUuid, uuid)
+	return args.Error(0)
+}
+
+func (m *MockUserService) TwoFactorRequired(user *models.User) (bool, error) {
+	args := m.Called(user)
+	return args.Bool(0), args.Error(1)
+}
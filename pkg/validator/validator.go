@@ -7,6 +7,8 @@ import (
 	"strings"
 
 	"github.com/go-playground/validator/v10"
+
+	"alfredo/ruu-properties/pkg/helpers"
 )
 
 type CustomValidator struct {
@@ -16,6 +18,7 @@ type CustomValidator struct {
 // NewValidator creates a new validator instance with configured field name function
 func NewValidator() *CustomValidator {
 	validate := validator.New()
+	_ = validate.RegisterValidation("phone", helpers.ValidatePhoneNumber)
 
 	// Register function to get field name from json tag
 	validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
@@ -76,6 +79,8 @@ func getErrorMsg(e validator.FieldError) string {
 		return fmt.Sprintf("should be greater than %s", e.Param())
 	case "gte":
 		return fmt.Sprintf("should be greater than or equal to %s", e.Param())
+	case "phone":
+		return "invalid phone number"
 	default:
 		return fmt.Sprintf("failed validation on %s", e.Tag())
 	}
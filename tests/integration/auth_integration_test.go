@@ -3,9 +3,17 @@ package integration
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"mime/multipart"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
@@ -14,6 +22,8 @@ import (
 
 	"alfredo/ruu-properties/config"
 	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/helpers"
+	"alfredo/ruu-properties/pkg/models"
 	"alfredo/ruu-properties/pkg/router"
 )
 
@@ -35,6 +45,7 @@ func (suite *AuthIntegrationTestSuite) SetupSuite() {
 func (suite *AuthIntegrationTestSuite) SetupTest() {
 	// Clean database before each test
 	suite.db.Exec("TRUNCATE TABLE users RESTART IDENTITY CASCADE")
+	suite.db.Exec("TRUNCATE TABLE user_sessions RESTART IDENTITY CASCADE")
 }
 
 func (suite *AuthIntegrationTestSuite) TearDownSuite() {
@@ -51,7 +62,6 @@ func (suite *AuthIntegrationTestSuite) TestRegisterAndLogin_Success() {
 		"password":              "password123",
 		"confirmation_password": "password123",
 		"phone_number":          "+1234567890",
-		"role":                  "user",
 	}
 
 	// Create multipart form for registration
@@ -95,3 +105,550 @@ func (suite *AuthIntegrationTestSuite) TestRegisterAndLogin_Success() {
 func TestAuthIntegrationTestSuite(t *testing.T) {
 	suite.Run(t, new(AuthIntegrationTestSuite))
 }
+
+// register signs a user up with the password "password123"
+func (suite *AuthIntegrationTestSuite) register(email string, phoneNumber string) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for key, value := range map[string]string{
+		"name":                  "Integration Test User",
+		"email":                 email,
+		"password":              "password123",
+		"confirmation_password": "password123",
+		"phone_number":          phoneNumber,
+	} {
+		writer.WriteField(key, value)
+	}
+	writer.Close()
+
+	registerReq := httptest.NewRequest("POST", "/api/v1/user/register", body)
+	registerReq.Header.Set("Content-Type", writer.FormDataContentType())
+	registerResp, err := suite.app.Test(registerReq)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, registerResp.StatusCode)
+}
+
+// registerAndLogin registers a user and returns the data of their login response
+func (suite *AuthIntegrationTestSuite) registerAndLogin(email string, phoneNumber string) map[string]interface{} {
+	suite.register(email, phoneNumber)
+
+	loginBody, _ := json.Marshal(dtos.LoginRequest{Email: email, Password: "password123"})
+	loginReq := httptest.NewRequest("POST", "/api/v1/auth/login", bytes.NewBuffer(loginBody))
+	loginReq.Header.Set("Content-Type", "application/json")
+	loginResp, err := suite.app.Test(loginReq)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, loginResp.StatusCode)
+
+	var loginResponse dtos.SuccessResponse
+	json.NewDecoder(loginResp.Body).Decode(&loginResponse)
+	data, _ := loginResponse.Data.(map[string]interface{})
+	return data
+}
+
+// refresh exchanges a refresh token and returns the status and the new token pair
+func (suite *AuthIntegrationTestSuite) refresh(refreshToken string) (int, map[string]interface{}) {
+	reqBody, _ := json.Marshal(dtos.RefreshTokenRequest{RefreshToken: refreshToken})
+	req := httptest.NewRequest("POST", "/api/v1/auth/refresh-token", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+
+	var response dtos.SuccessResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+	data, _ := response.Data.(map[string]interface{})
+	return resp.StatusCode, data
+}
+
+func (suite *AuthIntegrationTestSuite) TestRefreshToken_RotationAndReuse() {
+	login := suite.registerAndLogin("refresh@test.com", "+1234567891")
+	firstRefresh, _ := login["refresh_token"].(string)
+	assert.NotEmpty(suite.T(), firstRefresh)
+
+	// Every refresh hands out a new pair
+	status, rotated := suite.refresh(firstRefresh)
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+	secondRefresh, _ := rotated["refresh_token"].(string)
+	accessToken, _ := rotated["access_token"].(string)
+	assert.NotEmpty(suite.T(), secondRefresh)
+	assert.NotEqual(suite.T(), firstRefresh, secondRefresh)
+
+	req := httptest.NewRequest("GET", "/api/v1/clients", nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+
+	// Replaying the used token revokes the family, so the newest tokens stop working as well
+	status, _ = suite.refresh(firstRefresh)
+	assert.Equal(suite.T(), fiber.StatusUnauthorized, status)
+	status, _ = suite.refresh(secondRefresh)
+	assert.Equal(suite.T(), fiber.StatusUnauthorized, status)
+
+	req = httptest.NewRequest("GET", "/api/v1/clients", nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err = suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusUnauthorized, resp.StatusCode)
+
+	// An access token is no refresh token
+	status, _ = suite.refresh(login["access_token"].(string))
+	assert.Equal(suite.T(), fiber.StatusUnauthorized, status)
+}
+
+// authorizedPost sends a JSON POST with the access token and returns the status
+func (suite *AuthIntegrationTestSuite) authorizedPost(path string, accessToken string, payload interface{}) int {
+	reqBody, _ := json.Marshal(payload)
+	req := httptest.NewRequest("POST", path, bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	return resp.StatusCode
+}
+
+func (suite *AuthIntegrationTestSuite) TestLogout_RevokesAccessAndRefreshToken() {
+	login := suite.registerAndLogin("logout@test.com", "+1234567892")
+	accessToken, _ := login["access_token"].(string)
+	refreshToken, _ := login["refresh_token"].(string)
+
+	status := suite.authorizedPost("/api/v1/auth/logout", accessToken, dtos.LogoutRequest{RefreshToken: refreshToken})
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+
+	// Both tokens are dead
+	status = suite.authorizedPost("/api/v1/auth/logout", accessToken, nil)
+	assert.Equal(suite.T(), fiber.StatusUnauthorized, status)
+	status, _ = suite.refresh(refreshToken)
+	assert.Equal(suite.T(), fiber.StatusUnauthorized, status)
+}
+
+func (suite *AuthIntegrationTestSuite) TestLogoutAll_RevokesEveryLogin() {
+	first := suite.registerAndLogin("everywhere@test.com", "+1234567893")
+
+	loginBody, _ := json.Marshal(dtos.LoginRequest{Email: "everywhere@test.com", Password: "password123"})
+	loginReq := httptest.NewRequest("POST", "/api/v1/auth/login", bytes.NewBuffer(loginBody))
+	loginReq.Header.Set("Content-Type", "application/json")
+	loginResp, err := suite.app.Test(loginReq)
+	assert.NoError(suite.T(), err)
+	var loginResponse dtos.SuccessResponse
+	json.NewDecoder(loginResp.Body).Decode(&loginResponse)
+	second, _ := loginResponse.Data.(map[string]interface{})
+
+	status := suite.authorizedPost("/api/v1/auth/logout-all", second["access_token"].(string), nil)
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+
+	for _, login := range []map[string]interface{}{first, second} {
+		status = suite.authorizedPost("/api/v1/auth/logout", login["access_token"].(string), nil)
+		assert.Equal(suite.T(), fiber.StatusUnauthorized, status)
+		status, _ = suite.refresh(login["refresh_token"].(string))
+		assert.Equal(suite.T(), fiber.StatusUnauthorized, status)
+	}
+}
+
+func (suite *AuthIntegrationTestSuite) TestLogoutAll_LoginRightAfterIsValid() {
+	first := suite.registerAndLogin("straightback@test.com", "+1234567894")
+
+	status := suite.authorizedPost("/api/v1/auth/logout-all", first["access_token"].(string), nil)
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+
+	// Logging in within the same second as the revocation gets tokens that work
+	second := suite.login("straightback@test.com", "")
+	status, _ = suite.refresh(second["refresh_token"].(string))
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+}
+
+// login signs an existing user in from the given device and returns the data of the response
+func (suite *AuthIntegrationTestSuite) login(email string, device string) map[string]interface{} {
+	loginBody, _ := json.Marshal(dtos.LoginRequest{Email: email, Password: "password123", Device: device})
+	loginReq := httptest.NewRequest("POST", "/api/v1/auth/login", bytes.NewBuffer(loginBody))
+	loginReq.Header.Set("Content-Type", "application/json")
+	loginReq.Header.Set("User-Agent", "RuuTablet/1.0")
+	loginResp, err := suite.app.Test(loginReq)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, loginResp.StatusCode)
+
+	var loginResponse dtos.SuccessResponse
+	json.NewDecoder(loginResp.Body).Decode(&loginResponse)
+	data, _ := loginResponse.Data.(map[string]interface{})
+	return data
+}
+
+// sessions lists the sessions visible to the access token
+func (suite *AuthIntegrationTestSuite) sessions(accessToken string) (int, []interface{}) {
+	req := httptest.NewRequest("GET", "/api/v1/auth/sessions", nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+
+	var response dtos.SuccessResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+	items, _ := response.Data.([]interface{})
+	return resp.StatusCode, items
+}
+
+func (suite *AuthIntegrationTestSuite) TestSessions_ListAndRevoke() {
+	suite.registerAndLogin("sessions@test.com", "+1234567894")
+	tablet := suite.login("sessions@test.com", "Front desk tablet")
+	phone := suite.login("sessions@test.com", "")
+	tabletToken, _ := tablet["access_token"].(string)
+	phoneToken, _ := phone["access_token"].(string)
+
+	status, items := suite.sessions(phoneToken)
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+	assert.Len(suite.T(), items, 3)
+
+	var tabletSession map[string]interface{}
+	for _, item := range items {
+		session, _ := item.(map[string]interface{})
+		if session["device"] == "Front desk tablet" {
+			tabletSession = session
+		}
+	}
+	if assert.NotNil(suite.T(), tabletSession) {
+		assert.Equal(suite.T(), "RuuTablet/1.0", tabletSession["user_agent"])
+		assert.Equal(suite.T(), false, tabletSession["current"])
+	}
+
+	// The phone signs the shared tablet out
+	req := httptest.NewRequest("DELETE", fmt.Sprintf("/api/v1/auth/sessions/%s", tabletSession["uuid"]), nil)
+	req.Header.Set("Authorization", "Bearer "+phoneToken)
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+
+	status, _ = suite.sessions(tabletToken)
+	assert.Equal(suite.T(), fiber.StatusUnauthorized, status)
+	status, _ = suite.refresh(tablet["refresh_token"].(string))
+	assert.Equal(suite.T(), fiber.StatusUnauthorized, status)
+
+	status, items = suite.sessions(phoneToken)
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+	assert.Len(suite.T(), items, 2)
+
+	// Sessions of other users cannot be revoked
+	other := suite.registerAndLogin("other-sessions@test.com", "+1234567895")
+	_, otherItems := suite.sessions(other["access_token"].(string))
+	otherSession, _ := otherItems[0].(map[string]interface{})
+	req = httptest.NewRequest("DELETE", fmt.Sprintf("/api/v1/auth/sessions/%s", otherSession["uuid"]), nil)
+	req.Header.Set("Authorization", "Bearer "+phoneToken)
+	resp, err = suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusNotFound, resp.StatusCode)
+}
+
+func (suite *AuthIntegrationTestSuite) TestSessions_AdminRevokesEverySession() {
+	agent := suite.registerAndLogin("agent-sessions@test.com", "+1234567896")
+	admin := suite.registerAndLogin("admin-sessions@test.com", "+1234567897")
+	adminToken, _ := admin["access_token"].(string)
+	path := fmt.Sprintf("/api/v1/auth/users/%s/sessions", agent["user_uuid"])
+
+	req := httptest.NewRequest("DELETE", path, nil)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusForbidden, resp.StatusCode)
+
+	suite.db.Model(&models.User{}).Where("email = ?", "admin-sessions@test.com").Update("role", "admin")
+
+	req = httptest.NewRequest("DELETE", path, nil)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	resp, err = suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+
+	status, _ := suite.sessions(agent["access_token"].(string))
+	assert.Equal(suite.T(), fiber.StatusUnauthorized, status)
+	status, _ = suite.sessions(adminToken)
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+}
+
+var mailTokenPattern = regexp.MustCompile(`token=([A-Za-z0-9_-]+)`)
+
+// mailToken reads the token of the latest mail sent to email from the outbox, empty when no
+// mail was sent
+func (suite *AuthIntegrationTestSuite) mailToken(email string) string {
+	files, _ := filepath.Glob(filepath.Join(config.MailOutboxDir, "*.eml"))
+	sort.Sort(sort.Reverse(sort.StringSlice(files)))
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil || !bytes.Contains(content, []byte("To: "+email+"\r\n")) {
+			continue
+		}
+		if match := mailTokenPattern.FindSubmatch(content); match != nil {
+			return string(match[1])
+		}
+	}
+	return ""
+}
+
+// awaitMailToken waits for a mail sent in the background and returns its token, empty when none
+// arrived in time
+func (suite *AuthIntegrationTestSuite) awaitMailToken(email string) string {
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+		if token := suite.mailToken(email); token != "" {
+			return token
+		}
+	}
+	return ""
+}
+
+func (suite *AuthIntegrationTestSuite) TestPasswordReset_SingleUseAndSignsOut() {
+	email := fmt.Sprintf("reset-%d@test.com", time.Now().UnixNano())
+	login := suite.registerAndLogin(email, "+1234567897")
+
+	// Unknown emails get the same answer and no mail
+	status := suite.authorizedPost("/api/v1/auth/forgot-password", "", dtos.ForgotPasswordRequest{Email: "nobody-" + email})
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+
+	status = suite.authorizedPost("/api/v1/auth/forgot-password", "", dtos.ForgotPasswordRequest{Email: email})
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+	token := suite.awaitMailToken(email)
+	assert.NotEmpty(suite.T(), token)
+	assert.Empty(suite.T(), suite.mailToken("nobody-"+email))
+
+	reset := dtos.ResetPasswordRequest{Token: token, Password: "newpassword123", ConfirmationPassword: "newpassword123"}
+	status = suite.authorizedPost("/api/v1/auth/reset-password", "", reset)
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+	status = suite.authorizedPost("/api/v1/auth/reset-password", "", reset)
+	assert.Equal(suite.T(), fiber.StatusBadRequest, status)
+
+	// The sessions signed in with the old password are gone
+	status = suite.authorizedPost("/api/v1/auth/logout", login["access_token"].(string), nil)
+	assert.Equal(suite.T(), fiber.StatusUnauthorized, status)
+	status, _ = suite.refresh(login["refresh_token"].(string))
+	assert.Equal(suite.T(), fiber.StatusUnauthorized, status)
+
+	status = suite.authorizedPost("/api/v1/auth/login", "", dtos.LoginRequest{Email: email, Password: "password123"})
+	assert.NotEqual(suite.T(), fiber.StatusOK, status)
+	status = suite.authorizedPost("/api/v1/auth/login", "", dtos.LoginRequest{Email: email, Password: "newpassword123"})
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+}
+
+func (suite *AuthIntegrationTestSuite) TestEmailVerification_RequiredBlocksLogin() {
+	required := config.EmailVerifyRequired
+	config.EmailVerifyRequired = "true"
+	defer func() { config.EmailVerifyRequired = required }()
+
+	email := fmt.Sprintf("verify-%d@test.com", time.Now().UnixNano())
+	suite.register(email, "+1234567898")
+	credentials := dtos.LoginRequest{Email: email, Password: "password123"}
+
+	status := suite.authorizedPost("/api/v1/auth/login", "", credentials)
+	assert.Equal(suite.T(), fiber.StatusForbidden, status)
+
+	// Resending replaces the link of the registration
+	firstToken := suite.mailToken(email)
+	assert.NotEmpty(suite.T(), firstToken)
+	status = suite.authorizedPost("/api/v1/auth/resend-verification", "", dtos.ResendVerificationRequest{Email: email})
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+	secondToken := suite.mailToken(email)
+	assert.NotEqual(suite.T(), firstToken, secondToken)
+
+	status = suite.authorizedPost("/api/v1/auth/verify-email", "", dtos.VerifyEmailRequest{Token: firstToken})
+	assert.Equal(suite.T(), fiber.StatusBadRequest, status)
+	status = suite.authorizedPost("/api/v1/auth/verify-email", "", dtos.VerifyEmailRequest{Token: secondToken})
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+	status = suite.authorizedPost("/api/v1/auth/verify-email", "", dtos.VerifyEmailRequest{Token: secondToken})
+	assert.Equal(suite.T(), fiber.StatusBadRequest, status)
+
+	status = suite.authorizedPost("/api/v1/auth/login", "", credentials)
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+
+	var user models.User
+	assert.NoError(suite.T(), suite.db.Where("email = ?", email).First(&user).Error)
+	assert.NotNil(suite.T(), user.EmailVerifiedAt)
+}
+
+// send sends a JSON request with the access token and returns the status and the data of the response
+func (suite *AuthIntegrationTestSuite) send(method string, path string, accessToken string, payload interface{}) (int, map[string]interface{}) {
+	reqBody, _ := json.Marshal(payload)
+	req := httptest.NewRequest(method, path, bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+
+	var response dtos.SuccessResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+	data, _ := response.Data.(map[string]interface{})
+	return resp.StatusCode, data
+}
+
+// totp is the code of the authenticator app, periods after now
+func (suite *AuthIntegrationTestSuite) totp(secret string, periods int) string {
+	code, err := helpers.TotpCode(secret, time.Now().Add(time.Duration(periods*helpers.TotpPeriod)*time.Second))
+	assert.NoError(suite.T(), err)
+	return code
+}
+
+func (suite *AuthIntegrationTestSuite) TestTwoFactor_EnrollLoginAndRecover() {
+	email := fmt.Sprintf("two-factor-%d@test.com", time.Now().UnixNano())
+	login := suite.registerAndLogin(email, "+1234567899")
+	accessToken, _ := login["access_token"].(string)
+
+	status, enrollment := suite.send("POST", "/api/v1/auth/2fa/enroll", accessToken, nil)
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+	secret, _ := enrollment["secret"].(string)
+	assert.NotEmpty(suite.T(), secret)
+	assert.Contains(suite.T(), enrollment["provisioning_uri"], "otpauth://totp/")
+
+	var user models.User
+	assert.NoError(suite.T(), suite.db.Where("email = ?", email).First(&user).Error)
+	assert.NotContains(suite.T(), user.TwoFactorSecret, secret)
+	assert.Nil(suite.T(), user.TwoFactorEnabledAt)
+
+	status, _ = suite.send("POST", "/api/v1/auth/2fa/confirm", accessToken, dtos.TwoFactorCodeRequest{Code: "000000"})
+	assert.Equal(suite.T(), fiber.StatusBadRequest, status)
+	status, confirmed := suite.send("POST", "/api/v1/auth/2fa/confirm", accessToken, dtos.TwoFactorCodeRequest{Code: suite.totp(secret, 0)})
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+	recoveryCodes, _ := confirmed["recovery_codes"].([]interface{})
+	assert.Len(suite.T(), recoveryCodes, 10)
+
+	// The password alone only gets a challenge
+	challenge := suite.login(email, "Office laptop")
+	assert.Equal(suite.T(), true, challenge["two_factor_required"])
+	assert.Nil(suite.T(), challenge["access_token"])
+	challengeToken, _ := challenge["challenge_token"].(string)
+
+	// The code used to confirm cannot be replayed
+	status, _ = suite.send("POST", "/api/v1/auth/2fa/verify", "", dtos.TwoFactorVerifyRequest{ChallengeToken: challengeToken, Code: suite.totp(secret, 0)})
+	assert.Equal(suite.T(), fiber.StatusUnauthorized, status)
+	status, verified := suite.send("POST", "/api/v1/auth/2fa/verify", "", dtos.TwoFactorVerifyRequest{ChallengeToken: challengeToken, Code: suite.totp(secret, 1)})
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+	assert.NotEmpty(suite.T(), verified["access_token"])
+	status, _ = suite.send("POST", "/api/v1/auth/2fa/verify", "", dtos.TwoFactorVerifyRequest{ChallengeToken: challengeToken, Code: suite.totp(secret, -1)})
+	assert.Equal(suite.T(), fiber.StatusUnauthorized, status)
+
+	// A recovery code works once, in whatever case it is typed
+	recoveryCode, _ := recoveryCodes[0].(string)
+	challengeToken, _ = suite.login(email, "")["challenge_token"].(string)
+	status, _ = suite.send("POST", "/api/v1/auth/2fa/verify", "", dtos.TwoFactorVerifyRequest{ChallengeToken: challengeToken, Code: strings.ToUpper(recoveryCode)})
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+	challengeToken, _ = suite.login(email, "")["challenge_token"].(string)
+	status, _ = suite.send("POST", "/api/v1/auth/2fa/verify", "", dtos.TwoFactorVerifyRequest{ChallengeToken: challengeToken, Code: recoveryCode})
+	assert.Equal(suite.T(), fiber.StatusUnauthorized, status)
+
+	status, twoFactor := suite.send("GET", "/api/v1/auth/2fa", accessToken, nil)
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+	assert.Equal(suite.T(), true, twoFactor["enabled"])
+	assert.Equal(suite.T(), float64(9), twoFactor["recovery_codes_left"])
+
+	// Too many wrong codes drop the challenge
+	for i := 0; i < 5; i++ {
+		status, _ = suite.send("POST", "/api/v1/auth/2fa/verify", "", dtos.TwoFactorVerifyRequest{ChallengeToken: challengeToken, Code: "000000"})
+		assert.Equal(suite.T(), fiber.StatusUnauthorized, status)
+	}
+	status, _ = suite.send("POST", "/api/v1/auth/2fa/verify", "", dtos.TwoFactorVerifyRequest{ChallengeToken: challengeToken, Code: recoveryCodes[1].(string)})
+	assert.Equal(suite.T(), fiber.StatusUnauthorized, status)
+
+	status, _ = suite.send("POST", "/api/v1/auth/2fa/disable", accessToken, dtos.TwoFactorCodeRequest{Code: recoveryCodes[1].(string)})
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+	status = suite.authorizedPost("/api/v1/auth/login", "", dtos.LoginRequest{Email: email, Password: "password123"})
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+	assert.NotEmpty(suite.T(), suite.login(email, "")["access_token"])
+}
+
+func (suite *AuthIntegrationTestSuite) TestTwoFactor_RequiredByRole() {
+	admin := suite.registerAndLogin("admin-two-factor@test.com", "+1234567800")
+	adminToken, _ := admin["access_token"].(string)
+	suite.db.Model(&models.User{}).Where("email = ?", "admin-two-factor@test.com").Update("role", "admin")
+
+	email := fmt.Sprintf("finance-two-factor-%d@test.com", time.Now().UnixNano())
+	login := suite.registerAndLogin(email, "+1234567801")
+	accessToken, _ := login["access_token"].(string)
+	suite.db.Model(&models.User{}).Where("email = ?", email).Update("role", "finance")
+
+	status, role := suite.send("PUT", "/api/v1/roles/finance/two-factor", adminToken, dtos.RoleTwoFactorRequest{Required: true})
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+	assert.Equal(suite.T(), true, role["require_two_factor"])
+	defer suite.send("PUT", "/api/v1/roles/finance/two-factor", adminToken, dtos.RoleTwoFactorRequest{Required: false})
+
+	// Until they enroll, finance users can do nothing else
+	req := httptest.NewRequest("GET", "/api/v1/clients", nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusForbidden, resp.StatusCode)
+
+	status, twoFactor := suite.send("GET", "/api/v1/auth/2fa", accessToken, nil)
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+	assert.Equal(suite.T(), true, twoFactor["required"])
+
+	status, enrollment := suite.send("POST", "/api/v1/auth/2fa/enroll", accessToken, nil)
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+	secret, _ := enrollment["secret"].(string)
+	status, _ = suite.send("POST", "/api/v1/auth/2fa/confirm", accessToken, dtos.TwoFactorCodeRequest{Code: suite.totp(secret, 0)})
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+
+	req = httptest.NewRequest("GET", "/api/v1/clients", nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err = suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+
+	// The role keeps them from turning it off
+	status, _ = suite.send("POST", "/api/v1/auth/2fa/disable", accessToken, dtos.TwoFactorCodeRequest{Code: suite.totp(secret, 1)})
+	assert.Equal(suite.T(), fiber.StatusForbidden, status)
+}
+
+func (suite *AuthIntegrationTestSuite) TestTwoFactor_WrongCodesLockOut() {
+	email := fmt.Sprintf("two-factor-lockout-%d@test.com", time.Now().UnixNano())
+	login := suite.registerAndLogin(email, "+1234567802")
+	accessToken, _ := login["access_token"].(string)
+
+	_, enrollment := suite.send("POST", "/api/v1/auth/2fa/enroll", accessToken, nil)
+	secret, _ := enrollment["secret"].(string)
+	status, _ := suite.send("POST", "/api/v1/auth/2fa/confirm", accessToken, dtos.TwoFactorCodeRequest{Code: suite.totp(secret, 0)})
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+
+	// Parallel guesses against one challenge share its five attempts
+	challengeToken, _ := suite.login(email, "")["challenge_token"].(string)
+	statuses := make(chan int, 8)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status, _ := suite.send("POST", "/api/v1/auth/2fa/verify", "", dtos.TwoFactorVerifyRequest{ChallengeToken: challengeToken, Code: "000000"})
+			statuses <- status
+		}()
+	}
+	wg.Wait()
+	close(statuses)
+	for status := range statuses {
+		assert.Equal(suite.T(), fiber.StatusUnauthorized, status)
+	}
+	status, _ = suite.send("POST", "/api/v1/auth/2fa/verify", "", dtos.TwoFactorVerifyRequest{ChallengeToken: challengeToken, Code: suite.totp(secret, 1)})
+	assert.Equal(suite.T(), fiber.StatusUnauthorized, status)
+
+	// Fresh challenges do not reset the count of the user
+	challengeToken, _ = suite.login(email, "")["challenge_token"].(string)
+	for i := 0; i < 5; i++ {
+		status, _ = suite.send("POST", "/api/v1/auth/2fa/verify", "", dtos.TwoFactorVerifyRequest{ChallengeToken: challengeToken, Code: "000000"})
+		assert.Equal(suite.T(), fiber.StatusUnauthorized, status)
+	}
+	status = suite.authorizedPost("/api/v1/auth/login", "", dtos.LoginRequest{Email: email, Password: "password123"})
+	assert.Equal(suite.T(), fiber.StatusTooManyRequests, status)
+}
+
+func (suite *AuthIntegrationTestSuite) TestLogin_SameErrorForUnknownEmailAndWrongPassword() {
+	email := fmt.Sprintf("enumeration-%d@test.com", time.Now().UnixNano())
+	suite.register(email, "+1234567803")
+
+	responses := make([]dtos.ErrorResponseDTO, 0, 2)
+	for _, credentials := range []dtos.LoginRequest{
+		{Email: "nobody-" + email, Password: "password123"},
+		{Email: email, Password: "wrongpassword"},
+	} {
+		reqBody, _ := json.Marshal(credentials)
+		req := httptest.NewRequest("POST", "/api/v1/auth/login", bytes.NewBuffer(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := suite.app.Test(req)
+		assert.NoError(suite.T(), err)
+		assert.NotEqual(suite.T(), fiber.StatusOK, resp.StatusCode)
+
+		var response dtos.ErrorResponseDTO
+		json.NewDecoder(resp.Body).Decode(&response)
+		responses = append(responses, response)
+	}
+	assert.Equal(suite.T(), responses[0], responses[1])
+}
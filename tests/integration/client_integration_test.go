@@ -1,12 +1,18 @@
 package integration
 
 import (
+	"archive/zip"
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net/http"
 	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -17,7 +23,11 @@ import (
 
 	"alfredo/ruu-properties/config"
 	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/helpers"
+	"alfredo/ruu-properties/pkg/models"
+	"alfredo/ruu-properties/pkg/repositories"
 	"alfredo/ruu-properties/pkg/router"
+	"alfredo/ruu-properties/pkg/services"
 )
 
 type ClientIntegrationTestSuite struct {
@@ -44,6 +54,10 @@ func (suite *ClientIntegrationTestSuite) SetupTest() {
 	// Clean database before each test
 	suite.db.Exec("TRUNCATE TABLE users RESTART IDENTITY CASCADE")
 	suite.db.Exec("TRUNCATE TABLE clients RESTART IDENTITY CASCADE")
+	suite.db.Exec("TRUNCATE TABLE client_activities RESTART IDENTITY CASCADE")
+	suite.db.Exec("TRUNCATE TABLE change_logs RESTART IDENTITY CASCADE")
+	suite.db.Exec("TRUNCATE TABLE client_documents RESTART IDENTITY CASCADE")
+	suite.db.Exec("TRUNCATE TABLE client_contacts RESTART IDENTITY CASCADE")
 
 	// Setup auth token after cleaning database
 	suite.setupAuthToken()
@@ -59,72 +73,16 @@ func (suite *ClientIntegrationTestSuite) TearDownSuite() {
 	db.Close()
 }
 
-// setupAuthToken creates a user and gets authentication token
+// setupAuthToken signs an agent in
 func (suite *ClientIntegrationTestSuite) setupAuthToken() {
-	// Generate unique email for each test run
-	timestamp := time.Now().UnixNano()
-	email := fmt.Sprintf("integration-%d@test.com", timestamp)
-
-	// First, register a user
-	registerData := map[string]string{
-		"name":                  "Integration Test User",
-		"email":                 email, // Use unique email
-		"password":              "password123",
-		"confirmation_password": "password123",
-		"phone_number":          fmt.Sprintf("+123456789%d", timestamp%1000), // Unique phone too
-		"role":                  "user",
-	}
-
-	// Create multipart form for registration
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-	for key, value := range registerData {
-		writer.WriteField(key, value)
-	}
-	writer.Close()
-
-	// Register user
-	registerReq := httptest.NewRequest("POST", "/api/v1/user/register", body)
-	registerReq.Header.Set("Content-Type", writer.FormDataContentType())
-
-	registerResp, err := suite.app.Test(registerReq)
-	fmt.Println("Register Response Status:", registerResp.StatusCode)
-
-	// Read response body for debugging
-	respBody, _ := io.ReadAll(registerResp.Body)
-	fmt.Println("Register Response Body:", string(respBody))
-
-	// Reset body for further reading
-	registerResp.Body = io.NopCloser(bytes.NewBuffer(respBody))
-
-	assert.NoError(suite.T(), err)
-	assert.Equal(suite.T(), fiber.StatusOK, registerResp.StatusCode)
-
-	// Login to get token
-	loginData := dtos.LoginRequest{
-		Email:    email,
-		Password: "password123",
-	}
-
-	loginBody, _ := json.Marshal(loginData)
-	loginReq := httptest.NewRequest("POST", "/api/v1/auth/login", bytes.NewBuffer(loginBody))
-	loginReq.Header.Set("Content-Type", "application/json")
-
-	loginResp, err := suite.app.Test(loginReq)
-	assert.NoError(suite.T(), err)
-	assert.Equal(suite.T(), fiber.StatusOK, loginResp.StatusCode)
-
-	var loginResponse dtos.SuccessResponse
-	json.NewDecoder(loginResp.Body).Decode(&loginResponse)
-
-	// Extract token from response
-	if data, ok := loginResponse.Data.(map[string]interface{}); ok {
-		if token, ok := data["access_token"].(string); ok {
-			suite.token = token
-		}
-	}
+	suite.token, _ = signUp(suite.T(), suite.app, suite.db, "integration", "agent")
+}
 
-	assert.NotEmpty(suite.T(), suite.token, "Token should not be empty")
+// userUUID looks up the user signed up with the given email prefix
+func (suite *ClientIntegrationTestSuite) userUUID(prefix string) string {
+	var user models.User
+	assert.NoError(suite.T(), suite.db.Where("email LIKE ?", prefix+"-%").First(&user).Error)
+	return user.UUID
 }
 
 func (suite *ClientIntegrationTestSuite) TestCreateClient_Success() {
@@ -546,3 +504,1338 @@ func (suite *ClientIntegrationTestSuite) TestUpdateClient_Success() {
 	assert.Equal(suite.T(), "Client updated successfully", updateResponse.Message)
 
 }
+
+func (suite *ClientIntegrationTestSuite) TestExportClients_CSV() {
+	// Create clients to export
+	for i := 1; i <= 3; i++ {
+		clientData := dtos.ClientRequest{
+			Name:          fmt.Sprintf("PT. Export Company %d", i),
+			Email:         fmt.Sprintf("export%d@company.com", i),
+			PhoneNumber:   fmt.Sprintf("+62812345678%d", i),
+			Address:       fmt.Sprintf("Jl. Export No. %d, Jakarta", i),
+			ContactPerson: fmt.Sprintf("John Doe %d", i),
+		}
+
+		clientBody, _ := json.Marshal(clientData)
+		createReq := httptest.NewRequest("POST", "/api/v1/clients", bytes.NewBuffer(clientBody))
+		createReq.Header.Set("Content-Type", "application/json")
+		createReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+
+		createResp, err := suite.app.Test(createReq)
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), fiber.StatusOK, createResp.StatusCode)
+	}
+
+	req := httptest.NewRequest("GET", "/api/v1/clients/export?format=csv&columns=name,email&search=Company%202&search_by=name", nil)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+	assert.Contains(suite.T(), resp.Header.Get("Content-Type"), "text/csv")
+	assert.Contains(suite.T(), resp.Header.Get("Content-Disposition"), "attachment")
+
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(suite.T(), "name,email\nPT. Export Company 2,export2@company.com\n", string(body))
+}
+
+func (suite *ClientIntegrationTestSuite) TestExportClients_EscapesFormulas() {
+	suite.createClient(dtos.ClientRequest{
+		Name:          "=HYPERLINK(\"http://evil.example\")",
+		Email:         "formula@company.com",
+		PhoneNumber:   "+6281234567801",
+		Address:       "-1+cmd|' /C calc'!A0",
+		ContactPerson: "+1-HYPERLINK(\"http://evil.example\")",
+	})
+
+	req := httptest.NewRequest("GET", "/api/v1/clients/export?format=csv&columns=name,phone_number,address,contact_person", nil)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+
+	// Only the E.164 phone number keeps its leading +
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), records, 2) {
+		assert.Equal(suite.T(), []string{
+			"'=HYPERLINK(\"http://evil.example\")",
+			"+6281234567801",
+			"'-1+cmd|' /C calc'!A0",
+			"'+1-HYPERLINK(\"http://evil.example\")",
+		}, records[1])
+	}
+}
+
+func (suite *ClientIntegrationTestSuite) TestExportClients_InvalidFormat() {
+	req := httptest.NewRequest("GET", "/api/v1/clients/export?format=pdf", nil)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusBadRequest, resp.StatusCode)
+}
+
+// createClient creates a client through the API and returns its UUID
+func (suite *ClientIntegrationTestSuite) createClient(clientData dtos.ClientRequest) string {
+	clientBody, _ := json.Marshal(clientData)
+	createReq := httptest.NewRequest("POST", "/api/v1/clients", bytes.NewBuffer(clientBody))
+	createReq.Header.Set("Content-Type", "application/json")
+	createReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+
+	createResp, err := suite.app.Test(createReq)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, createResp.StatusCode)
+
+	var createResponse dtos.SuccessResponse
+	json.NewDecoder(createResp.Body).Decode(&createResponse)
+	data, _ := createResponse.Data.(map[string]interface{})
+	uuid, _ := data["uuid"].(string)
+	return uuid
+}
+
+// createLegacyClient inserts a client directly, as rows stored before phone numbers were normalized.
+// The client belongs to the signed in test user unless it names another owner.
+func (suite *ClientIntegrationTestSuite) createLegacyClient(client models.Client) string {
+	if client.OwnerUUID == nil {
+		owner := suite.userUUID("integration")
+		client.OwnerUUID = &owner
+	}
+	assert.NoError(suite.T(), suite.db.Create(&client).Error)
+	return client.UUID
+}
+
+func (suite *ClientIntegrationTestSuite) TestGetDuplicateClients_Success() {
+	suite.createLegacyClient(models.Client{
+		Name:          "Budi S.",
+		Email:         "budi.s@gmail.com",
+		PhoneNumber:   "0812-3456-7890",
+		Address:       "Jl. Sudirman No. 1, Jakarta",
+		ContactPerson: "Budi",
+	})
+	suite.createClient(dtos.ClientRequest{
+		Name:          "Budi Santoso",
+		Email:         "budi.santoso@company.com",
+		PhoneNumber:   "+6281234567890",
+		Address:       "Jl. Sudirman No. 1, Jakarta",
+		ContactPerson: "Budi",
+	})
+	suite.createClient(dtos.ClientRequest{
+		Name:          "PT. Unrelated Company",
+		Email:         "info@unrelated.com",
+		PhoneNumber:   "+628999999999",
+		Address:       "Jl. Thamrin No. 2, Jakarta",
+		ContactPerson: "Jane Doe",
+	})
+
+	req := httptest.NewRequest("GET", "/api/v1/clients/duplicates", nil)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+
+	var response struct {
+		Data []dtos.ClientDuplicateResponse `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&response)
+	assert.Len(suite.T(), response.Data, 1)
+	assert.Contains(suite.T(), response.Data[0].Reasons, "phone_number")
+	assert.Contains(suite.T(), response.Data[0].Reasons, "name")
+}
+
+func (suite *ClientIntegrationTestSuite) TestMergeClients_Success() {
+	survivorUUID := suite.createClient(dtos.ClientRequest{
+		Name:          "Budi Santoso",
+		Email:         "budi.santoso@company.com",
+		PhoneNumber:   "+6281234567890",
+		Address:       "Jl. Sudirman No. 1, Jakarta",
+		ContactPerson: "Budi",
+	})
+	duplicateUUID := suite.createLegacyClient(models.Client{
+		Name:          "Budi S.",
+		Email:         "budi.s@gmail.com",
+		PhoneNumber:   "0812-3456-7890",
+		Address:       "Jl. Sudirman No. 1, Jakarta",
+		ContactPerson: "Budi",
+	})
+
+	err := suite.db.Exec("INSERT INTO properties (owner_client_uuid, title, price, address, city) VALUES (?, ?, ?, ?, ?)",
+		duplicateUUID, "Rumah Sudirman", 1000, "Jl. Sudirman No. 1", "Jakarta").Error
+	assert.NoError(suite.T(), err)
+
+	mergeBody, _ := json.Marshal(map[string]string{"duplicate_uuid": duplicateUUID})
+	req := httptest.NewRequest("POST", fmt.Sprintf("/api/v1/clients/%s/merge", survivorUUID), bytes.NewBuffer(mergeBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+
+	// The property of the duplicate now belongs to the survivor
+	var owner string
+	suite.db.Raw("SELECT owner_client_uuid FROM properties WHERE title = ?", "Rumah Sudirman").Scan(&owner)
+	assert.Equal(suite.T(), survivorUUID, owner)
+
+	// The duplicate is soft-deleted
+	getReq := httptest.NewRequest("GET", fmt.Sprintf("/api/v1/clients/%s", duplicateUUID), nil)
+	getReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+
+	getResp, err := suite.app.Test(getReq)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusBadRequest, getResp.StatusCode)
+}
+
+func (suite *ClientIntegrationTestSuite) TestMergeClients_OppositeDirectionsAtOnce() {
+	first := suite.createClient(dtos.ClientRequest{
+		Name:          "Budi Santoso",
+		Email:         "budi.santoso@company.com",
+		PhoneNumber:   "+6281234567890",
+		Address:       "Jl. Sudirman No. 1, Jakarta",
+		ContactPerson: "Budi",
+	})
+	second := suite.createClient(dtos.ClientRequest{
+		Name:          "Budi S.",
+		Email:         "budi.s@gmail.com",
+		PhoneNumber:   "+6281234567891",
+		Address:       "Jl. Sudirman No. 1, Jakarta",
+		ContactPerson: "Budi",
+	})
+
+	merge := func(survivorUUID string, duplicateUUID string) int {
+		mergeBody, _ := json.Marshal(map[string]string{"duplicate_uuid": duplicateUUID})
+		req := httptest.NewRequest("POST", fmt.Sprintf("/api/v1/clients/%s/merge", survivorUUID), bytes.NewBuffer(mergeBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+		resp, err := suite.app.Test(req, -1)
+		assert.NoError(suite.T(), err)
+		return resp.StatusCode
+	}
+
+	statuses := make([]int, 2)
+	var wg sync.WaitGroup
+	for i, pair := range [][2]string{{first, second}, {second, first}} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			statuses[i] = merge(pair[0], pair[1])
+		}()
+	}
+	wg.Wait()
+
+	// One merge wins, the other finds its survivor or duplicate gone and one client is left
+	assert.ElementsMatch(suite.T(), []int{fiber.StatusOK, fiber.StatusNotFound}, statuses)
+	var remaining int64
+	suite.db.Model(&models.Client{}).Where("uuid IN ?", []string{first, second}).Count(&remaining)
+	assert.Equal(suite.T(), int64(1), remaining)
+}
+
+func (suite *ClientIntegrationTestSuite) TestMergeClients_IntoItself() {
+	survivorUUID := suite.createClient(dtos.ClientRequest{
+		Name:          "Budi Santoso",
+		Email:         "budi.santoso@company.com",
+		PhoneNumber:   "+6281234567890",
+		Address:       "Jl. Sudirman No. 1, Jakarta",
+		ContactPerson: "Budi",
+	})
+
+	mergeBody, _ := json.Marshal(map[string]string{"duplicate_uuid": survivorUUID})
+	req := httptest.NewRequest("POST", fmt.Sprintf("/api/v1/clients/%s/merge", survivorUUID), bytes.NewBuffer(mergeBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func (suite *ClientIntegrationTestSuite) TestCreateClient_NormalizesPhoneNumber() {
+	suite.createClient(dtos.ClientRequest{
+		Name:          "Budi Santoso",
+		Email:         "budi.santoso@company.com",
+		PhoneNumber:   "0812-3456-7890",
+		Address:       "Jl. Sudirman No. 1, Jakarta",
+		ContactPerson: "Budi",
+	})
+
+	var client models.Client
+	assert.NoError(suite.T(), suite.db.Where("email = ?", "budi.santoso@company.com").First(&client).Error)
+	assert.Equal(suite.T(), "+6281234567890", client.PhoneNumber)
+
+	// The same number in another format hits the unique index
+	clientBody, _ := json.Marshal(dtos.ClientRequest{
+		Name:          "Budi S.",
+		Email:         "budi.s@gmail.com",
+		PhoneNumber:   "62 812 3456 7890",
+		Address:       "Jl. Sudirman No. 1, Jakarta",
+		ContactPerson: "Budi",
+	})
+	req := httptest.NewRequest("POST", "/api/v1/clients", bytes.NewBuffer(clientBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.NotEqual(suite.T(), fiber.StatusOK, resp.StatusCode)
+}
+
+func (suite *ClientIntegrationTestSuite) TestCreateClient_FifteenDigitPhoneNumber() {
+	// The longest number E.164 allows, 16 characters with its +
+	suite.createClient(dtos.ClientRequest{
+		Name:          "Li Wei",
+		Email:         "li.wei@company.com",
+		PhoneNumber:   "+86 1381 2345 6789 0",
+		Address:       "Jl. Sudirman No. 1, Jakarta",
+		ContactPerson: "Li",
+	})
+
+	var client models.Client
+	assert.NoError(suite.T(), suite.db.Where("email = ?", "li.wei@company.com").First(&client).Error)
+	assert.Equal(suite.T(), "+861381234567890", client.PhoneNumber)
+}
+
+func (suite *ClientIntegrationTestSuite) TestCreateClient_InvalidPhoneNumber() {
+	clientBody, _ := json.Marshal(dtos.ClientRequest{
+		Name:          "Budi Santoso",
+		Email:         "budi.santoso@company.com",
+		PhoneNumber:   "call me maybe",
+		Address:       "Jl. Sudirman No. 1, Jakarta",
+		ContactPerson: "Budi",
+	})
+	req := httptest.NewRequest("POST", "/api/v1/clients", bytes.NewBuffer(clientBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func (suite *ClientIntegrationTestSuite) TestClientTimeline_NotesAndCalls() {
+	clientUUID := suite.createClient(dtos.ClientRequest{
+		Name:          "Budi Santoso",
+		Email:         "budi.santoso@company.com",
+		PhoneNumber:   "+6281234567890",
+		Address:       "Jl. Sudirman No. 1, Jakarta",
+		ContactPerson: "Budi",
+	})
+
+	noteBody, _ := json.Marshal(map[string]string{"body": "Interested in a 2BR unit near Sudirman"})
+	noteReq := httptest.NewRequest("POST", fmt.Sprintf("/api/v1/clients/%s/notes", clientUUID), bytes.NewBuffer(noteBody))
+	noteReq.Header.Set("Content-Type", "application/json")
+	noteReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+
+	noteResp, err := suite.app.Test(noteReq)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusCreated, noteResp.StatusCode)
+
+	callBody, _ := json.Marshal(map[string]string{
+		"summary":      "Asked for a viewing next week",
+		"outcome":      "answered",
+		"follow_up_at": time.Now().Add(7 * 24 * time.Hour).Format(time.RFC3339),
+	})
+	callReq := httptest.NewRequest("POST", fmt.Sprintf("/api/v1/clients/%s/calls", clientUUID), bytes.NewBuffer(callBody))
+	callReq.Header.Set("Content-Type", "application/json")
+	callReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+
+	callResp, err := suite.app.Test(callReq)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusCreated, callResp.StatusCode)
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("/api/v1/clients/%s/timeline", clientUUID), nil)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+
+	var response struct {
+		Data []dtos.ClientTimelineItem `json:"data"`
+		Meta dtos.PaginationMeta       `json:"meta"`
+	}
+	json.NewDecoder(resp.Body).Decode(&response)
+	assert.Equal(suite.T(), 2, response.Meta.Total)
+	if assert.Len(suite.T(), response.Data, 2) {
+		// Newest first
+		assert.Equal(suite.T(), "call", response.Data[0].Type)
+		assert.Equal(suite.T(), "answered", response.Data[0].Outcome)
+		assert.NotNil(suite.T(), response.Data[0].FollowUpAt)
+		assert.Equal(suite.T(), "note", response.Data[1].Type)
+	}
+}
+
+func (suite *ClientIntegrationTestSuite) TestLogCall_InvalidOutcome() {
+	clientUUID := suite.createClient(dtos.ClientRequest{
+		Name:          "Budi Santoso",
+		Email:         "budi.santoso@company.com",
+		PhoneNumber:   "+6281234567890",
+		Address:       "Jl. Sudirman No. 1, Jakarta",
+		ContactPerson: "Budi",
+	})
+
+	callBody, _ := json.Marshal(map[string]string{"summary": "Left a message", "outcome": "maybe"})
+	req := httptest.NewRequest("POST", fmt.Sprintf("/api/v1/clients/%s/calls", clientUUID), bytes.NewBuffer(callBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func (suite *ClientIntegrationTestSuite) TestClientTimeline_NotFound() {
+	req := httptest.NewRequest("GET", "/api/v1/clients/123e4567-e89b-12d3-a456-426614174000/timeline", nil)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusNotFound, resp.StatusCode)
+}
+
+func (suite *ClientIntegrationTestSuite) getClientPage(query string) (int, dtos.CursorPaginatedSuccessResponse) {
+	req := httptest.NewRequest("GET", "/api/v1/clients?"+query, nil)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+
+	var response dtos.CursorPaginatedSuccessResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+	return resp.StatusCode, response
+}
+
+func (suite *ClientIntegrationTestSuite) TestGetAllClients_Cursor() {
+	for i := 1; i <= 3; i++ {
+		suite.createClient(dtos.ClientRequest{
+			Name:          fmt.Sprintf("PT. Cursor %d", i),
+			Email:         fmt.Sprintf("cursor%d@company.com", i),
+			PhoneNumber:   fmt.Sprintf("+62812345600%d", i),
+			Address:       "Jl. Cursor, Jakarta",
+			ContactPerson: "John Doe",
+		})
+	}
+
+	status, firstPage := suite.getClientPage("cursor=&limit=2&sort_by=name&sort_order=asc")
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+	assert.Len(suite.T(), firstPage.Data, 2)
+	assert.True(suite.T(), firstPage.Meta.HasMore)
+	assert.NotEmpty(suite.T(), firstPage.Meta.NextCursor)
+	if assert.NotNil(suite.T(), firstPage.Meta.Total) {
+		assert.Equal(suite.T(), 3, *firstPage.Meta.Total)
+	}
+
+	status, secondPage := suite.getClientPage("cursor=" + firstPage.Meta.NextCursor + "&limit=2&sort_by=name&sort_order=asc&with_total=false")
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+	assert.False(suite.T(), secondPage.Meta.HasMore)
+	assert.Empty(suite.T(), secondPage.Meta.NextCursor)
+	assert.Nil(suite.T(), secondPage.Meta.Total)
+
+	clients, _ := secondPage.Data.([]interface{})
+	if assert.Len(suite.T(), clients, 1) {
+		assert.Equal(suite.T(), "PT. Cursor 3", clients[0].(map[string]interface{})["name"])
+	}
+}
+
+func (suite *ClientIntegrationTestSuite) TestGetAllClients_Filters() {
+	for i, name := range []string{"Alpha Corp", "Beta Corp", "Gamma Ltd"} {
+		suite.createClient(dtos.ClientRequest{
+			Name:          name,
+			Email:         fmt.Sprintf("filter%d@%s.com", i, strings.ToLower(strings.Fields(name)[1])),
+			PhoneNumber:   fmt.Sprintf("+62812345608%d", i),
+			Address:       "Jl. Filter No. 1, Jakarta",
+			ContactPerson: "John Doe",
+		})
+	}
+
+	var beta models.Client
+	assert.NoError(suite.T(), suite.db.Where("name = ?", "Beta Corp").First(&beta).Error)
+	suite.db.Create(&models.ClientTag{ClientUUID: beta.UUID, Tag: "vip"})
+
+	getClients := func(query string) (int, []dtos.ClientResponse) {
+		req := httptest.NewRequest("GET", "/api/v1/clients?"+query, nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+		resp, err := suite.app.Test(req)
+		assert.NoError(suite.T(), err)
+
+		var response struct {
+			Data []dtos.ClientResponse `json:"data"`
+		}
+		json.NewDecoder(resp.Body).Decode(&response)
+		return resp.StatusCode, response.Data
+	}
+
+	status, clients := getClients("filter%5Bemail%5D%5Bilike%5D=corp.com&filter%5Bcreated_at%5D%5Bgte%5D=2000-01-01")
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+	assert.Len(suite.T(), clients, 2)
+
+	status, clients = getClients("filter%5Bemail%5D%5Bilike%5D=corp.com&filter%5Btags%5D%5Bin%5D=VIP,other")
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+	if assert.Len(suite.T(), clients, 1) {
+		assert.Equal(suite.T(), "Beta Corp", clients[0].Name)
+	}
+
+	status, clients = getClients("filter%5Bname%5D%5Bnin%5D=Alpha%20Corp,Beta%20Corp")
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+	if assert.Len(suite.T(), clients, 1) {
+		assert.Equal(suite.T(), "Gamma Ltd", clients[0].Name)
+	}
+
+	// Wildcards in an ilike value match only themselves
+	for _, value := range []string{"%25", "_", "%5C"} {
+		status, clients = getClients("filter%5Bname%5D%5Bilike%5D=" + value)
+		assert.Equal(suite.T(), fiber.StatusOK, status)
+		assert.Empty(suite.T(), clients, value)
+	}
+
+	status, _ = getClients("filter%5Bpassword%5D%5Beq%5D=secret")
+	assert.Equal(suite.T(), fiber.StatusBadRequest, status)
+	status, _ = getClients("filter%5Bcreated_at%5D%5Bilike%5D=2025")
+	assert.Equal(suite.T(), fiber.StatusBadRequest, status)
+	status, _ = getClients("filter%5Bcreated_at%5D%5Bgte%5D=yesterday")
+	assert.Equal(suite.T(), fiber.StatusBadRequest, status)
+}
+
+func (suite *ClientIntegrationTestSuite) TestGetAllClients_CursorForOtherSort() {
+	suite.createClient(dtos.ClientRequest{
+		Name:          "PT. Cursor",
+		Email:         "cursor@company.com",
+		PhoneNumber:   "+628123456010",
+		Address:       "Jl. Cursor, Jakarta",
+		ContactPerson: "John Doe",
+	})
+	suite.createClient(dtos.ClientRequest{
+		Name:          "PT. Cursor Dua",
+		Email:         "cursor2@company.com",
+		PhoneNumber:   "+628123456011",
+		Address:       "Jl. Cursor, Jakarta",
+		ContactPerson: "John Doe",
+	})
+
+	_, firstPage := suite.getClientPage("cursor=&limit=1")
+	assert.NotEmpty(suite.T(), firstPage.Meta.NextCursor)
+
+	status, _ := suite.getClientPage("cursor=" + firstPage.Meta.NextCursor + "&limit=1&sort_by=name")
+	assert.Equal(suite.T(), fiber.StatusBadRequest, status)
+
+	status, _ = suite.getClientPage("cursor=not-a-cursor")
+	assert.Equal(suite.T(), fiber.StatusBadRequest, status)
+}
+
+func (suite *ClientIntegrationTestSuite) TestGetAllClients_TamperedCursor() {
+	suite.createClient(dtos.ClientRequest{
+		Name:          "PT. Tampered",
+		Email:         "tampered@company.com",
+		PhoneNumber:   "+628123456012",
+		Address:       "Jl. Cursor, Jakarta",
+		ContactPerson: "John Doe",
+	})
+
+	// A hand-edited cursor is a bad request, not a failed query
+	cursors := []dtos.Cursor{
+		{SortBy: "created_at", SortOrder: "desc", Value: time.Now().Format(time.RFC3339Nano), UUID: "not-a-uuid"},
+		{SortBy: "created_at", SortOrder: "desc", Value: "yesterday", UUID: "00000000-0000-0000-0000-000000000000"},
+		{SortBy: "name", SortOrder: "asc", Value: "PT.\x00", UUID: "00000000-0000-0000-0000-000000000000"},
+	}
+	for _, cursor := range cursors {
+		query := "cursor=" + helpers.EncodeCursor(cursor) + "&sort_by=" + cursor.SortBy + "&sort_order=" + cursor.SortOrder
+		status, _ := suite.getClientPage(query)
+		assert.Equal(suite.T(), fiber.StatusBadRequest, status, cursor.UUID+" "+cursor.Value)
+	}
+}
+
+func (suite *ClientIntegrationTestSuite) TestUpdateClient_IfMatch() {
+	clientUUID := suite.createClient(dtos.ClientRequest{
+		Name:          "PT. Versioned",
+		Email:         "versioned@company.com",
+		PhoneNumber:   "+628123456020",
+		Address:       "Jl. Versi No. 1, Jakarta",
+		ContactPerson: "John Doe",
+	})
+
+	getReq := httptest.NewRequest("GET", fmt.Sprintf("/api/v1/clients/%s", clientUUID), nil)
+	getReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+	getResp, err := suite.app.Test(getReq)
+	assert.NoError(suite.T(), err)
+	etag := getResp.Header.Get(fiber.HeaderETag)
+	assert.NotEmpty(suite.T(), etag)
+
+	update := func(name string, ifMatch string) *http.Response {
+		body, _ := json.Marshal(dtos.ClientUpdateRequest{Name: name})
+		req := httptest.NewRequest("PUT", fmt.Sprintf("/api/v1/clients/%s/update", clientUUID), bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+		req.Header.Set(fiber.HeaderIfMatch, ifMatch)
+		resp, err := suite.app.Test(req)
+		assert.NoError(suite.T(), err)
+		return resp
+	}
+
+	// If-Match compares strongly, a weak tag of the current version does not match
+	weakResp := update("PT. Versioned Lemah", "W/"+etag)
+	assert.Equal(suite.T(), fiber.StatusPreconditionFailed, weakResp.StatusCode)
+
+	firstResp := update("PT. Versioned Satu", etag)
+	assert.Equal(suite.T(), fiber.StatusOK, firstResp.StatusCode)
+	newETag := firstResp.Header.Get(fiber.HeaderETag)
+	assert.NotEqual(suite.T(), etag, newETag)
+
+	// A second agent still holding the first version must not overwrite the change
+	staleResp := update("PT. Versioned Dua", etag)
+	assert.Equal(suite.T(), fiber.StatusPreconditionFailed, staleResp.StatusCode)
+
+	deleteReq := httptest.NewRequest("DELETE", fmt.Sprintf("/api/v1/clients/%s/delete", clientUUID), nil)
+	deleteReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+	deleteReq.Header.Set(fiber.HeaderIfMatch, etag)
+	deleteResp, err := suite.app.Test(deleteReq)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusPreconditionFailed, deleteResp.StatusCode)
+
+	deleteReq = httptest.NewRequest("DELETE", fmt.Sprintf("/api/v1/clients/%s/delete", clientUUID), nil)
+	deleteReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+	deleteReq.Header.Set(fiber.HeaderIfMatch, newETag)
+	deleteResp, err = suite.app.Test(deleteReq)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, deleteResp.StatusCode)
+}
+
+// patchClient sends a PATCH with the given content type and body
+func (suite *ClientIntegrationTestSuite) patchClient(clientUUID string, contentType string, body string) (*http.Response, dtos.SuccessResponse) {
+	req := httptest.NewRequest("PATCH", fmt.Sprintf("/api/v1/clients/%s", clientUUID), bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+
+	var response dtos.SuccessResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+	return resp, response
+}
+
+func (suite *ClientIntegrationTestSuite) TestPatchClient_MergePatch() {
+	clientUUID := suite.createClient(dtos.ClientRequest{
+		Name:          "PT. Patch",
+		Email:         "patch@company.com",
+		PhoneNumber:   "+628123456030",
+		Address:       "Jl. Patch No. 1, Jakarta",
+		ContactPerson: "John Doe",
+	})
+
+	resp, response := suite.patchClient(clientUUID, "application/merge-patch+json", `{"name": "PT. Patch Baru"}`)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+	assert.NotEmpty(suite.T(), resp.Header.Get(fiber.HeaderETag))
+	data := response.Data.(map[string]interface{})
+	assert.Equal(suite.T(), "PT. Patch Baru", data["name"])
+	assert.Equal(suite.T(), "patch@company.com", data["email"])
+	assert.Equal(suite.T(), "Jl. Patch No. 1, Jakarta", data["address"])
+
+	resp, response = suite.patchClient(clientUUID, "application/json-patch+json",
+		`[{"op": "test", "path": "/name", "value": "PT. Patch Baru"}, {"op": "replace", "path": "/address", "value": "Jl. Patch No. 2, Jakarta"}]`)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+	assert.Equal(suite.T(), "Jl. Patch No. 2, Jakarta", response.Data.(map[string]interface{})["address"])
+
+	resp, _ = suite.patchClient(clientUUID, "application/json-patch+json", `[{"op": "test", "path": "/name", "value": "PT. Patch"}]`)
+	assert.Equal(suite.T(), fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func (suite *ClientIntegrationTestSuite) TestPatchClient_RejectsInvalidResult() {
+	clientUUID := suite.createClient(dtos.ClientRequest{
+		Name:          "PT. Patch Invalid",
+		Email:         "patch.invalid@company.com",
+		PhoneNumber:   "+628123456031",
+		Address:       "Jl. Patch No. 3, Jakarta",
+		ContactPerson: "John Doe",
+	})
+
+	for _, body := range []string{
+		`{"email": null}`,
+		`{"email": "not-an-email"}`,
+		`{"nickname": "Patchy"}`,
+	} {
+		resp, _ := suite.patchClient(clientUUID, "application/merge-patch+json", body)
+		assert.Equal(suite.T(), fiber.StatusBadRequest, resp.StatusCode, body)
+	}
+
+	resp, _ := suite.patchClient(clientUUID, "application/json-patch+json", `[{"op": "remove", "path": "/contact_person"}]`)
+	assert.Equal(suite.T(), fiber.StatusBadRequest, resp.StatusCode)
+
+	resp, _ = suite.patchClient(clientUUID, "text/plain", `{"name": "PT. Patch Text"}`)
+	assert.Equal(suite.T(), fiber.StatusUnsupportedMediaType, resp.StatusCode)
+
+	var client models.Client
+	assert.NoError(suite.T(), suite.db.Where("uuid = ?", clientUUID).First(&client).Error)
+	assert.Equal(suite.T(), "patch.invalid@company.com", client.Email)
+	assert.Equal(suite.T(), "John Doe", client.ContactPerson)
+}
+
+// getClientHistory fetches a page of the change history of a client
+func (suite *ClientIntegrationTestSuite) getClientHistory(clientUUID string, query string) (int, []dtos.ChangeLogResponse) {
+	req := httptest.NewRequest("GET", fmt.Sprintf("/api/v1/clients/%s/history?%s", clientUUID, query), nil)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+
+	var response struct {
+		Data []dtos.ChangeLogResponse `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&response)
+	return resp.StatusCode, response.Data
+}
+
+func (suite *ClientIntegrationTestSuite) TestClientHistory_RecordsChangedFields() {
+	clientUUID := suite.createClient(dtos.ClientRequest{
+		Name:          "PT. Riwayat",
+		Email:         "riwayat@company.com",
+		PhoneNumber:   "+628123456040",
+		Address:       "Jl. Riwayat No. 1, Jakarta",
+		ContactPerson: "John Doe",
+	})
+
+	resp, _ := suite.patchClient(clientUUID, "application/merge-patch+json", `{"phone_number": "+628123456041"}`)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+
+	deleteReq := httptest.NewRequest("DELETE", fmt.Sprintf("/api/v1/clients/%s/delete", clientUUID), nil)
+	deleteReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+	deleteResp, err := suite.app.Test(deleteReq)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, deleteResp.StatusCode)
+
+	// The history of a deleted client stays readable
+	status, entries := suite.getClientHistory(clientUUID, "")
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+	assert.Len(suite.T(), entries, 3)
+	if len(entries) == 3 {
+		assert.Equal(suite.T(), "delete", entries[0].Action)
+		assert.Equal(suite.T(), "update", entries[1].Action)
+		assert.Equal(suite.T(), "create", entries[2].Action)
+		assert.Nil(suite.T(), entries[2].Changes["name"].From)
+		assert.Equal(suite.T(), "PT. Riwayat", entries[2].Changes["name"].To)
+	}
+
+	status, entries = suite.getClientHistory(clientUUID, "field=phone_number")
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+	assert.Len(suite.T(), entries, 3)
+	for _, entry := range entries {
+		assert.NotNil(suite.T(), entry.ActorUUID)
+		if entry.Action == "update" {
+			assert.Len(suite.T(), entry.Changes, 1)
+			assert.Equal(suite.T(), "+628123456040", entry.Changes["phone_number"].From)
+			assert.Equal(suite.T(), "+628123456041", entry.Changes["phone_number"].To)
+		}
+	}
+
+	status, _ = suite.getClientHistory("123e4567-e89b-12d3-a456-426614174000", "")
+	assert.Equal(suite.T(), fiber.StatusNotFound, status)
+}
+
+// uploadDocument uploads a KYC document with a minimal PDF scan and returns the response
+func (suite *ClientIntegrationTestSuite) uploadDocument(clientUUID string, documentType string, number string) (int, map[string]interface{}) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	writer.WriteField("type", documentType)
+	writer.WriteField("number", number)
+	part, _ := writer.CreateFormFile("file", documentType+".pdf")
+	part.Write([]byte("%PDF-1.4\n%%EOF\n"))
+	writer.Close()
+
+	req := httptest.NewRequest("POST", fmt.Sprintf("/api/v1/clients/%s/documents", clientUUID), body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+
+	var response dtos.SuccessResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+	data, _ := response.Data.(map[string]interface{})
+	return resp.StatusCode, data
+}
+
+func (suite *ClientIntegrationTestSuite) reviewDocument(clientUUID string, documentUUID string, review map[string]string) int {
+	body, _ := json.Marshal(review)
+	req := httptest.NewRequest("POST", fmt.Sprintf("/api/v1/clients/%s/documents/%s/review", clientUUID, documentUUID), bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	return resp.StatusCode
+}
+
+func (suite *ClientIntegrationTestSuite) TestClientKyc_VerifyDocuments() {
+	clientUUID := suite.createClient(dtos.ClientRequest{
+		Name:          "PT. KYC",
+		Email:         "kyc@company.com",
+		PhoneNumber:   "+628123456050",
+		Address:       "Jl. KYC No. 1, Jakarta",
+		ContactPerson: "John Doe",
+	})
+
+	status, ktp := suite.uploadDocument(clientUUID, "ktp", "3171 0123 4567 0001")
+	assert.Equal(suite.T(), fiber.StatusCreated, status)
+	assert.Equal(suite.T(), "3171012345670001", ktp["number"])
+	assert.Equal(suite.T(), "pending", ktp["status"])
+	status, npwp := suite.uploadDocument(clientUUID, "npwp", "01.234.567.8-901.000")
+	assert.Equal(suite.T(), fiber.StatusCreated, status)
+
+	// Only admins review documents
+	assert.Equal(suite.T(), fiber.StatusForbidden, suite.reviewDocument(clientUUID, ktp["uuid"].(string), map[string]string{"status": "verified"}))
+	suite.promoteToAdmin()
+
+	assert.Equal(suite.T(), fiber.StatusBadRequest, suite.reviewDocument(clientUUID, npwp["uuid"].(string), map[string]string{"status": "rejected"}))
+	assert.Equal(suite.T(), fiber.StatusOK, suite.reviewDocument(clientUUID, ktp["uuid"].(string), map[string]string{"status": "verified"}))
+	assert.Equal(suite.T(), fiber.StatusOK, suite.reviewDocument(clientUUID, npwp["uuid"].(string), map[string]string{"status": "rejected", "reason": "Scan is blurry"}))
+	assert.Equal(suite.T(), fiber.StatusConflict, suite.reviewDocument(clientUUID, npwp["uuid"].(string), map[string]string{"status": "verified"}))
+
+	var client models.Client
+	assert.NoError(suite.T(), suite.db.Where("uuid = ?", clientUUID).First(&client).Error)
+	assert.Equal(suite.T(), "rejected", client.KycStatus)
+
+	status, npwp = suite.uploadDocument(clientUUID, "npwp", "012345678901000")
+	assert.Equal(suite.T(), fiber.StatusCreated, status)
+	assert.Equal(suite.T(), fiber.StatusOK, suite.reviewDocument(clientUUID, npwp["uuid"].(string), map[string]string{"status": "verified"}))
+
+	assert.NoError(suite.T(), suite.db.Where("uuid = ?", clientUUID).First(&client).Error)
+	assert.Equal(suite.T(), "verified", client.KycStatus)
+}
+
+func (suite *ClientIntegrationTestSuite) TestClientKyc_InvalidDocument() {
+	clientUUID := suite.createClient(dtos.ClientRequest{
+		Name:          "PT. KYC Invalid",
+		Email:         "kyc.invalid@company.com",
+		PhoneNumber:   "+628123456051",
+		Address:       "Jl. KYC No. 2, Jakarta",
+		ContactPerson: "John Doe",
+	})
+
+	status, _ := suite.uploadDocument(clientUUID, "ktp", "12345")
+	assert.Equal(suite.T(), fiber.StatusBadRequest, status)
+	status, _ = suite.uploadDocument(clientUUID, "driving_license", "12345")
+	assert.Equal(suite.T(), fiber.StatusBadRequest, status)
+	status, _ = suite.uploadDocument("123e4567-e89b-12d3-a456-426614174000", "passport", "A1234567")
+	assert.Equal(suite.T(), fiber.StatusNotFound, status)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	writer.WriteField("type", "passport")
+	writer.WriteField("number", "A1234567")
+	part, _ := writer.CreateFormFile("file", "passport.pdf")
+	part.Write([]byte("not a pdf"))
+	writer.Close()
+
+	req := httptest.NewRequest("POST", fmt.Sprintf("/api/v1/clients/%s/documents", clientUUID), body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusBadRequest, resp.StatusCode)
+}
+
+// sendContact posts or puts a contact and returns the response
+func (suite *ClientIntegrationTestSuite) sendContact(method string, path string, contact map[string]interface{}) (int, map[string]interface{}) {
+	body, _ := json.Marshal(contact)
+	req := httptest.NewRequest(method, "/api/v1/clients/"+path, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+
+	var response dtos.SuccessResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+	data, _ := response.Data.(map[string]interface{})
+	return resp.StatusCode, data
+}
+
+func (suite *ClientIntegrationTestSuite) TestClientContacts_PrimaryContact() {
+	clientUUID := suite.createClient(dtos.ClientRequest{
+		Name:          "PT. Kontak",
+		Email:         "kontak@company.com",
+		PhoneNumber:   "+628123456060",
+		Address:       "Jl. Kontak No. 1, Jakarta",
+		ContactPerson: "John Doe",
+	})
+
+	var contacts []models.ClientContact
+	assert.NoError(suite.T(), suite.db.Where("client_uuid = ?", clientUUID).Find(&contacts).Error)
+	assert.Len(suite.T(), contacts, 1)
+	assert.Equal(suite.T(), "John Doe", contacts[0].Name)
+	assert.True(suite.T(), contacts[0].IsPrimary)
+
+	status, _ := suite.sendContact("POST", clientUUID+"/contacts", map[string]interface{}{
+		"name": "Jane Finance", "role": "finance", "preferred_channel": "email",
+	})
+	assert.Equal(suite.T(), fiber.StatusBadRequest, status)
+
+	status, finance := suite.sendContact("POST", clientUUID+"/contacts", map[string]interface{}{
+		"name": "Jane Finance", "role": "finance", "email": "finance@company.com",
+		"phone_number": "0812 3456 061", "preferred_channel": "whatsapp", "is_primary": true,
+	})
+	assert.Equal(suite.T(), fiber.StatusCreated, status)
+	assert.Equal(suite.T(), "+628123456061", finance["phone_number"])
+	assert.Equal(suite.T(), true, finance["is_primary"])
+
+	req := httptest.NewRequest("GET", "/api/v1/clients/"+clientUUID, nil)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	var response struct {
+		Data dtos.ClientResponse `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&response)
+	assert.Equal(suite.T(), "Jane Finance", response.Data.ContactPerson)
+	assert.Len(suite.T(), response.Data.Contacts, 2)
+	if len(response.Data.Contacts) == 2 {
+		assert.Equal(suite.T(), "Jane Finance", response.Data.Contacts[0].Name)
+		assert.False(suite.T(), response.Data.Contacts[1].IsPrimary)
+	}
+
+	financePath := fmt.Sprintf("%s/contacts/%s", clientUUID, finance["uuid"])
+	status, _ = suite.sendContact("PUT", financePath, map[string]interface{}{
+		"name": "Jane Finance", "role": "finance", "is_primary": false,
+	})
+	assert.Equal(suite.T(), fiber.StatusBadRequest, status)
+	status, _ = suite.sendContact("DELETE", financePath, nil)
+	assert.Equal(suite.T(), fiber.StatusBadRequest, status)
+
+	status, _ = suite.sendContact("DELETE", fmt.Sprintf("%s/contacts/%s", clientUUID, contacts[0].UUID), nil)
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+	status, _ = suite.sendContact("POST", "123e4567-e89b-12d3-a456-426614174000/contacts", map[string]interface{}{
+		"name": "Nobody", "role": "general",
+	})
+	assert.Equal(suite.T(), fiber.StatusNotFound, status)
+}
+
+func (suite *ClientIntegrationTestSuite) TestClientPrivacy_ExportAndErase() {
+	clientUUID := suite.createClient(dtos.ClientRequest{
+		Name:          "Siti Rahma",
+		Email:         "siti.rahma@company.com",
+		PhoneNumber:   "+628123456070",
+		Address:       "Jl. Privasi No. 1, Jakarta",
+		ContactPerson: "Siti",
+	})
+
+	noteBody, _ := json.Marshal(map[string]string{"body": "Prefers to be called after 5pm"})
+	noteReq := httptest.NewRequest("POST", fmt.Sprintf("/api/v1/clients/%s/notes", clientUUID), bytes.NewBuffer(noteBody))
+	noteReq.Header.Set("Content-Type", "application/json")
+	noteReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+	noteResp, err := suite.app.Test(noteReq)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusCreated, noteResp.StatusCode)
+
+	status, _ := suite.uploadDocument(clientUUID, "passport", "A1234567")
+	assert.Equal(suite.T(), fiber.StatusCreated, status)
+
+	exportPath := fmt.Sprintf("/api/v1/clients/%s/data-export", clientUUID)
+	req := httptest.NewRequest("GET", exportPath, nil)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusForbidden, resp.StatusCode)
+	suite.promoteToAdmin()
+
+	req = httptest.NewRequest("GET", exportPath, nil)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+	resp, err = suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+	var export struct {
+		Data dtos.ClientDataExport `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&export)
+	assert.Equal(suite.T(), "siti.rahma@company.com", export.Data.Client.Email)
+	assert.Len(suite.T(), export.Data.Client.Contacts, 1)
+	assert.Len(suite.T(), export.Data.Documents, 1)
+	assert.Len(suite.T(), export.Data.Timeline, 1)
+	assert.NotEmpty(suite.T(), export.Data.History)
+
+	req = httptest.NewRequest("GET", exportPath+"?format=zip", nil)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+	resp, err = suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+	archive, _ := io.ReadAll(resp.Body)
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if assert.NoError(suite.T(), err) {
+		assert.Len(suite.T(), reader.File, 2)
+		assert.Equal(suite.T(), "client.json", reader.File[0].Name)
+	}
+
+	erasePath := fmt.Sprintf("/api/v1/clients/%s/erase", clientUUID)
+	req = httptest.NewRequest("POST", erasePath, nil)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+	resp, err = suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+
+	var client models.Client
+	assert.NoError(suite.T(), suite.db.Unscoped().Where("uuid = ?", clientUUID).First(&client).Error)
+	assert.Equal(suite.T(), "Erased client", client.Name)
+	assert.NotContains(suite.T(), client.Email, "siti")
+	assert.NotEqual(suite.T(), "+628123456070", client.PhoneNumber)
+	assert.NotNil(suite.T(), client.ErasedAt)
+	assert.True(suite.T(), client.DeletedAt.Valid)
+
+	var remaining int64
+	suite.db.Unscoped().Model(&models.ClientDocument{}).Where("client_uuid = ?", clientUUID).Count(&remaining)
+	assert.Zero(suite.T(), remaining)
+	suite.db.Unscoped().Model(&models.ClientActivity{}).Where("client_uuid = ?", clientUUID).Count(&remaining)
+	assert.Zero(suite.T(), remaining)
+	suite.db.Model(&models.ChangeLog{}).Where("entity_uuid = ? AND changes::text ILIKE ?", clientUUID, "%siti%").Count(&remaining)
+	assert.Zero(suite.T(), remaining)
+
+	req = httptest.NewRequest("POST", erasePath, nil)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+	resp, err = suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusConflict, resp.StatusCode)
+
+	req = httptest.NewRequest("POST", fmt.Sprintf("/api/v1/clients/%s/restore", clientUUID), nil)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+	resp, err = suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusConflict, resp.StatusCode)
+}
+
+func (suite *ClientIntegrationTestSuite) TestClientPrivacy_EraseMergedClients() {
+	survivorUUID := suite.createClient(dtos.ClientRequest{
+		Name:          "Siti Rahma",
+		Email:         "siti.rahma@company.com",
+		PhoneNumber:   "+628123456071",
+		Address:       "Jl. Privasi No. 2, Jakarta",
+		ContactPerson: "Siti",
+	})
+	duplicateUUID := suite.createLegacyClient(models.Client{
+		Name:          "Siti R.",
+		Email:         "siti.r@gmail.com",
+		PhoneNumber:   "0812-3456-0072",
+		Address:       "Jl. Privasi No. 2, Jakarta",
+		ContactPerson: "Siti",
+	})
+	assert.NoError(suite.T(), suite.db.Create(&models.ClientTag{ClientUUID: duplicateUUID, Tag: "siti-keluarga"}).Error)
+
+	photo, err := os.CreateTemp("", "deduction-*.jpg")
+	assert.NoError(suite.T(), err)
+	photo.Close()
+	deposit := models.Deposit{
+		ClientUUID:   duplicateUUID,
+		PropertyUUID: "550e8400-e29b-41d4-a716-446655440000",
+		Amount:       1000,
+		ReceivedAt:   time.Now(),
+		Notes:        "Paid by Siti's husband",
+		Deductions: []models.DepositDeduction{{
+			Category:    "damage",
+			Description: "Broken window",
+			Amount:      100,
+			Photos:      []models.DepositDeductionPhoto{{PhotoURL: photo.Name()}},
+		}},
+	}
+	assert.NoError(suite.T(), suite.db.Create(&deposit).Error)
+
+	mergeBody, _ := json.Marshal(map[string]string{"duplicate_uuid": duplicateUUID})
+	req := httptest.NewRequest("POST", fmt.Sprintf("/api/v1/clients/%s/merge", survivorUUID), bytes.NewBuffer(mergeBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+
+	suite.promoteToAdmin()
+	req = httptest.NewRequest("POST", fmt.Sprintf("/api/v1/clients/%s/erase", survivorUUID), nil)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+	resp, err = suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+	var erasure struct {
+		Data dtos.ClientErasureResponse `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&erasure)
+	assert.Equal(suite.T(), int64(1), erasure.Data.MergedClients)
+
+	// The duplicate merged away earlier is erased along with the survivor
+	var duplicate models.Client
+	assert.NoError(suite.T(), suite.db.Unscoped().Where("uuid = ?", duplicateUUID).First(&duplicate).Error)
+	assert.Equal(suite.T(), "Erased client", duplicate.Name)
+	assert.NotContains(suite.T(), duplicate.Email, "siti")
+	assert.Empty(suite.T(), duplicate.Address)
+	assert.NotNil(suite.T(), duplicate.ErasedAt)
+
+	var remaining int64
+	clients := []string{survivorUUID, duplicateUUID}
+	suite.db.Model(&models.ChangeLog{}).Where("entity_uuid IN ? AND changes::text ILIKE ?", clients, "%siti%").Count(&remaining)
+	assert.Zero(suite.T(), remaining)
+	for _, phone := range []string{"%628123456071%", "%0812-3456-0072%"} {
+		suite.db.Model(&models.ChangeLog{}).Where("entity_uuid IN ? AND changes::text LIKE ?", clients, phone).Count(&remaining)
+		assert.Zero(suite.T(), remaining)
+	}
+	suite.db.Model(&models.ClientTag{}).Where("client_uuid IN ?", clients).Count(&remaining)
+	assert.Zero(suite.T(), remaining)
+
+	// The deposit stays as a financial record, without its notes and photos
+	var kept models.Deposit
+	assert.NoError(suite.T(), suite.db.Unscoped().Preload("Deductions.Photos").Where("uuid = ?", deposit.UUID).First(&kept).Error)
+	assert.Equal(suite.T(), survivorUUID, kept.ClientUUID)
+	assert.Empty(suite.T(), kept.Notes)
+	if assert.Len(suite.T(), kept.Deductions, 1) {
+		assert.Empty(suite.T(), kept.Deductions[0].Photos)
+	}
+	_, err = os.Stat(photo.Name())
+	assert.True(suite.T(), os.IsNotExist(err))
+}
+
+// promoteToAdmin gives the signed in test user the admin role. The JWT middleware reloads the
+// user on every request, so the existing token picks it up.
+func (suite *ClientIntegrationTestSuite) promoteToAdmin() {
+	assert.NoError(suite.T(), suite.db.Model(&models.User{}).Where("uuid = ?", suite.userUUID("integration")).Update("role", models.RoleAdmin).Error)
+}
+
+func (suite *ClientIntegrationTestSuite) TestClientTrash_PurgeRemovesDocumentScans() {
+	clientUUID := suite.createClient(dtos.ClientRequest{
+		Name:          "PT. Purge Company",
+		Email:         "purge@company.com",
+		PhoneNumber:   "+6281234567802",
+		Address:       "Jl. Purge No. 1, Jakarta",
+		ContactPerson: "Budi",
+	})
+	status, _ := suite.uploadDocument(clientUUID, "passport", "A1234567")
+	assert.Equal(suite.T(), fiber.StatusCreated, status)
+
+	var document models.ClientDocument
+	assert.NoError(suite.T(), suite.db.Where("client_uuid = ?", clientUUID).First(&document).Error)
+	_, err := os.Stat(document.FilePath)
+	assert.NoError(suite.T(), err)
+
+	// Deleted long before the retention period
+	suite.db.Exec("UPDATE clients SET deleted_at = ? WHERE uuid = ?", time.Now().AddDate(0, 0, -60), clientUUID)
+
+	_, err = services.NewTrashService(repositories.NewTrashRepository(suite.db)).Purge(30)
+	assert.NoError(suite.T(), err)
+
+	var documents int64
+	suite.db.Unscoped().Model(&models.ClientDocument{}).Where("client_uuid = ?", clientUUID).Count(&documents)
+	assert.Zero(suite.T(), documents)
+	_, err = os.Stat(document.FilePath)
+	assert.True(suite.T(), os.IsNotExist(err))
+}
+
+func (suite *ClientIntegrationTestSuite) TestClientTrash_ForbiddenForUsers() {
+	req := httptest.NewRequest("GET", "/api/v1/clients/trash", nil)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusForbidden, resp.StatusCode)
+}
+
+func (suite *ClientIntegrationTestSuite) TestRestoreClient_Success() {
+	clientUUID := suite.createClient(dtos.ClientRequest{
+		Name:          "PT. Trash Company",
+		Email:         "trash@company.com",
+		PhoneNumber:   "+628123450001",
+		Address:       "Jl. Trash No. 1, Jakarta",
+		ContactPerson: "John Doe",
+	})
+	suite.promoteToAdmin()
+
+	deleteReq := httptest.NewRequest("DELETE", fmt.Sprintf("/api/v1/clients/%s/delete", clientUUID), nil)
+	deleteReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+	deleteResp, err := suite.app.Test(deleteReq)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, deleteResp.StatusCode)
+
+	trashReq := httptest.NewRequest("GET", "/api/v1/clients/trash", nil)
+	trashReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+	trashResp, err := suite.app.Test(trashReq)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, trashResp.StatusCode)
+
+	var trashResponse dtos.PaginatedSuccessResponse
+	json.NewDecoder(trashResp.Body).Decode(&trashResponse)
+	trashed, _ := trashResponse.Data.([]interface{})
+	assert.Len(suite.T(), trashed, 1)
+	if len(trashed) == 1 {
+		trashedClient := trashed[0].(map[string]interface{})
+		assert.Equal(suite.T(), clientUUID, trashedClient["uuid"])
+		assert.NotEmpty(suite.T(), trashedClient["deleted_at"])
+	}
+
+	restoreReq := httptest.NewRequest("POST", fmt.Sprintf("/api/v1/clients/%s/restore", clientUUID), nil)
+	restoreReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+	restoreResp, err := suite.app.Test(restoreReq)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, restoreResp.StatusCode)
+
+	getReq := httptest.NewRequest("GET", fmt.Sprintf("/api/v1/clients/%s", clientUUID), nil)
+	getReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+	getResp, err := suite.app.Test(getReq)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, getResp.StatusCode)
+}
+
+func (suite *ClientIntegrationTestSuite) TestRestoreClient_Conflict() {
+	trashedUUID := suite.createLegacyClient(models.Client{
+		Name:          "PT. Old Company",
+		Email:         "Sales@Company.com",
+		PhoneNumber:   "+628123450002",
+		Address:       "Jl. Lama No. 2, Jakarta",
+		ContactPerson: "Jane Doe",
+		DeletedAt:     gorm.DeletedAt{Time: time.Now(), Valid: true},
+	})
+	suite.createClient(dtos.ClientRequest{
+		Name:          "PT. New Company",
+		Email:         "sales@company.com",
+		PhoneNumber:   "+628123450003",
+		Address:       "Jl. Baru No. 3, Jakarta",
+		ContactPerson: "Jane Doe",
+	})
+	suite.promoteToAdmin()
+
+	req := httptest.NewRequest("POST", fmt.Sprintf("/api/v1/clients/%s/restore", trashedUUID), nil)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusConflict, resp.StatusCode)
+}
+
+func (suite *ClientIntegrationTestSuite) TestRestoreClient_NotInTrash() {
+	clientUUID := suite.createClient(dtos.ClientRequest{
+		Name:          "PT. Live Company",
+		Email:         "live@company.com",
+		PhoneNumber:   "+628123450004",
+		Address:       "Jl. Hidup No. 4, Jakarta",
+		ContactPerson: "John Doe",
+	})
+	suite.promoteToAdmin()
+
+	req := httptest.NewRequest("POST", fmt.Sprintf("/api/v1/clients/%s/restore", clientUUID), nil)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusNotFound, resp.StatusCode)
+}
+
+func (suite *ClientIntegrationTestSuite) TestClientTags_Visibility() {
+	clientUUID := suite.createClient(dtos.ClientRequest{
+		Name:          "PT. Tagged Company",
+		Email:         "tagged@company.com",
+		PhoneNumber:   "+628123450111",
+		Address:       "Jl. Label No. 1, Jakarta",
+		ContactPerson: "John Doe",
+	})
+	otherToken, _ := signUp(suite.T(), suite.app, suite.db, "agent", "agent")
+
+	send := func(method, path, token string, payload interface{}) *http.Response {
+		var body io.Reader
+		if payload != nil {
+			raw, _ := json.Marshal(payload)
+			body = bytes.NewBuffer(raw)
+		}
+		req := httptest.NewRequest(method, path, body)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		resp, err := suite.app.Test(req)
+		assert.NoError(suite.T(), err)
+		return resp
+	}
+	tags := func(token string) []interface{} {
+		resp := send("GET", "/api/v1/clients/tags", token, nil)
+		assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+		var response dtos.SuccessResponse
+		json.NewDecoder(resp.Body).Decode(&response)
+		items, _ := response.Data.([]interface{})
+		return items
+	}
+
+	resp := send("PUT", fmt.Sprintf("/api/v1/clients/%s/tags", clientUUID), suite.token, dtos.ClientTagRequest{Tags: []string{"vip"}})
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+
+	// The tags of a client are only counted for the users who see it
+	assert.Len(suite.T(), tags(suite.token), 1)
+	assert.Len(suite.T(), tags(otherToken), 0)
+}
+
+func (suite *ClientIntegrationTestSuite) TestClientOwnership_Visibility() {
+	clientUUID := suite.createClient(dtos.ClientRequest{
+		Name:          "PT. Owned Company",
+		Email:         "owned@company.com",
+		PhoneNumber:   "+628123450101",
+		Address:       "Jl. Milik No. 1, Jakarta",
+		ContactPerson: "John Doe",
+	})
+	ownerUUID := suite.userUUID("integration")
+	otherToken, otherUUID := signUp(suite.T(), suite.app, suite.db, "agent", "agent")
+
+	send := func(method, path, token string, payload interface{}) *http.Response {
+		var body io.Reader
+		if payload != nil {
+			raw, _ := json.Marshal(payload)
+			body = bytes.NewBuffer(raw)
+		}
+		req := httptest.NewRequest(method, path, body)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		resp, err := suite.app.Test(req)
+		assert.NoError(suite.T(), err)
+		return resp
+	}
+	listed := func(token string) []interface{} {
+		resp := send("GET", "/api/v1/clients", token, nil)
+		assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+		var page dtos.CursorPaginatedSuccessResponse
+		json.NewDecoder(resp.Body).Decode(&page)
+		items, _ := page.Data.([]interface{})
+		return items
+	}
+
+	// Another agent neither finds nor lists the client
+	resp := send("GET", fmt.Sprintf("/api/v1/clients/%s", clientUUID), otherToken, nil)
+	assert.Equal(suite.T(), fiber.StatusNotFound, resp.StatusCode)
+	resp = send("PATCH", fmt.Sprintf("/api/v1/clients/%s", clientUUID), otherToken, map[string]string{"address": "Jl. Lain No. 2"})
+	assert.Equal(suite.T(), fiber.StatusNotFound, resp.StatusCode)
+	assert.Len(suite.T(), listed(otherToken), 0)
+	assert.Len(suite.T(), listed(suite.token), 1)
+
+	// Team mates see each other's clients
+	suite.db.Model(&models.User{}).Where("uuid IN ?", []string{ownerUUID, otherUUID}).Update("team", "north")
+	resp = send("GET", fmt.Sprintf("/api/v1/clients/%s", clientUUID), otherToken, nil)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+	assert.Len(suite.T(), listed(otherToken), 1)
+	suite.db.Model(&models.User{}).Where("uuid IN ?", []string{ownerUUID, otherUUID}).Update("team", "")
+
+	// Only admins hand clients over
+	resp = send("PUT", fmt.Sprintf("/api/v1/clients/%s/owner", clientUUID), suite.token, dtos.ClientOwnerRequest{OwnerUUID: otherUUID})
+	assert.Equal(suite.T(), fiber.StatusForbidden, resp.StatusCode)
+
+	suite.promoteToAdmin()
+
+	resp = send("PUT", fmt.Sprintf("/api/v1/clients/%s/owner", clientUUID), suite.token, dtos.ClientOwnerRequest{OwnerUUID: otherUUID})
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+	assert.Len(suite.T(), listed(otherToken), 1)
+
+	resp = send("PUT", fmt.Sprintf("/api/v1/clients/%s/owner", clientUUID), suite.token, dtos.ClientOwnerRequest{OwnerUUID: clientUUID})
+	assert.Equal(suite.T(), fiber.StatusNotFound, resp.StatusCode)
+
+	// Bulk reassignment moves everything back, unowned clients are picked up with an empty from_owner_uuid
+	assert.NoError(suite.T(), suite.db.Create(&models.Client{
+		Name:          "PT. Unowned Company",
+		Email:         "unowned@company.com",
+		PhoneNumber:   "+628123450102",
+		Address:       "Jl. Bebas No. 3, Jakarta",
+		ContactPerson: "Jane Doe",
+	}).Error)
+
+	resp = send("POST", "/api/v1/clients/reassign", suite.token, dtos.ClientReassignRequest{FromOwnerUUID: otherUUID, ToOwnerUUID: ownerUUID})
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+	resp = send("POST", "/api/v1/clients/reassign", suite.token, dtos.ClientReassignRequest{ToOwnerUUID: otherUUID})
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+
+	var response dtos.SuccessResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+	data, _ := response.Data.(map[string]interface{})
+	assert.Equal(suite.T(), float64(1), data["reassigned"])
+	assert.Len(suite.T(), listed(otherToken), 1)
+
+	var history int64
+	suite.db.Model(&models.ChangeLog{}).Where("entity_uuid = ? AND action = ? AND changes::text LIKE ?", clientUUID, models.ChangeLogActionUpdate, "%owner_uuid%").Count(&history)
+	assert.Equal(suite.T(), int64(2), history)
+}
@@ -0,0 +1,313 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+
+	"alfredo/ruu-properties/config"
+	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/models"
+	"alfredo/ruu-properties/pkg/router"
+	"alfredo/ruu-properties/pkg/services"
+)
+
+type DepositIntegrationTestSuite struct {
+	suite.Suite
+	app   *fiber.App
+	db    *gorm.DB
+	token string
+}
+
+func (suite *DepositIntegrationTestSuite) SetupSuite() {
+	suite.db = config.InitTestDatabase()
+
+	suite.app = fiber.New()
+	router.SetupRoutes(suite.app)
+}
+
+func (suite *DepositIntegrationTestSuite) SetupTest() {
+	// Clean database before each test
+	suite.db.Exec("TRUNCATE TABLE users RESTART IDENTITY CASCADE")
+	suite.db.Exec("TRUNCATE TABLE clients RESTART IDENTITY CASCADE")
+	suite.db.Exec("TRUNCATE TABLE deposits RESTART IDENTITY CASCADE")
+	suite.db.Exec("TRUNCATE TABLE deposit_deductions RESTART IDENTITY CASCADE")
+
+	// Setup auth token after cleaning database
+	suite.setupAuthToken()
+}
+
+func (suite *DepositIntegrationTestSuite) TearDownSuite() {
+	// Clean up
+	suite.db.Exec("TRUNCATE TABLE users RESTART IDENTITY CASCADE")
+	suite.db.Exec("TRUNCATE TABLE clients RESTART IDENTITY CASCADE")
+	suite.db.Exec("TRUNCATE TABLE deposits RESTART IDENTITY CASCADE")
+	suite.db.Exec("TRUNCATE TABLE deposit_deductions RESTART IDENTITY CASCADE")
+
+	// Close database connection
+	db, _ := suite.db.DB()
+	db.Close()
+}
+
+// setupAuthToken signs an agent in
+func (suite *DepositIntegrationTestSuite) setupAuthToken() {
+	suite.token, _ = signUp(suite.T(), suite.app, suite.db, "integration", "agent")
+}
+
+// createDeposit creates a client and records a deposit for it, returning the deposit UUID
+func (suite *DepositIntegrationTestSuite) createDeposit(amount float64) string {
+	clientData := dtos.ClientRequest{
+		Name:          "PT. Test Company",
+		Email:         "test@company.com",
+		PhoneNumber:   "+628123456789",
+		Address:       "Jl. Test No. 123, Jakarta",
+		ContactPerson: "John Doe",
+	}
+
+	clientBody, _ := json.Marshal(clientData)
+	clientReq := httptest.NewRequest("POST", "/api/v1/clients", bytes.NewBuffer(clientBody))
+	clientReq.Header.Set("Content-Type", "application/json")
+	clientReq.Header.Set("Authorization", "Bearer "+suite.token)
+
+	clientResp, err := suite.app.Test(clientReq)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, clientResp.StatusCode)
+
+	var clientResponse dtos.SuccessResponse
+	json.NewDecoder(clientResp.Body).Decode(&clientResponse)
+	clientUUID := clientResponse.Data.(map[string]interface{})["uuid"].(string)
+
+	depositData := dtos.DepositRequest{
+		ClientUUID:    clientUUID,
+		PropertyUUID:  "550e8400-e29b-41d4-a716-446655440000",
+		Amount:        amount,
+		PaymentMethod: "bank_transfer",
+	}
+
+	depositBody, _ := json.Marshal(depositData)
+	req := httptest.NewRequest("POST", "/api/v1/deposits", bytes.NewBuffer(depositBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+suite.token)
+
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusCreated, resp.StatusCode)
+
+	var response dtos.SuccessResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+
+	responseData := response.Data.(map[string]interface{})
+	assert.Equal(suite.T(), "held", responseData["status"])
+	assert.Equal(suite.T(), amount, responseData["refundable_amount"])
+
+	return responseData["uuid"].(string)
+}
+
+// addDeduction records a damage deduction on the given deposit
+func (suite *DepositIntegrationTestSuite) addDeduction(depositUUID string, amount string) *httptest.ResponseRecorder {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	writer.WriteField("category", "damage")
+	writer.WriteField("description", "Broken window in the living room")
+	writer.WriteField("amount", amount)
+	writer.Close()
+
+	req := httptest.NewRequest("POST", fmt.Sprintf("/api/v1/deposits/%s/deductions", depositUUID), body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+suite.token)
+
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+
+	recorder := httptest.NewRecorder()
+	recorder.WriteHeader(resp.StatusCode)
+	io.Copy(recorder, resp.Body)
+	return recorder
+}
+
+func (suite *DepositIntegrationTestSuite) TestDepositLifecycle_Success() {
+	depositUUID := suite.createDeposit(5000000)
+
+	deductionResp := suite.addDeduction(depositUUID, "1250000")
+	assert.Equal(suite.T(), fiber.StatusCreated, deductionResp.Code)
+
+	// Refund the remaining balance
+	refundBody, _ := json.Marshal(dtos.DepositRefundRequest{
+		RefundMethod:    "bank_transfer",
+		RefundReference: "TRF-001",
+	})
+	refundReq := httptest.NewRequest("POST", fmt.Sprintf("/api/v1/deposits/%s/refund", depositUUID), bytes.NewBuffer(refundBody))
+	refundReq.Header.Set("Content-Type", "application/json")
+	refundReq.Header.Set("Authorization", "Bearer "+suite.token)
+
+	refundResp, err := suite.app.Test(refundReq)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, refundResp.StatusCode)
+
+	var refundResponse dtos.SuccessResponse
+	json.NewDecoder(refundResp.Body).Decode(&refundResponse)
+
+	refundData := refundResponse.Data.(map[string]interface{})
+	assert.Equal(suite.T(), "refunded", refundData["status"])
+	assert.Equal(suite.T(), float64(3750000), refundData["refund_amount"])
+
+	// Deductions are no longer accepted once refunded
+	lateDeduction := suite.addDeduction(depositUUID, "100000")
+	assert.Equal(suite.T(), fiber.StatusBadRequest, lateDeduction.Code)
+
+	// Settlement statement
+	settlementReq := httptest.NewRequest("GET", fmt.Sprintf("/api/v1/deposits/%s/settlement", depositUUID), nil)
+	settlementReq.Header.Set("Authorization", "Bearer "+suite.token)
+
+	settlementResp, err := suite.app.Test(settlementReq)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, settlementResp.StatusCode)
+
+	var settlementResponse dtos.SuccessResponse
+	json.NewDecoder(settlementResp.Body).Decode(&settlementResponse)
+
+	settlementData := settlementResponse.Data.(map[string]interface{})
+	assert.Equal(suite.T(), float64(5000000), settlementData["deposit_amount"])
+	assert.Equal(suite.T(), float64(1250000), settlementData["total_deductions"])
+	assert.Equal(suite.T(), float64(3750000), settlementData["refundable_amount"])
+	assert.Equal(suite.T(), float64(0), settlementData["amount_owed_by_client"])
+}
+
+func (suite *DepositIntegrationTestSuite) TestSettlement_DeductionsExceedDeposit() {
+	depositUUID := suite.createDeposit(1000000)
+
+	deductionResp := suite.addDeduction(depositUUID, "1500000")
+	assert.Equal(suite.T(), fiber.StatusCreated, deductionResp.Code)
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("/api/v1/deposits/%s/settlement", depositUUID), nil)
+	req.Header.Set("Authorization", "Bearer "+suite.token)
+
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+
+	var response dtos.SuccessResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+
+	responseData := response.Data.(map[string]interface{})
+	assert.Equal(suite.T(), float64(0), responseData["refundable_amount"])
+	assert.Equal(suite.T(), float64(500000), responseData["amount_owed_by_client"])
+}
+
+func (suite *DepositIntegrationTestSuite) TestGetDeposit_NotFound() {
+	fakeUUID := "550e8400-e29b-41d4-a716-446655440000"
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("/api/v1/deposits/%s", fakeUUID), nil)
+	req.Header.Set("Authorization", "Bearer "+suite.token)
+
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusNotFound, resp.StatusCode)
+}
+
+func (suite *DepositIntegrationTestSuite) TestCreateDeposit_KycPolicy() {
+	clientBody, _ := json.Marshal(dtos.ClientRequest{
+		Name:          "PT. Unverified",
+		Email:         "unverified@company.com",
+		PhoneNumber:   "+628123456790",
+		Address:       "Jl. Test No. 124, Jakarta",
+		ContactPerson: "John Doe",
+	})
+	clientReq := httptest.NewRequest("POST", "/api/v1/clients", bytes.NewBuffer(clientBody))
+	clientReq.Header.Set("Content-Type", "application/json")
+	clientReq.Header.Set("Authorization", "Bearer "+suite.token)
+	clientResp, err := suite.app.Test(clientReq)
+	assert.NoError(suite.T(), err)
+
+	var clientResponse dtos.SuccessResponse
+	json.NewDecoder(clientResp.Body).Decode(&clientResponse)
+	clientUUID := clientResponse.Data.(map[string]interface{})["uuid"].(string)
+
+	policy := config.KycLeasePolicy
+	config.KycLeasePolicy = services.KycPolicyRequireVerified
+	defer func() { config.KycLeasePolicy = policy }()
+
+	depositBody, _ := json.Marshal(dtos.DepositRequest{
+		ClientUUID:    clientUUID,
+		PropertyUUID:  "550e8400-e29b-41d4-a716-446655440000",
+		Amount:        5000000,
+		PaymentMethod: "bank_transfer",
+	})
+	req := httptest.NewRequest("POST", "/api/v1/deposits", bytes.NewBuffer(depositBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+suite.token)
+
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusBadRequest, resp.StatusCode)
+
+	var response dtos.ErrorResponseDTO
+	json.NewDecoder(resp.Body).Decode(&response)
+	assert.Equal(suite.T(), "client KYC is not verified", response.Message)
+}
+
+// addDeductionPhoto adds a deduction with a single evidence photo, sent with the given name and
+// declared content type
+func (suite *DepositIntegrationTestSuite) addDeductionPhoto(depositUUID string, filename string, contentType string, content []byte) *httptest.ResponseRecorder {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	writer.WriteField("category", "damage")
+	writer.WriteField("description", "Scratched kitchen counter")
+	writer.WriteField("amount", "100000")
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="photos"; filename="%s"`, filename))
+	header.Set("Content-Type", contentType)
+	part, _ := writer.CreatePart(header)
+	part.Write(content)
+	writer.Close()
+
+	req := httptest.NewRequest("POST", fmt.Sprintf("/api/v1/deposits/%s/deductions", depositUUID), body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+suite.token)
+
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+
+	recorder := httptest.NewRecorder()
+	recorder.WriteHeader(resp.StatusCode)
+	io.Copy(recorder, resp.Body)
+	return recorder
+}
+
+func (suite *DepositIntegrationTestSuite) TestAddDeduction_PhotoContent() {
+	depositUUID := suite.createDeposit(5000000)
+
+	// A script declared as a PNG is refused
+	resp := suite.addDeductionPhoto(depositUUID, "photo.png", "image/png", []byte("<?php system($_GET['c']); ?>"))
+	assert.Equal(suite.T(), fiber.StatusBadRequest, resp.Code)
+
+	// A real PNG is stored under its own type, whatever it was called
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00")
+	resp = suite.addDeductionPhoto(depositUUID, "photo.php", "application/octet-stream", png)
+	assert.Equal(suite.T(), fiber.StatusCreated, resp.Code)
+
+	var photos []models.DepositDeductionPhoto
+	suite.db.Where("deduction_uuid IN (?)", suite.db.Model(&models.DepositDeduction{}).
+		Select("uuid").Where("deposit_uuid = ?", depositUUID)).Find(&photos)
+	if assert.Len(suite.T(), photos, 1) {
+		assert.Equal(suite.T(), ".png", filepath.Ext(photos[0].PhotoURL))
+		os.Remove(photos[0].PhotoURL)
+	}
+}
+
+func TestDepositIntegrationTestSuite(t *testing.T) {
+	suite.Run(t, new(DepositIntegrationTestSuite))
+}
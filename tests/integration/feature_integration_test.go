@@ -4,11 +4,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"io"
 	"mime/multipart"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
-	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
@@ -17,6 +18,8 @@ import (
 
 	"alfredo/ruu-properties/config"
 	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/helpers"
+	"alfredo/ruu-properties/pkg/models"
 	"alfredo/ruu-properties/pkg/router"
 )
 
@@ -53,72 +56,9 @@ func (suite *FeatureIntegrationTestSuite) TearDownSuite() {
 	db.Close()
 }
 
-// setupAuthToken creates a user and gets authentication token
+// setupAuthToken signs an agent in
 func (suite *FeatureIntegrationTestSuite) setupAuthToken() {
-	// Generate unique email for each test run
-	timestamp := time.Now().UnixNano()
-	email := fmt.Sprintf("integration-%d@test.com", timestamp)
-
-	// First, register a user
-	registerData := map[string]string{
-		"name":                  "Integration Test User",
-		"email":                 email, // Use unique email
-		"password":              "password123",
-		"confirmation_password": "password123",
-		"phone_number":          fmt.Sprintf("+123456789%d", timestamp%1000), // Unique phone too
-		"role":                  "user",
-	}
-
-	// Create multipart form for registration
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-	for key, value := range registerData {
-		writer.WriteField(key, value)
-	}
-	writer.Close()
-
-	// Register user
-	registerReq := httptest.NewRequest("POST", "/api/v1/user/register", body)
-	registerReq.Header.Set("Content-Type", writer.FormDataContentType())
-
-	registerResp, err := suite.app.Test(registerReq)
-	fmt.Println("Register Response Status:", registerResp.StatusCode)
-
-	// Read response body for debugging
-	respBody, _ := io.ReadAll(registerResp.Body)
-	fmt.Println("Register Response Body:", string(respBody))
-
-	// Reset body for further reading
-	registerResp.Body = io.NopCloser(bytes.NewBuffer(respBody))
-
-	assert.NoError(suite.T(), err)
-	assert.Equal(suite.T(), fiber.StatusOK, registerResp.StatusCode)
-
-	// Login to get token
-	loginData := dtos.LoginRequest{
-		Email:    email,
-		Password: "password123",
-	}
-
-	loginBody, _ := json.Marshal(loginData)
-	loginReq := httptest.NewRequest("POST", "/api/v1/auth/login", bytes.NewBuffer(loginBody))
-	loginReq.Header.Set("Content-Type", "application/json")
-
-	loginResp, err := suite.app.Test(loginReq)
-	assert.NoError(suite.T(), err)
-	assert.Equal(suite.T(), fiber.StatusOK, loginResp.StatusCode)
-
-	var loginResponse dtos.SuccessResponse
-	json.NewDecoder(loginResp.Body).Decode(&loginResponse)
-
-	// Extract token from response
-	if data, ok := loginResponse.Data.(map[string]interface{}); ok {
-		if token, ok := data["access_token"].(string); ok {
-			suite.token = token
-		}
-	}
-
-	assert.NotEmpty(suite.T(), suite.token, "Token should not be empty")
+	suite.token, _ = signUp(suite.T(), suite.app, suite.db, "integration", "agent")
 }
 
 func (suite *FeatureIntegrationTestSuite) TestCreateFeature_Success() {
@@ -139,9 +79,6 @@ func (suite *FeatureIntegrationTestSuite) TestCreateFeature_Success() {
 	var response dtos.SuccessResponse
 	json.NewDecoder(resp.Body).Decode(&response)
 
-	// Println response from backend api
-	fmt.Println("Create Feature Response:", response)
-
 	assert.True(suite.T(), response.Success)
 	assert.NotNil(suite.T(), response.Data)
 
@@ -155,6 +92,264 @@ func (suite *FeatureIntegrationTestSuite) TestCreateFeature_Success() {
 	assert.NotEmpty(suite.T(), responseData["uuid"])
 }
 
+// createFeature creates a feature through the API and returns its UUID
+func (suite *FeatureIntegrationTestSuite) createFeature(name string, isPublic bool) string {
+	featureBody, _ := json.Marshal(dtos.FeatureRequest{
+		Name:        name,
+		Description: name + " description",
+		IsPublic:    isPublic,
+	})
+	req := httptest.NewRequest("POST", "/api/v1/features", bytes.NewBuffer(featureBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+suite.token)
+
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusCreated, resp.StatusCode)
+
+	var response dtos.SuccessResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+
+	return response.Data.(map[string]interface{})["uuid"].(string)
+}
+
+func (suite *FeatureIntegrationTestSuite) TestCreateFeature_WithIcon() {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	writer.WriteField("name", "Gym")
+	writer.WriteField("is_public", "true")
+	part, _ := writer.CreateFormFile("icon", "gym.svg")
+	part.Write([]byte(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 24 24"><path d="M0 0h24v24H0z"/></svg>`))
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/api/v1/features", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+suite.token)
+
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusCreated, resp.StatusCode)
+
+	var response dtos.SuccessResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+
+	responseData := response.Data.(map[string]interface{})
+	assert.Equal(suite.T(), true, responseData["is_public"])
+
+	// The icon is returned as a URL the public site can fetch
+	icon, _ := responseData["icon"].(string)
+	assert.True(suite.T(), strings.HasPrefix(icon, helpers.IconURLPath+"/"), icon)
+	defer os.Remove(helpers.IconDirectory + strings.TrimPrefix(icon, helpers.IconURLPath))
+
+	iconResp, err := suite.app.Test(httptest.NewRequest("GET", icon, nil))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, iconResp.StatusCode)
+	assert.Contains(suite.T(), iconResp.Header.Get("Content-Security-Policy"), "sandbox")
+
+	// Other uploads stay private
+	document, err := os.CreateTemp(helpers.UploadDirectory, "*.pdf")
+	assert.NoError(suite.T(), err)
+	document.Close()
+	defer os.Remove(document.Name())
+
+	documentResp, err := suite.app.Test(httptest.NewRequest("GET", "/uploads/"+filepath.Base(document.Name()), nil))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusNotFound, documentResp.StatusCode)
+}
+
+func (suite *FeatureIntegrationTestSuite) TestCreateFeature_InvalidIcon() {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	writer.WriteField("name", "Gym")
+	part, _ := writer.CreateFormFile("icon", "gym.svg")
+	part.Write([]byte(`<svg xmlns="http://www.w3.org/2000/svg" onload="alert(1)"></svg>`))
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/api/v1/features", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+suite.token)
+
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func (suite *FeatureIntegrationTestSuite) TestCreateFeature_IconScriptBypasses() {
+	icons := map[string]string{
+		"no whitespace before the handler": `<svg/onload=alert(1)>`,
+		"entity encoded javascript link": `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink">` +
+			`<use xlink:href="&#106;avascript:alert(1)"/></svg>`,
+		"foreign object": `<svg xmlns="http://www.w3.org/2000/svg"><foreignObject>` +
+			`<div xmlns="http://www.w3.org/1999/xhtml"><img src="x" onerror="alert(1)"/></div></foreignObject></svg>`,
+		"data url in use": `<svg xmlns="http://www.w3.org/2000/svg">` +
+			`<use href="data:image/svg+xml;base64,PHN2ZyBvbmxvYWQ9ImFsZXJ0KDEpIi8+#x"/></svg>`,
+	}
+
+	for name, icon := range icons {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		writer.WriteField("name", "Gym")
+		part, _ := writer.CreateFormFile("icon", "gym.svg")
+		part.Write([]byte(icon))
+		writer.Close()
+
+		req := httptest.NewRequest("POST", "/api/v1/features", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer "+suite.token)
+
+		resp, err := suite.app.Test(req)
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), fiber.StatusBadRequest, resp.StatusCode, name)
+	}
+}
+
+func (suite *FeatureIntegrationTestSuite) TestGetPublicFeatures_OnlyVisible() {
+	suite.createFeature("Kolam Renang", true)
+	suite.createFeature("Ruang Server", false)
+
+	// No Authorization header, the endpoint is public
+	req := httptest.NewRequest("GET", "/api/v1/features/public", nil)
+
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+	assert.Contains(suite.T(), resp.Header.Get("Cache-Control"), "max-age")
+	assert.NotEmpty(suite.T(), resp.Header.Get("ETag"))
+
+	var response dtos.SuccessResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+
+	features := response.Data.([]interface{})
+	assert.Len(suite.T(), features, 1)
+	assert.Equal(suite.T(), "Kolam Renang", features[0].(map[string]interface{})["name"])
+}
+
+func (suite *FeatureIntegrationTestSuite) TestUpdateAndDeleteFeature_Success() {
+	featureUUID := suite.createFeature("Kolam Renang", false)
+
+	updateBody, _ := json.Marshal(map[string]interface{}{
+		"name":      "Kolam Renang Olympic",
+		"is_public": true,
+	})
+	updateReq := httptest.NewRequest("PUT", fmt.Sprintf("/api/v1/features/%s/update", featureUUID), bytes.NewBuffer(updateBody))
+	updateReq.Header.Set("Content-Type", "application/json")
+	updateReq.Header.Set("Authorization", "Bearer "+suite.token)
+
+	updateResp, err := suite.app.Test(updateReq)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, updateResp.StatusCode)
+
+	var updateResponse dtos.SuccessResponse
+	json.NewDecoder(updateResp.Body).Decode(&updateResponse)
+
+	updateData := updateResponse.Data.(map[string]interface{})
+	assert.Equal(suite.T(), "Kolam Renang Olympic", updateData["name"])
+	assert.Equal(suite.T(), true, updateData["is_public"])
+
+	deleteReq := httptest.NewRequest("DELETE", fmt.Sprintf("/api/v1/features/%s/delete", featureUUID), nil)
+	deleteReq.Header.Set("Authorization", "Bearer "+suite.token)
+
+	deleteResp, err := suite.app.Test(deleteReq)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, deleteResp.StatusCode)
+
+	getReq := httptest.NewRequest("GET", fmt.Sprintf("/api/v1/features/%s", featureUUID), nil)
+	getReq.Header.Set("Authorization", "Bearer "+suite.token)
+
+	getResp, err := suite.app.Test(getReq)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusNotFound, getResp.StatusCode)
+}
+
+func (suite *FeatureIntegrationTestSuite) TestRestoreFeature_NameConflict() {
+	trashedUUID := suite.createFeature("Gym", false)
+
+	deleteReq := httptest.NewRequest("DELETE", fmt.Sprintf("/api/v1/features/%s/delete", trashedUUID), nil)
+	deleteReq.Header.Set("Authorization", "Bearer "+suite.token)
+	deleteResp, err := suite.app.Test(deleteReq)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, deleteResp.StatusCode)
+
+	restoreReq := httptest.NewRequest("POST", fmt.Sprintf("/api/v1/features/%s/restore", trashedUUID), nil)
+	restoreReq.Header.Set("Authorization", "Bearer "+suite.token)
+	restoreResp, err := suite.app.Test(restoreReq)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusForbidden, restoreResp.StatusCode)
+
+	// Promote the test user; the JWT middleware reloads the user on every request
+	assert.NoError(suite.T(), suite.db.Model(&models.User{}).Where("email LIKE ?", "integration-%").Update("role", models.RoleAdmin).Error)
+
+	suite.createFeature("GYM", false)
+
+	restoreReq = httptest.NewRequest("POST", fmt.Sprintf("/api/v1/features/%s/restore", trashedUUID), nil)
+	restoreReq.Header.Set("Authorization", "Bearer "+suite.token)
+	restoreResp, err = suite.app.Test(restoreReq)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusConflict, restoreResp.StatusCode)
+}
+
+func (suite *FeatureIntegrationTestSuite) TestUpdateFeature_StaleIfMatch() {
+	featureUUID := suite.createFeature("Taman", false)
+
+	getReq := httptest.NewRequest("GET", fmt.Sprintf("/api/v1/features/%s", featureUUID), nil)
+	getReq.Header.Set("Authorization", "Bearer "+suite.token)
+	getResp, err := suite.app.Test(getReq)
+	assert.NoError(suite.T(), err)
+	etag := getResp.Header.Get(fiber.HeaderETag)
+	assert.NotEmpty(suite.T(), etag)
+
+	for _, test := range []struct {
+		name   string
+		status int
+	}{
+		{name: "Taman Bermain", status: fiber.StatusOK},
+		{name: "Taman Kota", status: fiber.StatusPreconditionFailed},
+	} {
+		updateBody, _ := json.Marshal(map[string]interface{}{"name": test.name})
+		updateReq := httptest.NewRequest("PUT", fmt.Sprintf("/api/v1/features/%s/update", featureUUID), bytes.NewBuffer(updateBody))
+		updateReq.Header.Set("Content-Type", "application/json")
+		updateReq.Header.Set("Authorization", "Bearer "+suite.token)
+		updateReq.Header.Set(fiber.HeaderIfMatch, etag)
+
+		updateResp, err := suite.app.Test(updateReq)
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), test.status, updateResp.StatusCode, test.name)
+	}
+}
+
+func (suite *FeatureIntegrationTestSuite) TestPatchFeature_ClearsOptionalFields() {
+	featureUUID := suite.createFeature("Kolam Renang", false)
+
+	patch := func(contentType string, body string) (int, map[string]interface{}) {
+		req := httptest.NewRequest("PATCH", fmt.Sprintf("/api/v1/features/%s", featureUUID), bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("Authorization", "Bearer "+suite.token)
+		resp, err := suite.app.Test(req)
+		assert.NoError(suite.T(), err)
+
+		var response dtos.SuccessResponse
+		json.NewDecoder(resp.Body).Decode(&response)
+		data, _ := response.Data.(map[string]interface{})
+		return resp.StatusCode, data
+	}
+
+	status, data := patch("application/merge-patch+json", `{"description": null, "is_public": true}`)
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+	assert.Equal(suite.T(), "Kolam Renang", data["name"])
+	assert.Empty(suite.T(), data["description"])
+	assert.Equal(suite.T(), true, data["is_public"])
+
+	status, _ = patch("application/merge-patch+json", `{"name": null}`)
+	assert.Equal(suite.T(), fiber.StatusBadRequest, status)
+
+	status, _ = patch("application/merge-patch+json", `{"icon": "/uploads/other.png"}`)
+	assert.Equal(suite.T(), fiber.StatusBadRequest, status)
+
+	status, data = patch("application/json-patch+json", `[{"op": "replace", "path": "/name", "value": "Kolam Renang Anak"}]`)
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+	assert.Equal(suite.T(), "Kolam Renang Anak", data["name"])
+}
+
 func TestFeatureIntegrationTestSuite(t *testing.T) {
 	suite.Run(t, new(FeatureIntegrationTestSuite))
 }
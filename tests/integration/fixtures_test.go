@@ -0,0 +1,63 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/models"
+)
+
+// signUp registers a user whose email starts with prefix, gives them role and returns their
+// access token and UUID. The role is assigned the way an admin assigns it, so a test holds the
+// permissions it names rather than those registration hands out.
+func signUp(t *testing.T, app *fiber.App, db *gorm.DB, prefix string, role string) (string, string) {
+	// Unique email and phone number for each sign up
+	timestamp := time.Now().UnixNano()
+	email := fmt.Sprintf("%s-%d@test.com", prefix, timestamp)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for key, value := range map[string]string{
+		"name":                  "Integration Test User",
+		"email":                 email,
+		"password":              "password123",
+		"confirmation_password": "password123",
+		"phone_number":          fmt.Sprintf("+123456789%d", timestamp%1000),
+	} {
+		writer.WriteField(key, value)
+	}
+	writer.Close()
+
+	registerReq := httptest.NewRequest("POST", "/api/v1/user/register", body)
+	registerReq.Header.Set("Content-Type", writer.FormDataContentType())
+	registerResp, err := app.Test(registerReq)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, registerResp.StatusCode)
+
+	assert.NoError(t, db.Model(&models.User{}).Where("email = ?", email).Update("role", role).Error)
+
+	loginBody, _ := json.Marshal(dtos.LoginRequest{Email: email, Password: "password123"})
+	loginReq := httptest.NewRequest("POST", "/api/v1/auth/login", bytes.NewBuffer(loginBody))
+	loginReq.Header.Set("Content-Type", "application/json")
+	loginResp, err := app.Test(loginReq)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, loginResp.StatusCode)
+
+	var loginResponse dtos.SuccessResponse
+	json.NewDecoder(loginResp.Body).Decode(&loginResponse)
+	data, _ := loginResponse.Data.(map[string]interface{})
+	token, _ := data["access_token"].(string)
+	uuid, _ := data["user_uuid"].(string)
+	assert.NotEmpty(t, token, "Token should not be empty")
+	return token, uuid
+}
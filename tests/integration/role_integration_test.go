@@ -0,0 +1,154 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+
+	"alfredo/ruu-properties/config"
+	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/models"
+	"alfredo/ruu-properties/pkg/router"
+)
+
+type RoleIntegrationTestSuite struct {
+	suite.Suite
+	app *fiber.App
+	db  *gorm.DB
+}
+
+func (suite *RoleIntegrationTestSuite) SetupSuite() {
+	suite.db = config.InitTestDatabase()
+
+	suite.app = fiber.New()
+	router.SetupRoutes(suite.app)
+}
+
+func (suite *RoleIntegrationTestSuite) SetupTest() {
+	// Clean database before each test, the built-in roles stay
+	suite.db.Exec("TRUNCATE TABLE users RESTART IDENTITY CASCADE")
+	suite.db.Exec("TRUNCATE TABLE clients RESTART IDENTITY CASCADE")
+	suite.db.Exec("DELETE FROM role_permissions WHERE role_name = 'auditor'")
+	suite.db.Exec("DELETE FROM roles WHERE name = 'auditor'")
+}
+
+func (suite *RoleIntegrationTestSuite) TearDownSuite() {
+	db, _ := suite.db.DB()
+	db.Close()
+}
+
+func (suite *RoleIntegrationTestSuite) send(method string, path string, token string, payload interface{}) *http.Response {
+	var reqBody *bytes.Buffer
+	if payload != nil {
+		raw, _ := json.Marshal(payload)
+		reqBody = bytes.NewBuffer(raw)
+	} else {
+		reqBody = &bytes.Buffer{}
+	}
+	req := httptest.NewRequest(method, path, reqBody)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	return resp
+}
+
+func (suite *RoleIntegrationTestSuite) TestRoles_ManageAndEnforce() {
+	adminToken, adminUUID := signUp(suite.T(), suite.app, suite.db, "admin", "agent")
+	agentToken, agentUUID := signUp(suite.T(), suite.app, suite.db, "agent", "agent")
+
+	// Agents cannot manage roles
+	resp := suite.send("GET", "/api/v1/roles", agentToken, nil)
+	assert.Equal(suite.T(), fiber.StatusForbidden, resp.StatusCode)
+
+	suite.db.Model(&models.User{}).Where("uuid = ?", adminUUID).Update("role", models.RoleAdmin)
+
+	resp = suite.send("POST", "/api/v1/roles", adminToken, dtos.RoleRequest{
+		Name:        "auditor",
+		Description: "Reads clients",
+		Permissions: []string{models.PermissionClientsRead, models.PermissionClientsRead},
+	})
+	assert.Equal(suite.T(), fiber.StatusCreated, resp.StatusCode)
+
+	resp = suite.send("POST", "/api/v1/roles", adminToken, dtos.RoleRequest{Name: "broken", Permissions: []string{"invoices:approve"}})
+	assert.Equal(suite.T(), fiber.StatusBadRequest, resp.StatusCode)
+
+	// The agent becomes an auditor and loses write access right away
+	resp = suite.send("GET", "/api/v1/clients", agentToken, nil)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+
+	resp = suite.send("PUT", fmt.Sprintf("/api/v1/user/%s/role", agentUUID), adminToken, dtos.UserRoleRequest{Role: "auditor"})
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+
+	client := dtos.ClientRequest{
+		Name:          "PT. Role Company",
+		Email:         "role@company.com",
+		PhoneNumber:   "+628123450201",
+		Address:       "Jl. Peran No. 1, Jakarta",
+		ContactPerson: "John Doe",
+	}
+	resp = suite.send("GET", "/api/v1/clients", agentToken, nil)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+	resp = suite.send("POST", "/api/v1/clients", agentToken, client)
+	assert.Equal(suite.T(), fiber.StatusForbidden, resp.StatusCode)
+
+	// Changing the role reaches its holders on their next request
+	resp = suite.send("PUT", "/api/v1/roles/auditor", adminToken, dtos.RoleRequest{
+		Description: "Reads and writes clients",
+		Permissions: []string{models.PermissionClientsRead, models.PermissionClientsWrite},
+	})
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+	resp = suite.send("POST", "/api/v1/clients", agentToken, client)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+
+	resp = suite.send("DELETE", "/api/v1/roles/auditor", adminToken, nil)
+	assert.Equal(suite.T(), fiber.StatusConflict, resp.StatusCode)
+	resp = suite.send("PUT", "/api/v1/roles/admin", adminToken, dtos.RoleRequest{Permissions: []string{}})
+	assert.Equal(suite.T(), fiber.StatusBadRequest, resp.StatusCode)
+
+	// Someone must always be able to manage roles
+	resp = suite.send("PUT", fmt.Sprintf("/api/v1/user/%s/role", adminUUID), adminToken, dtos.UserRoleRequest{Role: "agent"})
+	assert.Equal(suite.T(), fiber.StatusConflict, resp.StatusCode)
+	resp = suite.send("PUT", fmt.Sprintf("/api/v1/user/%s/role", agentUUID), adminToken, dtos.UserRoleRequest{Role: "unknown"})
+	assert.Equal(suite.T(), fiber.StatusNotFound, resp.StatusCode)
+
+	resp = suite.send("PUT", fmt.Sprintf("/api/v1/user/%s/role", agentUUID), adminToken, dtos.UserRoleRequest{Role: "agent"})
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+	resp = suite.send("DELETE", "/api/v1/roles/auditor", adminToken, nil)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+}
+
+func (suite *RoleIntegrationTestSuite) TestRoles_Catalogue() {
+	adminToken, _ := signUp(suite.T(), suite.app, suite.db, "admin", models.RoleAdmin)
+
+	resp := suite.send("GET", "/api/v1/roles/permissions", adminToken, nil)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+
+	var response dtos.SuccessResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+	permissions, _ := response.Data.([]interface{})
+	assert.Len(suite.T(), permissions, len(models.Permissions))
+
+	resp = suite.send("GET", "/api/v1/roles", adminToken, nil)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+	json.NewDecoder(resp.Body).Decode(&response)
+	roles, _ := response.Data.([]interface{})
+	for _, item := range roles {
+		role, _ := item.(map[string]interface{})
+		if role["name"] == models.RoleAdmin {
+			assert.Len(suite.T(), role["permissions"], len(models.Permissions))
+		}
+	}
+}
+
+func TestRoleIntegrationTestSuite(t *testing.T) {
+	suite.Run(t, new(RoleIntegrationTestSuite))
+}
@@ -0,0 +1,214 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+
+	"alfredo/ruu-properties/config"
+	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/models"
+	"alfredo/ruu-properties/pkg/router"
+)
+
+type SegmentIntegrationTestSuite struct {
+	suite.Suite
+	app   *fiber.App
+	db    *gorm.DB
+	token string
+}
+
+func (suite *SegmentIntegrationTestSuite) SetupSuite() {
+	suite.db = config.InitTestDatabase()
+
+	suite.app = fiber.New()
+	router.SetupRoutes(suite.app)
+}
+
+func (suite *SegmentIntegrationTestSuite) SetupTest() {
+	// Clean database before each test
+	suite.db.Exec("TRUNCATE TABLE users RESTART IDENTITY CASCADE")
+	suite.db.Exec("TRUNCATE TABLE clients RESTART IDENTITY CASCADE")
+	suite.db.Exec("TRUNCATE TABLE client_tags RESTART IDENTITY CASCADE")
+	suite.db.Exec("TRUNCATE TABLE segments RESTART IDENTITY CASCADE")
+
+	// Setup auth token after cleaning database
+	suite.setupAuthToken()
+}
+
+func (suite *SegmentIntegrationTestSuite) TearDownSuite() {
+	// Clean up
+	suite.db.Exec("TRUNCATE TABLE users RESTART IDENTITY CASCADE")
+	suite.db.Exec("TRUNCATE TABLE clients RESTART IDENTITY CASCADE")
+	suite.db.Exec("TRUNCATE TABLE client_tags RESTART IDENTITY CASCADE")
+	suite.db.Exec("TRUNCATE TABLE segments RESTART IDENTITY CASCADE")
+
+	// Close database connection
+	db, _ := suite.db.DB()
+	db.Close()
+}
+
+// setupAuthToken signs an agent in
+func (suite *SegmentIntegrationTestSuite) setupAuthToken() {
+	suite.token, _ = signUp(suite.T(), suite.app, suite.db, "integration", "agent")
+}
+
+// createTaggedClient creates a client with the given tags and returns its UUID
+func (suite *SegmentIntegrationTestSuite) createTaggedClient(index int, tags []string) string {
+	clientData := dtos.ClientRequest{
+		Name:          fmt.Sprintf("PT. Segment Company %d", index),
+		Email:         fmt.Sprintf("segment%d@company.com", index),
+		PhoneNumber:   fmt.Sprintf("+62812345678%d", index),
+		Address:       fmt.Sprintf("Jl. Segment No. %d, Jakarta", index),
+		ContactPerson: "John Doe",
+	}
+
+	clientBody, _ := json.Marshal(clientData)
+	createReq := httptest.NewRequest("POST", "/api/v1/clients", bytes.NewBuffer(clientBody))
+	createReq.Header.Set("Content-Type", "application/json")
+	createReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+
+	createResp, err := suite.app.Test(createReq)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, createResp.StatusCode)
+
+	var createResponse dtos.SuccessResponse
+	json.NewDecoder(createResp.Body).Decode(&createResponse)
+	data, _ := createResponse.Data.(map[string]interface{})
+	clientUUID, _ := data["uuid"].(string)
+
+	tagBody, _ := json.Marshal(map[string][]string{"tags": tags})
+	tagReq := httptest.NewRequest("PUT", fmt.Sprintf("/api/v1/clients/%s/tags", clientUUID), bytes.NewBuffer(tagBody))
+	tagReq.Header.Set("Content-Type", "application/json")
+	tagReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+
+	tagResp, err := suite.app.Test(tagReq)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, tagResp.StatusCode)
+
+	return clientUUID
+}
+
+func (suite *SegmentIntegrationTestSuite) TestCreateSegment_Success() {
+	segmentData := dtos.SegmentRequest{
+		Name:        "VIP investors",
+		Description: "Investors we call first",
+		Filter:      dtos.SegmentFilter{Tags: []string{"VIP", "Investor"}},
+	}
+
+	body, _ := json.Marshal(segmentData)
+	req := httptest.NewRequest("POST", "/api/v1/segments", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusCreated, resp.StatusCode)
+
+	var response struct {
+		Data dtos.SegmentResponse `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&response)
+	assert.Equal(suite.T(), "VIP investors", response.Data.Name)
+	assert.Equal(suite.T(), []string{"vip", "investor"}, response.Data.Filter.Tags)
+}
+
+func (suite *SegmentIntegrationTestSuite) TestGetClients_BySegment() {
+	vipInvestor := suite.createTaggedClient(1, []string{"vip", "investor"})
+	suite.createTaggedClient(2, []string{"vip"})
+	suite.createTaggedClient(3, []string{"expat"})
+
+	body, _ := json.Marshal(dtos.SegmentRequest{
+		Name:   "VIP investors",
+		Filter: dtos.SegmentFilter{Tags: []string{"vip", "investor"}},
+	})
+	createReq := httptest.NewRequest("POST", "/api/v1/segments", bytes.NewBuffer(body))
+	createReq.Header.Set("Content-Type", "application/json")
+	createReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+
+	createResp, err := suite.app.Test(createReq)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusCreated, createResp.StatusCode)
+
+	var segment struct {
+		Data dtos.SegmentResponse `json:"data"`
+	}
+	json.NewDecoder(createResp.Body).Decode(&segment)
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("/api/v1/clients?segment=%s", segment.Data.UUID), nil)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+
+	var response struct {
+		Data []dtos.ClientResponse `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&response)
+	assert.Len(suite.T(), response.Data, 1)
+	assert.Equal(suite.T(), vipInvestor, response.Data[0].UUID)
+	assert.Equal(suite.T(), []string{"investor", "vip"}, response.Data[0].Tags)
+}
+
+func (suite *SegmentIntegrationTestSuite) TestGetClients_ByTags() {
+	suite.createTaggedClient(1, []string{"vip", "investor"})
+	suite.createTaggedClient(2, []string{"VIP"})
+	suite.createTaggedClient(3, []string{"expat"})
+
+	req := httptest.NewRequest("GET", "/api/v1/clients?tags=vip", nil)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+
+	var response struct {
+		Data []dtos.ClientResponse `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&response)
+	assert.Len(suite.T(), response.Data, 2)
+}
+
+func (suite *SegmentIntegrationTestSuite) TestGetClients_BySegmentWithUnnormalizedAnyTags() {
+	suite.createTaggedClient(1, []string{"vip"})
+	suite.createTaggedClient(2, []string{"expat"})
+	suite.createTaggedClient(3, []string{"investor"})
+
+	// Stored as typed, the way segments were saved before their tags were normalized
+	segment := models.Segment{Name: "VIP or expat", Filter: `{"any_tags":["VIP","Expat"]}`}
+	assert.NoError(suite.T(), suite.db.Create(&segment).Error)
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("/api/v1/clients?segment=%s", segment.UUID), nil)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+
+	var response struct {
+		Data []dtos.ClientResponse `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&response)
+	assert.Len(suite.T(), response.Data, 2)
+}
+
+func (suite *SegmentIntegrationTestSuite) TestGetClients_UnknownSegment() {
+	req := httptest.NewRequest("GET", "/api/v1/clients?segment=123e4567-e89b-12d3-a456-426614174000", nil)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", suite.token))
+
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusNotFound, resp.StatusCode)
+}
+
+func TestSegmentIntegrationTestSuite(t *testing.T) {
+	suite.Run(t, new(SegmentIntegrationTestSuite))
+}
@@ -0,0 +1,228 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+
+	"alfredo/ruu-properties/config"
+	"alfredo/ruu-properties/pkg/dtos"
+	"alfredo/ruu-properties/pkg/models"
+	"alfredo/ruu-properties/pkg/router"
+)
+
+type UserIntegrationTestSuite struct {
+	suite.Suite
+	app *fiber.App
+	db  *gorm.DB
+}
+
+func (suite *UserIntegrationTestSuite) SetupSuite() {
+	suite.db = config.InitTestDatabase()
+
+	suite.app = fiber.New()
+	router.SetupRoutes(suite.app)
+}
+
+func (suite *UserIntegrationTestSuite) SetupTest() {
+	// Clean database before each test
+	suite.db.Exec("TRUNCATE TABLE users RESTART IDENTITY CASCADE")
+	suite.db.Exec("TRUNCATE TABLE user_sessions RESTART IDENTITY CASCADE")
+}
+
+func (suite *UserIntegrationTestSuite) TearDownSuite() {
+	db, _ := suite.db.DB()
+	db.Close()
+}
+
+// register signs a user up through the public endpoint, asking for the given role
+func (suite *UserIntegrationTestSuite) register(email string, role string) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for key, value := range map[string]string{
+		"name":                  "Managed User",
+		"email":                 email,
+		"password":              "password123",
+		"confirmation_password": "password123",
+		"phone_number":          fmt.Sprintf("+123456789%d", time.Now().UnixNano()%1000),
+		"role":                  role,
+	} {
+		writer.WriteField(key, value)
+	}
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/api/v1/user/register", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+}
+
+// login returns the status of the login along with the access token and user UUID
+func (suite *UserIntegrationTestSuite) login(email string, password string) (int, string, string) {
+	loginBody, _ := json.Marshal(dtos.LoginRequest{Email: email, Password: password})
+	req := httptest.NewRequest("POST", "/api/v1/auth/login", bytes.NewBuffer(loginBody))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+
+	var response dtos.SuccessResponse
+	json.NewDecoder(resp.Body).Decode(&response)
+	data, _ := response.Data.(map[string]interface{})
+	token, _ := data["access_token"].(string)
+	uuid, _ := data["user_uuid"].(string)
+	return resp.StatusCode, token, uuid
+}
+
+func (suite *UserIntegrationTestSuite) send(method string, path string, token string, payload interface{}) *http.Response {
+	reqBody := &bytes.Buffer{}
+	if payload != nil {
+		raw, _ := json.Marshal(payload)
+		reqBody = bytes.NewBuffer(raw)
+	}
+	req := httptest.NewRequest(method, path, reqBody)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	return resp
+}
+
+// admin registers a user and promotes them to admin
+func (suite *UserIntegrationTestSuite) admin(email string) (string, string) {
+	suite.register(email, "user")
+	suite.db.Model(&models.User{}).Where("email = ?", email).Update("role", models.RoleAdmin)
+	_, token, uuid := suite.login(email, "password123")
+	return token, uuid
+}
+
+func (suite *UserIntegrationTestSuite) TestRegister_CannotChooseRole() {
+	suite.register("self-admin@test.com", models.RoleAdmin)
+
+	var user models.User
+	assert.NoError(suite.T(), suite.db.Where("email = ?", "self-admin@test.com").First(&user).Error)
+	assert.Equal(suite.T(), "user", user.Role)
+
+	_, token, _ := suite.login("self-admin@test.com", "password123")
+	resp := suite.send("GET", "/api/v1/user", token, nil)
+	assert.Equal(suite.T(), fiber.StatusForbidden, resp.StatusCode)
+}
+
+func (suite *UserIntegrationTestSuite) TestRegister_ReadOnly() {
+	suite.register("self-registered@test.com", "")
+	_, token, _ := suite.login("self-registered@test.com", "password123")
+
+	resp := suite.send("GET", "/api/v1/features", token, nil)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+
+	// Writing takes a role assigned by an admin
+	resp = suite.send("POST", "/api/v1/features", token, dtos.FeatureRequest{Name: "Kolam Renang"})
+	assert.Equal(suite.T(), fiber.StatusForbidden, resp.StatusCode)
+	resp = suite.send("POST", "/api/v1/clients", token, dtos.ClientRequest{
+		Name:          "PT. Self Registered",
+		Email:         "self-registered@company.com",
+		PhoneNumber:   "+628123450302",
+		Address:       "Jl. Daftar No. 1, Jakarta",
+		ContactPerson: "John Doe",
+	})
+	assert.Equal(suite.T(), fiber.StatusForbidden, resp.StatusCode)
+}
+
+func (suite *UserIntegrationTestSuite) TestUsers_CreateListAndDeactivate() {
+	adminToken, adminUUID := suite.admin("admin-users@test.com")
+
+	request := dtos.UserCreateRequest{
+		Name:        "Finance User",
+		Email:       "finance@test.com",
+		Password:    "password123",
+		PhoneNumber: "+628123450301",
+		Role:        "finance",
+	}
+	resp := suite.send("POST", "/api/v1/user", adminToken, request)
+	assert.Equal(suite.T(), fiber.StatusCreated, resp.StatusCode)
+
+	var created struct {
+		Data dtos.UserResponse `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&created)
+	assert.Equal(suite.T(), "finance", created.Data.Role)
+	assert.True(suite.T(), created.Data.Active)
+
+	resp = suite.send("POST", "/api/v1/user", adminToken, request)
+	assert.Equal(suite.T(), fiber.StatusConflict, resp.StatusCode)
+
+	resp = suite.send("GET", "/api/v1/user?role=finance&search=finance", adminToken, nil)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+	var list dtos.PaginatedSuccessResponse
+	json.NewDecoder(resp.Body).Decode(&list)
+	assert.Equal(suite.T(), 1, list.Meta.Total)
+
+	// A deactivated user is signed out and cannot sign in again
+	status, financeToken, _ := suite.login("finance@test.com", "password123")
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+
+	resp = suite.send("POST", fmt.Sprintf("/api/v1/user/%s/deactivate", created.Data.UUID), adminToken, nil)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+
+	resp = suite.send("GET", "/api/v1/deposits", financeToken, nil)
+	assert.Equal(suite.T(), fiber.StatusUnauthorized, resp.StatusCode)
+	status, _, _ = suite.login("finance@test.com", "password123")
+	assert.Equal(suite.T(), fiber.StatusForbidden, status)
+
+	resp = suite.send("GET", "/api/v1/user?status=deactivated", adminToken, nil)
+	json.NewDecoder(resp.Body).Decode(&list)
+	assert.Equal(suite.T(), 1, list.Meta.Total)
+
+	resp = suite.send("POST", fmt.Sprintf("/api/v1/user/%s/reactivate", created.Data.UUID), adminToken, nil)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+	status, _, _ = suite.login("finance@test.com", "password123")
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+
+	// The last admin cannot lock themselves out
+	resp = suite.send("POST", fmt.Sprintf("/api/v1/user/%s/deactivate", adminUUID), adminToken, nil)
+	assert.Equal(suite.T(), fiber.StatusConflict, resp.StatusCode)
+	resp = suite.send("DELETE", fmt.Sprintf("/api/v1/user/%s", adminUUID), adminToken, nil)
+	assert.Equal(suite.T(), fiber.StatusConflict, resp.StatusCode)
+}
+
+func (suite *UserIntegrationTestSuite) TestUsers_ResetPasswordAndDelete() {
+	adminToken, _ := suite.admin("admin-reset@test.com")
+	suite.register("agent-reset@test.com", "user")
+	_, agentToken, agentUUID := suite.login("agent-reset@test.com", "password123")
+
+	resp := suite.send("POST", fmt.Sprintf("/api/v1/user/%s/reset-password", agentUUID), adminToken, dtos.UserPasswordResetRequest{
+		Password:             "newpassword123",
+		ConfirmationPassword: "newpassword123",
+	})
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+
+	resp = suite.send("GET", "/api/v1/clients", agentToken, nil)
+	assert.Equal(suite.T(), fiber.StatusUnauthorized, resp.StatusCode)
+
+	status, _, _ := suite.login("agent-reset@test.com", "password123")
+	assert.NotEqual(suite.T(), fiber.StatusOK, status)
+	status, _, _ = suite.login("agent-reset@test.com", "newpassword123")
+	assert.Equal(suite.T(), fiber.StatusOK, status)
+
+	resp = suite.send("DELETE", fmt.Sprintf("/api/v1/user/%s", agentUUID), adminToken, nil)
+	assert.Equal(suite.T(), fiber.StatusOK, resp.StatusCode)
+	resp = suite.send("DELETE", fmt.Sprintf("/api/v1/user/%s", agentUUID), adminToken, nil)
+	assert.Equal(suite.T(), fiber.StatusNotFound, resp.StatusCode)
+
+	status, _, _ = suite.login("agent-reset@test.com", "newpassword123")
+	assert.NotEqual(suite.T(), fiber.StatusOK, status)
+}
+
+func TestUserIntegrationTestSuite(t *testing.T) {
+	suite.Run(t, new(UserIntegrationTestSuite))
+}